@@ -61,6 +61,7 @@ func initFlags() {
 	}
 	performanceFlags = []cli.Flag{
 		flags.CacheFlag,
+		flags.DBBackendFlag,
 	}
 	networkingFlags = []cli.Flag{
 		flags.BootnodesFlag,
@@ -88,6 +89,7 @@ func initFlags() {
 		flags.TxPoolAccountQueueFlag,
 		flags.TxPoolGlobalQueueFlag,
 		flags.TxPoolLifetimeFlag,
+		flags.TxPoolPrivateLifetimeFlag,
 	}
 	operaFlags = []cli.Flag{
 		flags.IdentityFlag,
@@ -103,7 +105,13 @@ func initFlags() {
 		flags.ValidatorIDFlag,
 		flags.ValidatorPubkeyFlag,
 		flags.ValidatorPasswordFlag,
+		flags.ValidatorSignerFlag,
+		flags.ValidatorSignerSecretFlag,
+		flags.ValidatorSignerTLSCertFlag,
+		flags.ValidatorSignerTLSKeyFlag,
+		flags.ValidatorSignerTLSCAFlag,
 		flags.ModeFlag,
+		flags.SyncModeFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -126,6 +134,9 @@ func initFlags() {
 		flags.RPCGlobalEVMTimeoutFlag,
 		flags.RPCGlobalTxFeeCapFlag,
 		flags.RPCGlobalTimeoutFlag,
+		flags.TraceIndexFlag,
+		flags.ArchiveVisibleBlocksFlag,
+		flags.ArchiveVisibleEpochsFlag,
 	}
 
 	metricsFlags = []cli.Flag{
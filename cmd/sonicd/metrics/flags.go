@@ -2,13 +2,19 @@ package metrics
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
 	"github.com/ethereum/go-ethereum/metrics/influxdb"
+	ethprometheus "github.com/ethereum/go-ethereum/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gopkg.in/urfave/cli.v1"
-	"strings"
-	"time"
+
+	"github.com/Fantom-foundation/go-opera/gossip/sonicmetrics"
 )
 
 var (
@@ -144,12 +150,37 @@ func SetupMetrics(ctx *cli.Context) error {
 		if ctx.GlobalIsSet(MetricsHTTPFlag.Name) {
 			address := fmt.Sprintf("%s:%d", ctx.GlobalString(MetricsHTTPFlag.Name), ctx.GlobalInt(MetricsPortFlag.Name))
 			log.Info("Enabling stand-alone metrics HTTP endpoint", "address", address)
-			exp.Setup(address)
+			setupHTTP(address)
 		}
 	}
 	return nil
 }
 
+// setupHTTP starts a dedicated metrics server at the given address. Along with the go-ethereum
+// endpoints, it serves the Prometheus-native metrics in the OpenMetrics format at /metrics.
+func setupHTTP(address string) {
+	m := http.NewServeMux()
+	m.Handle("/debug/metrics", exp.ExpHandler(metrics.DefaultRegistry))
+	m.Handle("/debug/metrics/prometheus", ethprometheus.Handler(metrics.DefaultRegistry))
+	m.Handle("/metrics", promhttp.HandlerFor(sonicmetrics.Gatherer(), promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+		ErrorLog:          promLogger{},
+	}))
+	log.Info("Starting metrics server", "addr", fmt.Sprintf("http://%s/metrics", address))
+	go func() {
+		if err := http.ListenAndServe(address, m); err != nil {
+			log.Error("Failure in running metrics server", "err", err)
+		}
+	}()
+}
+
+// promLogger logs the errors of the Prometheus handler
+type promLogger struct{}
+
+func (promLogger) Println(v ...interface{}) {
+	log.Warn("Failed to gather metrics", "err", fmt.Sprint(v...))
+}
+
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
 	tagsMap := map[string]string{}
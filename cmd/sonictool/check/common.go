@@ -24,9 +24,11 @@ func createGdb(dataDir string, cacheRatio cachescale.Func, archive carmen.Archiv
 		return nil, nil, fmt.Errorf("unable to validate: datadir does not contain carmen")
 	}
 
-	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBCacheConfig{
-		Cache:   cacheRatio.U64(480 * opt.MiB),
-		Fdlimit: 100,
+	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: 100,
+		},
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to make DB producer: %v", err)
@@ -23,9 +23,11 @@ func compactDbs(ctx *cli.Context) error {
 		return err
 	}
 	chaindataDir := filepath.Join(dataDir, "chaindata")
-	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBCacheConfig{
-		Cache:   cacheRatio.U64(480 * opt.MiB),
-		Fdlimit: 100,
+	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: 100,
+		},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to make DB producer: %v", err)
@@ -55,9 +55,11 @@ func MakeDbProducer(chaindataDir string, cacheRatio cachescale.Func) (kvdb.FullD
 	if err != nil {
 		return nil, fmt.Errorf("failed to create datadir directory: %w", err)
 	}
-	return integration.GetDbProducer(chaindataDir, integration.DBCacheConfig{
-		Cache:   cacheRatio.U64(480 * opt.MiB),
-		Fdlimit: makeDatabaseHandles(),
+	return integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: makeDatabaseHandles(),
+		},
 	})
 }
 
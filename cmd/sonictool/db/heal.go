@@ -21,10 +21,16 @@ import (
 )
 
 func HealChaindata(chaindataDir string, cacheRatio cachescale.Func, cfg *config.Config, lastCarmenBlock idx.Block) (idx.Block, error) {
-	producer := &DummyScopedProducer{integration.GetRawDbProducer(chaindataDir, integration.DBCacheConfig{
-		Cache:   cacheRatio.U64(480 * opt.MiB),
-		Fdlimit: makeDatabaseHandles(),
-	})}
+	rawProducer, err := integration.GetRawDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: makeDatabaseHandles(),
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to make DB producer: %w", err)
+	}
+	producer := &DummyScopedProducer{rawProducer}
 	defer producer.Close()
 
 	log.Info("Healing gossip db...")
@@ -7,6 +7,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/cmd/sonictool/genesis"
 	"github.com/Fantom-foundation/go-opera/config/flags"
 	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"gopkg.in/urfave/cli.v1"
@@ -17,6 +18,11 @@ import (
 	"syscall"
 )
 
+var SinceEpochFlag = cli.Uint64Flag{
+	Name:  "since-epoch",
+	Usage: "Export only a delta with epochs and blocks after the given epoch",
+}
+
 func exportGenesis(ctx *cli.Context) error {
 	dataDir := ctx.GlobalString(flags.DataDirFlag.Name)
 	if dataDir == "" {
@@ -39,9 +45,11 @@ func exportGenesis(ctx *cli.Context) error {
 		return err
 	}
 	chaindataDir := filepath.Join(dataDir, "chaindata")
-	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBCacheConfig{
-		Cache:   cacheRatio.U64(480 * opt.MiB),
-		Fdlimit: 100,
+	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: 100,
+		},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to make DB producer: %v", err)
@@ -64,5 +72,8 @@ func exportGenesis(ctx *cli.Context) error {
 	_ = os.RemoveAll(tmpPath)
 	defer os.RemoveAll(tmpPath)
 
+	if ctx.IsSet(SinceEpochFlag.Name) {
+		return genesis.ExportGenesisDelta(cancelCtx, gdb, idx.Epoch(ctx.Uint64(SinceEpochFlag.Name)), fh, tmpPath)
+	}
 	return genesis.ExportGenesis(cancelCtx, gdb, !forValidatorMode, fh, tmpPath)
 }
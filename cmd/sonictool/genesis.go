@@ -65,6 +65,9 @@ func gfileGenesisImport(ctx *cli.Context) error {
 			return fmt.Errorf("genesis file check failed: %w", err)
 		}
 	}
+	if _, err := genesisStore.Genesis().DeltaSection.GetBase(); err == nil {
+		return genesis.ImportGenesisDelta(genesisStore, dataDir, validatorMode, cacheRatio)
+	}
 	return genesis.ImportGenesisStore(genesisStore, dataDir, validatorMode, cacheRatio)
 }
 
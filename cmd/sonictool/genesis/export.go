@@ -52,7 +52,7 @@ func ExportGenesis(ctx context.Context, gdb *gossip.Store, includeArchive bool,
 	if err := writer.Start(header, "brs", tmpPath); err != nil {
 		return err
 	}
-	if err := exportBlocksSection(ctx, gdb, writer, from, to); err != nil {
+	if err := exportBlocksSection(ctx, gdb, writer, getEpochBlock(from, gdb), getEpochBlock(to, gdb)); err != nil {
 		return err
 	}
 
@@ -78,6 +78,164 @@ func ExportGenesis(ctx context.Context, gdb *gossip.Store, includeArchive bool,
 	return nil
 }
 
+// ExportGenesisDelta writes a delta genesis, which contains epochs and blocks after the given epoch,
+// the LLR votes confirming them, and the state changes of the blocks read from the archive.
+// The delta is applicable on top of a datadir with the block preceding the epoch sinceEpoch+1.
+func ExportGenesisDelta(ctx context.Context, gdb *gossip.Store, sinceEpoch idx.Epoch, out *os.File, tmpPath string) error {
+	header := genesis.Header{
+		GenesisID:   *gdb.GetGenesisID(),
+		NetworkID:   gdb.GetEpochState().Rules.NetworkID,
+		NetworkName: gdb.GetEpochState().Rules.Name,
+	}
+	to := gdb.GetEpoch()
+	if sinceEpoch >= to {
+		return fmt.Errorf("the epoch %d is not below the current epoch %d", sinceEpoch, to)
+	}
+	baseBlock := getEpochBlock(sinceEpoch, gdb)
+	br := gdb.GetBlock(baseBlock)
+	if br == nil {
+		return fmt.Errorf("the base block %d of epoch %d is missing in gdb", baseBlock, sinceEpoch)
+	}
+
+	// base
+	writer := newUnitWriter(out)
+	if err := writer.Start(header, genesisstore.DeltaSectionName, tmpPath); err != nil {
+		return err
+	}
+	if err := exportDeltaSection(writer, genesis.DeltaBase{
+		Epoch:   sinceEpoch,
+		Block:   baseBlock,
+		Atropos: br.Atropos,
+		Root:    br.Root,
+	}); err != nil {
+		return err
+	}
+
+	// epochs
+	writer = newUnitWriter(out)
+	if err := writer.Start(header, "ers", tmpPath); err != nil {
+		return err
+	}
+	if err := exportEpochsSection(ctx, gdb, writer, sinceEpoch+1, to); err != nil {
+		return err
+	}
+
+	// blocks
+	topBlock := getEpochBlock(to, gdb)
+	writer = newUnitWriter(out)
+	if err := writer.Start(header, "brs", tmpPath); err != nil {
+		return err
+	}
+	if err := exportBlocksSection(ctx, gdb, writer, baseBlock+1, topBlock); err != nil {
+		return err
+	}
+
+	// LLR votes
+	writer = newUnitWriter(out)
+	if err := writer.Start(header, genesisstore.EpochVotesSectionName, tmpPath); err != nil {
+		return err
+	}
+	if err := exportEpochVotesSection(ctx, gdb, writer, sinceEpoch+1, to); err != nil {
+		return err
+	}
+	writer = newUnitWriter(out)
+	if err := writer.Start(header, genesisstore.BlockVotesSectionName, tmpPath); err != nil {
+		return err
+	}
+	if err := exportBlockVotesSection(ctx, gdb, writer, sinceEpoch, to-1); err != nil {
+		return err
+	}
+
+	// state changes
+	writer = newUnitWriter(out)
+	if err := writer.Start(header, genesisstore.StateDiffsSectionName, tmpPath); err != nil {
+		return err
+	}
+	return exportStateDiffsSection(ctx, gdb, writer, baseBlock+1, topBlock)
+}
+
+func exportDeltaSection(writer *unitWriter, base genesis.DeltaBase) error {
+	log.Info("Exporting delta base", "epoch", base.Epoch, "block", base.Block)
+	b, _ := rlp.EncodeToBytes(base)
+	if _, err := writer.Write(b); err != nil {
+		return err
+	}
+	deltaHash, err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("- Delta hash: %v \n", deltaHash.String())
+	return nil
+}
+
+func exportEpochVotesSection(ctx context.Context, gdb *gossip.Store, writer *unitWriter, from, to idx.Epoch) error {
+	log.Info("Exporting epoch votes", "from", from, "to", to)
+	var err error
+	for i := from; i <= to && err == nil; i++ {
+		gdb.IterateEpochVotesRLP(i.Bytes(), func(ev rlp.RawValue) bool {
+			_, err = writer.Write(ev)
+			return err == nil
+		})
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	votesHash, err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	log.Info("Exported epoch votes")
+	fmt.Printf("- Epoch votes hash: %v \n", votesHash.String())
+	return nil
+}
+
+func exportBlockVotesSection(ctx context.Context, gdb *gossip.Store, writer *unitWriter, from, to idx.Epoch) error {
+	log.Info("Exporting block votes", "from", from, "to", to)
+	var err error
+	gdb.IterateOverlappingBlockVotesRLP(from.Bytes(), func(key []byte, bvs rlp.RawValue) bool {
+		if idx.BytesToEpoch(key[:4]) > to {
+			return false
+		}
+		if _, err = writer.Write(bvs); err != nil {
+			return false
+		}
+		err = ctx.Err()
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	votesHash, err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	log.Info("Exported block votes")
+	fmt.Printf("- Block votes hash: %v \n", votesHash.String())
+	return nil
+}
+
+func exportStateDiffsSection(ctx context.Context, gdb *gossip.Store, writer *unitWriter, fromBlock, toBlock idx.Block) error {
+	log.Info("Exporting state changes", "from", fromBlock, "to", toBlock)
+	err := gdb.EvmStore().ExportStateDiffs(ctx, fromBlock, toBlock, func(diff genesis.StateDiff) error {
+		b, _ := rlp.EncodeToBytes(diff)
+		_, err := writer.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	diffsHash, err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	log.Info("Exported state changes")
+	fmt.Printf("- State changes hash: %v \n", diffsHash.String())
+	return nil
+}
+
 func exportEpochsSection(ctx context.Context, gdb *gossip.Store, writer *unitWriter, from, to idx.Epoch) error {
 	log.Info("Exporting epochs", "from", from, "to", to)
 	for i := to; i >= from; i-- {
@@ -107,9 +265,7 @@ func exportEpochsSection(ctx context.Context, gdb *gossip.Store, writer *unitWri
 	return nil
 }
 
-func exportBlocksSection(ctx context.Context, gdb *gossip.Store, writer *unitWriter, from, to idx.Epoch) error {
-	toBlock := getEpochBlock(to, gdb)
-	fromBlock := getEpochBlock(from, gdb)
+func exportBlocksSection(ctx context.Context, gdb *gossip.Store, writer *unitWriter, fromBlock, toBlock idx.Block) error {
 	if fromBlock < 1 {
 		// avoid underflow
 		fromBlock = 1
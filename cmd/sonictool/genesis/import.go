@@ -98,3 +98,66 @@ func IsGenesisTrusted(genesisStore *genesisstore.Store, genesisHashes genesis.Ha
 	}
 	return nil
 }
+
+// ImportGenesisDelta applies a delta genesis on top of the existing datadir.
+func ImportGenesisDelta(genesisStore *genesisstore.Store, dataDir string, validatorMode bool, cacheRatio cachescale.Func) error {
+	if err := db.AssertDatabaseNotInitialized(dataDir); err == nil {
+		return fmt.Errorf("database in datadir is not initialized - the delta genesis requires an existing database")
+	}
+
+	chaindataDir := filepath.Join(dataDir, "chaindata")
+	dbs, err := db.MakeDbProducer(chaindataDir, cacheRatio)
+	if err != nil {
+		return err
+	}
+	defer dbs.Close()
+	setGenesisProcessing(chaindataDir)
+
+	gdb, err := db.MakeGossipDb(dbs, dataDir, validatorMode, cacheRatio)
+	if err != nil {
+		return err
+	}
+	defer gdb.Close()
+	if err := gdb.EvmStore().Open(); err != nil {
+		return fmt.Errorf("failed to open EvmStore: %w", err)
+	}
+
+	err = gdb.ApplyGenesisDelta(genesisStore.Genesis())
+	if err != nil {
+		return fmt.Errorf("failed to apply genesis delta: %w", err)
+	}
+
+	cMainDb, err := dbs.OpenDB("lachesis")
+	if err != nil {
+		return err
+	}
+	cGetEpochDB := func(epoch idx.Epoch) kvdb.Store {
+		db, err := dbs.OpenDB(fmt.Sprintf("lachesis-%d", epoch))
+		if err != nil {
+			panic(fmt.Errorf("failed to open epoch db: %w", err))
+		}
+		return db
+	}
+	abftCrit := func(err error) {
+		panic(fmt.Errorf("lachesis store error: %w", err))
+	}
+	cdb := abft.NewStore(cMainDb, cGetEpochDB, abftCrit, abft.DefaultStoreConfig(cacheRatio))
+	defer cdb.Close()
+
+	// switch the consensus to a new empty epoch, the same way as the genesis does
+	cdb.SetEpochState(&abft.EpochState{
+		Epoch:      gdb.GetEpoch(),
+		Validators: gdb.GetValidators(),
+	})
+	cdb.SetLastDecidedState(&abft.LastDecidedState{
+		LastDecidedFrame: abft.FirstFrame - 1,
+	})
+
+	err = gdb.Commit()
+	if err != nil {
+		return err
+	}
+	setGenesisComplete(chaindataDir)
+	log.Info("Successfully applied genesis delta", "epoch", gdb.GetEpoch(), "block", gdb.GetLatestBlockIndex())
+	return nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Fantom-foundation/go-opera/cmd/sonictool/db"
+	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	IndexLogsFlag = cli.BoolFlag{
+		Name:  "logs",
+		Usage: "Rebuild the EVM logs index, used by eth_getLogs",
+	}
+	IndexTxHashesFlag = cli.BoolFlag{
+		Name:  "txhashes",
+		Usage: "Rebuild the transaction positions index, used to find transactions and receipts by hash",
+	}
+	IndexWorkersFlag = cli.IntFlag{
+		Name:  "workers",
+		Usage: "Number of blocks indexed in parallel",
+		Value: runtime.NumCPU(),
+	}
+)
+
+const (
+	// indexBatchSize is the number of blocks indexed between commits
+	indexBatchSize = 1000
+	// indexProgressFile stores the progress of an interrupted rebuild, to resume it
+	indexProgressFile = "index-rebuild.json"
+)
+
+// indexProgress is the state of a rebuild, which is persisted after every commit.
+type indexProgress struct {
+	From     idx.Block `json:"from"`
+	To       idx.Block `json:"to"`
+	Logs     bool      `json:"logs"`
+	TxHashes bool      `json:"txhashes"`
+	Next     idx.Block `json:"next"`
+}
+
+func readIndexProgress(path string) (*indexProgress, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p indexProgress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+func writeIndexProgress(path string, p indexProgress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// reindexBlocks indexes the blocks of the range in parallel.
+func reindexBlocks(ctx context.Context, gdb *gossip.Store, from, to idx.Block, logs, txHashes bool, workers int) error {
+	var (
+		wg   sync.WaitGroup
+		next = make(chan idx.Block)
+		errs = make(chan error, workers)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range next {
+				if err := gdb.ReindexBlock(n, logs, txHashes); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	var err error
+loop:
+	for n := from; n <= to; n++ {
+		select {
+		case next <- n:
+		case err = <-errs:
+			break loop
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		}
+	}
+	close(next)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	select {
+	case err = <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+func rebuildIndex(ctx *cli.Context) error {
+	dataDir := ctx.GlobalString(flags.DataDirFlag.Name)
+	if dataDir == "" {
+		return fmt.Errorf("--%s need to be set", flags.DataDirFlag.Name)
+	}
+	logs, txHashes := ctx.Bool(IndexLogsFlag.Name), ctx.Bool(IndexTxHashesFlag.Name)
+	if !logs && !txHashes {
+		return fmt.Errorf("at least one of --%s and --%s need to be set", IndexLogsFlag.Name, IndexTxHashesFlag.Name)
+	}
+	workers := ctx.Int(IndexWorkersFlag.Name)
+	if workers < 1 {
+		workers = 1
+	}
+	cacheRatio, err := cacheScaler(ctx)
+	if err != nil {
+		return err
+	}
+
+	cancelCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	chaindataDir := filepath.Join(dataDir, "chaindata")
+	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: 100,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to make DB producer: %v", err)
+	}
+	defer dbs.Close()
+
+	gdb, err := db.MakeGossipDb(dbs, dataDir, false, cacheRatio)
+	if err != nil {
+		return err
+	}
+	defer gdb.Close()
+
+	from := idx.Block(1)
+	if ctx.IsSet(FromBlockFlag.Name) && ctx.Uint64(FromBlockFlag.Name) > 0 {
+		from = idx.Block(ctx.Uint64(FromBlockFlag.Name))
+	}
+	to := gdb.GetLatestBlockIndex()
+	if ctx.IsSet(ToBlockFlag.Name) {
+		to = idx.Block(ctx.Uint64(ToBlockFlag.Name))
+	}
+	if latest := gdb.GetLatestBlockIndex(); to > latest {
+		return fmt.Errorf("the last block %d is above the latest block %d", to, latest)
+	}
+
+	// resume the interrupted rebuild of the same range
+	progressPath := filepath.Join(dataDir, indexProgressFile)
+	progress := indexProgress{From: from, To: to, Logs: logs, TxHashes: txHashes, Next: from}
+	if prev, err := readIndexProgress(progressPath); err != nil {
+		return err
+	} else if prev != nil && prev.From == from && prev.To == to && prev.Logs == logs && prev.TxHashes == txHashes {
+		progress.Next = prev.Next
+		log.Info("Resuming the index rebuild", "block", progress.Next)
+	}
+
+	log.Info("Rebuilding indexes", "from", from, "to", to, "logs", logs, "txhashes", txHashes, "workers", workers)
+	start, reported := time.Now(), time.Now()
+	for progress.Next <= to {
+		batchTo := progress.Next + indexBatchSize - 1
+		if batchTo > to {
+			batchTo = to
+		}
+		if err := reindexBlocks(cancelCtx, gdb, progress.Next, batchTo, logs, txHashes, workers); err != nil {
+			return err
+		}
+		if err := gdb.Commit(); err != nil {
+			return err
+		}
+		progress.Next = batchTo + 1
+		if err := writeIndexProgress(progressPath, progress); err != nil {
+			return err
+		}
+		if time.Since(reported) > 8*time.Second {
+			log.Info("Rebuilding indexes", "block", batchTo, "left", to-batchTo, "elapsed", time.Since(start))
+			reported = time.Now()
+		}
+	}
+	if err := os.Remove(progressPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	log.Info("Indexes rebuilt", "blocks", to+1-from, "elapsed", time.Since(start))
+	return nil
+}
@@ -29,6 +29,8 @@ func main() {
 
 Requires a first argument of the genesis file to import.
 Initialize the database using data from the genesis file.
+A delta genesis (see "genesis export --since-epoch") is applied on top
+of the existing database instead, after checking its base block.
 `,
 
 			ArgsUsage: "<filename>",
@@ -73,15 +75,21 @@ Initialize the database for a testing fakenet.
 				{
 					Name:      "export",
 					Usage:     "Export current state into a genesis file",
-					ArgsUsage: "<filename> [--mode=validator]",
+					ArgsUsage: "<filename> [--mode=validator] [--since-epoch=<epoch>]",
 					Action:    exportGenesis,
 					Flags: []cli.Flag{
 						ModeFlag,
+						SinceEpochFlag,
 					},
 					Description: `
 Export current state into a genesis file.
 Requires a first argument of the file to write to.
 Use --mode=validator to generate a genesis without an archive section.
+Use --since-epoch=<epoch> to generate a delta genesis, containing only epochs
+and blocks after the given epoch, their LLR votes and the state changes of the
+blocks. The state changes are read from the archive, so the datadir must not be
+in validator mode. The delta is imported by "genesis" command on top of an
+existing datadir, which state is at the given epoch or later.
 `,
 				},
 				{
@@ -126,6 +134,81 @@ The archive state is used for RPC - allows to handle state-related RPC queries.
 			},
 		},
 
+		{
+			Name:  "traces",
+			Usage: "Manage the transaction traces store",
+			Subcommands: []cli.Command{
+				{
+					Name:   "backfill",
+					Usage:  "Store transaction traces of already processed blocks",
+					Action: backfillTraces,
+					Flags: []cli.Flag{
+						FromBlockFlag,
+						ToBlockFlag,
+						OverwriteFlag,
+					},
+					Description: `
+    sonictool --datadir=<datadir> traces backfill [--from=<block>] [--to=<block>]
+
+Replays the blocks of the range using the archive state and stores
+the transaction traces, so the trace_* RPC calls can be served without
+replaying blocks. Use together with the --trace.index flag of the node
+to store the traces of the new blocks. Blocks with already stored traces
+are skipped unless --overwrite is set.
+`,
+				},
+			},
+		},
+
+		{
+			Name:  "index",
+			Usage: "Manage the EVM logs and transaction hashes indexes",
+			Subcommands: []cli.Command{
+				{
+					Name:   "rebuild",
+					Usage:  "Regenerate indexes of already processed blocks",
+					Action: rebuildIndex,
+					Flags: []cli.Flag{
+						IndexLogsFlag,
+						IndexTxHashesFlag,
+						FromBlockFlag,
+						ToBlockFlag,
+						IndexWorkersFlag,
+					},
+					Description: `
+    sonictool --datadir=<datadir> index rebuild --logs --txhashes [--from=<block>] [--to=<block>]
+
+Regenerates the EVM logs index and/or the transaction positions index
+from the stored blocks, events and receipts, so a node which processed
+blocks with the indexing disabled can serve eth_getLogs and transaction
+lookups by hash. Blocks are indexed in parallel. An interrupted rebuild
+of the same range is resumed from the last committed block.
+`,
+				},
+			},
+		},
+
+		{
+			Name:   "replay",
+			Usage:  "Re-execute blocks and compare results with the stored ones",
+			Action: replayBlocks,
+			Flags: []cli.Flag{
+				FromBlockFlag,
+				ToBlockFlag,
+				DiffFlag,
+			},
+			Description: `
+    sonictool --datadir=<datadir> replay [--from=<block>] [--to=<block>] [--diff]
+
+Re-executes the transactions of the blocks range on a live copy of the archive
+state of the block before the range, and reports every mismatch in receipts,
+logs, gas used or state root. On a state root mismatch, the state of the touched
+accounts is compared with the archive. With --diff the changes of the touched
+accounts are logged for every transaction. Requires the archive state, and
+space for a copy of the state in the datadir.
+`,
+		},
+
 		{
 			Name:        "heal",
 			Usage:       "Fix database in dirty state",
@@ -133,6 +216,21 @@ The archive state is used for RPC - allows to handle state-related RPC queries.
 			Description: "Tries to recover database corrupted by incorrect termination of the client.",
 		},
 
+		{
+			Name:   "prune-archive",
+			Usage:  "Remove archive states hidden by the archive window from the disk",
+			Action: pruneArchive,
+			Description: `
+    sonictool --datadir=<datadir> prune-archive
+
+Rebuilds the archive state database without the states of the blocks
+before the archive window of the node (--archive.visible.blocks or
+--archive.visible.epochs), so their disk space is reclaimed. The node
+hides the states out of the window while running, but the archive can
+only be shrunk offline. The node must be stopped.
+`,
+		},
+
 		{
 			Name:        "compact",
 			Usage:       "Compact all pebble databases",
@@ -140,6 +238,32 @@ The archive state is used for RPC - allows to handle state-related RPC queries.
 			Description: "Compacts (optimize) all the Pebble databases in the data directory.",
 		},
 
+		{
+			Name:  "db",
+			Usage: "Manage the key-value databases",
+			Subcommands: []cli.Command{
+				{
+					Name:   "migrate-backend",
+					Usage:  "Convert the databases to another key-value DB backend",
+					Action: migrateBackend,
+					Flags: []cli.Flag{
+						BackendFlag,
+						RouteFlag,
+					},
+					Description: `
+    sonictool --datadir=<datadir> db migrate-backend --backend=<pebble|leveldb> [--route=<pattern>=<backend>:<dir>]...
+
+Copies the databases of the datadir into the target backend and replaces
+the original ones. The DBs with names matching a route pattern are stored
+by the backend and in the directory of the route, e.g. the epoch DBs
+may be placed on a separate disk by --route="lachesis-%d=pebble:/mnt/nvme/epochs".
+Relative route directories are relative to the chaindata directory.
+The node must be stopped, and its --db.backend must match the new backend.
+`,
+				},
+			},
+		},
+
 		{
 			Name:      "cli",
 			Usage:     "Start an interactive JavaScript environment, attach to a node",
@@ -392,6 +516,93 @@ password to file or expose in any other way.
 Converts an account private key to a validator private key and saves in the validator keystore.
 `,
 				},
+				{
+					Name:   "rotate",
+					Usage:  "Create a new validator key to rotate the current one",
+					Action: validatorKeyRotate,
+					Flags: []cli.Flag{
+						flags.DataDirFlag,
+						flags.KeyStoreDirFlag,
+						flags.PasswordFileFlag,
+					},
+					Description: `
+    sonictool validator rotate
+
+Creates a new validator key in the keystore and prints the SFC call data
+which registers it for the validator. At the epoch where the new key is
+registered, sonicd unlocks it from the keystore with the passwords of the
+--validator.password file and switches to it without a restart.
+`,
+				},
+				{
+					Name:   "signer",
+					Usage:  "Serve a validator key as a remote signer",
+					Action: validatorSigner,
+					Flags: []cli.Flag{
+						flags.DataDirFlag,
+						flags.KeyStoreDirFlag,
+						flags.PasswordFileFlag,
+						flags.ValidatorPubkeyFlag,
+						flags.ValidatorSignerSecretFlag,
+						SignerListenFlag,
+						SignerTLSCertFlag,
+						SignerTLSKeyFlag,
+						SignerTLSClientCAFlag,
+						SlashingProtectionFlag,
+					},
+					Description: `
+    sonictool validator signer --validator.pubkey=<pubkey> --validator.signer.secret=<file> [--listen=unix:///path/to/socket]
+
+Unlocks the validator key and serves it as a remote signer for
+"sonicd --validator.signer=<url> --validator.signer.secret=<file>".
+Both sides authenticate each other with the shared secret.
+Use --tls.cert and --tls.key to serve over HTTPS, and --tls.clientca
+to additionally require TLS client certificates.
+Every signed event is checked against and recorded in the signer's own
+slashing-protection DB (default = <datadir>/signer/slashing-protection),
+so the node has to send whole events to the signer.
+Keys added to the keystore later, e.g. rotated keys, are unlocked on the
+first request with the passwords of the --password file.
+`,
+				},
+				{
+					Name:  "slashing-protection",
+					Usage: "Export or import the slashing-protection DB",
+					Description: `
+The slashing-protection DB records every event and LLR vote signed by
+the validator. Export it from the old machine and import it on the new
+one before starting to validate there, to never sign conflicting data.
+`,
+					Subcommands: []cli.Command{
+						{
+							Name:      "export",
+							Usage:     "Export the slashing-protection records into a JSON file",
+							ArgsUsage: "<filename>",
+							Action:    exportSlashingProtection,
+							Flags: []cli.Flag{
+								SlashingProtectionFlag,
+							},
+							Description: `
+    sonictool --datadir=<datadir> validator slashing-protection export <filename>
+`,
+						},
+						{
+							Name:      "import",
+							Usage:     "Import the slashing-protection records from a JSON file",
+							ArgsUsage: "<filename>",
+							Action:    importSlashingProtection,
+							Flags: []cli.Flag{
+								SlashingProtectionFlag,
+							},
+							Description: `
+    sonictool --datadir=<datadir> validator slashing-protection import <filename>
+
+The records are merged into the existing DB. Nothing is imported
+if any record conflicts with the existing ones.
+`,
+						},
+					},
+				},
 			},
 		},
 	}
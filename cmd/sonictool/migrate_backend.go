@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/integration"
+	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	BackendFlag = cli.StringFlag{
+		Name:  "backend",
+		Usage: `Target key-value DB backend ("pebble" or "leveldb")`,
+	}
+	RouteFlag = cli.StringSliceFlag{
+		Name:  "route",
+		Usage: `Route the DBs matching the pattern to the backend and directory, e.g. "lachesis-%d=pebble:/mnt/nvme/epochs"`,
+	}
+)
+
+func parseDBRoute(s string) (string, integration.DBRoute, error) {
+	pattern, target, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", integration.DBRoute{}, fmt.Errorf("invalid DB route %q, expected <pattern>=<backend>:<dir>", s)
+	}
+	backend, dir, ok := strings.Cut(target, ":")
+	if !ok || dir == "" {
+		return "", integration.DBRoute{}, fmt.Errorf("invalid DB route %q, expected <pattern>=<backend>:<dir>", s)
+	}
+	return pattern, integration.DBRoute{Backend: backend, Dir: dir}, nil
+}
+
+func migrateBackend(ctx *cli.Context) error {
+	dataDir := ctx.GlobalString(flags.DataDirFlag.Name)
+	if dataDir == "" {
+		return fmt.Errorf("--%s need to be set", flags.DataDirFlag.Name)
+	}
+	if !ctx.IsSet(BackendFlag.Name) {
+		return fmt.Errorf("--%s need to be set", BackendFlag.Name)
+	}
+	target := integration.DBsConfig{
+		Backend: ctx.String(BackendFlag.Name),
+	}
+	for _, s := range ctx.StringSlice(RouteFlag.Name) {
+		pattern, route, err := parseDBRoute(s)
+		if err != nil {
+			return err
+		}
+		if target.Routing == nil {
+			target.Routing = make(map[string]integration.DBRoute)
+		}
+		target.Routing[pattern] = route
+	}
+	chaindataDir := filepath.Join(dataDir, "chaindata")
+	return integration.MigrateDBsBackend(chaindataDir, target)
+}
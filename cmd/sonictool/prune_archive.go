@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/Fantom-foundation/go-opera/cmd/sonictool/db"
+	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func pruneArchive(ctx *cli.Context) error {
+	dataDir := ctx.GlobalString(flags.DataDirFlag.Name)
+	if dataDir == "" {
+		return fmt.Errorf("--%s need to be set", flags.DataDirFlag.Name)
+	}
+	cacheRatio, err := cacheScaler(ctx)
+	if err != nil {
+		return err
+	}
+	carmenArchiveDir := filepath.Join(dataDir, "carmen", "archive")
+	if info, err := os.Stat(carmenArchiveDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("archive database not found in datadir")
+	}
+
+	// read the archive window of the node
+	chaindataDir := filepath.Join(dataDir, "chaindata")
+	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: 100,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to make DB producer: %v", err)
+	}
+	gdb, err := db.MakeGossipDb(dbs, dataDir, false, cacheRatio)
+	if err != nil {
+		dbs.Close()
+		return err
+	}
+	defer dbs.Close()
+	defer gdb.Close()
+	oldest := gdb.EvmStore().GetOldestArchiveBlock()
+	if oldest == 0 {
+		log.Info("No archive states are out of the archive window")
+		return nil
+	}
+
+	cancelCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Info("Pruning archive", "oldest", oldest)
+	if err := evmstore.PruneArchive(cancelCtx, carmenArchiveDir, oldest); err != nil {
+		return err
+	}
+	if err := gdb.EvmStore().SetArchivePruned(oldest); err != nil {
+		return err
+	}
+	return gdb.Commit()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/Fantom-foundation/go-opera/cmd/sonictool/db"
+	"github.com/Fantom-foundation/go-opera/cmd/sonictool/replay"
+	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"gopkg.in/urfave/cli.v1"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+var (
+	DiffFlag = cli.BoolFlag{
+		Name:  "diff",
+		Usage: "Log changes of the touched accounts made by every replayed transaction",
+	}
+)
+
+func replayBlocks(ctx *cli.Context) error {
+	dataDir := ctx.GlobalString(flags.DataDirFlag.Name)
+	if dataDir == "" {
+		return fmt.Errorf("--%s need to be set", flags.DataDirFlag.Name)
+	}
+	cacheRatio, err := cacheScaler(ctx)
+	if err != nil {
+		return err
+	}
+
+	cancelCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	chaindataDir := filepath.Join(dataDir, "chaindata")
+	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: 100,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to make DB producer: %v", err)
+	}
+	defer dbs.Close()
+
+	gdb, err := db.MakeGossipDb(dbs, dataDir, false, cacheRatio)
+	if err != nil {
+		return err
+	}
+	defer gdb.Close()
+	if err := gdb.EvmStore().Open(); err != nil {
+		return fmt.Errorf("failed to open EvmStore: %v", err)
+	}
+
+	archiveHeight, empty, err := gdb.EvmStore().GetArchiveBlockHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get archive block height: %v", err)
+	}
+	if empty {
+		return fmt.Errorf("the archive state is empty")
+	}
+	cfg := replay.Config{
+		From:   idx.Block(1),
+		To:     gdb.GetLatestBlockIndex(),
+		Diff:   ctx.Bool(DiffFlag.Name),
+		TmpDir: dataDir,
+	}
+	if ctx.IsSet(FromBlockFlag.Name) {
+		cfg.From = idx.Block(ctx.Uint64(FromBlockFlag.Name))
+	}
+	if ctx.IsSet(ToBlockFlag.Name) {
+		cfg.To = idx.Block(ctx.Uint64(ToBlockFlag.Name))
+	}
+	if cfg.To > idx.Block(archiveHeight) {
+		return fmt.Errorf("the last block %d is above the archive block height %d", cfg.To, archiveHeight)
+	}
+	return replay.Replay(cancelCtx, gdb, cfg)
+}
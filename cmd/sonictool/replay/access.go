@@ -0,0 +1,167 @@
+package replay
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+)
+
+// touched is a set of accounts modified by a transaction, with written storage slots.
+type touched map[common.Address]map[common.Hash]struct{}
+
+func (t touched) addAccount(addr common.Address) {
+	if _, ok := t[addr]; !ok {
+		t[addr] = make(map[common.Hash]struct{})
+	}
+}
+
+func (t touched) addSlot(addr common.Address, slot common.Hash) {
+	t.addAccount(addr)
+	t[addr][slot] = struct{}{}
+}
+
+func (t touched) merge(other touched) {
+	for addr, slots := range other {
+		t.addAccount(addr)
+		for slot := range slots {
+			t[addr][slot] = struct{}{}
+		}
+	}
+}
+
+// txAccess is the access record of one replayed transaction.
+type txAccess struct {
+	tx      *types.Transaction
+	touched touched
+	// after is the state of the touched accounts at the end of the transaction
+	after map[common.Address]account
+}
+
+// accessTracer records accounts and storage slots modified by every transaction of a block.
+// It implements evmcore.TxTracer.
+type accessTracer struct {
+	db      vm.StateDB
+	current *txAccess
+	txs     []*txAccess
+}
+
+func (t *accessTracer) CaptureTxStart(tx *types.Transaction, msg types.Message) {
+	t.current = &txAccess{
+		tx:      tx,
+		touched: make(touched),
+	}
+	t.current.touched.addAccount(msg.From())
+	if msg.To() != nil {
+		t.current.touched.addAccount(*msg.To())
+	}
+}
+
+func (t *accessTracer) CaptureTxEnd(result *evmcore.ExecutionResult, err error) {
+	if t.current == nil {
+		return
+	}
+	if err == nil && t.db != nil {
+		// the state changes of the transaction are not finalised yet, but already visible
+		t.current.after = make(map[common.Address]account, len(t.current.touched))
+		for addr, slots := range t.current.touched {
+			t.current.after[addr] = readAccount(t.db, addr, slots)
+		}
+		t.txs = append(t.txs, t.current)
+	}
+	t.current = nil
+}
+
+// blockTouched returns all the accounts modified by the block.
+func (t *accessTracer) blockTouched() touched {
+	all := make(touched)
+	for _, tx := range t.txs {
+		all.merge(tx.touched)
+	}
+	return all
+}
+
+func (t *accessTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.db = env.StateDB
+	if t.current != nil {
+		t.current.touched.addAccount(from)
+		t.current.touched.addAccount(to)
+	}
+}
+
+func (t *accessTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if t.current == nil || op != vm.SSTORE || scope.Stack == nil {
+		return
+	}
+	stack := scope.Stack.Data()
+	if len(stack) == 0 {
+		return
+	}
+	slot := common.Hash(stack[len(stack)-1].Bytes32())
+	t.current.touched.addSlot(scope.Contract.Address(), slot)
+}
+
+func (t *accessTracer) CaptureEnter(op vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.current != nil {
+		t.current.touched.addAccount(from)
+		t.current.touched.addAccount(to)
+	}
+}
+
+func (t *accessTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *accessTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {}
+
+func (t *accessTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// account is a snapshot of the account fields and the selected storage slots.
+type account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	CodeHash common.Hash
+	Storage  map[common.Hash]common.Hash
+}
+
+func readAccount(db vm.StateDB, addr common.Address, slots map[common.Hash]struct{}) account {
+	acc := account{
+		Nonce:    db.GetNonce(addr),
+		Balance:  new(big.Int).Set(db.GetBalance(addr)),
+		CodeHash: db.GetCodeHash(addr),
+		Storage:  make(map[common.Hash]common.Hash, len(slots)),
+	}
+	for slot := range slots {
+		acc.Storage[slot] = db.GetState(addr, slot)
+	}
+	return acc
+}
+
+// fieldDiff is a difference of one account field.
+type fieldDiff struct {
+	Field string
+	A, B  interface{}
+}
+
+// diffAccounts returns differences of two snapshots of the same account.
+func diffAccounts(a, b account) []fieldDiff {
+	var diffs []fieldDiff
+	if a.Nonce != b.Nonce {
+		diffs = append(diffs, fieldDiff{"nonce", a.Nonce, b.Nonce})
+	}
+	if a.Balance.Cmp(b.Balance) != 0 {
+		diffs = append(diffs, fieldDiff{"balance", a.Balance, b.Balance})
+	}
+	if a.CodeHash != b.CodeHash {
+		diffs = append(diffs, fieldDiff{"codeHash", a.CodeHash, b.CodeHash})
+	}
+	for slot, va := range a.Storage {
+		if vb, ok := b.Storage[slot]; ok && va != vb {
+			diffs = append(diffs, fieldDiff{"storage " + slot.Hex(), va, vb})
+		}
+	}
+	return diffs
+}
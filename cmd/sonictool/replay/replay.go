@@ -0,0 +1,260 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/utils/signers/gsignercache"
+)
+
+// Config of the blocks replay.
+type Config struct {
+	From, To idx.Block
+	// Diff enables logging of the per-transaction changes of the touched accounts.
+	Diff bool
+	// TmpDir is the directory for the live state copy, which the blocks are replayed on.
+	TmpDir string
+}
+
+// Replay re-executes the blocks range and compares the results with the stored receipts and state roots.
+// As the state root cannot be recomputed on a non-committable archive state, the blocks are replayed
+// on a live copy of the archive state of the block before the range, and every replayed block is committed
+// to compare its state root with the block record. If a state root mismatches, the accounts touched
+// by the block are compared with the archive state of the block to locate the difference,
+// and the live copy is recreated from the archive to continue with the following block.
+func Replay(ctx context.Context, gdb *gossip.Store, cfg Config) error {
+	if cfg.From == 0 {
+		cfg.From = 1 // genesis block is not replayable
+	}
+	log.Info("Replaying blocks", "from", cfg.From, "to", cfg.To)
+	live, err := newLiveState(ctx, gdb, cfg.From-1, cfg.TmpDir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if live != nil {
+			live.close()
+		}
+	}()
+
+	mismatches, mismatchedBlocks := 0, 0
+	for n := cfg.From; n <= cfg.To; n++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		found, diverged, err := replayBlock(gdb, live.store, n, cfg.Diff)
+		if err != nil {
+			return err
+		}
+		if found != 0 {
+			mismatches += found
+			mismatchedBlocks++
+		}
+		if diverged && n < cfg.To {
+			live.close()
+			live = nil
+			if live, err = newLiveState(ctx, gdb, n, cfg.TmpDir); err != nil {
+				return err
+			}
+		}
+		if n%1000 == 0 {
+			log.Info("Replaying blocks", "block", n, "mismatches", mismatches)
+		}
+	}
+	if mismatches != 0 {
+		return fmt.Errorf("replay found %d mismatches in %d blocks (from %d total blocks)", mismatches, mismatchedBlocks, cfg.To-cfg.From+1)
+	}
+	log.Info("Replay OK for all blocks", "from", cfg.From, "to", cfg.To)
+	return nil
+}
+
+// liveState is a live copy of an archive state in a temporary directory.
+type liveState struct {
+	store *evmstore.Store
+	dir   string
+}
+
+func newLiveState(ctx context.Context, gdb *gossip.Store, block idx.Block, tmpDir string) (*liveState, error) {
+	dir, err := os.MkdirTemp(tmpDir, "sonictool-replay")
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Copying archive state", "block", block)
+	store, err := gdb.EvmStore().NewLiveStateCopy(ctx, block, dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return &liveState{store: store, dir: dir}, nil
+}
+
+func (l *liveState) close() {
+	if err := l.store.Close(); err != nil {
+		log.Warn("Failed to close live state copy", "err", err)
+	}
+	_ = os.RemoveAll(l.dir)
+}
+
+// replayBlock replays the block on the live state and returns the number of found mismatches,
+// and whether the live state diverged from the block record.
+func replayBlock(gdb *gossip.Store, live *evmstore.Store, n idx.Block, diff bool) (int, bool, error) {
+	parent := gdb.GetBlock(n - 1)
+	if parent == nil {
+		return 0, false, fmt.Errorf("block %d not found", n-1)
+	}
+	replayed, err := live.GetLiveStateDb(parent.Root)
+	if err != nil {
+		return 0, false, err
+	}
+	defer replayed.Release()
+
+	tracer := &accessTracer{}
+	replayed.BeginBlock(uint64(n))
+	block, receipts, err := gdb.ReplayBlockOn(n, replayed, tracer)
+	if err != nil {
+		return 0, false, err
+	}
+	replayed.EndBlock(uint64(n))
+	root, err := replayed.Commit(true)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to commit state of block %d: %w", n, err)
+	}
+
+	mismatches := 0
+	report := func(msg string, ctx ...interface{}) {
+		mismatches++
+		log.Error("Replay mismatch: "+msg, append([]interface{}{"block", n}, ctx...)...)
+	}
+
+	signer := gsignercache.Wrap(types.MakeSigner(gdb.GetEvmChainConfig(), block.Number))
+	stored := gdb.EvmStore().GetReceipts(n, signer, block.Hash, block.Transactions)
+	compareReceipts(block, stored, receipts, report)
+	if stored := gdb.GetBlock(n); stored != nil && stored.GasUsed != block.GasUsed {
+		report("block gas used", "stored", stored.GasUsed, "replayed", block.GasUsed)
+	}
+
+	if diff {
+		if err := logTxDiffs(gdb, n, tracer); err != nil {
+			return mismatches, false, err
+		}
+	}
+
+	if root == block.Root {
+		return mismatches, false, nil
+	}
+	report("state root", "stored", block.Root, "replayed", root)
+	expected, err := gdb.EvmStore().GetRpcStateDb(block.Number, block.Root)
+	if err != nil {
+		log.Warn("Failed to get archive state of the block", "block", n, "err", err)
+		return mismatches, true, nil
+	}
+	defer expected.Release()
+	compareStates(expected, replayed, tracer.blockTouched(), report)
+	return mismatches, true, nil
+}
+
+func compareReceipts(block *evmcore.EvmBlock, stored, replayed types.Receipts, report func(string, ...interface{})) {
+	if len(stored) != len(replayed) {
+		report("receipts count", "stored", len(stored), "replayed", len(replayed))
+		return
+	}
+	for i, a := range stored {
+		b := replayed[i]
+		txHash := block.Transactions[i].Hash()
+		if a.Status != b.Status {
+			report("receipt status", "tx", txHash, "stored", a.Status, "replayed", b.Status)
+		}
+		if a.GasUsed != b.GasUsed {
+			report("receipt gas used", "tx", txHash, "stored", a.GasUsed, "replayed", b.GasUsed)
+		}
+		if a.ContractAddress != b.ContractAddress {
+			report("receipt contract address", "tx", txHash, "stored", a.ContractAddress, "replayed", b.ContractAddress)
+		}
+		if a.Bloom != b.Bloom {
+			report("receipt bloom", "tx", txHash)
+		}
+		if len(a.Logs) != len(b.Logs) {
+			report("logs count", "tx", txHash, "stored", len(a.Logs), "replayed", len(b.Logs))
+			continue
+		}
+		for j, la := range a.Logs {
+			if !logsEqual(la, b.Logs[j]) {
+				report("log", "tx", txHash, "index", j, "stored", la, "replayed", b.Logs[j])
+			}
+		}
+	}
+}
+
+func logsEqual(a, b *types.Log) bool {
+	if a.Address != b.Address || !bytes.Equal(a.Data, b.Data) || len(a.Topics) != len(b.Topics) {
+		return false
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func compareStates(expected, replayed state.StateDB, accounts touched, report func(string, ...interface{})) {
+	for addr, slots := range accounts {
+		for _, d := range diffAccounts(readAccount(expected, addr, slots), readAccount(replayed, addr, slots)) {
+			report("state", "account", addr, "field", d.Field, "stored", d.A, "replayed", d.B)
+		}
+	}
+}
+
+// logTxDiffs logs changes of the touched accounts made by every transaction of the block.
+func logTxDiffs(gdb *gossip.Store, n idx.Block, tracer *accessTracer) error {
+	parent := gdb.GetBlock(n - 1)
+	if parent == nil {
+		return fmt.Errorf("block %d not found", n-1)
+	}
+	base, err := gdb.EvmStore().GetRpcStateDb(new(big.Int).SetUint64(uint64(n-1)), common.Hash(parent.Root))
+	if err != nil {
+		return err
+	}
+	defer base.Release()
+
+	// last known state of the accounts touched by the previous transactions of the block
+	last := make(map[common.Address]account)
+	for _, tx := range tracer.txs {
+		for addr, after := range tx.after {
+			before := readAccount(base, addr, tx.touched[addr])
+			prev, ok := last[addr]
+			if ok {
+				before.Nonce, before.Balance, before.CodeHash = prev.Nonce, prev.Balance, prev.CodeHash
+				for slot := range before.Storage {
+					if value, ok := prev.Storage[slot]; ok {
+						before.Storage[slot] = value
+					}
+				}
+			}
+			for _, d := range diffAccounts(before, after) {
+				log.Info("Transaction state diff", "block", n, "tx", tx.tx.Hash(), "account", addr, "field", d.Field, "before", d.A, "after", d.B)
+			}
+			if ok {
+				for slot, value := range prev.Storage {
+					if _, ok := after.Storage[slot]; !ok {
+						after.Storage[slot] = value
+					}
+				}
+			}
+			last[addr] = after
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/Fantom-foundation/go-opera/gossip/contract/sfc100"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/opera/contracts/sfc"
+)
+
+// updatePubkeyCallData returns the SFC call data, which a validator sends from its auth address to change its pubkey.
+func updatePubkeyCallData(pubkey validatorpk.PubKey) ([]byte, error) {
+	sfcAbi, err := sfc100.ContractMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return sfcAbi.Pack("updateValidatorPubkey", pubkey.Bytes())
+}
+
+// validatorKeyRotate creates a new validator key and prints how to switch the validator to it.
+func validatorKeyRotate(ctx *cli.Context) error {
+	pubkey, _, err := createValidatorKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := updatePubkeyCallData(pubkey)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("To rotate the validator key, send the following transaction from the validator address:\n\n")
+	fmt.Printf("   To:    %s\n", sfc.ContractAddress.Hex())
+	fmt.Printf("   Value: 0\n")
+	fmt.Printf("   Data:  %s\n\n", hexutil.Encode(data))
+	fmt.Printf("The SFC of the network has to implement updateValidatorPubkey, the transaction is reverted otherwise,\n")
+	fmt.Printf("so estimate its gas before sending it.\n")
+	fmt.Printf("The new key is used starting from the next sealed epoch after the transaction.\n")
+	fmt.Printf("sonicd unlocks it from the validator keystore at that epoch, without a restart,\n")
+	fmt.Printf("so the password of the new key must be in the --validator.password file of sonicd,\n")
+	fmt.Printf("or in the --password file of the remote signer.\n")
+	fmt.Printf("Replace the old key in --validator.pubkey with the new one before the next restart:\n\n")
+	fmt.Printf("   --validator.pubkey=%s\n\n", pubkey.String())
+	return nil
+}
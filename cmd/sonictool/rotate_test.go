@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/contract/driverauth100"
+	"github.com/Fantom-foundation/go-opera/gossip/contract/sfc100"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+)
+
+func TestUpdatePubkeyCallData(t *testing.T) {
+	require := require.New(t)
+
+	pubkey := validatorpk.PubKey{Type: validatorpk.Types.Secp256k1, Raw: []byte{1, 2, 3}}
+	data, err := updatePubkeyCallData(pubkey)
+	require.NoError(err)
+
+	// the call is sent by the validator auth address to the SFC, which takes only the pubkey
+	sfcAbi, err := sfc100.ContractMetaData.GetAbi()
+	require.NoError(err)
+	method, err := sfcAbi.MethodById(data[:4])
+	require.NoError(err)
+	require.Equal("updateValidatorPubkey", method.Name)
+	args, err := method.Inputs.Unpack(data[4:])
+	require.NoError(err)
+	require.Equal([]interface{}{pubkey.Bytes()}, args)
+
+	// the driver auth method of the same name isn't callable by validators
+	driverAuthAbi, err := abi.JSON(strings.NewReader(driverauth100.ContractMetaData.ABI))
+	require.NoError(err)
+	require.NotEqual(driverAuthAbi.Methods["updateValidatorPubkey"].ID, data[:4])
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/Fantom-foundation/go-opera/config"
+	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/valkeystore"
+	"github.com/Fantom-foundation/go-opera/valkeystore/slashing"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	SignerListenFlag = cli.StringFlag{
+		Name:  "listen",
+		Usage: "Address to serve the signer on: host:port or unix:///path/to/socket",
+		Value: "127.0.0.1:18550",
+	}
+	SignerTLSCertFlag = cli.StringFlag{
+		Name:  "tls.cert",
+		Usage: "TLS server certificate file, the signer is served over plain HTTP if not set",
+	}
+	SignerTLSKeyFlag = cli.StringFlag{
+		Name:  "tls.key",
+		Usage: "TLS server key file",
+	}
+	SignerTLSClientCAFlag = cli.StringFlag{
+		Name:  "tls.clientca",
+		Usage: "CA certificate file to require and verify TLS client certificates with",
+	}
+)
+
+// validatorSigner serves the remote signer protocol with a key from the local validator keystore.
+func validatorSigner(ctx *cli.Context) error {
+	cfg, err := config.MakeAllConfigs(ctx)
+	if err != nil {
+		return err
+	}
+	if err := config.SetNodeConfig(ctx, &cfg.Node); err != nil {
+		return err
+	}
+	pubkeys, err := config.ParseValidatorPubKeys(ctx.String(flags.ValidatorPubkeyFlag.Name))
+	if err != nil {
+		return err
+	}
+	secret, err := config.ReadSignerSecret(ctx.String(flags.ValidatorSignerSecretFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	_, _, keystoreDir, err := cfg.Node.AccountConfig()
+	if err != nil {
+		return fmt.Errorf("failed to setup account config: %w", err)
+	}
+	valKeystore := valkeystore.NewDefaultFileKeystore(path.Join(keystoreDir, "validator"))
+	passwordList, err := config.MakePasswordList(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get password list: %w", err)
+	}
+	for _, pubkey := range pubkeys {
+		if !valKeystore.Has(pubkey) {
+			return valkeystore.ErrNotFound
+		}
+		password, err := config.GetPassPhrase("Unlocking validator key "+pubkey.String(), false, 0, passwordList)
+		if err != nil {
+			return fmt.Errorf("failed to get passphrase: %w", err)
+		}
+		if err := valKeystore.Unlock(pubkey, password); err != nil {
+			return fmt.Errorf("failed to unlock validator key: %w", err)
+		}
+	}
+
+	protectionDir, err := slashingProtectionPath(ctx, "signer")
+	if err != nil {
+		return err
+	}
+	protection, err := slashing.Open(protectionDir)
+	if err != nil {
+		return err
+	}
+	defer protection.Close()
+
+	signer := &unlockingSigner{
+		Signer:    valkeystore.NewSigner(valKeystore),
+		keystore:  valKeystore,
+		passwords: passwordList,
+	}
+	handler := valkeystore.NewRemoteSignerHandler(signer, secret)
+	handler.SetEventCheck(func(e *inter.EventPayload) error {
+		return protection.CheckAndRecordEvent(e)
+	})
+	server := &http.Server{
+		Handler: handler,
+	}
+	listen := ctx.String(SignerListenFlag.Name)
+	var listener net.Listener
+	if strings.HasPrefix(listen, "unix://") {
+		socket := strings.TrimPrefix(listen, "unix://")
+		_ = os.Remove(socket)
+		listener, err = net.Listen("unix", socket)
+		if err == nil {
+			err = os.Chmod(socket, 0600)
+		}
+	} else {
+		listener, err = net.Listen("tcp", listen)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+
+	useTLS := ctx.IsSet(SignerTLSCertFlag.Name)
+	if useTLS && ctx.IsSet(SignerTLSClientCAFlag.Name) {
+		pool, err := valkeystore.LoadCertPool(ctx.String(SignerTLSClientCAFlag.Name))
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  pool,
+		}
+	}
+
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		<-sigCh
+		_ = server.Shutdown(context.Background())
+	}()
+
+	log.Info("Serving validator signer", "pubkeys", len(pubkeys), "listen", listen, "tls", useTLS)
+	if useTLS {
+		err = server.ServeTLS(listener, ctx.String(SignerTLSCertFlag.Name), ctx.String(SignerTLSKeyFlag.Name))
+	} else {
+		err = server.Serve(listener)
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// unlockingSigner unlocks a key which is added to the keystore while the signer is running,
+// e.g. a rotated validator key, with the passwords of the --password file.
+type unlockingSigner struct {
+	*valkeystore.Signer
+	keystore  valkeystore.KeystoreI
+	passwords []string
+}
+
+func (s *unlockingSigner) Sign(pubkey validatorpk.PubKey, digest []byte) ([]byte, error) {
+	if err := valkeystore.UnlockWithPasswords(s.keystore, pubkey, s.passwords); err != nil {
+		return nil, err
+	}
+	return s.Signer.Sign(pubkey, digest)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/valkeystore/slashing"
+)
+
+var SlashingProtectionFlag = cli.StringFlag{
+	Name:  "slashing-protection",
+	Usage: "Slashing-protection DB directory (default = <datadir>/emitter/slashing-protection)",
+}
+
+func slashingProtectionPath(ctx *cli.Context, defaultSubdir string) (string, error) {
+	if ctx.IsSet(SlashingProtectionFlag.Name) {
+		return ctx.String(SlashingProtectionFlag.Name), nil
+	}
+	dataDir := ctx.GlobalString(flags.DataDirFlag.Name)
+	if dataDir == "" {
+		return "", fmt.Errorf("--%s or --%s need to be set", flags.DataDirFlag.Name, SlashingProtectionFlag.Name)
+	}
+	return filepath.Join(dataDir, defaultSubdir, "slashing-protection"), nil
+}
+
+func exportSlashingProtection(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		return fmt.Errorf("this command requires an argument - the output file")
+	}
+	dir, err := slashingProtectionPath(ctx, "emitter")
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("slashing-protection DB not found: %w", err)
+	}
+	store, err := slashing.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	fh, err := os.OpenFile(ctx.Args().First(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	if err := store.Export(fh); err != nil {
+		return err
+	}
+	log.Info("Exported slashing-protection DB", "dir", dir, "file", ctx.Args().First())
+	return nil
+}
+
+func importSlashingProtection(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		return fmt.Errorf("this command requires an argument - the input file")
+	}
+	dir, err := slashingProtectionPath(ctx, "emitter")
+	if err != nil {
+		return err
+	}
+	fh, err := os.Open(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	store, err := slashing.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	ic, err := store.ImportJSON(fh)
+	if err != nil {
+		return fmt.Errorf("failed to import slashing-protection records: %w", err)
+	}
+	log.Info("Imported slashing-protection records", "dir", dir, "events", len(ic.Events), "blockVotes", len(ic.BlockVotes), "epochVotes", len(ic.EpochVotes))
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/Fantom-foundation/go-opera/cmd/sonictool/db"
+	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"gopkg.in/urfave/cli.v1"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+var (
+	FromBlockFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First block of the range (default is the first block)",
+	}
+	ToBlockFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block of the range (default is the latest block)",
+	}
+	OverwriteFlag = cli.BoolFlag{
+		Name:  "overwrite",
+		Usage: "Replay also blocks, which traces are already stored",
+	}
+)
+
+func backfillTraces(ctx *cli.Context) error {
+	dataDir := ctx.GlobalString(flags.DataDirFlag.Name)
+	if dataDir == "" {
+		return fmt.Errorf("--%s need to be set", flags.DataDirFlag.Name)
+	}
+	cacheRatio, err := cacheScaler(ctx)
+	if err != nil {
+		return err
+	}
+
+	cancelCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	chaindataDir := filepath.Join(dataDir, "chaindata")
+	dbs, err := integration.GetDbProducer(chaindataDir, integration.DBsConfig{
+		RuntimeCache: integration.DBCacheConfig{
+			Cache:   cacheRatio.U64(480 * opt.MiB),
+			Fdlimit: 100,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to make DB producer: %v", err)
+	}
+	defer dbs.Close()
+
+	gdb, err := db.MakeGossipDb(dbs, dataDir, false, cacheRatio)
+	if err != nil {
+		return err
+	}
+	defer gdb.Close()
+	if err := gdb.EvmStore().Open(); err != nil {
+		return fmt.Errorf("failed to open EvmStore: %v", err)
+	}
+
+	from := idx.Block(1)
+	if ctx.IsSet(FromBlockFlag.Name) && ctx.Uint64(FromBlockFlag.Name) > 0 {
+		from = idx.Block(ctx.Uint64(FromBlockFlag.Name))
+	}
+	to := gdb.GetLatestBlockIndex()
+	if ctx.IsSet(ToBlockFlag.Name) {
+		to = idx.Block(ctx.Uint64(ToBlockFlag.Name))
+	}
+	if latest := gdb.GetLatestBlockIndex(); to > latest {
+		return fmt.Errorf("the last block %d is above the latest block %d", to, latest)
+	}
+	overwrite := ctx.Bool(OverwriteFlag.Name)
+
+	log.Info("Storing transaction traces", "from", from, "to", to)
+	start, reported := time.Now(), time.Now()
+	indexed := 0
+	for n := from; n <= to; n++ {
+		if cancelCtx.Err() != nil {
+			return cancelCtx.Err()
+		}
+		if !overwrite && gdb.EvmStore().HasTraces(n) {
+			continue
+		}
+		if err := gdb.IndexBlockTraces(n); err != nil {
+			return err
+		}
+		indexed++
+		if time.Since(reported) > 8*time.Second {
+			if err := gdb.Commit(); err != nil {
+				return err
+			}
+			log.Info("Storing transaction traces", "block", n, "indexed", indexed, "elapsed", time.Since(start))
+			reported = time.Now()
+		}
+	}
+	if err := gdb.Commit(); err != nil {
+		return err
+	}
+	log.Info("Transaction traces stored", "blocks", indexed, "elapsed", time.Since(start))
+	return nil
+}
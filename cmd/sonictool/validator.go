@@ -20,26 +20,32 @@ import (
 
 // validatorKeyCreate creates a new validator key into the keystore defined by the CLI flags.
 func validatorKeyCreate(ctx *cli.Context) error {
+	_, _, err := createValidatorKey(ctx)
+	return err
+}
+
+// createValidatorKey generates a new validator key, saves it into the keystore and prints it.
+func createValidatorKey(ctx *cli.Context) (validatorpk.PubKey, common.Address, error) {
 	cfg, err := config.MakeAllConfigs(ctx)
 	if err != nil {
-		return err
+		return validatorpk.PubKey{}, common.Address{}, err
 	}
 	if err := config.SetNodeConfig(ctx, &cfg.Node); err != nil {
-		return err
+		return validatorpk.PubKey{}, common.Address{}, err
 	}
 
 	passwordList, err := config.MakePasswordList(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get password list: %w", err)
+		return validatorpk.PubKey{}, common.Address{}, fmt.Errorf("failed to get password list: %w", err)
 	}
 	password, err := config.GetPassPhrase("Your new validator key is locked with a password. Please give a password. Do not forget this password.", true, 0, passwordList)
 	if err != nil {
-		return fmt.Errorf("failed to get passphrase: %w", err)
+		return validatorpk.PubKey{}, common.Address{}, fmt.Errorf("failed to get passphrase: %w", err)
 	}
 
 	privateKeyECDSA, err := ecdsa.GenerateKey(crypto.S256(), rand.Reader)
 	if err != nil {
-		return fmt.Errorf("failed to create account: %w", err)
+		return validatorpk.PubKey{}, common.Address{}, fmt.Errorf("failed to create account: %w", err)
 	}
 	privateKey := crypto.FromECDSA(privateKeyECDSA)
 	publicKey := validatorpk.PubKey{
@@ -49,30 +55,31 @@ func validatorKeyCreate(ctx *cli.Context) error {
 
 	_, _, keystoreDir, err := cfg.Node.AccountConfig()
 	if err != nil {
-		return fmt.Errorf("failed to setup account config: %w", err)
+		return validatorpk.PubKey{}, common.Address{}, fmt.Errorf("failed to setup account config: %w", err)
 	}
 
 	valKeystore := valkeystore.NewDefaultFileRawKeystore(path.Join(keystoreDir, "validator"))
 	err = valKeystore.Add(publicKey, privateKey, password)
 	if err != nil {
-		return fmt.Errorf("failed to create account: %w", err)
+		return validatorpk.PubKey{}, common.Address{}, fmt.Errorf("failed to create account: %w", err)
 	}
 
 	// Sanity check
 	_, err = valKeystore.Get(publicKey, password)
 	if err != nil {
-		return fmt.Errorf("failed to decrypt the account: %w", err)
+		return validatorpk.PubKey{}, common.Address{}, fmt.Errorf("failed to decrypt the account: %w", err)
 	}
 
+	address := crypto.PubkeyToAddress(privateKeyECDSA.PublicKey)
 	fmt.Printf("\nYour new key was generated\n\n")
 	fmt.Printf("Public key:                  %s\n", publicKey.String())
-	fmt.Printf("Public address of the key:   %s\n", crypto.PubkeyToAddress(privateKeyECDSA.PublicKey))
+	fmt.Printf("Public address of the key:   %s\n", address)
 	fmt.Printf("Path of the secret key file: %s\n\n", valKeystore.PathOf(publicKey))
 	fmt.Printf("- You can share your public key with anyone. Others need it to validate messages from you.\n")
 	fmt.Printf("- You must NEVER share the secret key with anyone! The key controls access to your validator!\n")
 	fmt.Printf("- You must BACKUP your key file! Without the key, it's impossible to operate the validator!\n")
 	fmt.Printf("- You must REMEMBER your password! Without the password, it's impossible to decrypt the key!\n\n")
-	return nil
+	return publicKey, address, nil
 }
 
 // validatorKeyConvert converts account key to validator key.
@@ -156,6 +156,9 @@ func setTxPool(ctx *cli.Context, cfg *evmcore.TxPoolConfig) error {
 	if ctx.GlobalIsSet(flags.TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(flags.TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(flags.TxPoolPrivateLifetimeFlag.Name) {
+		cfg.PrivateLifetime = ctx.GlobalUint64(flags.TxPoolPrivateLifetimeFlag.Name)
+	}
 	return nil
 }
 
@@ -174,6 +177,9 @@ func gossipConfigWithFlags(ctx *cli.Context, src gossip.Config) gossip.Config {
 	if ctx.GlobalIsSet(flags.RPCGlobalTimeoutFlag.Name) {
 		cfg.RPCTimeout = ctx.GlobalDuration(flags.RPCGlobalTimeoutFlag.Name)
 	}
+	if ctx.GlobalIsSet(flags.SyncModeFlag.Name) {
+		cfg.SyncMode = gossip.SyncMode(ctx.GlobalString(flags.SyncModeFlag.Name))
+	}
 
 	return cfg
 }
@@ -182,6 +188,16 @@ func setEvmStore(ctx *cli.Context, datadir string, src  evmstore.StoreConfig) (e
 	cfg := src
 	cfg.StateDb.Directory = filepath.Join(datadir, "carmen")
 
+	if ctx.GlobalIsSet(flags.TraceIndexFlag.Name) {
+		cfg.EnableTracesIndexing = ctx.GlobalBool(flags.TraceIndexFlag.Name)
+	}
+	if ctx.GlobalIsSet(flags.ArchiveVisibleBlocksFlag.Name) {
+		cfg.ArchiveVisibleBlocks = ctx.GlobalUint64(flags.ArchiveVisibleBlocksFlag.Name)
+	}
+	if ctx.GlobalIsSet(flags.ArchiveVisibleEpochsFlag.Name) {
+		cfg.ArchiveVisibleEpochs = ctx.GlobalUint64(flags.ArchiveVisibleEpochsFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(flags.ModeFlag.Name) || ctx.IsSet(flags.ModeFlag.Name) {
 		var mode string
 		if ctx.IsSet(flags.ModeFlag.Name) {
@@ -196,6 +212,7 @@ func setEvmStore(ctx *cli.Context, datadir string, src  evmstore.StoreConfig) (e
 			cfg.StateDb.Archive = carmen.NoArchive
 			cfg.DisableLogsIndexing = true
 			cfg.DisableTxHashesIndexing = true
+			cfg.EnableTracesIndexing = false
 		}
 	}
 	return cfg, nil
@@ -316,6 +333,9 @@ func MakeAllConfigsFromFile(ctx *cli.Context, configFile string) (*Config, error
 	if cfg.Emitter.Validator.ID != 0 && len(cfg.Emitter.PrevEmittedEventFile.Path) == 0 {
 		cfg.Emitter.PrevEmittedEventFile.Path = path.Join(cfg.Node.DataDir, "emitter", fmt.Sprintf("last-%d", cfg.Emitter.Validator.ID))
 	}
+	if cfg.Emitter.Validator.ID != 0 && len(cfg.Emitter.SlashingProtectionPath) == 0 {
+		cfg.Emitter.SlashingProtectionPath = path.Join(cfg.Node.DataDir, "emitter", "slashing-protection")
+	}
 	if err := setTxPool(ctx, &cfg.TxPool); err != nil {
 		return nil, err
 	}
@@ -325,6 +345,16 @@ func MakeAllConfigsFromFile(ctx *cli.Context, configFile string) (*Config, error
 	if err != nil {
 		return nil, err
 	}
+	if ctx.GlobalIsSet(flags.DBBackendFlag.Name) {
+		cfg.DBs.Backend = ctx.GlobalString(flags.DBBackendFlag.Name)
+	}
+	if cfg.DBs.Backend == integration.MemoryBackend {
+		// keep the live state in memory as well
+		cfg.OperaStore.EVM.StateDb.Variant = "go-memory"
+	}
+	if err := cfg.DBs.Validate(); err != nil {
+		return nil, err
+	}
 
 	if err := cfg.Opera.Validate(); err != nil {
 		return nil, err
@@ -17,6 +17,7 @@
 package flags
 
 import (
+	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip"
 	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
 	pcsclite "github.com/gballet/go-libpcsclite"
@@ -120,6 +121,11 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolPrivateLifetimeFlag = cli.Uint64Flag{
+		Name:  "txpool.privatelifetime",
+		Usage: "Number of blocks after which private transactions are dropped if not included",
+		Value: evmcore.DefaultTxPoolConfig.PrivateLifetime,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -276,6 +282,10 @@ var (
 		Name:  "cache",
 		Usage: "Megabytes of memory allocated to internal caching",
 	}
+	DBBackendFlag = cli.StringFlag{
+		Name:  "db.backend",
+		Usage: `Key-value DB backend ("pebble", "leveldb" or "memory"), must match the backend of an existing datadir`,
+	}
 	RPCGlobalGasCapFlag = cli.Uint64Flag{
 		Name:  "rpc.gascap",
 		Usage: "Sets a cap on gas that can be used in ftm_call/estimateGas (0=infinite)",
@@ -311,11 +321,28 @@ var (
 		Usage: "Limit maximum size in some RPC calls execution",
 		Value: gossip.DefaultConfig(cachescale.Identity).MaxResponseSize,
 	}
+	TraceIndexFlag = cli.BoolFlag{
+		Name:  "trace.index",
+		Usage: "Store transaction traces during blocks processing to serve trace_* RPC calls without replaying blocks",
+	}
+	ArchiveVisibleBlocksFlag = cli.Uint64Flag{
+		Name:  "archive.visible.blocks",
+		Usage: "Number of the latest blocks with archive state served over RPC, older states are hidden and pruned while the node runs, the pruned archive replaces the old one on the next start (0 serves all)",
+	}
+	ArchiveVisibleEpochsFlag = cli.Uint64Flag{
+		Name:  "archive.visible.epochs",
+		Usage: "Number of the latest epochs with archive state served over RPC, older states are hidden and pruned while the node runs, the pruned archive replaces the old one on the next start (0 serves all)",
+	}
 	ModeFlag = cli.StringFlag{
 		Name:  "mode",
 		Usage: `Mode of the node ("rpc" or "validator")`,
 		Value: "rpc",
 	}
+	SyncModeFlag = cli.StringFlag{
+		Name:  "sync",
+		Usage: `Synchronisation mode of the node ("full" processes all the events since the genesis, "llr" applies the block and epoch records decided by the validators votes, "snap" downloads the EVM state snapshot of a recent epoch)`,
+		Value: string(gossip.FullSync),
+	}
 	ExitWhenAgeFlag = cli.DurationFlag{
 		Name:  "exitwhensynced.age",
 		Usage: "Exits after synchronisation reaches the required age",
@@ -333,7 +360,7 @@ var (
 	}
 	ValidatorPubkeyFlag = cli.StringFlag{
 		Name:  "validator.pubkey",
-		Usage: "Public key of a validator to create events from",
+		Usage: "Public key of a validator to create events from. Several comma-separated keys may be given when rotating the key, the one registered for the current epoch is used",
 		Value: "",
 	}
 	ValidatorPasswordFlag = cli.StringFlag{
@@ -341,4 +368,29 @@ var (
 		Usage: "Password to unlock validator private key",
 		Value: "",
 	}
+	ValidatorSignerFlag = cli.StringFlag{
+		Name:  "validator.signer",
+		Usage: "URL of a remote signer to sign validator events with (http://, https:// or unix://), the local validator keystore is used if empty",
+		Value: "",
+	}
+	ValidatorSignerSecretFlag = cli.StringFlag{
+		Name:  "validator.signer.secret",
+		Usage: "File with a secret shared with the remote signer for the mutual authentication",
+		Value: "",
+	}
+	ValidatorSignerTLSCertFlag = cli.StringFlag{
+		Name:  "validator.signer.tls.cert",
+		Usage: "TLS client certificate file to authenticate at the remote signer",
+		Value: "",
+	}
+	ValidatorSignerTLSKeyFlag = cli.StringFlag{
+		Name:  "validator.signer.tls.key",
+		Usage: "TLS client key file to authenticate at the remote signer",
+		Value: "",
+	}
+	ValidatorSignerTLSCAFlag = cli.StringFlag{
+		Name:  "validator.signer.tls.ca",
+		Usage: "TLS CA certificate file to verify the remote signer",
+		Value: "",
+	}
 )
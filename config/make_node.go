@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"github.com/Fantom-foundation/go-opera/config/flags"
+	"github.com/Fantom-foundation/go-opera/ethapi/rpclimit"
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip"
 	"github.com/Fantom-foundation/go-opera/gossip/emitter"
@@ -10,10 +11,18 @@ import (
 	"github.com/Fantom-foundation/go-opera/utils/errlock"
 	"github.com/Fantom-foundation/go-opera/valkeystore"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
 	"gopkg.in/urfave/cli.v1"
+	"net"
+	"net/http"
 	"path"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -65,7 +74,16 @@ func MakeNode(ctx *cli.Context, cfg *Config) (*node.Node, *gossip.Service, func(
 		setBootnodes(ctx, bootnodes, &cfg.Node)
 	}
 
-	stack, err := makeNetworkStack(ctx, &cfg.Node)
+	limiter, err := rpclimit.New(cfg.Opera.RPCLimits)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create RPC limiter: %w", err)
+	}
+	nodeCfg := cfg.Node
+	if limiter.Enabled() {
+		// the WebSocket endpoint is served by the limiter instead, see registerRPCLimits
+		nodeCfg.WSHost = ""
+	}
+	stack, err := makeNetworkStack(ctx, &nodeCfg)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to unlock validator key: %w", err)
 	}
@@ -89,20 +107,28 @@ func MakeNode(ctx *cli.Context, cfg *Config) (*node.Node, *gossip.Service, func(
 		log.Info("Unlocked fake validator account", "address", coinbase.Address.Hex())
 	}
 
-	// unlock validator key
-	if !valPubkey.Empty() {
-		err := unlockValidatorKey(ctx, valPubkey, valKeystore)
-		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to unlock validator key: %w", err)
+	// unlock validator keys, unless the events are signed by a remote signer
+	if !ctx.GlobalIsSet(flags.ValidatorSignerFlag.Name) {
+		for _, pubkey := range validatorPubKeys(cfg.Emitter.Validator) {
+			err := unlockValidatorKey(ctx, pubkey, valKeystore)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to unlock validator key: %w", err)
+			}
 		}
 	}
-	signer := valkeystore.NewSigner(valKeystore)
+	signer, err := makeValidatorSigner(ctx, valKeystore)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create validator signer: %w", err)
+	}
 
 	// Create and register a gossip network service.
 	newTxPool := func(reader evmcore.StateReader) gossip.TxPool {
 		if cfg.TxPool.Journal != "" {
 			cfg.TxPool.Journal = path.Join(cfg.Node.DataDir, cfg.TxPool.Journal)
 		}
+		if cfg.TxPool.Denylist != "" {
+			cfg.TxPool.Denylist = path.Join(cfg.Node.DataDir, cfg.TxPool.Denylist)
+		}
 		return evmcore.NewTxPool(cfg.TxPool, reader.Config(), reader)
 	}
 	haltCheck := func(oldEpoch, newEpoch idx.Epoch, age time.Time) bool {
@@ -137,10 +163,17 @@ func MakeNode(ctx *cli.Context, cfg *Config) (*node.Node, *gossip.Service, func(
 	}
 
 	if cfg.Emitter.Validator.ID != 0 {
-		svc.RegisterEmitter(emitter.NewEmitter(cfg.Emitter, svc.EmitterWorld(signer)))
+		world := svc.EmitterWorld(signer)
+		world.LoadKey = makeValidatorKeyLoader(ctx, valKeystore)
+		svc.RegisterEmitter(emitter.NewEmitter(cfg.Emitter, world))
 	}
 
 	stack.RegisterAPIs(svc.APIs())
+	stopRPCLimits, err := registerRPCLimits(stack, &cfg.Node, limiter, svc.APIs())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cleanup = append(cleanup, stopRPCLimits)
 	stack.RegisterProtocols(svc.Protocols())
 	stack.RegisterLifecycle(svc)
 
@@ -159,3 +192,164 @@ func makeNetworkStack(ctx *cli.Context, cfg *node.Config) (*node.Node, error) {
 	}
 	return stack, nil
 }
+
+// registerRPCLimits serves the HTTP and WebSocket RPC endpoints through the limiter, if any limit is configured.
+// The limited handlers serve dedicated RPC servers, which have only the APIs of the HTTP and WebSocket modules,
+// as the in-process server of the node has all of them.
+// The limited HTTP handler takes over the endpoint path. The WebSocket endpoint of the node has to be disabled,
+// it's served by the limited HTTP handler if the ports are the same, and by an own server otherwise.
+func registerRPCLimits(stack *node.Node, cfg *node.Config, limiter *rpclimit.Limiter, apis []rpc.API) (stop func(), err error) {
+	if !limiter.Enabled() {
+		return func() {}, nil
+	}
+	var servers []*rpc.Server
+	stop = func() {
+		for _, srv := range servers {
+			srv.Stop()
+		}
+	}
+	var httpHandler, wsHandler http.Handler
+	if cfg.HTTPHost != "" {
+		handler, srv, err := newLimitedRPCHandler(cfg, limiter, apis)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, srv)
+		httpHandler = handler
+	}
+	if cfg.WSHost != "" {
+		handler, srv, err := newLimitedWSHandler(cfg, limiter, apis)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		servers = append(servers, srv)
+		wsHandler = handler
+	}
+
+	// the same as the node, WebSocket is served by the HTTP server if the ports are the same
+	sharedPort := httpHandler != nil && wsHandler != nil && cfg.WSPort == cfg.HTTPPort
+	if httpHandler != nil {
+		if sharedPort {
+			httpHandler = websocketOrHTTP(wsHandler, httpHandler)
+		}
+		// the pattern matches any path, so that no request of the RPC path reaches the unlimited RPC handler of the node,
+		// but the handlers registered at other paths take precedence
+		stack.RegisterHandler("Limited JSON-RPC", "/", httpHandler)
+	}
+	if wsHandler != nil && !sharedPort {
+		stack.RegisterLifecycle(&limitedWSServer{
+			addr:     net.JoinHostPort(cfg.WSHost, strconv.Itoa(cfg.WSPort)),
+			handler:  wsHandler,
+			timeouts: cfg.HTTPTimeouts,
+		})
+	}
+	limits := limiter.Config()
+	log.Info("RPC limits enabled", "rate", limits.Default.Rate, "burst", limits.Default.Burst, "clients", len(limits.Clients))
+	return stop, nil
+}
+
+// withWeb3API adds the web3 API of the node, as the APIs of the node itself aren't available to the limited servers.
+func withWeb3API(cfg *node.Config, apis []rpc.API) []rpc.API {
+	return append(apis[:len(apis):len(apis)], rpc.API{
+		Namespace: "web3",
+		Version:   "1.0",
+		Service:   &web3API{clientVersion: cfg.NodeName()},
+		Public:    true,
+	})
+}
+
+// newLimitedRPCHandler creates the RPC server of the HTTP modules, and its HTTP handler limited by the limiter.
+func newLimitedRPCHandler(cfg *node.Config, limiter *rpclimit.Limiter, apis []rpc.API) (http.Handler, *rpc.Server, error) {
+	srv := rpc.NewServer()
+	if err := node.RegisterApis(withWeb3API(cfg, apis), cfg.HTTPModules, srv, false); err != nil {
+		srv.Stop()
+		return nil, nil, err
+	}
+	handler := rpcPathHandler(cfg.HTTPPathPrefix, limiter.Handler(srv))
+	return node.NewHTTPHandlerStack(handler, cfg.HTTPCors, cfg.HTTPVirtualHosts), srv, nil
+}
+
+// newLimitedWSHandler creates the RPC server of the WebSocket modules, and its WebSocket handler limited by the limiter.
+func newLimitedWSHandler(cfg *node.Config, limiter *rpclimit.Limiter, apis []rpc.API) (http.Handler, *rpc.Server, error) {
+	srv := rpc.NewServer()
+	if err := node.RegisterApis(withWeb3API(cfg, apis), cfg.WSModules, srv, false); err != nil {
+		srv.Stop()
+		return nil, nil, err
+	}
+	return rpcPathHandler(cfg.WSPathPrefix, limiter.WebsocketHandler(srv, cfg.WSOrigins)), srv, nil
+}
+
+// websocketOrHTTP passes the WebSocket handshakes to the ws handler, and other requests to the next one.
+func websocketOrHTTP(ws, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			ws.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitedWSServer serves the limited WebSocket endpoint on its own port, as the node does for a port
+// other than the HTTP one.
+type limitedWSServer struct {
+	addr     string
+	handler  http.Handler
+	timeouts rpc.HTTPTimeouts
+	srv      *http.Server
+}
+
+// Start starts the server, it's called by the node.
+func (s *limitedWSServer) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen WebSocket endpoint: %w", err)
+	}
+	s.srv = &http.Server{
+		Handler:      s.handler,
+		ReadTimeout:  s.timeouts.ReadTimeout,
+		WriteTimeout: s.timeouts.WriteTimeout,
+		IdleTimeout:  s.timeouts.IdleTimeout,
+	}
+	go func() {
+		_ = s.srv.Serve(listener)
+	}()
+	log.Info("WebSocket enabled", "url", "ws://"+listener.Addr().String())
+	return nil
+}
+
+// Stop stops the server, the connections are closed by stopping the RPC server.
+func (s *limitedWSServer) Stop() error {
+	if s.srv == nil {
+		return nil
+	}
+	return s.srv.Close()
+}
+
+// rpcPathHandler passes the requests of the RPC path to the next handler, the same way the node does.
+// The other requests aren't found.
+func rpcPathHandler(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (prefix == "" && r.URL.Path != "/") || !strings.HasPrefix(r.URL.Path, prefix) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// web3API is the web3 API of the node, which is offered over HTTP by default.
+type web3API struct {
+	clientVersion string
+}
+
+// ClientVersion returns the node name.
+func (s *web3API) ClientVersion() string {
+	return s.clientVersion
+}
+
+// Sha3 applies the ethereum sha3 implementation on the input.
+func (s *web3API) Sha3(input hexutil.Bytes) hexutil.Bytes {
+	return crypto.Keccak256(input)
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi/rpclimit"
+	"github.com/Fantom-foundation/go-opera/gossip"
+)
+
+type testRPCService struct{}
+
+func (s *testRPCService) Echo(x string) string {
+	return x
+}
+
+func TestLimitedRPCHandler(t *testing.T) {
+	require := require.New(t)
+
+	limits := rpclimit.DefaultConfig()
+	limits.Default = rpclimit.Limit{Rate: 0.001, Burst: 5}
+	limiter, err := rpclimit.New(limits)
+	require.NoError(err)
+	apis := []rpc.API{
+		{Namespace: "public", Service: &testRPCService{}, Public: true},
+		{Namespace: "private", Service: &testRPCService{}, Public: false},
+		{Namespace: "other", Service: &testRPCService{}, Public: true},
+	}
+	cfg := node.Config{
+		HTTPModules:      []string{"public", "web3"},
+		HTTPVirtualHosts: []string{"*"},
+	}
+	handler, srv, err := newLimitedRPCHandler(&cfg, limiter, apis)
+	require.NoError(err)
+	defer srv.Stop()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.RemoteAddr = "10.0.0.1:5555"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// only the HTTP modules are served
+	w := post("/", `{"jsonrpc":"2.0","id":1,"method":"public_echo","params":["x"]}`)
+	require.Equal(http.StatusOK, w.Code)
+	require.Contains(w.Body.String(), `"result":"x"`)
+	w = post("/", `{"jsonrpc":"2.0","id":1,"method":"web3_sha3","params":["0x"]}`)
+	require.Contains(w.Body.String(), `"result":"0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"`)
+	for _, method := range []string{"private_echo", "other_echo"} {
+		w = post("/", `{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":["x"]}`)
+		require.Contains(w.Body.String(), "-32601", method)
+	}
+
+	// the RPC isn't served on other paths
+	w = post("/other", `{"jsonrpc":"2.0","id":1,"method":"public_echo","params":["x"]}`)
+	require.Equal(http.StatusNotFound, w.Code)
+
+	// trailing data doesn't bypass the limiter
+	w = post("/", `{"jsonrpc":"2.0","id":1,"method":"public_echo","params":["x"]}garbage`)
+	require.Contains(w.Body.String(), "-32700")
+
+	// the batch calls are limited
+	w = post("/", `[{"jsonrpc":"2.0","id":1,"method":"public_echo","params":["x"]},{"jsonrpc":"2.0","id":2,"method":"public_echo","params":["y"]}]`)
+	require.Equal(http.StatusTooManyRequests, w.Code)
+	require.Contains(w.Body.String(), "-32005")
+}
+
+func TestRPCPathHandler(t *testing.T) {
+	require := require.New(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, test := range []struct {
+		prefix, path string
+		found        bool
+	}{
+		{"", "/", true},
+		{"", "/other", false},
+		{"/rpc", "/rpc", true},
+		{"/rpc", "/rpc/x", true},
+		{"/rpc", "/", false},
+		{"/rpc", "/other", false},
+	} {
+		w := httptest.NewRecorder()
+		rpcPathHandler(test.prefix, next).ServeHTTP(w, httptest.NewRequest(http.MethodPost, test.path, nil))
+		require.Equal(test.found, w.Code == http.StatusOK, "prefix %q path %q", test.prefix, test.path)
+	}
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestRegisterRPCLimitsWithWebSocket(t *testing.T) {
+	for name, sharedPort := range map[string]bool{
+		"shared port": true,
+		"own port":    false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			limits := rpclimit.DefaultConfig()
+			limits.Default = rpclimit.Limit{Rate: 0.001, Burst: 3}
+			limiter, err := rpclimit.New(limits)
+			require.NoError(err)
+			apis := []rpc.API{
+				{Namespace: "public", Service: &testRPCService{}, Public: true},
+				{Namespace: "other", Service: &testRPCService{}, Public: true},
+			}
+			cfg := node.Config{
+				HTTPHost:         "127.0.0.1",
+				HTTPPort:         freePort(t),
+				HTTPModules:      []string{"public"},
+				HTTPVirtualHosts: []string{"*"},
+				WSHost:           "127.0.0.1",
+				WSModules:        []string{"public"},
+				P2P:              p2p.Config{NoDiscovery: true},
+			}
+			cfg.WSPort = cfg.HTTPPort
+			if !sharedPort {
+				cfg.WSPort = freePort(t)
+			}
+			nodeCfg := cfg
+			nodeCfg.WSHost = ""
+			stack, err := node.New(&nodeCfg)
+			require.NoError(err)
+			defer stack.Close()
+			stack.RegisterAPIs(apis)
+			// the node record has to advertise the protocol
+			stack.RegisterProtocols([]p2p.Protocol{{Name: gossip.ProtocolName, Attributes: []enr.Entry{&gossip.Enr{}}}})
+			stop, err := registerRPCLimits(stack, &cfg, limiter, apis)
+			require.NoError(err)
+			defer stop()
+			require.NoError(stack.Start())
+
+			conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://127.0.0.1:%d", cfg.WSPort), nil)
+			require.NoError(err)
+			defer conn.Close()
+			call := func(method string) string {
+				require.NoError(conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":["x"]}`)))
+				_, data, err := conn.ReadMessage()
+				require.NoError(err)
+				return string(data)
+			}
+
+			// only the WebSocket modules are served
+			require.Contains(call("public_echo"), `"result":"x"`)
+			require.Contains(call("other_echo"), "-32601")
+
+			// the messages are charged from the bucket of the client, which is shared with HTTP
+			resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d", cfg.HTTPPort), "application/json",
+				strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"public_echo","params":["x"]}`))
+			require.NoError(err)
+			require.NoError(resp.Body.Close())
+			require.Equal(http.StatusOK, resp.StatusCode)
+			require.Contains(call("public_echo"), "-32005")
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/Fantom-foundation/go-opera/config/flags"
 	"github.com/pkg/errors"
 	cli "gopkg.in/urfave/cli.v1"
@@ -38,15 +41,46 @@ func setValidator(ctx *cli.Context, cfg *emitter.Config) error {
 	}
 
 	if ctx.GlobalIsSet(flags.ValidatorPubkeyFlag.Name) {
-		pk, err := validatorpk.FromString(ctx.GlobalString(flags.ValidatorPubkeyFlag.Name))
+		pubkeys, err := ParseValidatorPubKeys(ctx.GlobalString(flags.ValidatorPubkeyFlag.Name))
 		if err != nil {
 			return err
 		}
-		cfg.Validator.PubKey = pk
+		cfg.Validator.PubKey = pubkeys[0]
+		cfg.Validator.PubKeys = nil
+		if len(pubkeys) > 1 {
+			cfg.Validator.PubKeys = pubkeys
+		}
 	}
 
+	if cfg.Validator.PubKey.Empty() && len(cfg.Validator.PubKeys) != 0 {
+		cfg.Validator.PubKey = cfg.Validator.PubKeys[0]
+	}
 	if cfg.Validator.ID != 0 && cfg.Validator.PubKey.Empty() {
 		return errors.New("validator public key is not set")
 	}
 	return nil
 }
+
+// ParseValidatorPubKeys parses a comma-separated list of validator public keys.
+func ParseValidatorPubKeys(s string) ([]validatorpk.PubKey, error) {
+	var pubkeys []validatorpk.PubKey
+	for _, str := range strings.Split(s, ",") {
+		pk, err := validatorpk.FromString(strings.TrimSpace(str))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode validator pubkey %q: %w", str, err)
+		}
+		pubkeys = append(pubkeys, pk)
+	}
+	return pubkeys, nil
+}
+
+// validatorPubKeys returns all the configured keys of the validator.
+func validatorPubKeys(cfg emitter.ValidatorConfig) []validatorpk.PubKey {
+	if len(cfg.PubKeys) != 0 {
+		return cfg.PubKeys
+	}
+	if cfg.PubKey.Empty() {
+		return nil
+	}
+	return []validatorpk.PubKey{cfg.PubKey}
+}
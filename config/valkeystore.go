@@ -73,3 +73,66 @@ func unlockValidatorKey(ctx *cli.Context, pubKey validatorpk.PubKey, valKeystore
 	// All trials expended to unlock account, bail out
 	return err
 }
+
+// makeValidatorKeyLoader returns the loader of the validator keys, which are rotated while the node is running.
+// A remote signer holds the keys itself. Otherwise the keys are unlocked with the passwords
+// of the --validator.password file, as there is no one to prompt for them at runtime.
+func makeValidatorKeyLoader(ctx *cli.Context, valKeystore valkeystore.KeystoreI) func(pubkey validatorpk.PubKey) error {
+	if ctx.GlobalIsSet(flags.ValidatorSignerFlag.Name) {
+		return func(pubkey validatorpk.PubKey) error {
+			return nil
+		}
+	}
+	return func(pubkey validatorpk.PubKey) error {
+		passwordList, err := makeValidatorPasswordList(ctx)
+		if err != nil {
+			return err
+		}
+		if err := valkeystore.UnlockWithPasswords(valKeystore, pubkey, passwordList); err != nil {
+			return err
+		}
+		log.Info("Unlocked validator key", "pubkey", pubkey.String())
+		return nil
+	}
+}
+
+// makeValidatorSigner creates a remote signer if the --validator.signer flag is set,
+// or a signer which uses the local validator keystore otherwise.
+func makeValidatorSigner(ctx *cli.Context, valKeystore valkeystore.KeystoreI) (valkeystore.SignerI, error) {
+	signerURL := ctx.GlobalString(flags.ValidatorSignerFlag.Name)
+	if signerURL == "" {
+		return valkeystore.NewSigner(valKeystore), nil
+	}
+	secret, err := ReadSignerSecret(ctx.GlobalString(flags.ValidatorSignerSecretFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	signer, err := valkeystore.NewRemoteSigner(valkeystore.RemoteSignerConfig{
+		URL:     signerURL,
+		Secret:  secret,
+		TLSCert: ctx.GlobalString(flags.ValidatorSignerTLSCertFlag.Name),
+		TLSKey:  ctx.GlobalString(flags.ValidatorSignerTLSKeyFlag.Name),
+		TLSCA:   ctx.GlobalString(flags.ValidatorSignerTLSCAFlag.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Using remote validator signer", "url", signerURL)
+	return signer, nil
+}
+
+// ReadSignerSecret reads the secret shared with a remote signer from the file.
+func ReadSignerSecret(path string) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("remote signer secret file is not specified")
+	}
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote signer secret file: %w", err)
+	}
+	secret = []byte(strings.TrimSpace(string(secret)))
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("remote signer secret file %s is empty", path)
+	}
+	return secret, nil
+}
@@ -49,10 +49,12 @@ import (
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/tyler-smith/go-bip39"
 
+	"github.com/Fantom-foundation/go-opera/ethapi/rpclimit"
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
 	"github.com/Fantom-foundation/go-opera/inter/state"
 	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace/native"
 	"github.com/Fantom-foundation/go-opera/utils/signers/gsignercache"
 	"github.com/Fantom-foundation/go-opera/utils/signers/internaltx"
 )
@@ -153,6 +155,55 @@ func (s *PublicEthereumAPI) EffectiveBaseFee(ctx context.Context) *hexutil.Big {
 	return (*hexutil.Big)(s.b.EffectiveMinGasPrice(ctx))
 }
 
+// RPCGasPriceStat is a snapshot of the gas price oracle's internal state at a block
+type RPCGasPriceStat struct {
+	Time                 hexutil.Uint64          `json:"time"`
+	Block                hexutil.Uint64          `json:"block"`
+	PoolGas              hexutil.Uint64          `json:"poolGas"`
+	PoolPercentiles      []*hexutil.Big          `json:"poolPercentiles"`
+	GasPowerLeft         hexutil.Uint64          `json:"gasPowerLeft"`
+	MinGasPrice          *hexutil.Big            `json:"minGasPrice"`
+	EffectiveMinGasPrice *hexutil.Big            `json:"effectiveMinGasPrice"`
+	Tips                 map[string]*hexutil.Big `json:"tips"`
+}
+
+// maxGasPriceStats is the maximum number of gas price stats returned by a single call
+const maxGasPriceStats = 1024
+
+// GasPriceStats returns the latest stats of the gas price oracle, from older to newer, one per block:
+// the txpool gas price percentiles, the total gas power left, the minimum gas price
+// and the suggested tips at each certainty level (in percents).
+func (s *PublicEthereumAPI) GasPriceStats(ctx context.Context, count *hexutil.Uint64) []*RPCGasPriceStat {
+	limit := uint64(maxGasPriceStats)
+	if count != nil && uint64(*count) < limit {
+		limit = uint64(*count)
+	}
+	stats := s.b.GasPriceStats(ctx, limit)
+	res := make([]*RPCGasPriceStat, len(stats))
+	for i, stat := range stats {
+		r := &RPCGasPriceStat{
+			Time:                 hexutil.Uint64(stat.Time.Unix()),
+			Block:                hexutil.Uint64(stat.Block),
+			PoolGas:              hexutil.Uint64(stat.PoolGas),
+			PoolPercentiles:      make([]*hexutil.Big, len(stat.PoolPercentiles)),
+			GasPowerLeft:         hexutil.Uint64(stat.GasPowerLeft),
+			MinGasPrice:          (*hexutil.Big)(stat.MinGasPrice),
+			EffectiveMinGasPrice: (*hexutil.Big)(stat.EffectiveMinGasPrice),
+			Tips:                 make(map[string]*hexutil.Big, len(stat.Tips)),
+		}
+		for j, p := range stat.PoolPercentiles {
+			r.PoolPercentiles[j] = (*hexutil.Big)(p)
+		}
+		for j, tip := range stat.Tips {
+			if j < len(gasprice.HistoryCertainties) {
+				r.Tips[fmt.Sprintf("%d", gasprice.HistoryCertainties[j]*100/gasprice.DecimalUnit)] = (*hexutil.Big)(tip)
+			}
+		}
+		res[i] = r
+	}
+	return res
+}
+
 // Syncing returns true if node is syncing
 func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 	progress := s.b.Progress()
@@ -279,6 +330,79 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	return content
 }
 
+// PrivateTxPoolAPI offers an administrative API for the transaction pool of this node.
+// It's served under the separate "txpooladmin" namespace, which is available over IPC,
+// and over HTTP and WS only if it's listed in --http.api or --ws.api,
+// so enabling the public "txpool" namespace doesn't expose it.
+type PrivateTxPoolAPI struct {
+	b Backend
+}
+
+// NewPrivateTxPoolAPI creates a new tx pool service for the node operator.
+func NewPrivateTxPoolAPI(b Backend) *PrivateTxPoolAPI {
+	return &PrivateTxPoolAPI{b}
+}
+
+// RemoveTransaction removes the transaction from the pool.
+func (s *PrivateTxPoolAPI) RemoveTransaction(hash common.Hash) bool {
+	return s.b.RemovePoolTransaction(hash)
+}
+
+// RemoveSender removes all the transactions of the sender from the pool.
+func (s *PrivateTxPoolAPI) RemoveSender(addr common.Address) hexutil.Uint {
+	return hexutil.Uint(s.b.RemovePoolSender(addr))
+}
+
+// Denylist returns the senders and recipients whose transactions are rejected by the pool.
+func (s *PrivateTxPoolAPI) Denylist() evmcore.TxDenylist {
+	return s.b.TxPoolDenylist()
+}
+
+// DenySender adds the sender to the deny list and removes its transactions from the pool.
+func (s *PrivateTxPoolAPI) DenySender(addr common.Address) (hexutil.Uint, error) {
+	removed, err := s.b.UpdateTxPoolDenylist(evmcore.TxDenylist{Senders: []common.Address{addr}}, evmcore.TxDenylist{})
+	return hexutil.Uint(removed), err
+}
+
+// AllowSender removes the sender from the deny list.
+func (s *PrivateTxPoolAPI) AllowSender(addr common.Address) error {
+	_, err := s.b.UpdateTxPoolDenylist(evmcore.TxDenylist{}, evmcore.TxDenylist{Senders: []common.Address{addr}})
+	return err
+}
+
+// DenyRecipient adds the recipient to the deny list and removes the transactions to it from the pool.
+func (s *PrivateTxPoolAPI) DenyRecipient(addr common.Address) (hexutil.Uint, error) {
+	removed, err := s.b.UpdateTxPoolDenylist(evmcore.TxDenylist{Recipients: []common.Address{addr}}, evmcore.TxDenylist{})
+	return hexutil.Uint(removed), err
+}
+
+// AllowRecipient removes the recipient from the deny list.
+func (s *PrivateTxPoolAPI) AllowRecipient(addr common.Address) error {
+	_, err := s.b.UpdateTxPoolDenylist(evmcore.TxDenylist{}, evmcore.TxDenylist{Recipients: []common.Address{addr}})
+	return err
+}
+
+// InspectQueued explains why the queued transactions, optionally of a single account,
+// are not pending: a nonce gap, an insufficient balance or an underpriced transaction.
+func (s *PrivateTxPoolAPI) InspectQueued(addr *common.Address) map[string]map[string]string {
+	content := make(map[string]map[string]string)
+	for account, reasons := range s.b.TxPoolQueuedReasons() {
+		if addr != nil && account != *addr {
+			continue
+		}
+		dump := make(map[string]string, len(reasons))
+		for nonce, reason := range reasons {
+			if reason == nil {
+				dump[fmt.Sprintf("%d", nonce)] = "executable"
+			} else {
+				dump[fmt.Sprintf("%d", nonce)] = reason.Error()
+			}
+		}
+		content[account.Hex()] = dump
+	}
+	return content
+}
+
 // PublicAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type PublicAccountAPI struct {
@@ -687,6 +811,25 @@ func (s *PublicBlockChainAPI) BlockNumber() hexutil.Uint64 {
 	return hexutil.Uint64(header.Number.Uint64())
 }
 
+// ArchiveBlockHeight is the range of blocks with queryable archive state.
+type ArchiveBlockHeight struct {
+	Oldest hexutil.Uint64 `json:"oldest"`
+	Latest hexutil.Uint64 `json:"latest"`
+}
+
+// GetArchiveBlockHeight returns the range of blocks, which states can be queried.
+// States of the blocks before the oldest one are hidden by the archive window.
+func (s *PublicBlockChainAPI) GetArchiveBlockHeight(ctx context.Context) (*ArchiveBlockHeight, error) {
+	oldest, latest, err := s.b.ArchiveBlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveBlockHeight{
+		Oldest: hexutil.Uint64(oldest),
+		Latest: hexutil.Uint64(latest),
+	}, nil
+}
+
 // GetBalance returns the amount of wei for the given address in the state of the
 // given block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta
 // block numbers are also allowed.
@@ -699,7 +842,10 @@ func (s *PublicBlockChainAPI) GetBalance(ctx context.Context, address common.Add
 	return (*hexutil.Big)(state.GetBalance(address)), state.Error()
 }
 
-// AccountResult is result struct for GetProof
+// AccountResult is result struct for GetProof.
+// The proofs are lists of hex-encoded RLP trie nodes of the Carmen state MPT,
+// which uses the Ethereum trie layout and account encoding. Unlike geth, the nodes
+// are not sorted from the root - see VerifyProof for how to check them.
 type AccountResult struct {
 	Address      common.Address  `json:"address"`
 	AccountProof []string        `json:"accountProof"`
@@ -710,7 +856,8 @@ type AccountResult struct {
 	StorageProof []StorageResult `json:"storageProof"`
 }
 
-// StorageResult is result struct for GetProof
+// StorageResult is result struct for GetProof.
+// The Proof is rooted in the StorageHash of the containing AccountResult.
 type StorageResult struct {
 	Key   string       `json:"key"`
 	Value *hexutil.Big `json:"value"`
@@ -718,6 +865,7 @@ type StorageResult struct {
 }
 
 // GetProof returns the Merkle-proof for a given account and optionally some storage keys.
+// The proof is rooted in the state root of the block header.
 func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*AccountResult, error) {
 	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if state == nil || err != nil {
@@ -725,22 +873,25 @@ func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Addre
 	}
 	defer state.Release()
 
-	storageTrie := state.StorageTrie(address)
+	exists := state.Exist(address)
 	storageHash := types.EmptyRootHash
 	codeHash := state.GetCodeHash(address)
 	storageProof := make([]StorageResult, len(storageKeys))
 
-	// if we have a storageTrie, (which means the account exists), we can update the storagehash
-	if storageTrie != nil {
-		storageHash = storageTrie.Hash()
+	// if the account exists, we can update the storagehash
+	if exists {
+		storageHash, err = state.GetStorageRoot(address)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		// no storageTrie means the account does not exist, so the codeHash is the hash of an empty bytearray.
+		// non-existing account has the codeHash of an empty bytearray.
 		codeHash = crypto.Keccak256Hash(nil)
 	}
 
 	// create the proof for the storageKeys
 	for i, key := range storageKeys {
-		if storageTrie != nil {
+		if exists {
 			proof, storageError := state.GetStorageProof(address, common.HexToHash(key))
 			if storageError != nil {
 				return nil, storageError
@@ -1002,6 +1153,9 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 	// Execute the message.
 	gp := new(evmcore.GasPool).AddGas(math.MaxUint64)
 	result, err := evmcore.ApplyMessage(evm, msg, gp)
+	if result != nil {
+		rpclimit.ChargeGas(ctx, result.UsedGas)
+	}
 	if err := vmError(); err != nil {
 		return nil, err
 	}
@@ -1562,6 +1716,9 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 			return nil, 0, nil, err
 		}
 		res, err := evmcore.ApplyMessage(vmenv, msg, new(evmcore.GasPool).AddGas(msg.Gas()))
+		if res != nil {
+			rpclimit.ChargeGas(ctx, res.UsedGas)
+		}
 		statedb.Release()
 		if err != nil {
 			return nil, 0, nil, fmt.Errorf("failed to apply transaction: %v err: %v", args.toTransaction().Hash(), err)
@@ -1814,6 +1971,16 @@ func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transacti
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
 func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, b.SendTx)
+}
+
+// SubmitPrivateTransaction is a helper function that submits tx to txPool as a private transaction,
+// which is never announced to peers, and logs a message.
+func SubmitPrivateTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, b.SendPrivateTx)
+}
+
+func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, send func(context.Context, *types.Transaction) error) (common.Hash, error) {
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
@@ -1823,7 +1990,7 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
 		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
 	}
-	if err := b.SendTx(ctx, tx); err != nil {
+	if err := send(ctx, tx); err != nil {
 		return common.Hash{}, err
 	} // Print a log with full tx details for manual investigations and interventions
 	signer := gsignercache.Wrap(types.MakeSigner(b.ChainConfig(), b.CurrentBlock().Number))
@@ -1900,6 +2067,17 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 	return SubmitTransaction(ctx, s.b, tx)
 }
 
+// SendPrivateRawTransaction will add the signed transaction to the transaction pool as a private one.
+// Private transactions are never broadcast to peers and are included only into events emitted by this node.
+// The sender is responsible for signing the transaction and using the correct nonce.
+func (s *PublicTransactionPoolAPI) SendPrivateRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return common.Hash{}, err
+	}
+	return SubmitPrivateTransaction(ctx, s.b, tx)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -2121,11 +2299,14 @@ func (api *PublicDebugAPI) BlocksTransactionTimes(ctx context.Context, untilBloc
 }
 
 // TraceConfig holds extra parameters to trace functions.
+// The Tracer is either a name of a native (Go) tracer - callTracer, prestateTracer
+// or 4byteTracer - configured by the TracerConfig, or a JavaScript tracer code or name.
 type TraceConfig struct {
 	*vm.LogConfig
-	Tracer  *string
-	Timeout *string
-	Reexec  *uint64
+	Tracer       *string
+	TracerConfig json.RawMessage
+	Timeout      *string
+	Reexec       *uint64
 }
 
 // TraceTransaction returns the structured logs created during the execution of EVM
@@ -2197,11 +2378,25 @@ func (api *PublicDebugAPI) traceTx(ctx context.Context, message evmcore.Message,
 		if rpcTimeout := api.b.RPCEVMTimeout(); rpcTimeout != 0 && rpcTimeout < timeout {
 			timeout = rpcTimeout
 		}
-		t, err := tracers.New(*config.Tracer, txctx)
+		// Prefer the native tracer of the name, fallback to the JavaScript engine
+		var t interface {
+			vm.Tracer
+			Stop(err error)
+		}
+		nativeTracer, isNative, err := native.New(*config.Tracer, txctx, config.TracerConfig)
 		if err != nil {
 			return nil, err
 		}
-		defer t.Destroy()
+		if isNative {
+			t = nativeTracer
+		} else {
+			jsTracer, err := tracers.New(*config.Tracer, txctx)
+			if err != nil {
+				return nil, err
+			}
+			defer jsTracer.Destroy()
+			t = jsTracer
+		}
 		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
 		go func() {
 			<-deadlineCtx.Done()
@@ -2237,13 +2432,23 @@ func (api *PublicDebugAPI) traceTx(ctx context.Context, message evmcore.Message,
 	// Call Prepare to clear out the statedb access list
 	statedb.Prepare(txctx.TxHash, txctx.TxIndex)
 
+	nativeTracer, isNative := tracer.(native.Tracer)
+	if isNative {
+		nativeTracer.CaptureTxStart(message.Gas())
+	}
 	result, err := evmcore.ApplyMessage(vmenv, message, new(evmcore.GasPool).AddGas(message.Gas()))
+	if result != nil {
+		rpclimit.ChargeGas(ctx, result.UsedGas)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %w", err)
 	}
 	if err := statedb.Error(); err != nil {
 		return nil, fmt.Errorf("StateDB error while tracing tx %s: %w", txctx.TxHash, err)
 	}
+	if isNative {
+		nativeTracer.CaptureTxEnd(message.Gas() - result.UsedGas)
+	}
 
 	// Depending on the tracer type, format and return the output.
 	switch tracer := tracer.(type) {
@@ -2265,6 +2470,13 @@ func (api *PublicDebugAPI) traceTx(ctx context.Context, message evmcore.Message,
 			Logs:        res,
 		}, nil
 
+	case native.Tracer:
+		result, err := tracer.GetResult()
+		if responseSizeLimit > 0 && len(result) > responseSizeLimit {
+			return nil, ErrMaxResponseSize
+		}
+		return result, err
+
 	case *tracers.Tracer:
 		result, err := tracer.GetResult()
 		if err != nil && result == nil {
@@ -2372,6 +2584,53 @@ func (api *PublicDebugAPI) traceBlock(ctx context.Context, block *evmcore.EvmBlo
 	return results, nil
 }
 
+// TraceBlock returns the structured logs created during the execution of EVM
+// for a raw RLP-encoded block, executed on top of the state of its parent block.
+// The block itself does not need to be known to the node.
+func (api *PublicDebugAPI) TraceBlock(ctx context.Context, blob hexutil.Bytes, config *TraceConfig) ([]*txTraceResult, error) {
+	ethBlock := new(types.Block)
+	if err := rlp.DecodeBytes(blob, ethBlock); err != nil {
+		return nil, fmt.Errorf("could not decode block: %w", err)
+	}
+	block := evmcore.NewEvmBlock(evmcore.ConvertFromEthHeader(ethBlock.Header()), ethBlock.Transactions())
+	return api.traceBlock(ctx, block, config)
+}
+
+// TraceCallConfig is the config for traceCall API. It holds one more
+// field to override the state for tracing.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides *StateOverride
+}
+
+// TraceCall lets you trace a given eth_call. It collects the structured logs
+// created during the execution of EVM if the given transaction was added on
+// top of the provided block and returns them as a JSON object.
+func (api *PublicDebugAPI) TraceCall(ctx context.Context, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	statedb, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	defer statedb.Release()
+
+	var traceConfig *TraceConfig
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, err
+		}
+		traceConfig = &config.TraceConfig
+	}
+
+	msg, err := args.ToMessage(api.b.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	txctx := &tracers.Context{
+		BlockHash: header.Hash,
+	}
+	return api.traceTx(ctx, msg, txctx, header, statedb, traceConfig)
+}
+
 // stateAtTransaction returns the execution environment of a certain transaction.
 func (api *PublicDebugAPI) stateAtTransaction(ctx context.Context, block *evmcore.EvmBlock, txIndex int) (evmcore.Message, state.StateDB, error) {
 	// Short circuit if it's genesis block.
@@ -33,9 +33,11 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 )
 
 // PeerProgress is synchronization status of a peer
@@ -55,6 +57,7 @@ type Backend interface {
 	Progress() PeerProgress
 	SuggestGasTipCap(ctx context.Context, certainty uint64) *big.Int
 	EffectiveMinGasPrice(ctx context.Context) *big.Int
+	GasPriceStats(ctx context.Context, limit uint64) []gasprice.Stat
 	AccountManager() *accounts.Manager
 	ExtRPCEnabled() bool
 	RPCGasCap() uint64            // global gas cap for eth_call over rpc: DoS protection
@@ -69,6 +72,7 @@ type Backend interface {
 	BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*evmcore.EvmBlock, error)
 	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (state.StateDB, *evmcore.EvmHeader, error)
 	ResolveRpcBlockNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (idx.Block, error)
+	ArchiveBlockHeight(ctx context.Context) (oldest idx.Block, latest idx.Block, err error)
 	BlockByHash(ctx context.Context, hash common.Hash) (*evmcore.EvmBlock, error)
 	GetReceiptsByNumber(ctx context.Context, number rpc.BlockNumber) (types.Receipts, error)
 	GetTd(hash common.Hash) *big.Int
@@ -76,8 +80,13 @@ type Backend interface {
 	MinGasPrice() *big.Int
 	MaxGasLimit() uint64
 
+	// Stored transaction traces API
+	GetBlockTraces(ctx context.Context, number idx.Block) ([]txtrace.ActionTrace, bool, error)
+	FindTraceBlocks(ctx context.Context, from, to idx.Block, fromAddresses, toAddresses []common.Address) ([]idx.Block, bool, error)
+
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SendPrivateTx(ctx context.Context, signedTx *types.Transaction) error
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, uint64, uint64, error)
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
@@ -85,6 +94,11 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions)
+	TxPoolQueuedReasons() map[common.Address]map[uint64]error
+	RemovePoolTransaction(txHash common.Hash) bool
+	RemovePoolSender(addr common.Address) int
+	TxPoolDenylist() evmcore.TxDenylist
+	UpdateTxPoolDenylist(add, remove evmcore.TxDenylist) (int, error)
 	SubscribeNewTxsNotify(chan<- evmcore.NewTxsNotify) notify.Subscription
 
 	ChainConfig() *params.ChainConfig
@@ -132,6 +146,12 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicTxPoolAPI(apiBackend),
 			Public:    true,
+		}, {
+			// served over HTTP and WS only if enabled explicitly with --http.api/--ws.api
+			Namespace: "txpooladmin",
+			Version:   "1.0",
+			Service:   NewPrivateTxPoolAPI(apiBackend),
+			Public:    false,
 		}, {
 			Namespace: "debug",
 			Version:   "1.0",
@@ -0,0 +1,124 @@
+package ethapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// proofAccount is the RLP encoding of an account in the state trie.
+type proofAccount struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// VerifyProof checks the account and storage proofs of an eth_getProof result
+// against the given state root (the stateRoot field of the block header).
+// It does not need access to a node, so it can be used to check proofs offline.
+// The proof nodes may be listed in any order.
+func VerifyProof(stateRoot common.Hash, res *AccountResult) error {
+	if res == nil {
+		return errors.New("missing proof")
+	}
+	accountDb, err := proofNodesDb(res.AccountProof)
+	if err != nil {
+		return fmt.Errorf("invalid account proof: %w", err)
+	}
+	accountRlp, err := trie.VerifyProof(stateRoot, crypto.Keccak256(res.Address.Bytes()), accountDb)
+	if err != nil {
+		return fmt.Errorf("invalid account proof: %w", err)
+	}
+
+	account := proofAccount{
+		Balance:  new(big.Int),
+		Root:     types.EmptyRootHash,
+		CodeHash: crypto.Keccak256(nil),
+	}
+	if accountRlp != nil {
+		if err := rlp.DecodeBytes(accountRlp, &account); err != nil {
+			return fmt.Errorf("invalid account encoding: %w", err)
+		}
+	}
+	if res.Balance == nil || account.Balance.Cmp(res.Balance.ToInt()) != 0 {
+		return fmt.Errorf("balance mismatch: proven %s", account.Balance)
+	}
+	if account.Nonce != uint64(res.Nonce) {
+		return fmt.Errorf("nonce mismatch: proven %d, got %d", account.Nonce, res.Nonce)
+	}
+	if !bytes.Equal(account.CodeHash, res.CodeHash.Bytes()) {
+		return fmt.Errorf("code hash mismatch: proven %x, got %s", account.CodeHash, res.CodeHash.Hex())
+	}
+	if account.Root != res.StorageHash {
+		return fmt.Errorf("storage hash mismatch: proven %s, got %s", account.Root.Hex(), res.StorageHash.Hex())
+	}
+
+	for _, slot := range res.StorageProof {
+		if err := verifyStorageProof(account.Root, slot); err != nil {
+			return fmt.Errorf("invalid storage proof of key %s: %w", slot.Key, err)
+		}
+	}
+	return nil
+}
+
+func verifyStorageProof(storageRoot common.Hash, slot StorageResult) error {
+	var value *big.Int
+	if slot.Value != nil {
+		value = slot.Value.ToInt()
+	} else {
+		value = new(big.Int)
+	}
+	if storageRoot == types.EmptyRootHash {
+		if value.Sign() != 0 {
+			return errors.New("non-zero value in empty storage")
+		}
+		return nil
+	}
+
+	storageDb, err := proofNodesDb(slot.Proof)
+	if err != nil {
+		return err
+	}
+	key := common.HexToHash(slot.Key)
+	valueRlp, err := trie.VerifyProof(storageRoot, crypto.Keccak256(key.Bytes()), storageDb)
+	if err != nil {
+		return err
+	}
+	proven := new(big.Int)
+	if valueRlp != nil {
+		var content []byte
+		if err := rlp.DecodeBytes(valueRlp, &content); err != nil {
+			return fmt.Errorf("invalid value encoding: %w", err)
+		}
+		proven.SetBytes(content)
+	}
+	if proven.Cmp(value) != 0 {
+		return fmt.Errorf("value mismatch: proven %s, got %s", proven, value)
+	}
+	return nil
+}
+
+// proofNodesDb indexes hex-encoded trie nodes by their hash.
+func proofNodesDb(nodes []string) (*memorydb.Database, error) {
+	db := memorydb.New()
+	for _, node := range nodes {
+		raw, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Put(crypto.Keccak256(raw), raw); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
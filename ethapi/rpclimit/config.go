@@ -0,0 +1,63 @@
+package rpclimit
+
+import "time"
+
+// Limit is a token bucket of cost units.
+type Limit struct {
+	// Rate is the number of cost units regained per second, zero means unlimited
+	Rate float64
+	// Burst is the maximum number of cost units available at once
+	Burst float64
+	// Name of the client in metrics, the client identity is used if empty
+	Name string `toml:",omitempty"`
+}
+
+// Config of the RPC limits.
+type Config struct {
+	// Default is the limit of each client which has no own limit.
+	// The limiting is disabled if it's unlimited and no client limits are configured.
+	Default Limit
+	// Clients are the limits of particular clients, keyed by the client identity:
+	// "ip:<address>", "key:<API key>" or "jwt:<subject>".
+	Clients map[string]Limit `toml:",omitempty"`
+	// Methods are the static costs of RPC methods, the cost of an unlisted method is 1.
+	// The ftm methods cost as the eth ones unless listed separately.
+	Methods map[string]uint64 `toml:",omitempty"`
+	// GasUnit is the EVM gas charged as one cost unit, zero disables the gas accounting
+	GasUnit uint64
+	// TimeUnit is the execution time charged as one cost unit, zero disables the time accounting
+	TimeUnit time.Duration
+	// APIKeyHeader is the HTTP header identifying a client by API key.
+	// Only the API keys listed in Clients are recognized, other clients are identified by IP.
+	APIKeyHeader string
+	// JWTSecret is the hex-encoded HS256 secret to verify the bearer tokens identifying clients by subject
+	JWTSecret string `toml:",omitempty"`
+	// TrustForwardedFor identifies clients by the X-Forwarded-For header, for nodes behind a reverse proxy
+	TrustForwardedFor bool
+}
+
+// DefaultConfig returns the default limits, which are disabled.
+func DefaultConfig() Config {
+	return Config{
+		Methods: map[string]uint64{
+			"eth_call":                 2,
+			"eth_estimateGas":          2,
+			"eth_createAccessList":     2,
+			"eth_simulateV1":           10,
+			"eth_getLogs":              10,
+			"eth_getLogsPage":          10,
+			"eth_getFilterLogs":        10,
+			"debug_traceTransaction":   20,
+			"debug_traceCall":          20,
+			"debug_traceBlockByNumber": 100,
+			"debug_traceBlockByHash":   100,
+			"trace_transaction":        20,
+			"trace_get":                20,
+			"trace_block":              50,
+			"trace_filter":             100,
+		},
+		GasUnit:      1000000,
+		TimeUnit:     100 * time.Millisecond,
+		APIKeyHeader: "X-Api-Key",
+	}
+}
@@ -0,0 +1,224 @@
+package rpclimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRequestSize is the same as the request size limit of the RPC server
+	maxRequestSize = 5 * 1024 * 1024
+
+	errcodeParse          = -32700
+	errcodeInvalidRequest = -32600
+	errcodeLimitExceeded  = -32005
+)
+
+// call is a JSON-RPC request of a single call or a batch
+type call struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+}
+
+type callError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type callResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   callError       `json:"error"`
+}
+
+// Handler limits the JSON-RPC requests over HTTP before passing them to the next handler.
+// The next handler is expected to serve only the APIs offered over HTTP.
+// The requests are parsed strictly, so every call executed by the next handler is accounted.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return &handler{
+		limiter: l,
+		next:    next,
+	}
+}
+
+type handler struct {
+	limiter *Limiter
+	next    http.Handler
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	if r.ContentLength > maxRequestSize {
+		http.Error(w, fmt.Sprintf("content length too large (%d>%d)", r.ContentLength, maxRequestSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestSize {
+		http.Error(w, fmt.Sprintf("content length too large (>%d)", maxRequestSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	calls, batch, err := parseCalls(body)
+	if err != nil {
+		writeErrors(w, http.StatusOK, []call{{}}, false, callError{
+			Code:    errcodeParse,
+			Message: err.Error(),
+		})
+		return
+	}
+	if len(calls) == 0 {
+		writeErrors(w, http.StatusOK, []call{{}}, false, callError{
+			Code:    errcodeInvalidRequest,
+			Message: "empty batch",
+		})
+		return
+	}
+
+	client := h.limiter.clientOf(r)
+	var cost uint64
+	for _, c := range calls {
+		cost += h.limiter.MethodCost(c.Method)
+	}
+	if ok, wait := h.limiter.Take(client, cost); !ok {
+		h.limiter.markRejected(client, calls)
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(wait.Seconds())), 10))
+		writeErrors(w, http.StatusTooManyRequests, calls, batch, callError{
+			Code:    errcodeLimitExceeded,
+			Message: "request rate limit exceeded",
+		})
+		return
+	}
+
+	u := &usage{}
+	start := time.Now()
+	h.next.ServeHTTP(w, r.WithContext(withUsage(r.Context(), u)))
+	elapsed := time.Since(start)
+
+	execCost := h.limiter.ExecutionCost(u.Gas(), elapsed)
+	h.limiter.Charge(client, execCost)
+	h.limiter.markCalls(client, calls, execCost, elapsed)
+}
+
+// markCalls updates the metrics of the executed calls.
+// The measured cost and time of a batch are shared by its calls equally.
+func (l *Limiter) markCalls(client string, calls []call, execCost uint64, elapsed time.Duration) {
+	n := uint64(len(calls))
+	for i, c := range calls {
+		callCost := l.MethodCost(c.Method) + execCost/n
+		if uint64(i) < execCost%n {
+			callCost++
+		}
+		mm, cm := l.metricsOf(c.Method, client)
+		for _, m := range []*callMetrics{mm, cm} {
+			m.calls.Mark(1)
+			m.cost.Mark(int64(callCost))
+			m.time.Update(elapsed / time.Duration(n))
+		}
+	}
+}
+
+// markRejected updates the metrics of the rejected calls.
+func (l *Limiter) markRejected(client string, calls []call) {
+	for _, c := range calls {
+		mm, cm := l.metricsOf(c.Method, client)
+		mm.rejected.Mark(1)
+		cm.rejected.Mark(1)
+	}
+}
+
+// clientOf identifies the client of the request by JWT subject, API key or IP, in this order.
+func (l *Limiter) clientOf(r *http.Request) string {
+	if l.jwtSecret != nil {
+		if sub, ok := jwtSubject(r.Header.Get("Authorization"), l.jwtSecret, l.now()); ok {
+			return "jwt:" + sub
+		}
+	}
+	if l.cfg.APIKeyHeader != "" {
+		if key := r.Header.Get(l.cfg.APIKeyHeader); key != "" {
+			// unknown keys are ignored, otherwise a client would get a new bucket with every new key
+			if _, ok := l.cfg.Clients["key:"+key]; ok {
+				return "key:" + key
+			}
+		}
+	}
+	if l.cfg.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			// the last address is appended by the proxy, the previous ones are provided by the client
+			addrs := strings.Split(forwarded, ",")
+			return "ip:" + strings.TrimSpace(addrs[len(addrs)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// parseCalls parses the request body as a single call or a batch.
+// Anything but whitespace after the request is an error, because the RPC server
+// would execute the request and ignore the rest.
+func parseCalls(body []byte) (calls []call, batch bool, err error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, false, fmt.Errorf("parse error: %w", err)
+	}
+	if rest := body[dec.InputOffset():]; len(bytes.TrimSpace(rest)) != 0 {
+		return nil, false, errors.New("parse error: unexpected data after the request")
+	}
+	raw = bytes.TrimSpace(raw)
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &calls); err != nil {
+			return nil, true, fmt.Errorf("parse error: %w", err)
+		}
+		return calls, true, nil
+	}
+	var c call
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, false, fmt.Errorf("parse error: %w", err)
+	}
+	return []call{c}, false, nil
+}
+
+func writeErrors(w http.ResponseWriter, status int, calls []call, batch bool, callErr callError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponses(calls, batch, callErr))
+}
+
+// errorResponses returns the error responses of the calls, a batch is answered by a batch
+func errorResponses(calls []call, batch bool, callErr callError) interface{} {
+	responses := make([]callResponse, len(calls))
+	for i, c := range calls {
+		id := c.ID
+		if len(id) == 0 {
+			id = json.RawMessage("null")
+		}
+		responses[i] = callResponse{
+			Version: "2.0",
+			ID:      id,
+			Error:   callErr,
+		}
+	}
+	if batch {
+		return responses
+	}
+	return responses[0]
+}
@@ -0,0 +1,72 @@
+package rpclimit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func decodeJWTSecret(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		s = "0x" + s
+	}
+	secret, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, errors.New("invalid JWT secret: " + err.Error())
+	}
+	if len(secret) < 32 {
+		return nil, errors.New("JWT secret must be at least 32 bytes")
+	}
+	return secret, nil
+}
+
+// jwtSubject returns the subject of the HS256 bearer token, if the token is valid and not expired.
+func jwtSubject(authorization string, secret []byte, now time.Time) (string, bool) {
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if token == authorization {
+		return "", false
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return "", false
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if !decodeJWTPart(parts[0], &header) || header.Alg != "HS256" {
+		return "", false
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp"`
+	}
+	if !decodeJWTPart(parts[1], &claims) || claims.Sub == "" {
+		return "", false
+	}
+	if claims.Exp != 0 && now.Unix() >= claims.Exp {
+		return "", false
+	}
+	return claims.Sub, true
+}
+
+func decodeJWTPart(part string, v interface{}) bool {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, v) == nil
+}
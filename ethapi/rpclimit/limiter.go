@@ -0,0 +1,230 @@
+package rpclimit
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// purgePeriod is the period of dropping the buckets which are full, i.e. of idle clients
+	purgePeriod = time.Minute
+	// otherMetrics is the metrics name of unlisted methods and clients
+	otherMetrics = "other"
+)
+
+// bucket is a token bucket, the number of tokens may be negative after a post-charged cost
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.updated).Seconds() * b.limit.Rate
+	if b.tokens > b.limit.Burst {
+		b.tokens = b.limit.Burst
+	}
+	b.updated = now
+}
+
+// callMetrics are the metrics of a method or a client
+type callMetrics struct {
+	calls    metrics.Meter
+	cost     metrics.Meter
+	rejected metrics.Meter
+	time     metrics.Timer
+}
+
+func newCallMetrics(prefix string) *callMetrics {
+	return &callMetrics{
+		calls:    metrics.GetOrRegisterMeter(prefix+"/calls", nil),
+		cost:     metrics.GetOrRegisterMeter(prefix+"/cost", nil),
+		rejected: metrics.GetOrRegisterMeter(prefix+"/rejected", nil),
+		time:     metrics.GetOrRegisterTimer(prefix+"/time", nil),
+	}
+}
+
+// Limiter charges the RPC calls of clients by the cost model and limits them by token buckets.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	jwtSecret []byte
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPurge time.Time
+
+	metricsMu     sync.Mutex
+	methodMetrics map[string]*callMetrics
+	clientMetrics map[string]*callMetrics
+}
+
+// New creates a Limiter with the given config.
+func New(cfg Config) (*Limiter, error) {
+	l := &Limiter{
+		cfg:           cfg,
+		now:           time.Now,
+		buckets:       make(map[string]*bucket),
+		methodMetrics: make(map[string]*callMetrics),
+		clientMetrics: make(map[string]*callMetrics),
+	}
+	if cfg.JWTSecret != "" {
+		secret, err := decodeJWTSecret(cfg.JWTSecret)
+		if err != nil {
+			return nil, err
+		}
+		l.jwtSecret = secret
+	}
+	l.lastPurge = l.now()
+	return l, nil
+}
+
+// Enabled returns true if any limit is configured.
+func (l *Limiter) Enabled() bool {
+	if l.cfg.Default.Rate > 0 {
+		return true
+	}
+	for _, limit := range l.cfg.Clients {
+		if limit.Rate > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Config returns the configuration of the limits.
+func (l *Limiter) Config() Config {
+	return l.cfg
+}
+
+// MethodCost returns the static cost of the method.
+func (l *Limiter) MethodCost(method string) uint64 {
+	if cost, ok := l.cfg.Methods[method]; ok {
+		return cost
+	}
+	if strings.HasPrefix(method, "ftm_") {
+		if cost, ok := l.cfg.Methods["eth_"+strings.TrimPrefix(method, "ftm_")]; ok {
+			return cost
+		}
+	}
+	return 1
+}
+
+// ExecutionCost returns the measured cost of the execution.
+func (l *Limiter) ExecutionCost(gas uint64, elapsed time.Duration) uint64 {
+	var cost uint64
+	if l.cfg.GasUnit != 0 {
+		cost += gas / l.cfg.GasUnit
+	}
+	if l.cfg.TimeUnit != 0 {
+		cost += uint64(elapsed / l.cfg.TimeUnit)
+	}
+	return cost
+}
+
+func (l *Limiter) limitOf(client string) Limit {
+	if limit, ok := l.cfg.Clients[client]; ok {
+		return limit
+	}
+	return l.cfg.Default
+}
+
+// Take charges the client by the cost, if the client has the tokens.
+// A cost above the burst is allowed for a client with the full bucket.
+// Otherwise, it returns the time to wait for the tokens.
+func (l *Limiter) Take(client string, cost uint64) (ok bool, wait time.Duration) {
+	limit := l.limitOf(client)
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.purge(now)
+	b := l.bucketOf(client, limit, now)
+	need := float64(cost)
+	if need > limit.Burst {
+		need = limit.Burst
+	}
+	if b.tokens < need {
+		return false, time.Duration((need - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	b.tokens -= float64(cost)
+	return true, 0
+}
+
+// Charge charges the client by the cost measured after the execution, the client may go into debt.
+func (l *Limiter) Charge(client string, cost uint64) {
+	limit := l.limitOf(client)
+	if limit.Rate <= 0 || cost == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucketOf(client, limit, l.now())
+	b.tokens -= float64(cost)
+}
+
+func (l *Limiter) bucketOf(client string, limit Limit, now time.Time) *bucket {
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{
+			limit:   limit,
+			tokens:  limit.Burst,
+			updated: now,
+		}
+		l.buckets[client] = b
+	}
+	b.refill(now)
+	return b
+}
+
+func (l *Limiter) purge(now time.Time) {
+	if now.Sub(l.lastPurge) < purgePeriod {
+		return
+	}
+	l.lastPurge = now
+	for client, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= b.limit.Burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// metricsOf returns the metrics of the method and the client.
+// Only the methods with a configured cost and the configured clients have own metrics,
+// to bound the number of metrics.
+func (l *Limiter) metricsOf(method, client string) (*callMetrics, *callMetrics) {
+	methodName := otherMetrics
+	if _, ok := l.cfg.Methods[method]; ok {
+		methodName = method
+	}
+	clientName := otherMetrics
+	if limit, ok := l.cfg.Clients[client]; ok {
+		clientName = limit.Name
+		if clientName == "" {
+			clientName = client
+		}
+	}
+
+	l.metricsMu.Lock()
+	defer l.metricsMu.Unlock()
+	mm, ok := l.methodMetrics[methodName]
+	if !ok {
+		mm = newCallMetrics("rpc/limit/method/" + methodName)
+		l.methodMetrics[methodName] = mm
+	}
+	cm, ok := l.clientMetrics[clientName]
+	if !ok {
+		cm = newCallMetrics("rpc/limit/client/" + clientName)
+		l.clientMetrics[clientName] = cm
+	}
+	return mm, cm
+}
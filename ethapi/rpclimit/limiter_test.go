@@ -0,0 +1,282 @@
+package rpclimit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(t *testing.T, cfg Config) (*Limiter, *time.Time) {
+	l, err := New(cfg)
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+	l.lastPurge = now
+	return l, &now
+}
+
+func TestLimiter_Take(t *testing.T) {
+	require := require.New(t)
+
+	cfg := DefaultConfig()
+	cfg.Default = Limit{Rate: 10, Burst: 100}
+	cfg.Clients = map[string]Limit{
+		"key:unlimited": {},
+		"key:slow":      {Rate: 1, Burst: 5},
+	}
+	l, now := newTestLimiter(t, cfg)
+	require.True(l.Enabled())
+
+	ok, _ := l.Take("ip:1", 60)
+	require.True(ok)
+	ok, wait := l.Take("ip:1", 60)
+	require.False(ok)
+	require.Equal(2*time.Second, wait)
+	// other clients have own buckets
+	ok, _ = l.Take("ip:2", 60)
+	require.True(ok)
+	ok, _ = l.Take("key:slow", 6)
+	require.True(ok, "a cost above the burst is allowed with the full bucket")
+	ok, _ = l.Take("key:slow", 1)
+	require.False(ok)
+	for i := 0; i < 10; i++ {
+		ok, _ = l.Take("key:unlimited", 1000)
+		require.True(ok)
+	}
+
+	*now = now.Add(2 * time.Second)
+	ok, _ = l.Take("ip:1", 60)
+	require.True(ok)
+
+	// the post-charged cost puts the client into debt
+	l.Charge("ip:2", 150)
+	*now = now.Add(5 * time.Second)
+	ok, _ = l.Take("ip:2", 1)
+	require.False(ok)
+	*now = now.Add(5 * time.Second)
+	ok, _ = l.Take("ip:2", 1)
+	require.True(ok)
+
+	// full buckets are purged
+	*now = now.Add(time.Hour)
+	ok, _ = l.Take("ip:3", 1)
+	require.True(ok)
+	require.Len(l.buckets, 1)
+}
+
+func TestLimiter_Cost(t *testing.T) {
+	require := require.New(t)
+
+	l, _ := newTestLimiter(t, DefaultConfig())
+	require.False(l.Enabled())
+	require.Equal(uint64(100), l.MethodCost("trace_filter"))
+	require.Equal(uint64(10), l.MethodCost("ftm_getLogs"))
+	require.Equal(uint64(1), l.MethodCost("eth_blockNumber"))
+	require.Equal(uint64(0), l.ExecutionCost(999999, 99*time.Millisecond))
+	require.Equal(uint64(5), l.ExecutionCost(3000000, 250*time.Millisecond))
+}
+
+func testJWT(secret []byte, claims string) string {
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestLimiter_ClientOf(t *testing.T) {
+	require := require.New(t)
+
+	secret := strings.Repeat("ab", 32)
+	cfg := DefaultConfig()
+	cfg.JWTSecret = secret
+	cfg.TrustForwardedFor = true
+	cfg.Clients = map[string]Limit{"key:known": {}}
+	l, now := newTestLimiter(t, cfg)
+	secretBytes, _ := decodeJWTSecret(secret)
+
+	req := func(headers ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = "10.0.0.1:5555"
+		for i := 0; i < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		return r
+	}
+
+	require.Equal("ip:10.0.0.1", l.clientOf(req()))
+	require.Equal("ip:10.0.0.1", l.clientOf(req("X-Api-Key", "unknown")))
+	require.Equal("key:known", l.clientOf(req("X-Api-Key", "known")))
+	require.Equal("ip:2.2.2.2", l.clientOf(req("X-Forwarded-For", "1.1.1.1, 2.2.2.2")))
+
+	token := testJWT(secretBytes, `{"sub":"alice","exp":2000}`)
+	require.Equal("jwt:alice", l.clientOf(req("Authorization", "Bearer "+token, "X-Api-Key", "known")))
+	require.Equal("key:known", l.clientOf(req("Authorization", "Bearer "+token+"x", "X-Api-Key", "known")))
+	*now = time.Unix(2000, 0)
+	require.Equal("ip:10.0.0.1", l.clientOf(req("Authorization", "Bearer "+token)))
+}
+
+func TestLimiter_Handler(t *testing.T) {
+	require := require.New(t)
+
+	cfg := DefaultConfig()
+	cfg.Default = Limit{Rate: 1, Burst: 20}
+	cfg.TimeUnit = 0
+	l, _ := newTestLimiter(t, cfg)
+
+	var served int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		ChargeGas(r.Context(), 3000000)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x0"}`))
+	})
+	h := l.Handler(next)
+
+	call := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.RemoteAddr = "10.0.0.1:5555"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// malformed requests aren't passed to the RPC server
+	for _, body := range []string{
+		``,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_call"`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}garbage`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}{"jsonrpc":"2.0","id":2,"method":"eth_call","params":[]}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"eth_call"}]]`,
+		`[1]`,
+	} {
+		w := call(body)
+		require.Equal(http.StatusOK, w.Code, body)
+		require.Contains(w.Body.String(), "-32700", body)
+	}
+	w := call(`[]`)
+	require.Equal(http.StatusOK, w.Code)
+	require.Contains(w.Body.String(), "-32600")
+	require.Equal(0, served)
+
+	// static cost 2 and 3 units of the gas
+	w = call(" {\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"eth_call\",\"params\":[]}\n")
+	require.Equal(http.StatusOK, w.Code)
+	require.Equal(1, served)
+
+	// every call of a batch is accounted, the measured gas is of the whole batch
+	w = call(`[{"jsonrpc":"2.0","id":1,"method":"eth_call"},{"jsonrpc":"2.0","id":"x","method":"trace_filter"}]`)
+	require.Equal(http.StatusTooManyRequests, w.Code)
+	require.Contains(w.Body.String(), `"id":"x"`)
+	require.Contains(w.Body.String(), "-32005")
+	require.Equal(1, served)
+
+	w = call(`[{"jsonrpc":"2.0","id":1,"method":"eth_call"},{"jsonrpc":"2.0","id":2,"method":"eth_call"}]`)
+	require.Equal(http.StatusOK, w.Code)
+	require.Equal(2, served)
+	require.Equal(float64(8), l.buckets["ip:10.0.0.1"].tokens)
+
+	// oversized requests are rejected
+	w = call(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":["` + strings.Repeat("0", maxRequestSize) + `"]}`)
+	require.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	r.ContentLength = maxRequestSize + 1
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	require.Equal(2, served)
+}
+
+type testService struct{}
+
+func (s *testService) Echo(x string) string {
+	return x
+}
+
+func TestLimiter_WebsocketHandler(t *testing.T) {
+	require := require.New(t)
+
+	cfg := DefaultConfig()
+	cfg.Default = Limit{Rate: 1, Burst: 5}
+	cfg.Methods = map[string]uint64{"test_echo": 2}
+	l, _ := newTestLimiter(t, cfg)
+
+	srv := rpc.NewServer()
+	defer srv.Stop()
+	require.NoError(srv.RegisterName("test", &testService{}))
+	server := httptest.NewServer(l.WebsocketHandler(srv, []string{"http://example.com"}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(err)
+	defer conn.Close()
+	call := func(msg string) string {
+		require.NoError(conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+		_, data, err := conn.ReadMessage()
+		require.NoError(err)
+		return string(data)
+	}
+	tokens := func() float64 {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.buckets["ip:127.0.0.1"].tokens
+	}
+
+	// malformed messages are answered by the limiter
+	require.Contains(call(`{"jsonrpc":"2.0","id":1,"method":"test_echo"`), "-32700")
+	require.Contains(call(`[]`), "-32600")
+
+	// every message is charged
+	require.Contains(call(`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x"]}`), `"result":"x"`)
+	require.Equal(float64(3), tokens())
+	resp := call(`[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x"]},{"jsonrpc":"2.0","id":"y","method":"test_echo","params":["y"]}]`)
+	require.Contains(resp, `"id":"y"`)
+	require.Contains(resp, "-32005")
+	require.Equal(float64(3), tokens())
+
+	// the connection is kept after the rejection
+	require.Contains(call(`{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["z"]}`), `"result":"z"`)
+	require.Equal(float64(1), tokens())
+	require.Contains(call(`{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["z"]}`), "-32005")
+
+	// the origin is checked on the handshake
+	header := http.Header{"Origin": []string{"http://other.com"}}
+	_, _, err = websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+	require.Error(err)
+}
+
+func TestOriginMatches(t *testing.T) {
+	for _, test := range []struct {
+		rule, origin string
+		ok           bool
+	}{
+		{"http://localhost", "http://localhost", true},
+		{"http://localhost", "http://localhost:8080", true},
+		{"http://localhost", "https://localhost", false},
+		{"localhost", "https://localhost", true},
+		{"http://localhost:8080", "http://localhost:8081", false},
+		{"http://example.com", "http://localhost", false},
+	} {
+		require.Equal(t, test.ok, originMatches(test.rule, test.origin), "rule %q origin %q", test.rule, test.origin)
+	}
+}
+
+func TestChargeGas(t *testing.T) {
+	// no-op without the usage
+	ChargeGas(context.Background(), 1)
+
+	u := &usage{}
+	ctx := withUsage(context.Background(), u)
+	ChargeGas(ctx, 1)
+	ChargeGas(ctx, 2)
+	require.Equal(t, uint64(3), u.Gas())
+}
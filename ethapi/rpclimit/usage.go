@@ -0,0 +1,29 @@
+package rpclimit
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type usageKey struct{}
+
+// usage accumulates the resources consumed by an RPC request
+type usage struct {
+	gas uint64
+}
+
+func withUsage(ctx context.Context, u *usage) context.Context {
+	return context.WithValue(ctx, usageKey{}, u)
+}
+
+// ChargeGas accounts the EVM gas consumed by the RPC request of the context.
+// It does nothing if the request is not limited.
+func ChargeGas(ctx context.Context, gas uint64) {
+	if u, ok := ctx.Value(usageKey{}).(*usage); ok {
+		atomic.AddUint64(&u.gas, gas)
+	}
+}
+
+func (u *usage) Gas() uint64 {
+	return atomic.LoadUint64(&u.gas)
+}
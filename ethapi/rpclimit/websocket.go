@@ -0,0 +1,275 @@
+package rpclimit
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// the same as the limits of the WebSocket connections of the RPC server
+	wsMessageSizeLimit = 15 * 1024 * 1024
+	wsWriteTimeout     = 10 * time.Second
+)
+
+// WebsocketHandler serves the JSON-RPC server to WebSocket connections, limiting every incoming message
+// the same way as the HTTP requests. The server is expected to serve only the APIs offered over WebSocket.
+// The execution time of the calls is charged when they are answered, but the EVM gas isn't accounted,
+// as the server doesn't pass the context of the connection to the calls.
+// allowedOrigins are the allowed origin URLs, "*" allows any origin.
+func (l *Limiter) WebsocketHandler(srv *rpc.Server, allowedOrigins []string) http.Handler {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     originValidator(allowedOrigins),
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := l.clientOf(r)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Debug("WebSocket upgrade failed", "err", err)
+			return
+		}
+		conn.SetReadLimit(wsMessageSizeLimit)
+		c := &wsConn{
+			limiter: l,
+			client:  client,
+			conn:    conn,
+			pending: make(map[string]*wsRequest),
+		}
+		srv.ServeCodec(rpc.NewFuncCodec(c, c.encode, c.decode), 0)
+	})
+}
+
+// wsRequest is an admitted message, which is waiting for the response
+type wsRequest struct {
+	calls []call
+	start time.Time
+}
+
+// wsConn limits the messages of a WebSocket connection, it's the connection of the RPC codec
+type wsConn struct {
+	limiter *Limiter
+	client  string
+	conn    *websocket.Conn
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]*wsRequest // by ID of the calls
+}
+
+// decode reads the next message which is admitted by the limiter.
+// The rejected and malformed messages are answered directly.
+func (c *wsConn) decode(v interface{}) error {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if c.admit(data) {
+			return json.Unmarshal(data, v)
+		}
+	}
+}
+
+func (c *wsConn) admit(data []byte) bool {
+	calls, batch, err := parseCalls(data)
+	if err != nil {
+		c.write(errorResponses([]call{{}}, false, callError{
+			Code:    errcodeParse,
+			Message: err.Error(),
+		}))
+		return false
+	}
+	if len(calls) == 0 {
+		c.write(errorResponses([]call{{}}, false, callError{
+			Code:    errcodeInvalidRequest,
+			Message: "empty batch",
+		}))
+		return false
+	}
+
+	var cost uint64
+	for _, call := range calls {
+		cost += c.limiter.MethodCost(call.Method)
+	}
+	if ok, _ := c.limiter.Take(c.client, cost); !ok {
+		c.limiter.markRejected(c.client, calls)
+		c.write(errorResponses(calls, batch, callError{
+			Code:    errcodeLimitExceeded,
+			Message: "request rate limit exceeded",
+		}))
+		return false
+	}
+
+	req := &wsRequest{
+		calls: calls,
+		start: time.Now(),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var answered bool
+	for _, call := range calls {
+		if len(call.ID) != 0 {
+			c.pending[string(call.ID)] = req
+			answered = true
+		}
+	}
+	if !answered {
+		// notifications aren't answered, so their execution can't be measured
+		c.limiter.markCalls(c.client, calls, 0, 0)
+	}
+	return true
+}
+
+// encode writes the message of the RPC server, and charges the execution of the answered request.
+func (c *wsConn) encode(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.settle(data)
+	return c.writeMessage(data)
+}
+
+// settle charges the execution of the requests answered by the message.
+func (c *wsConn) settle(data []byte) {
+	responses, _, err := parseCalls(data)
+	if err != nil {
+		return
+	}
+	var answered []*wsRequest
+	c.mu.Lock()
+	for _, resp := range responses {
+		if len(resp.ID) == 0 {
+			continue // subscription notification
+		}
+		req, ok := c.pending[string(resp.ID)]
+		if !ok {
+			continue
+		}
+		// all the calls of a batch are answered at once
+		for _, call := range req.calls {
+			if c.pending[string(call.ID)] == req {
+				delete(c.pending, string(call.ID))
+			}
+		}
+		answered = append(answered, req)
+	}
+	c.mu.Unlock()
+
+	for _, req := range answered {
+		elapsed := time.Since(req.start)
+		execCost := c.limiter.ExecutionCost(0, elapsed)
+		c.limiter.Charge(c.client, execCost)
+		c.limiter.markCalls(c.client, req.calls, execCost, elapsed)
+	}
+}
+
+func (c *wsConn) write(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := c.writeMessage(data); err != nil {
+		log.Debug("Failed to write WebSocket message", "err", err)
+	}
+}
+
+func (c *wsConn) writeMessage(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// SetWriteDeadline is called by the RPC codec before every write, the deadline is set by the writes instead,
+// as the messages of the limiter are written concurrently.
+func (c *wsConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
+
+// RemoteAddr returns the address of the client for the logs of the RPC server.
+func (c *wsConn) RemoteAddr() string {
+	return c.conn.RemoteAddr().String()
+}
+
+// originValidator checks the origin of the WebSocket handshake the same way as the RPC server.
+// Requests without the origin aren't from a browser, so they are allowed.
+func originValidator(allowedOrigins []string) func(*http.Request) bool {
+	var origins []string
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			return func(*http.Request) bool { return true }
+		}
+		if origin != "" {
+			origins = append(origins, strings.ToLower(origin))
+		}
+	}
+	// only localhost is allowed by default
+	if len(origins) == 0 {
+		origins = append(origins, "http://localhost")
+		if hostname, err := os.Hostname(); err == nil {
+			origins = append(origins, "http://"+strings.ToLower(hostname))
+		}
+	}
+	return func(r *http.Request) bool {
+		if _, ok := r.Header["Origin"]; !ok {
+			return true
+		}
+		origin := strings.ToLower(r.Header.Get("Origin"))
+		for _, allowed := range origins {
+			if originMatches(allowed, origin) {
+				return true
+			}
+		}
+		log.Warn("Rejected WebSocket connection", "origin", origin)
+		return false
+	}
+}
+
+// originMatches checks the origin by the rule, the scheme and port are checked only if the rule has them.
+func originMatches(rule, origin string) bool {
+	ruleScheme, ruleHost, rulePort, err := parseOrigin(rule)
+	if err != nil {
+		return false
+	}
+	scheme, host, port, err := parseOrigin(origin)
+	if err != nil {
+		return false
+	}
+	return (ruleScheme == "" || ruleScheme == scheme) &&
+		ruleHost == host &&
+		(rulePort == "" || rulePort == port)
+}
+
+func parseOrigin(origin string) (scheme, host, port string, err error) {
+	if !strings.Contains(origin, "://") {
+		origin = "//" + origin
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return "", "", "", err
+	}
+	host = u.Host
+	if h, p, err := net.SplitHostPort(u.Host); err == nil {
+		host, port = h, p
+	}
+	return u.Scheme, host, port, nil
+}
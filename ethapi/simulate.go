@@ -0,0 +1,308 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/ethapi/rpclimit"
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks which can be simulated in one request.
+	maxSimulateBlocks = 256
+	// simulateTimeIncrement is the default time distance between simulated blocks in seconds.
+	simulateTimeIncrement = 1
+)
+
+// BlockOverrides is a set of header fields to override for a simulated block.
+type BlockOverrides struct {
+	Number        *hexutil.Big    `json:"number"`
+	Time          *hexutil.Uint64 `json:"time"`
+	GasLimit      *hexutil.Uint64 `json:"gasLimit"`
+	FeeRecipient  *common.Address `json:"feeRecipient"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas"`
+}
+
+// Apply overrides the given header fields.
+func (o *BlockOverrides) Apply(header *evmcore.EvmHeader) {
+	if o == nil {
+		return
+	}
+	if o.Number != nil {
+		header.Number = new(big.Int).Set(o.Number.ToInt())
+	}
+	if o.Time != nil {
+		header.Time = inter.FromUnix(int64(*o.Time))
+	}
+	if o.GasLimit != nil {
+		header.GasLimit = uint64(*o.GasLimit)
+	}
+	if o.FeeRecipient != nil {
+		header.Coinbase = *o.FeeRecipient
+	}
+	if o.BaseFeePerGas != nil {
+		header.BaseFee = new(big.Int).Set(o.BaseFeePerGas.ToInt())
+	}
+}
+
+// SimBlock is a batch of calls to be simulated sequentially in one block.
+type SimBlock struct {
+	BlockOverrides *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides *StateOverride    `json:"stateOverrides"`
+	Calls          []TransactionArgs `json:"calls"`
+}
+
+// SimOpts are the inputs of eth_simulateV1.
+// If Validation is set, calls are checked against the block base fee, the same way as transactions are.
+type SimOpts struct {
+	BlockStateCalls []SimBlock `json:"blockStateCalls"`
+	Validation      bool       `json:"validation"`
+}
+
+// SimCallError is the error of a simulated call, which doesn't break the simulation.
+type SimCallError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    string `json:"data,omitempty"`
+}
+
+// SimCallResult is the result of one simulated call.
+type SimCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *SimCallError  `json:"error,omitempty"`
+}
+
+// SimBlockResult is the result of one simulated block.
+type SimBlockResult struct {
+	Number        hexutil.Uint64  `json:"number"`
+	Hash          common.Hash     `json:"hash"`
+	ParentHash    common.Hash     `json:"parentHash"`
+	Timestamp     hexutil.Uint64  `json:"timestamp"`
+	GasLimit      hexutil.Uint64  `json:"gasLimit"`
+	GasUsed       hexutil.Uint64  `json:"gasUsed"`
+	FeeRecipient  common.Address  `json:"miner"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas"`
+	Calls         []SimCallResult `json:"calls"`
+}
+
+// SimulateV1 executes a sequence of calls, optionally spread over several simulated blocks,
+// on top of the state of the given block. Every call sees the state changes of the calls before it.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (s *PublicBlockChainAPI) SimulateV1(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimBlockResult, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errors.New("empty input")
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, fmt.Errorf("too many blocks, maximum is %d", maxSimulateBlocks)
+	}
+	if blockNrOrHash == nil {
+		n := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &n
+	}
+	return DoSimulate(ctx, s.b, opts, *blockNrOrHash, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
+}
+
+// DoSimulate executes the simulated blocks on top of the state of the given block.
+// The global gas cap limits the gas of all the calls together, zero means unlimited.
+func DoSimulate(ctx context.Context, b Backend, opts SimOpts, blockNrOrHash rpc.BlockNumberOrHash, timeout time.Duration, globalGasCap uint64) ([]*SimBlockResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM simulation finished", "runtime", time.Since(start)) }(time.Now())
+
+	statedb, base, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	defer statedb.Release()
+
+	// Setup context so it may be cancelled when the simulation has completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	// the gas cap is shared by all the calls of all the blocks
+	var budget *evmcore.GasPool
+	if globalGasCap != 0 {
+		budget = new(evmcore.GasPool).AddGas(globalGasCap)
+	}
+
+	results := make([]*SimBlockResult, 0, len(opts.BlockStateCalls))
+	parent := base
+	for i, block := range opts.BlockStateCalls {
+		header := &evmcore.EvmHeader{
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			ParentHash: parent.Hash,
+			Time:       parent.Time + inter.FromUnix(simulateTimeIncrement),
+			Coinbase:   parent.Coinbase,
+			GasLimit:   parent.GasLimit,
+			BaseFee:    parent.BaseFee,
+		}
+		block.BlockOverrides.Apply(header)
+		if header.Number.Cmp(parent.Number) <= 0 {
+			return nil, fmt.Errorf("block %d: number %d is not above the parent number %d", i, header.Number, parent.Number)
+		}
+		if header.Time <= parent.Time {
+			return nil, fmt.Errorf("block %d: timestamp %d is not above the parent timestamp %d", i, header.Time.Unix(), parent.Time.Unix())
+		}
+		// simulated blocks are not sealed by any event, so derive the hash from the header fields
+		header.Hash = crypto.Keccak256Hash(header.ParentHash.Bytes(), header.Number.Bytes(), header.Time.Bytes())
+
+		if err := block.StateOverrides.Apply(statedb); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		result, err := simulateBlock(ctx, b, statedb, header, block.Calls, opts.Validation, globalGasCap, budget)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+			}
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		results = append(results, result)
+		parent = header
+	}
+	return results, nil
+}
+
+// simulateBlock executes the calls of one simulated block on the given state.
+// The gas used by the calls is subtracted from the budget, if any.
+func simulateBlock(ctx context.Context, b Backend, statedb state.StateDB, header *evmcore.EvmHeader, calls []TransactionArgs, validation bool, globalGasCap uint64, budget *evmcore.GasPool) (*SimBlockResult, error) {
+	vmConfig := opera.DefaultVMConfig
+	vmConfig.NoBaseFee = !validation
+
+	var (
+		gp      = new(evmcore.GasPool).AddGas(header.GasLimit)
+		gasUsed uint64
+		results = make([]SimCallResult, 0, len(calls))
+	)
+	for i, args := range calls {
+		// Use zero address if sender unspecified and the remaining block gas if gas unspecified.
+		if args.From == nil {
+			args.From = new(common.Address)
+		}
+		if args.Gas == nil {
+			remaining := gp.Gas()
+			if budget != nil && budget.Gas() < remaining {
+				remaining = budget.Gas()
+			}
+			args.Gas = (*hexutil.Uint64)(&remaining)
+		}
+		msg, err := args.ToMessage(globalGasCap, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		if budget != nil && (budget.Gas() == 0 || msg.Gas() > budget.Gas()) {
+			return nil, fmt.Errorf("call %d: gas cap %d exceeded (supplied gas %d, remaining %d)", i, globalGasCap, msg.Gas(), budget.Gas())
+		}
+		txHash := simulatedTxHash(args, msg, statedb.GetNonce(msg.From()))
+		statedb.Prepare(txHash, i)
+
+		evm, vmError, err := b.GetEVM(ctx, msg, statedb, header, &vmConfig)
+		if err != nil {
+			return nil, err
+		}
+		stop := context.AfterFunc(ctx, evm.Cancel)
+		result, err := evmcore.ApplyMessage(evm, msg, gp)
+		if result != nil {
+			rpclimit.ChargeGas(ctx, result.UsedGas)
+		}
+		stop()
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if err := statedb.Error(); err != nil {
+			return nil, fmt.Errorf("StateDB error: %w", err)
+		}
+		if evm.Cancelled() {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w (supplied gas %d)", i, err, msg.Gas())
+		}
+		gasUsed += result.UsedGas
+		if budget != nil {
+			if err := budget.SubGas(result.UsedGas); err != nil {
+				return nil, fmt.Errorf("call %d: %w", i, err)
+			}
+		}
+
+		callResult := SimCallResult{
+			ReturnValue: result.Return(),
+			Logs:        statedb.GetLogs(txHash, header.Hash),
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			Status:      hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if callResult.Logs == nil {
+			callResult.Logs = []*types.Log{}
+		}
+		for _, l := range callResult.Logs {
+			l.BlockNumber = header.Number.Uint64()
+		}
+		if result.Failed() {
+			callResult.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			if len(result.Revert()) > 0 {
+				revertErr := newRevertError(result)
+				callResult.ReturnValue = result.Revert()
+				callResult.Error = &SimCallError{
+					Message: revertErr.Error(),
+					Code:    revertErr.ErrorCode(),
+					Data:    revertErr.reason,
+				}
+			} else {
+				callResult.Error = &SimCallError{
+					Message: result.Err.Error(),
+					Code:    -32015, // VM execution error
+				}
+			}
+		}
+		results = append(results, callResult)
+		statedb.Finalise()
+	}
+
+	res := &SimBlockResult{
+		Number:       hexutil.Uint64(header.Number.Uint64()),
+		Hash:         header.Hash,
+		ParentHash:   header.ParentHash,
+		Timestamp:    hexutil.Uint64(header.Time.Unix()),
+		GasLimit:     hexutil.Uint64(header.GasLimit),
+		GasUsed:      hexutil.Uint64(gasUsed),
+		FeeRecipient: header.Coinbase,
+		Calls:        results,
+	}
+	if header.BaseFee != nil {
+		res.BaseFeePerGas = (*hexutil.Big)(header.BaseFee)
+	}
+	return res, nil
+}
+
+// simulatedTxHash returns a hash of the transaction, which would correspond to the simulated call.
+// It is used to attach the logs to the call.
+func simulatedTxHash(args TransactionArgs, msg types.Message, nonce uint64) common.Hash {
+	gas := hexutil.Uint64(msg.Gas())
+	args.Gas = &gas
+	args.Nonce = (*hexutil.Uint64)(&nonce)
+	if args.MaxFeePerGas == nil && args.GasPrice == nil {
+		args.GasPrice = (*hexutil.Big)(msg.GasPrice())
+	}
+	return args.toTransaction().Hash()
+}
@@ -10,12 +10,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/Fantom-foundation/go-opera/ethapi/rpclimit"
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/inter/state"
 	"github.com/Fantom-foundation/go-opera/opera"
@@ -115,6 +117,11 @@ func (s *PublicTxTraceAPI) replayBlock(ctx context.Context, block *evmcore.EvmBl
 		return nil, fmt.Errorf("genesis block is not traceable")
 	}
 
+	// use stored traces if available
+	if traces, ok, err := s.storedBlockTraces(ctx, block, txHash, traceIndex); err != nil || ok {
+		return traces, err
+	}
+
 	blockNumber := block.Number.Int64()
 	parentBlockNr := rpc.BlockNumber(blockNumber - 1)
 	callTrace := txtrace.CallTrace{
@@ -182,6 +189,9 @@ func (s *PublicTxTraceAPI) replayBlock(ctx context.Context, block *evmcore.EvmBl
 			}
 
 			res, err := evmcore.ApplyMessage(vmenv, msg, new(evmcore.GasPool).AddGas(msg.Gas()))
+			if res != nil {
+				rpclimit.ChargeGas(ctx, res.UsedGas)
+			}
 			failed := false
 			if err != nil {
 				failed = true
@@ -217,6 +227,42 @@ func (s *PublicTxTraceAPI) replayBlock(ctx context.Context, block *evmcore.EvmBl
 	return &callTrace.Actions, nil
 }
 
+// storedBlockTraces returns traces of the block from the traces store
+// filtered the same way as replayBlock does.
+// The returned bool is false if the block traces are not stored.
+func (s *PublicTxTraceAPI) storedBlockTraces(ctx context.Context, block *evmcore.EvmBlock, txHash *common.Hash, traceIndex *[]hexutil.Uint) (*[]txtrace.ActionTrace, bool, error) {
+	traces, ok, err := s.b.GetBlockTraces(ctx, idx.Block(block.NumberU64()))
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot get stored traces for block %v, error: %v", block.NumberU64(), err.Error())
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	if txHash != nil {
+		txTraces := make([]txtrace.ActionTrace, 0)
+		for _, trace := range traces {
+			if trace.TransactionHash == *txHash {
+				txTraces = append(txTraces, trace)
+			}
+		}
+		traces = txTraces
+	}
+	callTrace := txtrace.CallTrace{
+		Actions: make([]txtrace.ActionTrace, 0),
+	}
+	callTrace.AddTraces(&traces, traceIndex)
+
+	// In case of empty result create empty trace for empty block
+	if len(callTrace.Actions) == 0 {
+		if traceIndex != nil || txHash != nil {
+			return nil, true, nil
+		}
+		return getEmptyBlockTrace(block.Hash, *block.Number), true, nil
+	}
+	return &callTrace.Actions, true, nil
+}
+
 // traceTx trace transaction with EVM replay and return processed result
 func (s *PublicTxTraceAPI) traceTx(
 	ctx context.Context, b Backend, header *evmcore.EvmHeader, msg types.Message,
@@ -259,6 +305,9 @@ func (s *PublicTxTraceAPI) traceTx(
 	gp := new(evmcore.GasPool).AddGas(msg.Gas())
 	state.Prepare(tx.Hash(), int(index))
 	result, err := evmcore.ApplyMessage(vmenv, msg, gp)
+	if result != nil {
+		rpclimit.ChargeGas(ctx, result.UsedGas)
+	}
 
 	traceActions := txTracer.GetResult()
 	state.Finalise()
@@ -336,6 +385,11 @@ func (s *PublicTxTraceAPI) Filter(ctx context.Context, args FilterArgs) (json.Ra
 		log.Debug("Executing trace_filter call finished", data...)
 	}(time.Now())
 
+	// use stored traces if the whole range is indexed
+	if res, ok, err := filterStoredTraces(ctx, s, args); err != nil || ok {
+		return res, err
+	}
+
 	if args.Count == 0 && args.After == 0 {
 		// count and order of traces doesn't matter so filter blocks in parallel
 		return filterBlocksInParallel(ctx, s, args)
@@ -389,6 +443,67 @@ func filterBlocks(ctx context.Context, s *PublicTxTraceAPI, args FilterArgs) (js
 	return resultBuffer.GetResult()
 }
 
+// Filter specified block range using the traces store.
+// The returned bool is false if traces of some blocks in the range are not stored.
+func filterStoredTraces(ctx context.Context, s *PublicTxTraceAPI, args FilterArgs) (json.RawMessage, bool, error) {
+	fromBlock, toBlock, fromAddresses, toAddresses := parseFilterArguments(s.b, args)
+	if fromBlock < 0 || toBlock < 0 {
+		return nil, false, nil
+	}
+
+	var fromList, toList []common.Address
+	if args.FromAddress != nil {
+		fromList = *args.FromAddress
+	}
+	if args.ToAddress != nil {
+		toList = *args.ToAddress
+	}
+	blocks, ok, err := s.b.FindTraceBlocks(ctx, idx.Block(fromBlock), idx.Block(toBlock), fromList, toList)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	// resultBuffer is buffer for collecting result traces
+	resultBuffer, err := NewJsonResultBuffer()
+	if err != nil {
+		return nil, true, err
+	}
+	var traceAdded, traceCount uint
+	for _, n := range blocks {
+		traces, ok, err := s.b.GetBlockTraces(ctx, n)
+		if err != nil {
+			return nil, true, err
+		}
+		if !ok {
+			return nil, true, fmt.Errorf("stored traces of block %v not found", n)
+		}
+
+		for _, trace := range traces {
+			if trace.Action == nil || !containsAddress(trace.Action.From, trace.Action.To, fromAddresses, toAddresses) {
+				continue
+			}
+			if traceCount >= args.After {
+				if err := resultBuffer.AddObject(&trace); err != nil {
+					return nil, true, err
+				}
+				traceAdded++
+			}
+			if args.Count != 0 && traceAdded >= args.Count {
+				res, err := resultBuffer.GetResult()
+				return res, true, err
+			}
+			traceCount++
+		}
+
+		// when context ended return error
+		if ctx.Err() != nil {
+			return nil, true, ctx.Err()
+		}
+	}
+	res, err := resultBuffer.GetResult()
+	return res, true, err
+}
+
 // Filter specified block range in parallel
 func filterBlocksInParallel(ctx context.Context, s *PublicTxTraceAPI, args FilterArgs) (json.RawMessage, error) {
 
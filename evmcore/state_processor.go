@@ -40,6 +40,17 @@ type StateProcessor struct {
 	bc     DummyChain          // Canonical block chain
 }
 
+// TxTracer is an optional extension of vm.Tracer, which is notified
+// about the start and the end of every transaction applied by the StateProcessor.
+type TxTracer interface {
+	vm.Tracer
+	// CaptureTxStart is called before the transaction is applied.
+	CaptureTxStart(tx *types.Transaction, msg types.Message)
+	// CaptureTxEnd is called after the transaction is applied.
+	// The err is not nil if the transaction is skipped.
+	CaptureTxEnd(result *ExecutionResult, err error)
+}
+
 // NewStateProcessor initialises a new StateProcessor.
 func NewStateProcessor(config *params.ChainConfig, bc DummyChain) *StateProcessor {
 	return &StateProcessor{
@@ -116,8 +127,16 @@ func applyTransaction(
 	txContext := NewEVMTxContext(msg)
 	evm.Reset(txContext, statedb)
 
+	txTracer, _ := evm.Config.Tracer.(TxTracer)
+	if txTracer != nil {
+		txTracer.CaptureTxStart(tx, msg)
+	}
+
 	// Apply the transaction to the current state (included in the env).
 	result, err := ApplyMessage(evm, msg, gp)
+	if txTracer != nil {
+		txTracer.CaptureTxEnd(result, err)
+	}
 	if err != nil {
 		return nil, 0, result == nil, err
 	}
@@ -0,0 +1,135 @@
+package evmcore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrDenied is returned if the sender or the recipient of a transaction is on the local deny list.
+var ErrDenied = errors.New("transaction sender or recipient is denied")
+
+// TxDenylist is a list of senders and recipients whose transactions are rejected by the pool.
+type TxDenylist struct {
+	Senders    []common.Address `json:"senders"`
+	Recipients []common.Address `json:"recipients"`
+}
+
+// txDenylist is the local deny list of the pool, persisted into a JSON file to survive node restarts.
+type txDenylist struct {
+	path       string // Filesystem path to store the list at, the list isn't persisted if empty
+	senders    map[common.Address]struct{}
+	recipients map[common.Address]struct{}
+}
+
+// newTxDenylist loads the deny list from the path, if it exists.
+func newTxDenylist(path string) (*txDenylist, error) {
+	l := &txDenylist{
+		path:       path,
+		senders:    make(map[common.Address]struct{}),
+		recipients: make(map[common.Address]struct{}),
+	}
+	if path == "" {
+		return l, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, err
+	}
+	var stored TxDenylist
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return l, err
+	}
+	l.update(stored, TxDenylist{})
+	return l, nil
+}
+
+// denies checks whether the transaction from the sender is denied.
+func (l *txDenylist) denies(from common.Address, tx *types.Transaction) bool {
+	if _, ok := l.senders[from]; ok {
+		return true
+	}
+	if tx.To() != nil {
+		if _, ok := l.recipients[*tx.To()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// copy returns a copy of the list, persisted at the same path.
+func (l *txDenylist) copy() *txDenylist {
+	cp := &txDenylist{
+		path:       l.path,
+		senders:    make(map[common.Address]struct{}, len(l.senders)),
+		recipients: make(map[common.Address]struct{}, len(l.recipients)),
+	}
+	for addr := range l.senders {
+		cp.senders[addr] = struct{}{}
+	}
+	for addr := range l.recipients {
+		cp.recipients[addr] = struct{}{}
+	}
+	return cp
+}
+
+func (l *txDenylist) update(add, remove TxDenylist) {
+	for _, addr := range add.Senders {
+		l.senders[addr] = struct{}{}
+	}
+	for _, addr := range add.Recipients {
+		l.recipients[addr] = struct{}{}
+	}
+	for _, addr := range remove.Senders {
+		delete(l.senders, addr)
+	}
+	for _, addr := range remove.Recipients {
+		delete(l.recipients, addr)
+	}
+}
+
+func sortedAddresses(set map[common.Address]struct{}) []common.Address {
+	res := make([]common.Address, 0, len(set))
+	for addr := range set {
+		res = append(res, addr)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return bytes.Compare(res[i].Bytes(), res[j].Bytes()) < 0
+	})
+	return res
+}
+
+func (l *txDenylist) list() TxDenylist {
+	return TxDenylist{
+		Senders:    sortedAddresses(l.senders),
+		Recipients: sortedAddresses(l.recipients),
+	}
+}
+
+// save writes the list into the file atomically.
+func (l *txDenylist) save() error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	tmp := l.path + ".new"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
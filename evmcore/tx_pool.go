@@ -18,6 +18,7 @@ package evmcore
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"math/rand"
@@ -71,6 +72,10 @@ var (
 	// another remote transaction.
 	ErrTxPoolOverflow = errors.New("txpool is full")
 
+	// ErrNonceGap is returned as the reason why a queued transaction isn't
+	// executable if a transaction with a lower nonce is missing.
+	ErrNonceGap = errors.New("nonce gap")
+
 	// ErrReplaceUnderpriced is returned if a transaction is attempted to be replaced
 	// with a different one without the required price bump.
 	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
@@ -114,6 +119,9 @@ var (
 	underpricedTxMeter = metrics.GetOrRegisterMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.GetOrRegisterMeter("txpool/overflowed", nil)
 
+	// privateExpiredMeter counts the private transactions dropped because they weren't included in time
+	privateExpiredMeter = metrics.GetOrRegisterMeter("txpool/private/expired", nil)
+
 	pendingGauge = metrics.GetOrRegisterGauge("txpool/pending", nil)
 	queuedGauge  = metrics.GetOrRegisterGauge("txpool/queued", nil)
 	localGauge   = metrics.GetOrRegisterGauge("txpool/local", nil)
@@ -167,6 +175,7 @@ type TxPoolConfig struct {
 	NoLocals  bool             // Whether local transaction handling should be disabled
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	Denylist  string           // Deny list of senders and recipients to survive node restarts
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -177,6 +186,8 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	PrivateLifetime uint64 // Number of blocks after which private transactions are dropped if not included
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -184,6 +195,7 @@ type TxPoolConfig struct {
 var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
+	Denylist:  "txpool-denylist.json",
 
 	PriceLimit: 1,
 	PriceBump:  10,
@@ -194,6 +206,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  256,
 
 	Lifetime: 3 * time.Hour,
+
+	PrivateLifetime: 100,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -232,6 +246,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.PrivateLifetime < 1 {
+		log.Warn("Sanitizing invalid txpool private lifetime", "provided", conf.PrivateLifetime, "updated", DefaultTxPoolConfig.PrivateLifetime)
+		conf.PrivateLifetime = DefaultTxPoolConfig.PrivateLifetime
+	}
 	return conf
 }
 
@@ -260,8 +278,12 @@ type TxPool struct {
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	locals   *accountSet // Set of local transaction to exempt from eviction rules
+	journal  *txJournal  // Journal of local transaction to back up to disk
+	denylist *txDenylist // Senders and recipients whose transactions are rejected
+
+	private      map[common.Hash]uint64 // Private transactions, which aren't announced, and the blocks they expire at
+	currentBlock uint64                 // Current block number, used to expire private transactions
 
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
@@ -306,12 +328,18 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain State
 		reorgDoneCh:     make(chan chan struct{}),
 		reorgShutdownCh: make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
+		private:         make(map[common.Hash]uint64),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
 		log.Debug("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	denylist, err := newTxDenylist(config.Denylist)
+	if err != nil {
+		log.Warn("Failed to load transaction deny list", "err", err)
+	}
+	pool.denylist = denylist
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -540,6 +568,144 @@ func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.
 	return pending, queued
 }
 
+// RemoveTx removes the transaction from the pool, moving all the subsequent
+// pending transactions of the sender back to the future queue.
+func (pool *TxPool) RemoveTx(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.all.Get(hash) == nil {
+		return false
+	}
+	pool.removeTx(hash, true)
+	return true
+}
+
+// RemoveSender removes all the transactions of the sender from the pool.
+func (pool *TxPool) RemoveSender(addr common.Address) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.removeSender(addr)
+}
+
+func (pool *TxPool) removeSender(addr common.Address) int {
+	var txs types.Transactions
+	if list, ok := pool.pending[addr]; ok {
+		txs = append(txs, list.Flatten()...)
+	}
+	if list, ok := pool.queue[addr]; ok {
+		txs = append(txs, list.Flatten()...)
+	}
+	for _, tx := range txs {
+		pool.removeTx(tx.Hash(), true)
+	}
+	return len(txs)
+}
+
+// Denylist returns the senders and recipients whose transactions are rejected by the pool.
+func (pool *TxPool) Denylist() TxDenylist {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.denylist.list()
+}
+
+// UpdateDenylist adds and removes senders and recipients of the deny list, and persists it.
+// The list is left intact if it can't be persisted.
+// The transactions of the newly denied senders and recipients are removed from the pool,
+// the number of removed transactions is returned.
+func (pool *TxPool) UpdateDenylist(add, remove TxDenylist) (int, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	updated := pool.denylist.copy()
+	updated.update(add, remove)
+	if err := updated.save(); err != nil {
+		return 0, err
+	}
+	pool.denylist = updated
+	removed := 0
+	for _, addr := range add.Senders {
+		removed += pool.removeSender(addr)
+	}
+	if len(add.Recipients) != 0 {
+		var denied types.Transactions
+		pool.all.Range(func(hash common.Hash, tx *types.Transaction, local bool) bool {
+			from, _ := types.Sender(pool.signer, tx) // already validated during insertion
+			if pool.denylist.denies(from, tx) {
+				denied = append(denied, tx)
+			}
+			return true
+		}, true, true)
+		for _, tx := range denied {
+			if pool.all.Get(tx.Hash()) != nil {
+				pool.removeTx(tx.Hash(), true)
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// QueuedReasons explains why the queued transactions are not pending,
+// grouped by account and nonce. The nil reason means that the transaction
+// is executable and awaits promotion.
+func (pool *TxPool) QueuedReasons() map[common.Address]map[uint64]error {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	reasons := make(map[common.Address]map[uint64]error, len(pool.queue))
+	for addr, list := range pool.queue {
+		reasons[addr] = pool.queuedReasons(addr, list.Flatten())
+	}
+	return reasons
+}
+
+// queuedReasons explains why the queued transactions of the account, sorted by nonce, are not pending.
+func (pool *TxPool) queuedReasons(addr common.Address, txs types.Transactions) map[uint64]error {
+	reasons := make(map[uint64]error, len(txs))
+	next := pool.pendingNonces.get(addr)
+	balance := pool.currentState.GetBalance(addr)
+	local := pool.locals.contains(addr)
+	var blocker error
+	for _, tx := range txs {
+		if blocker != nil {
+			reasons[tx.Nonce()] = blocker
+			continue
+		}
+		var reason error
+		if tx.Nonce() > next {
+			reason = fmt.Errorf("%w: missing nonce %d", ErrNonceGap, next)
+		} else if balance.Cmp(tx.Cost()) < 0 {
+			reason = fmt.Errorf("%w: balance %s, cost %s", ErrInsufficientFunds, balance, tx.Cost())
+		} else if pool.underpriced(tx, local) {
+			reason = ErrUnderpriced
+		}
+		if reason != nil {
+			reasons[tx.Nonce()] = reason
+			blocker = fmt.Errorf("blocked by nonce %d: %w", tx.Nonce(), reason)
+			continue
+		}
+		reasons[tx.Nonce()] = nil
+		next = tx.Nonce() + 1
+	}
+	return reasons
+}
+
+// underpriced checks whether the transaction is priced below the current pool and network minimums.
+func (pool *TxPool) underpriced(tx *types.Transaction, local bool) bool {
+	if !local && tx.GasTipCapIntCmp(pool.gasPrice) < 0 {
+		return true
+	}
+	if recommendedGasTip, minPrice := pool.chain.EffectiveMinTip(), pool.chain.MinGasPrice(); recommendedGasTip != nil && minPrice != nil {
+		if tx.GasTipCapIntCmp(recommendedGasTip) < 0 || tx.GasFeeCapIntCmp(new(big.Int).Add(recommendedGasTip, minPrice)) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -576,7 +742,7 @@ func (pool *TxPool) SampleHashes(max int) []common.Hash {
 	defer pool.mu.Unlock()
 	pendingSample := sampleTxHashes(pool.pending, max * 9 / 10)
 	queueSample := sampleTxHashes(pool.queue, max - len(pendingSample))
-	return append(pendingSample, queueSample...)
+	return pool.withoutPrivateHashes(append(pendingSample, queueSample...))
 }
 
 func sampleTxHashes(txListsMap map[common.Address]*txList, max int) (out []common.Hash) {
@@ -682,6 +848,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Drop transactions denied by the node operator
+	if pool.denylist.denies(from, tx) {
+		return ErrDenied
+	}
 	// Drop non-local transactions under our own minimal accepted gas price or tip
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !local && tx.GasTipCapIntCmp(pool.gasPrice) < 0 {
@@ -933,6 +1103,86 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 	return errs[0]
 }
 
+// AddPrivate enqueues a single private transaction into the pool if it is valid.
+// Private transactions are never announced to peers and are available only to
+// the own emitter. They are dropped if not included within the configured
+// number of blocks.
+//
+// Private transactions aren't journaled, as they are not tracked as local ones.
+func (pool *TxPool) AddPrivate(tx *types.Transaction) error {
+	hash := tx.Hash()
+	pool.mu.Lock()
+	if pool.all.Get(hash) != nil {
+		pool.mu.Unlock()
+		return ErrAlreadyKnown
+	}
+	// mark the transaction before it's added, so it's never announced
+	pool.private[hash] = pool.currentBlock + pool.config.PrivateLifetime
+	pool.mu.Unlock()
+
+	err := pool.addTxs([]*types.Transaction{tx}, false, true)[0]
+	if err != nil {
+		pool.mu.Lock()
+		if pool.all.Get(hash) == nil {
+			delete(pool.private, hash)
+		}
+		pool.mu.Unlock()
+	}
+	return err
+}
+
+// IsPrivate checks whether the transaction was submitted privately, so it must not be announced.
+func (pool *TxPool) IsPrivate(hash common.Hash) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	_, ok := pool.private[hash]
+	return ok
+}
+
+// withoutPrivate filters out private transactions. The pool lock must be held.
+func (pool *TxPool) withoutPrivate(txs []*types.Transaction) []*types.Transaction {
+	if len(pool.private) == 0 {
+		return txs
+	}
+	public := make([]*types.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if _, ok := pool.private[tx.Hash()]; !ok {
+			public = append(public, tx)
+		}
+	}
+	return public
+}
+
+// withoutPrivateHashes filters out hashes of private transactions. The pool lock must be held.
+func (pool *TxPool) withoutPrivateHashes(hashes []common.Hash) []common.Hash {
+	if len(pool.private) == 0 {
+		return hashes
+	}
+	public := hashes[:0]
+	for _, hash := range hashes {
+		if _, ok := pool.private[hash]; !ok {
+			public = append(public, hash)
+		}
+	}
+	return public
+}
+
+// expirePrivate drops private transactions which weren't included in time.
+// The pool lock must be held.
+func (pool *TxPool) expirePrivate() {
+	for hash, expiration := range pool.private {
+		if pool.currentBlock < expiration {
+			continue
+		}
+		if pool.all.Get(hash) != nil {
+			pool.removeTx(hash, true)
+			privateExpiredMeter.Mark(1)
+		}
+		delete(pool.private, hash)
+	}
+}
+
 // AddRemotes enqueues a batch of transactions into the pool if they are valid. If the
 // senders are not among the locally tracked ones, full pricing constraints will apply.
 //
@@ -1243,6 +1493,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	if reset != nil {
 		// Reset from the old head to the new, rescheduling any reorged transactions
 		pool.reset(reset.oldHead, reset.newHead)
+		pool.expirePrivate()
 
 		// Nonces were reset, discard any events that became stale
 		for addr := range events {
@@ -1301,7 +1552,13 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		for _, set := range events {
 			txs = append(txs, set.Flatten()...)
 		}
-		pool.txFeed.Send(NewTxsNotify{txs})
+		// Private transactions are never announced
+		pool.mu.RLock()
+		txs = pool.withoutPrivate(txs)
+		pool.mu.RUnlock()
+		if len(txs) > 0 {
+			pool.txFeed.Send(NewTxsNotify{txs})
+		}
 	}
 }
 
@@ -1391,6 +1648,7 @@ func (pool *TxPool) reset(oldHead, newHead *EvmHeader) {
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = pool.chain.MaxGasLimit()
+	pool.currentBlock = newHead.Number.Uint64()
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -46,6 +47,7 @@ var (
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.Denylist = ""
 
 	cpy := *params.TestChainConfig
 	eip1559Config = &cpy
@@ -2764,3 +2766,216 @@ func BenchmarkInsertRemoteWithAllLocals(b *testing.B) {
 		pool.Stop()
 	}
 }
+
+// Tests that the node operator may remove a single transaction or all the
+// transactions of a sender from the pool.
+func TestTransactionRemoval(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000000))
+
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key), transaction(3, 100000, key)}
+	for i, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 2/1", pending, queued)
+	}
+	if pool.RemoveTx(common.Hash{}) {
+		t.Fatalf("unknown transaction removed")
+	}
+	// Removing a pending transaction moves the following ones into the queue
+	if !pool.RemoveTx(txs[0].Hash()) {
+		t.Fatalf("pending transaction isn't removed")
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 2 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 0/2", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	if removed := pool.RemoveSender(account); removed != 2 {
+		t.Fatalf("removed transactions mismatch: have %d, want 2", removed)
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 0/0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions from denied senders and to denied recipients are
+// rejected and evicted, and that the deny list survives pool restarts.
+func TestTransactionDenylist(t *testing.T) {
+	t.Parallel()
+
+	config := testTxPoolConfig
+	config.Denylist = filepath.Join(t.TempDir(), "denylist.json")
+
+	blockchain := &testBlockChain{newTestTxPoolStateDb(), 1000000, new(event.Feed)}
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	sender, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(sender.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(transaction(0, 100000, sender)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	removed, err := pool.UpdateDenylist(TxDenylist{Senders: []common.Address{crypto.PubkeyToAddress(sender.PublicKey)}}, TxDenylist{})
+	if err != nil {
+		t.Fatalf("failed to update deny list: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("removed transactions mismatch: have %d, want 1", removed)
+	}
+	if err := pool.addRemoteSync(transaction(0, 100000, sender)); !errors.Is(err, ErrDenied) {
+		t.Fatalf("denied sender error mismatch: have %v, want %v", err, ErrDenied)
+	}
+	// The recipient of the test transactions is the zero address
+	if _, err := pool.UpdateDenylist(TxDenylist{Recipients: []common.Address{{}}}, TxDenylist{}); err != nil {
+		t.Fatalf("failed to update deny list: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(0, 100000, other)); !errors.Is(err, ErrDenied) {
+		t.Fatalf("denied recipient error mismatch: have %v, want %v", err, ErrDenied)
+	}
+	pool.Stop()
+
+	// Restart the pool and check the deny list is loaded
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	list := pool.Denylist()
+	if len(list.Senders) != 1 || list.Senders[0] != crypto.PubkeyToAddress(sender.PublicKey) {
+		t.Fatalf("denied senders mismatch: have %v", list.Senders)
+	}
+	if len(list.Recipients) != 1 || list.Recipients[0] != (common.Address{}) {
+		t.Fatalf("denied recipients mismatch: have %v", list.Recipients)
+	}
+	if _, err := pool.UpdateDenylist(TxDenylist{}, list); err != nil {
+		t.Fatalf("failed to update deny list: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(0, 100000, other)); err != nil {
+		t.Fatalf("failed to add allowed transaction: %v", err)
+	}
+
+	// The deny list is left intact if it can't be persisted
+	pool.mu.Lock()
+	pool.denylist.path = filepath.Join(config.Denylist, "denylist.json")
+	pool.mu.Unlock()
+	if _, err := pool.UpdateDenylist(TxDenylist{Senders: []common.Address{crypto.PubkeyToAddress(other.PublicKey)}}, TxDenylist{}); err == nil {
+		t.Fatalf("deny list update succeeded without persisting it")
+	}
+	if list := pool.Denylist(); len(list.Senders) != 0 || len(list.Recipients) != 0 {
+		t.Fatalf("deny list changed by a failed update: have %v", list)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatch: have %d, want 1", pending)
+	}
+}
+
+// Tests that the pool explains why the queued transactions aren't executable.
+func TestTransactionQueuedReasons(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000))
+
+	if err := pool.addRemoteSync(transaction(2, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(3, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	reasons := pool.QueuedReasons()[account]
+	if len(reasons) != 2 {
+		t.Fatalf("queued reasons mismatch: have %v", reasons)
+	}
+	if !errors.Is(reasons[2], ErrNonceGap) {
+		t.Errorf("nonce gap reason mismatch: have %v, want %v", reasons[2], ErrNonceGap)
+	}
+	if !errors.Is(reasons[3], ErrNonceGap) {
+		t.Errorf("blocked reason mismatch: have %v, want %v", reasons[3], ErrNonceGap)
+	}
+
+	// Executable transactions are kept in the queue until promoted,
+	// so they may become unaffordable after a balance change
+	testAddBalance(pool, account, big.NewInt(-900001))
+	pool.mu.RLock()
+	reasons = pool.queuedReasons(account, types.Transactions{transaction(0, 100000, key), transaction(1, 100000, key)})
+	pool.mu.RUnlock()
+	if !errors.Is(reasons[0], ErrInsufficientFunds) {
+		t.Errorf("insufficient funds reason mismatch: have %v, want %v", reasons[0], ErrInsufficientFunds)
+	}
+	if !errors.Is(reasons[1], ErrInsufficientFunds) {
+		t.Errorf("blocked reason mismatch: have %v, want %v", reasons[1], ErrInsufficientFunds)
+	}
+}
+
+// Tests that private transactions are never announced and are dropped if not
+// included within the configured number of blocks.
+func TestTransactionPrivate(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000000))
+
+	events := make(chan NewTxsNotify, 32)
+	sub := pool.SubscribeNewTxsNotify(events)
+	defer sub.Unsubscribe()
+
+	private, public := transaction(0, 100000, key), transaction(1, 100000, key)
+	if err := pool.AddPrivate(private); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate(private); !errors.Is(err, ErrAlreadyKnown) {
+		t.Fatalf("known private transaction error mismatch: have %v, want %v", err, ErrAlreadyKnown)
+	}
+	if err := pool.addRemoteSync(public); err != nil {
+		t.Fatalf("failed to add public transaction: %v", err)
+	}
+	if !pool.IsPrivate(private.Hash()) || pool.IsPrivate(public.Hash()) {
+		t.Fatalf("private transaction isn't marked")
+	}
+	// The private transaction is executable, but only the public one is announced
+	pending, _ := pool.Pending(false)
+	if len(pending[account]) != 2 {
+		t.Fatalf("pending transactions mismatch: have %d, want 2", len(pending[account]))
+	}
+	if err := validateEvents(events, 1); err != nil {
+		t.Fatalf("announced transactions mismatch: %v", err)
+	}
+	if sample := pool.SampleHashes(10); len(sample) != 1 || sample[0] != public.Hash() {
+		t.Fatalf("sampled hashes mismatch: have %v, want %v", sample, []common.Hash{public.Hash()})
+	}
+
+	// The private transaction expires with the configured lifetime
+	head := pool.chain.CurrentBlock().Header()
+	head.Number = new(big.Int).SetUint64(head.Number.Uint64() + testTxPoolConfig.PrivateLifetime - 1)
+	<-pool.requestReset(nil, head)
+	if pool.Get(private.Hash()) == nil {
+		t.Fatalf("private transaction dropped before expiration")
+	}
+	head.Number = new(big.Int).Add(head.Number, common.Big1)
+	<-pool.requestReset(nil, head)
+	if pool.Get(private.Hash()) != nil || pool.IsPrivate(private.Hash()) {
+		t.Fatalf("private transaction isn't dropped after expiration")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package ftmclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+)
+
+// Validator is a validator profile of an epoch.
+type Validator struct {
+	Weight *big.Int
+	PubKey validatorpk.PubKey
+}
+
+// Downtime is a validator's downtime.
+type Downtime struct {
+	OfflineBlocks idx.Block
+	OfflineTime   inter.Timestamp
+}
+
+// rpcValidators are the validators of an epoch in the RPC output, keyed by validator ID.
+type rpcValidators map[hexutil.Uint64]*struct {
+	Weight *hexutil.Big `json:"weight"`
+	PubKey string       `json:"pubkey"`
+}
+
+func (vv rpcValidators) toValidators() (map[idx.ValidatorID]Validator, error) {
+	res := make(map[idx.ValidatorID]Validator, len(vv))
+	for id, v := range vv {
+		pubkey, err := validatorpk.FromString(v.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		res[idx.ValidatorID(id)] = Validator{
+			Weight: (*big.Int)(v.Weight),
+			PubKey: pubkey,
+		}
+	}
+	return res, nil
+}
+
+// GetValidators returns validators of an epoch.
+// * When epoch is -2 the validators of latest epoch are returned.
+// * When epoch is -1 the validators of latest sealed epoch are returned.
+func (ec *Client) GetValidators(ctx context.Context, epoch *big.Int) (map[idx.ValidatorID]Validator, error) {
+	var raw rpcValidators
+	err := ec.c.CallContext(ctx, &raw, "abft_getValidators", toBlockNumArg(epoch))
+	if err != nil {
+		return nil, err
+	} else if raw == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return raw.toValidators()
+}
+
+// GetDowntime returns validator's downtime.
+func (ec *Client) GetDowntime(ctx context.Context, validatorID idx.ValidatorID) (*Downtime, error) {
+	var raw struct {
+		OfflineBlocks hexutil.Uint64 `json:"offlineBlocks"`
+		OfflineTime   hexutil.Uint64 `json:"offlineTime"`
+	}
+	err := ec.c.CallContext(ctx, &raw, "abft_getDowntime", hexutil.Uint(validatorID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Downtime{
+		OfflineBlocks: idx.Block(raw.OfflineBlocks),
+		OfflineTime:   inter.Timestamp(raw.OfflineTime),
+	}, nil
+}
+
+// GetEpochUptime returns validator's epoch uptime in nanoseconds.
+func (ec *Client) GetEpochUptime(ctx context.Context, validatorID idx.ValidatorID) (inter.Timestamp, error) {
+	var raw hexutil.Uint64
+	err := ec.c.CallContext(ctx, &raw, "abft_getEpochUptime", hexutil.Uint(validatorID))
+	if err != nil {
+		return 0, err
+	}
+
+	return inter.Timestamp(raw), nil
+}
+
+// GetOriginatedEpochFee returns validator's originated epoch fee.
+func (ec *Client) GetOriginatedEpochFee(ctx context.Context, validatorID idx.ValidatorID) (*big.Int, error) {
+	var raw *hexutil.Big
+	err := ec.c.CallContext(ctx, &raw, "abft_getOriginatedEpochFee", hexutil.Uint(validatorID))
+	if err != nil {
+		return nil, err
+	} else if raw == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return (*big.Int)(raw), nil
+}
@@ -0,0 +1,270 @@
+package ftmclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/filters"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/drivertype"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace"
+)
+
+var testAtropos = hash.Event(common.HexToHash("0x0000000500000007a1"))
+
+type testAbftAPI struct{}
+
+func (testAbftAPI) GetValidators(epoch rpc.BlockNumber) map[hexutil.Uint64]interface{} {
+	return map[hexutil.Uint64]interface{}{
+		1: map[string]interface{}{
+			"weight": (*hexutil.Big)(big.NewInt(100)),
+			"pubkey": "0xc00102",
+		},
+	}
+}
+
+func (testAbftAPI) GetDowntime(validatorID hexutil.Uint) map[string]interface{} {
+	return map[string]interface{}{
+		"offlineBlocks": hexutil.Uint64(validatorID) * 10,
+		"offlineTime":   hexutil.Uint64(validatorID) * 1000,
+	}
+}
+
+type testEthAPI struct{}
+
+func (testEthAPI) GetRules(epoch rpc.BlockNumber) *opera.Rules {
+	rules := opera.FakeNetRules()
+	return &rules
+}
+
+func (testEthAPI) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) map[string]interface{} {
+	return map[string]interface{}{
+		"number":        (*hexutil.Big)(big.NewInt(int64(number))),
+		"epoch":         hexutil.Uint64(testAtropos.Epoch()),
+		"hash":          common.Hash(testAtropos),
+		"gasUsed":       hexutil.Uint64(21000),
+		"timestamp":     hexutil.Uint64(2),
+		"timestampNano": hexutil.Uint64(2000000001),
+		"extraData":     hexutil.Bytes{},
+		"transactions":  []common.Hash{{1}, {2}},
+	}
+}
+
+func (testEthAPI) GetTransactionReceipt(txHash common.Hash) map[string]interface{} {
+	return map[string]interface{}{
+		"transactionHash":   txHash,
+		"from":              common.Address{1},
+		"to":                nil,
+		"contractAddress":   common.Address{2},
+		"gasUsed":           hexutil.Uint64(21000),
+		"cumulativeGasUsed": hexutil.Uint64(42000),
+		"effectiveGasPrice": hexutil.Uint64(1000),
+		"logs":              []interface{}{},
+		"logsBloom":         hexutil.Bytes(make([]byte, 256)),
+		"status":            hexutil.Uint(1),
+	}
+}
+
+// notifyOnce creates a subscription which sends a single notification.
+func notifyOnce(ctx context.Context, data interface{}) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		_ = notifier.Notify(sub.ID, data)
+	}()
+	return sub, nil
+}
+
+func (testEthAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return notifyOnce(ctx, map[string]interface{}{
+		"number":    (*hexutil.Big)(big.NewInt(5)),
+		"timestamp": hexutil.Uint64(2),
+		"extraData": hexutil.Bytes(testAtropos.Bytes()),
+	})
+}
+
+func (testEthAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	return notifyOnce(ctx, common.Hash{4})
+}
+
+func (testEthAPI) Epochs(ctx context.Context) (*rpc.Subscription, error) {
+	prevRules := opera.FakeNetRules()
+	rules := prevRules.Copy()
+	rules.Blocks.MaxBlockGas++
+	builder := pos.NewBuilder()
+	builder.Set(1, 100)
+	return notifyOnce(ctx, filters.RPCMarshalNewEpoch(filters.NewEpochNotify{
+		EpochState: &iblockproc.EpochState{
+			Epoch:      7,
+			EpochStart: 1000,
+			Validators: builder.Build(),
+			ValidatorProfiles: iblockproc.ValidatorProfiles{
+				1: drivertype.Validator{
+					Weight: big.NewInt(100),
+					PubKey: validatorpk.PubKey{Type: validatorpk.Types.Secp256k1, Raw: []byte{1, 2}},
+				},
+			},
+			Rules: rules,
+		},
+		PrevRules: prevRules,
+	}))
+}
+
+func (testEthAPI) DagEvents(ctx context.Context) (*rpc.Subscription, error) {
+	return notifyOnce(ctx, testDagEvent())
+}
+
+func testDagEvent() map[string]interface{} {
+	me := &inter.MutableEventPayload{}
+	me.SetEpoch(2)
+	me.SetCreator(3)
+	me.SetLamport(4)
+	me.SetFrame(5)
+	me.SetTxs(types.Transactions{types.NewTransaction(1, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)})
+	fields, _ := inter.RPCMarshalEventPayload(me.Build(), true, false)
+	return fields
+}
+
+type testTraceAPI struct{}
+
+func (testTraceAPI) Filter(args map[string]interface{}) []txtrace.ActionTrace {
+	trace := txtrace.NewActionTrace(common.Hash(testAtropos), *big.NewInt(3), common.Hash{1}, 0, "call")
+	trace.TraceAddress = []uint32{uint32(args["count"].(float64))}
+	return []txtrace.ActionTrace{*trace}
+}
+
+func newTestClient(t *testing.T) *Client {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("abft", testAbftAPI{}))
+	require.NoError(t, server.RegisterName("eth", testEthAPI{}))
+	require.NoError(t, server.RegisterName("trace", testTraceAPI{}))
+	t.Cleanup(server.Stop)
+	return NewClient(rpc.DialInProc(server))
+}
+
+func TestClient(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	validators, err := client.GetValidators(ctx, nil)
+	require.NoError(err)
+	require.Len(validators, 1)
+	require.Equal(big.NewInt(100), validators[1].Weight)
+	require.Equal("0xc00102", validators[1].PubKey.String())
+
+	downtime, err := client.GetDowntime(ctx, 3)
+	require.NoError(err)
+	require.Equal(idx.Block(30), downtime.OfflineBlocks)
+	require.Equal(inter.Timestamp(3000), downtime.OfflineTime)
+
+	rules, err := client.GetRules(ctx, nil)
+	require.NoError(err)
+	require.Equal(opera.FakeNetRules().String(), rules.String())
+
+	block, err := client.GetBlockByNumber(ctx, big.NewInt(5), false)
+	require.NoError(err)
+	require.Equal(big.NewInt(5), block.Number)
+	require.Equal(common.Hash(testAtropos), block.Hash)
+	require.Equal(testAtropos.Epoch(), block.Epoch)
+	require.Equal(inter.Timestamp(2000000001), block.Time)
+	require.Equal([]common.Hash{{1}, {2}}, block.TxHashes)
+	require.Empty(block.Transactions)
+
+	receipt, err := client.GetTransactionReceipt(ctx, common.Hash{3})
+	require.NoError(err)
+	require.Equal(common.Hash{3}, receipt.TxHash)
+	require.Equal(common.Address{1}, receipt.From)
+	require.Nil(receipt.To)
+	require.Equal(common.Address{2}, receipt.ContractAddress)
+	require.Equal(big.NewInt(1000), receipt.EffectiveGasPrice)
+	require.Equal(uint64(1), receipt.Status)
+
+	traces, err := client.TraceFilter(ctx, TraceFilterQuery{Count: 7})
+	require.NoError(err)
+	require.Len(traces, 1)
+	require.Equal(common.Hash(testAtropos), traces[0].BlockHash)
+	require.Equal(big.NewInt(3), &traces[0].BlockNumber)
+	require.Equal([]uint32{7}, traces[0].TraceAddress)
+}
+
+func TestHeaderUnmarshalNewHeads(t *testing.T) {
+	// newHeads notification is an Ethereum header with the block hash in the extra data
+	var h Header
+	require.NoError(t, h.UnmarshalJSON([]byte(`{"number":"0x5","timestamp":"0x2","extraData":"`+common.Hash(testAtropos).Hex()+`"}`)))
+	require.Equal(t, common.Hash(testAtropos), h.Hash)
+	require.Equal(t, testAtropos.Epoch(), h.Epoch)
+	require.Equal(t, inter.FromUnix(2), h.Time)
+}
+
+func TestClientSubscriptions(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	receive := func(sub ethereum.Subscription, err error, ch interface{}) interface{} {
+		require.NoError(err)
+		defer sub.Unsubscribe()
+		chosen, value, _ := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sub.Err())},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(time.After(time.Second))},
+		})
+		require.Equal(0, chosen, "no notification")
+		return value.Interface()
+	}
+
+	heads := make(chan *Header, 1)
+	sub, err := client.SubscribeNewHeads(ctx, heads)
+	head := receive(sub, err, heads).(*Header)
+	require.Equal(big.NewInt(5), head.Number)
+	require.Equal(common.Hash(testAtropos), head.Hash)
+
+	txs := make(chan common.Hash, 1)
+	sub, err = client.SubscribeNewPendingTransactions(ctx, txs)
+	require.Equal(common.Hash{4}, receive(sub, err, txs))
+
+	epochs := make(chan *Epoch, 1)
+	sub, err = client.SubscribeEpochs(ctx, epochs)
+	epoch := receive(sub, err, epochs).(*Epoch)
+	require.Equal(idx.Epoch(7), epoch.Epoch)
+	require.Equal(inter.Timestamp(1000), epoch.EpochStart)
+	require.Len(epoch.Validators, 1)
+	require.Equal(big.NewInt(100), epoch.Validators[1].Weight)
+	require.Equal("0xc00102", epoch.Validators[1].PubKey.String())
+	require.Equal(opera.FakeNetRules().Blocks.MaxBlockGas+1, epoch.Rules.Blocks.MaxBlockGas)
+	require.Len(epoch.RulesDiff, 1)
+	diff := epoch.RulesDiff["Blocks.MaxBlockGas"]
+	require.JSONEq(fmt.Sprint(opera.FakeNetRules().Blocks.MaxBlockGas), string(diff.Prev))
+	require.JSONEq(fmt.Sprint(opera.FakeNetRules().Blocks.MaxBlockGas+1), string(diff.New))
+
+	events := make(chan *DagEvent, 1)
+	sub, err = client.SubscribeDagEvents(ctx, events)
+	event := receive(sub, err, events).(*DagEvent)
+	expected := testDagEvent()
+	require.Equal(expected["id"], hexutil.Bytes(event.ID().Bytes()))
+	require.Equal(idx.ValidatorID(3), event.Creator())
+	require.Equal(idx.Frame(5), event.Frame())
+	require.Len(event.TxHashes, 1)
+	require.Equal(expected["transactions"].([]interface{})[0], event.TxHashes[0])
+}
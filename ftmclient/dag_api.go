@@ -2,6 +2,7 @@ package ftmclient
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
@@ -12,6 +13,29 @@ import (
 	"github.com/Fantom-foundation/go-opera/inter"
 )
 
+// DagEvent is a notification of a confirmed DAG event, it has the event header and hashes of the event transactions.
+type DagEvent struct {
+	inter.EventI
+	TxHashes []common.Hash
+}
+
+// UnmarshalJSON decodes the dagEvents notification.
+func (e *DagEvent) UnmarshalJSON(input []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(input, &raw); err != nil {
+		return err
+	}
+	var dec struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	e.EventI = inter.RPCUnmarshalEvent(raw)
+	e.TxHashes = dec.Transactions
+	return nil
+}
+
 // GetEvent returns Lachesis event by hash or short ID.
 func (ec *Client) GetEvent(ctx context.Context, h hash.Event) (e inter.EventI, err error) {
 	var raw map[string]interface{}
@@ -89,3 +113,9 @@ func toBlockNumArg(number *big.Int) string {
 	}
 	return hexutil.EncodeBig(number)
 }
+
+// SubscribeDagEvents subscribes to notifications about DAG events confirmed by the node.
+// Events are skipped by the node if the client doesn't keep up with the notifications.
+func (ec *Client) SubscribeDagEvents(ctx context.Context, ch chan<- *DagEvent) (ethereum.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "dagEvents")
+}
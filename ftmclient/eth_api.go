@@ -0,0 +1,297 @@
+package ftmclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// Header is a Sonic block header.
+// Unlike the Ethereum header, its hash is the Atropos event ID and isn't derived from the header fields.
+type Header struct {
+	Number       *big.Int
+	Epoch        idx.Epoch
+	Hash         common.Hash
+	ParentHash   common.Hash
+	Root         common.Hash
+	TxHash       common.Hash
+	ReceiptsRoot common.Hash
+	Bloom        types.Bloom
+	Coinbase     common.Address
+	GasUsed      uint64
+	BaseFee      *big.Int
+	Time         inter.Timestamp
+	Size         uint64
+}
+
+// Block is a Sonic block.
+// Transactions are filled only if the block is requested with full transactions.
+type Block struct {
+	Header
+	TxHashes     []common.Hash
+	Transactions []*types.Transaction
+}
+
+// Epoch is a notification of a sealed epoch, it has the validators and rules of the new epoch.
+type Epoch struct {
+	Epoch      idx.Epoch
+	EpochStart inter.Timestamp
+	Validators map[idx.ValidatorID]Validator
+	Rules      opera.Rules
+	// RulesDiff has the rules fields which differ from the sealed epoch, keyed by the dot-separated field path
+	RulesDiff map[string]RulesFieldDiff
+}
+
+// RulesFieldDiff is the previous and the new JSON values of a rules field.
+type RulesFieldDiff struct {
+	Prev json.RawMessage `json:"prev"`
+	New  json.RawMessage `json:"new"`
+}
+
+// Receipt is a Sonic transaction receipt.
+type Receipt struct {
+	types.Receipt
+	From              common.Address
+	To                *common.Address
+	EffectiveGasPrice *big.Int
+}
+
+type rpcHeader struct {
+	Number        *hexutil.Big    `json:"number"`
+	Epoch         hexutil.Uint64  `json:"epoch"`
+	Hash          common.Hash     `json:"hash"`
+	ParentHash    common.Hash     `json:"parentHash"`
+	Root          common.Hash     `json:"stateRoot"`
+	TxHash        common.Hash     `json:"transactionsRoot"`
+	ReceiptsRoot  common.Hash     `json:"receiptsRoot"`
+	Bloom         types.Bloom     `json:"logsBloom"`
+	Coinbase      common.Address  `json:"miner"`
+	GasUsed       hexutil.Uint64  `json:"gasUsed"`
+	BaseFee       *hexutil.Big    `json:"baseFeePerGas"`
+	Time          hexutil.Uint64  `json:"timestamp"`
+	TimestampNano *hexutil.Uint64 `json:"timestampNano"`
+	Size          hexutil.Uint64  `json:"size"`
+	Extra         hexutil.Bytes   `json:"extraData"`
+}
+
+// UnmarshalJSON decodes both the Sonic block header and the header of a newHeads notification.
+func (h *Header) UnmarshalJSON(input []byte) error {
+	var dec rpcHeader
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*h = Header{
+		Number:       (*big.Int)(dec.Number),
+		Epoch:        idx.Epoch(dec.Epoch),
+		Hash:         dec.Hash,
+		ParentHash:   dec.ParentHash,
+		Root:         dec.Root,
+		TxHash:       dec.TxHash,
+		ReceiptsRoot: dec.ReceiptsRoot,
+		Bloom:        dec.Bloom,
+		Coinbase:     dec.Coinbase,
+		GasUsed:      uint64(dec.GasUsed),
+		BaseFee:      (*big.Int)(dec.BaseFee),
+		Time:         inter.FromUnix(int64(dec.Time)),
+		Size:         uint64(dec.Size),
+	}
+	if dec.TimestampNano != nil {
+		h.Time = inter.Timestamp(*dec.TimestampNano)
+	}
+	// the newHeads notification carries the block hash in the extra data instead of the epoch
+	if len(dec.Extra) == common.HashLength {
+		h.Hash = common.BytesToHash(dec.Extra)
+		h.Epoch = hash.Event(h.Hash).Epoch()
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the Sonic block with either transaction hashes or full transactions.
+func (b *Block) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Transactions []json.RawMessage `json:"transactions"`
+	}
+	if err := json.Unmarshal(input, &b.Header); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	b.TxHashes = make([]common.Hash, len(dec.Transactions))
+	b.Transactions = nil
+	for i, raw := range dec.Transactions {
+		if err := json.Unmarshal(raw, &b.TxHashes[i]); err == nil {
+			continue
+		}
+		tx := new(types.Transaction)
+		if err := json.Unmarshal(raw, tx); err != nil {
+			return err
+		}
+		b.TxHashes[i] = tx.Hash()
+		b.Transactions = append(b.Transactions, tx)
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the Sonic receipt.
+func (r *Receipt) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		From              common.Address  `json:"from"`
+		To                *common.Address `json:"to"`
+		EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	}
+	if err := json.Unmarshal(input, &r.Receipt); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	r.From = dec.From
+	r.To = dec.To
+	r.EffectiveGasPrice = (*big.Int)(dec.EffectiveGasPrice)
+	return nil
+}
+
+// UnmarshalJSON decodes the epochs notification.
+func (e *Epoch) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Epoch      hexutil.Uint64            `json:"epoch"`
+		EpochStart hexutil.Uint64            `json:"epochStart"`
+		Validators rpcValidators             `json:"validators"`
+		Rules      opera.Rules               `json:"rules"`
+		RulesDiff  map[string]RulesFieldDiff `json:"rulesDiff"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	validators, err := dec.Validators.toValidators()
+	if err != nil {
+		return err
+	}
+	*e = Epoch{
+		Epoch:      idx.Epoch(dec.Epoch),
+		EpochStart: inter.Timestamp(dec.EpochStart),
+		Validators: validators,
+		Rules:      dec.Rules,
+		RulesDiff:  dec.RulesDiff,
+	}
+	return nil
+}
+
+// GetRules returns network rules for an epoch.
+// * When epoch is -2 the rules of latest epoch are returned.
+// * When epoch is -1 the rules of latest sealed epoch are returned.
+func (ec *Client) GetRules(ctx context.Context, epoch *big.Int) (*opera.Rules, error) {
+	var rules *opera.Rules
+	err := ec.c.CallContext(ctx, &rules, "eth_getRules", toBlockNumArg(epoch))
+	if err != nil {
+		return nil, err
+	} else if rules == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return rules, nil
+}
+
+// GetEpochBlock returns block height in a beginning of an epoch.
+// * When epoch is -2 the block of latest epoch is returned.
+// * When epoch is -1 the block of latest sealed epoch is returned.
+func (ec *Client) GetEpochBlock(ctx context.Context, epoch *big.Int) (idx.Block, error) {
+	var raw hexutil.Uint64
+	err := ec.c.CallContext(ctx, &raw, "eth_getEpochBlock", toBlockNumArg(epoch))
+	if err != nil {
+		return 0, err
+	}
+
+	return idx.Block(raw), nil
+}
+
+// GetHeaderByNumber returns Sonic block header by number.
+// When number is nil the latest header is returned.
+func (ec *Client) GetHeaderByNumber(ctx context.Context, number *big.Int) (*Header, error) {
+	var head *Header
+	err := ec.c.CallContext(ctx, &head, "eth_getHeaderByNumber", toBlockNumArg(number))
+	if err != nil {
+		return nil, err
+	} else if head == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return head, nil
+}
+
+// GetBlockByNumber returns Sonic block by number.
+// When number is nil the latest block is returned.
+func (ec *Client) GetBlockByNumber(ctx context.Context, number *big.Int, fullTx bool) (*Block, error) {
+	return ec.getBlock(ctx, "eth_getBlockByNumber", toBlockNumArg(number), fullTx)
+}
+
+// GetBlockByHash returns Sonic block by hash.
+func (ec *Client) GetBlockByHash(ctx context.Context, h common.Hash, fullTx bool) (*Block, error) {
+	return ec.getBlock(ctx, "eth_getBlockByHash", h, fullTx)
+}
+
+func (ec *Client) getBlock(ctx context.Context, method string, args ...interface{}) (*Block, error) {
+	var block *Block
+	err := ec.c.CallContext(ctx, &block, method, args...)
+	if err != nil {
+		return nil, err
+	} else if block == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return block, nil
+}
+
+// GetTransactionReceipt returns Sonic receipt of a transaction.
+func (ec *Client) GetTransactionReceipt(ctx context.Context, txHash common.Hash) (*Receipt, error) {
+	var receipt *Receipt
+	err := ec.c.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash)
+	if err != nil {
+		return nil, err
+	} else if receipt == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return receipt, nil
+}
+
+// GetBlockReceipts returns Sonic receipts of all the transactions in a block.
+// When number is nil the receipts of latest block are returned.
+func (ec *Client) GetBlockReceipts(ctx context.Context, number *big.Int) ([]*Receipt, error) {
+	var receipts []*Receipt
+	err := ec.c.CallContext(ctx, &receipts, "eth_getBlockReceipts", toBlockNumArg(number))
+	if err != nil {
+		return nil, err
+	} else if receipts == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return receipts, nil
+}
+
+// SubscribeNewHeads subscribes to notifications about Sonic block headers of new blocks.
+// The notified headers have the time with a precision of seconds only.
+func (ec *Client) SubscribeNewHeads(ctx context.Context, ch chan<- *Header) (ethereum.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "newHeads")
+}
+
+// SubscribeNewPendingTransactions subscribes to notifications about hashes of the transactions added to the pool.
+func (ec *Client) SubscribeNewPendingTransactions(ctx context.Context, ch chan<- common.Hash) (ethereum.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions")
+}
+
+// SubscribeEpochs subscribes to notifications about sealed epochs.
+func (ec *Client) SubscribeEpochs(ctx context.Context, ch chan<- *Epoch) (ethereum.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "epochs")
+}
@@ -0,0 +1,79 @@
+package ftmclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/Fantom-foundation/go-opera/txtrace"
+)
+
+// TraceFilterQuery contains options for the trace filtering.
+type TraceFilterQuery struct {
+	FromBlock   *big.Int // beginning of the queried range, nil means the first block
+	ToBlock     *big.Int // end of the range, nil means latest block
+	FromAddress []common.Address
+	ToAddress   []common.Address
+	After       uint // number of traces to skip
+	Count       uint // maximal number of returned traces, 0 means all (with After also 0)
+}
+
+// TraceTransaction returns inner traces of a transaction.
+func (ec *Client) TraceTransaction(ctx context.Context, txHash common.Hash) ([]txtrace.ActionTrace, error) {
+	var traces []txtrace.ActionTrace
+	err := ec.c.CallContext(ctx, &traces, "trace_transaction", txHash)
+	return traces, err
+}
+
+// TraceBlockByNumber returns inner traces of all the transactions in a block.
+// When number is nil the traces of latest block are returned.
+func (ec *Client) TraceBlockByNumber(ctx context.Context, number *big.Int) ([]txtrace.ActionTrace, error) {
+	var traces []txtrace.ActionTrace
+	err := ec.c.CallContext(ctx, &traces, "trace_block", toBlockNumArg(number))
+	return traces, err
+}
+
+// TraceBlockByHash returns inner traces of all the transactions in a block.
+func (ec *Client) TraceBlockByHash(ctx context.Context, h common.Hash) ([]txtrace.ActionTrace, error) {
+	var traces []txtrace.ActionTrace
+	err := ec.c.CallContext(ctx, &traces, "trace_block", h)
+	return traces, err
+}
+
+// TraceGet returns inner traces of a transaction at the given trace address.
+func (ec *Client) TraceGet(ctx context.Context, txHash common.Hash, traceIndex []uint) ([]txtrace.ActionTrace, error) {
+	indexes := make([]hexutil.Uint, len(traceIndex))
+	for i, v := range traceIndex {
+		indexes[i] = hexutil.Uint(v)
+	}
+	var traces []txtrace.ActionTrace
+	err := ec.c.CallContext(ctx, &traces, "trace_get", txHash, indexes)
+	return traces, err
+}
+
+// TraceFilter returns inner traces matching the given filter query.
+func (ec *Client) TraceFilter(ctx context.Context, q TraceFilterQuery) ([]txtrace.ActionTrace, error) {
+	var traces []txtrace.ActionTrace
+	err := ec.c.CallContext(ctx, &traces, "trace_filter", toTraceFilterArg(q))
+	return traces, err
+}
+
+func toTraceFilterArg(q TraceFilterQuery) interface{} {
+	arg := map[string]interface{}{
+		"toBlock": toBlockNumArg(q.ToBlock),
+		"after":   q.After,
+		"count":   q.Count,
+	}
+	if q.FromBlock != nil {
+		arg["fromBlock"] = toBlockNumArg(q.FromBlock)
+	}
+	if len(q.FromAddress) > 0 {
+		arg["fromAddress"] = q.FromAddress
+	}
+	if len(q.ToAddress) > 0 {
+		arg["toAddress"] = q.ToAddress
+	}
+	return arg
+}
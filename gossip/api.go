@@ -1,8 +1,13 @@
 package gossip
 
 import (
+	"bytes"
+	"sort"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
 // PublicEthereumAPI provides an API to access Ethereum-like information.
@@ -35,3 +40,53 @@ func (api *PublicEthereumAPI) Hashrate() hexutil.Uint64 {
 func (api *PublicEthereumAPI) ChainId() hexutil.Uint64 {
 	return hexutil.Uint64(api.s.store.GetRules().NetworkID)
 }
+
+// PrivateAdminAPI provides an API to access the node's P2P administration information.
+type PrivateAdminAPI struct {
+	s *Service
+}
+
+// NewPrivateAdminAPI creates a new admin API for gossip.
+func NewPrivateAdminAPI(s *Service) *PrivateAdminAPI {
+	return &PrivateAdminAPI{s}
+}
+
+// PeerScore is the reputation of a peer.
+type PeerScore struct {
+	ID          enode.ID   `json:"id"`
+	Score       int64      `json:"score"`
+	Connected   bool       `json:"connected"`
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"`
+}
+
+// PeerScores returns the reputations of the connected and the recently scored peers, the best peers first.
+func (api *PrivateAdminAPI) PeerScores() []PeerScore {
+	h := api.s.handler
+	now := h.reputation.now()
+	reps := h.reputation.Reputations()
+	for _, p := range h.peers.List() {
+		if _, ok := reps[p.ID()]; !ok {
+			reps[p.ID()] = PeerReputation{}
+		}
+	}
+	scores := make([]PeerScore, 0, len(reps))
+	for id, rep := range reps {
+		score := PeerScore{
+			ID:        id,
+			Score:     rep.Score,
+			Connected: h.peers.Peer(id.String()) != nil,
+		}
+		if rep.Banned(now) {
+			bannedUntil := rep.BannedUntil
+			score.BannedUntil = &bannedUntil
+		}
+		scores = append(scores, score)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return bytes.Compare(scores[i].ID[:], scores[j].ID[:]) < 0
+	})
+	return scores
+}
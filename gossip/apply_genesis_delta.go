@@ -0,0 +1,343 @@
+package gossip
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+
+	"github.com/Fantom-foundation/go-opera/eventcheck/heavycheck"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+	"github.com/Fantom-foundation/go-opera/inter/ibr"
+	"github.com/Fantom-foundation/go-opera/inter/ier"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+)
+
+// ApplyGenesisDelta writes epochs and blocks of a delta genesis on top of the existing state.
+// The new records are written only if they are confirmed by the LLR votes carried by the delta,
+// and the state changes of the new blocks are applied onto the live state,
+// every resulting state root is checked against the block record.
+// The EvmStore has to be open.
+func (s *Store) ApplyGenesisDelta(g genesis.Genesis) (err error) {
+	base, err := g.DeltaSection.GetBase()
+	if err != nil {
+		return fmt.Errorf("not a delta genesis: %w", err)
+	}
+	if genesisID := s.GetGenesisID(); genesisID == nil || *genesisID != g.GenesisID {
+		return errors.New("genesis ID mismatch")
+	}
+	if rules := s.GetRules(); rules.NetworkID != g.NetworkID || rules.Name != g.NetworkName {
+		return errors.New("network ID/name mismatch")
+	}
+	if g.BlockVotes == nil || g.EpochVotes == nil || g.StateDiffs == nil {
+		return errors.New("no LLR votes or state changes in the delta genesis")
+	}
+
+	// check the base
+	baseBlock := s.GetBlock(base.Block)
+	if baseBlock == nil {
+		return fmt.Errorf("base block %d of the delta is not known", base.Block)
+	}
+	if baseBlock.Atropos != base.Atropos || baseBlock.Root != base.Root {
+		return fmt.Errorf("base block %d of the delta mismatch (atropos %s != %s, root %s != %s)",
+			base.Block, baseBlock.Atropos, base.Atropos, baseBlock.Root, base.Root)
+	}
+	head := s.GetLatestBlockIndex()
+	if head < base.Block {
+		return fmt.Errorf("latest block %d is below the delta base block %d", head, base.Block)
+	}
+	if epoch := s.GetEpoch(); epoch < base.Epoch {
+		return fmt.Errorf("current epoch %d is below the delta base epoch %d", epoch, base.Epoch)
+	}
+
+	// read epochs
+	var ers []ier.LlrIdxFullEpochRecord
+	g.Epochs.ForEach(func(er ier.LlrIdxFullEpochRecord) bool {
+		if er.EpochState.Rules.NetworkID != g.NetworkID || er.EpochState.Rules.Name != g.NetworkName {
+			err = errors.New("network ID/name mismatch")
+			return false
+		}
+		ers = append(ers, er)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if len(ers) == 0 {
+		return errors.New("no ERs in genesis")
+	}
+	// epochs are written in ascending order, as upgrade heights depend on the previous epoch
+	sort.Slice(ers, func(i, j int) bool {
+		return ers[i].Idx < ers[j].Idx
+	})
+	topEr := ers[len(ers)-1]
+	if topEr.Idx <= s.GetEpoch() {
+		return fmt.Errorf("current epoch %d is not below the delta top epoch %d", s.GetEpoch(), topEr.Idx)
+	}
+	top := topEr.BlockState.LastBlock.Idx
+	if head > top {
+		return fmt.Errorf("latest block %d is above the delta top block %d", head, top)
+	}
+
+	// verify the new records before anything is written
+	v := newDeltaVerifier(s)
+	if err := v.verifyEpochs(ers, g.EpochVotes); err != nil {
+		return err
+	}
+	if err := v.verifyBlocks(g.Blocks, g.BlockVotes, base.Epoch, topEr.Idx, head); err != nil {
+		return err
+	}
+
+	if err := s.writeDeltaRecords(g, ers, base.Block, head); err != nil {
+		return err
+	}
+
+	// apply state changes of the new blocks
+	err = s.evm.ApplyStateDiffs(g.StateDiffs, head+1, top, func(n idx.Block, root hash.Hash) error {
+		stored := s.GetBlock(n)
+		if stored == nil {
+			return fmt.Errorf("block %d not found", n)
+		}
+		if root != stored.Root {
+			return fmt.Errorf("state root of block %d mismatch (%s != %s)", n, root, stored.Root)
+		}
+		if n%1000 == 0 {
+			s.Log.Info("Applying genesis delta blocks", "block", n, "top", top)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.getAnyEpochStore() != nil {
+		s.resetEpochStore(topEr.Idx)
+	}
+	s.SetBlockEpochState(topEr.BlockState, topEr.EpochState)
+	s.FlushBlockEpochState()
+
+	// write LLR state
+	s.ModifyLlrState(func(llrs *LlrState) {
+		if llrs.LowestEpochToDecide <= topEr.Idx {
+			llrs.LowestEpochToDecide = topEr.Idx + 1
+		}
+		if llrs.LowestEpochToFill <= topEr.Idx {
+			llrs.LowestEpochToFill = topEr.Idx + 1
+		}
+		if llrs.LowestBlockToDecide <= top {
+			llrs.LowestBlockToDecide = top + 1
+		}
+		if llrs.LowestBlockToFill <= top {
+			llrs.LowestBlockToFill = top + 1
+		}
+	})
+	s.FlushLlrState()
+
+	s.Log.Info("Genesis delta applied", "epoch", topEr.Idx, "block", top)
+	return nil
+}
+
+// writeDeltaRecords writes the new epoch and block records of the delta, and checks the known blocks.
+func (s *Store) writeDeltaRecords(g genesis.Genesis, ers []ier.LlrIdxFullEpochRecord, base, head idx.Block) (err error) {
+	// use batching wrapper for hot tables
+	unwrap := s.WrapTablesAsBatched()
+	defer unwrap()
+
+	// write epochs
+	for _, er := range ers {
+		if s.HasHistoryBlockEpochState(er.Idx) {
+			continue
+		}
+		s.WriteFullEpochRecord(er)
+		s.WriteUpgradeHeight(er.BlockState, er.EpochState, s.GetHistoryEpochState(er.Idx-1))
+	}
+
+	// write blocks, which are not known yet, and check the known ones
+	g.Blocks.ForEach(func(br ibr.LlrIdxFullBlockRecord) bool {
+		if br.Idx <= base {
+			return true
+		}
+		if br.Idx <= head {
+			if known := s.GetBlock(br.Idx); known == nil || known.Atropos != br.Atropos {
+				err = fmt.Errorf("block %d of the delta mismatches the known block", br.Idx)
+				return false
+			}
+			return true
+		}
+		s.WriteFullBlockRecord(br)
+		return true
+	})
+	return err
+}
+
+// deltaVerifier checks the new records of a delta genesis against the LLR votes carried by the delta.
+// A record is confirmed once the validators, which have voted for its hash, have more than 1/3 of the weight,
+// the same as the LLR voting decides a record. The votes of an epoch are checked against the validators
+// of the epoch, which are known from the local history or from the verified epoch records of the delta.
+type deltaVerifier struct {
+	store   *Store
+	ers     map[idx.Epoch]*ier.LlrIdxFullEpochRecord
+	checker *heavycheck.Checker
+}
+
+func newDeltaVerifier(s *Store) *deltaVerifier {
+	v := &deltaVerifier{
+		store: s,
+		ers:   make(map[idx.Epoch]*ier.LlrIdxFullEpochRecord),
+	}
+	v.checker = heavycheck.New(heavycheck.DefaultConfig(), v, nil)
+	return v
+}
+
+func (v *deltaVerifier) getEpochState(epoch idx.Epoch) (*iblockproc.BlockState, *iblockproc.EpochState) {
+	if er, ok := v.ers[epoch]; ok {
+		return &er.BlockState, &er.EpochState
+	}
+	return v.store.GetHistoryBlockEpochState(epoch)
+}
+
+// GetEpochPubKeys isn't used for the votes checking
+func (v *deltaVerifier) GetEpochPubKeys() (map[idx.ValidatorID]validatorpk.PubKey, idx.Epoch) {
+	return nil, 0
+}
+
+// GetEpochPubKeysOf returns the pubkeys of the validators of the epoch
+func (v *deltaVerifier) GetEpochPubKeysOf(epoch idx.Epoch) map[idx.ValidatorID]validatorpk.PubKey {
+	_, es := v.getEpochState(epoch)
+	if es == nil {
+		return nil
+	}
+	pubkeys := make(map[idx.ValidatorID]validatorpk.PubKey, len(es.ValidatorProfiles))
+	for id, profile := range es.ValidatorProfiles {
+		pubkeys[id] = profile.PubKey
+	}
+	return pubkeys
+}
+
+// GetEpochBlockStart returns the last block before the epoch
+func (v *deltaVerifier) GetEpochBlockStart(epoch idx.Epoch) idx.Block {
+	bs, _ := v.getEpochState(epoch)
+	if bs == nil {
+		return 0
+	}
+	return bs.LastBlock.Idx
+}
+
+// llrTally sums up the weight of the validators, which have voted for the record hash.
+type llrTally struct {
+	record     hash.Hash
+	epoch      idx.Epoch
+	validators *pos.Validators
+	voted      map[idx.ValidatorID]bool
+	weight     pos.Weight
+}
+
+func newLlrTally(record hash.Hash, epoch idx.Epoch, validators *pos.Validators) *llrTally {
+	return &llrTally{
+		record:     record,
+		epoch:      epoch,
+		validators: validators,
+		voted:      make(map[idx.ValidatorID]bool),
+	}
+}
+
+func (t *llrTally) add(vid idx.ValidatorID) {
+	if t.voted[vid] {
+		return
+	}
+	t.voted[vid] = true
+	t.weight += t.validators.Get(vid)
+}
+
+func (t *llrTally) decided() bool {
+	return t.weight >= t.validators.TotalWeight()/3+1
+}
+
+// verifyEpochs checks the new epoch records in ascending order, as the epoch votes are signed by the validators of the previous epoch.
+func (v *deltaVerifier) verifyEpochs(ers []ier.LlrIdxFullEpochRecord, evs genesis.EpochVotes) error {
+	votes := make(map[idx.Epoch][]inter.LlrSignedEpochVote)
+	evs.ForEach(func(ev inter.LlrSignedEpochVote) bool {
+		votes[ev.Val.Epoch] = append(votes[ev.Val.Epoch], ev)
+		return true
+	})
+	for i := range ers {
+		er := &ers[i]
+		if v.store.HasHistoryBlockEpochState(er.Idx) {
+			continue
+		}
+		_, es := v.getEpochState(er.Idx - 1)
+		if es == nil {
+			return fmt.Errorf("validators of epoch %d are not known", er.Idx-1)
+		}
+		tally := newLlrTally(er.Hash(), er.Idx, es.Validators)
+		for _, ev := range votes[er.Idx] {
+			if ev.Val.Vote != tally.record || v.checker.ValidateEV(ev) != nil {
+				continue
+			}
+			tally.add(ev.Signed.Locator.Creator)
+		}
+		if !tally.decided() {
+			return fmt.Errorf("epoch %d of the delta is not confirmed by the LLR votes", er.Idx)
+		}
+		v.ers[er.Idx] = er
+	}
+	return nil
+}
+
+// verifyBlocks checks the block records after the head, which are signed by the validators of the block epochs.
+func (v *deltaVerifier) verifyBlocks(brs genesis.Blocks, bvs genesis.BlockVotes, from, to idx.Epoch, head idx.Block) error {
+	// the last blocks before the epochs
+	var starts []idx.Block
+	for e := from; e <= to; e++ {
+		starts = append(starts, v.GetEpochBlockStart(e))
+	}
+	tallies := make(map[idx.Block]*llrTally)
+	var err error
+	brs.ForEach(func(br ibr.LlrIdxFullBlockRecord) bool {
+		if br.Idx <= head {
+			return true
+		}
+		i := sort.Search(len(starts), func(i int) bool {
+			return starts[i] >= br.Idx
+		})
+		if i == 0 || i == len(starts) {
+			err = fmt.Errorf("block %d of the delta is not within the delta epochs", br.Idx)
+			return false
+		}
+		epoch := from + idx.Epoch(i-1)
+		_, es := v.getEpochState(epoch)
+		tallies[br.Idx] = newLlrTally(br.Hash(), epoch, es.Validators)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	bvs.ForEach(func(bvs inter.LlrSignedBlockVotes) bool {
+		var voted []*llrTally
+		for i, bv := range bvs.Val.Votes {
+			tally := tallies[bvs.Val.Start+idx.Block(i)]
+			if tally != nil && tally.epoch == bvs.Val.Epoch && tally.record == bv {
+				voted = append(voted, tally)
+			}
+		}
+		if len(voted) == 0 || v.checker.ValidateBVs(bvs) != nil {
+			return true
+		}
+		for _, tally := range voted {
+			tally.add(bvs.Signed.Locator.Creator)
+		}
+		return true
+	})
+	for n, tally := range tallies {
+		if !tally.decided() {
+			return fmt.Errorf("block %d of the delta is not confirmed by the LLR votes", n)
+		}
+	}
+	return nil
+}
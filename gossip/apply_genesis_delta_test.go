@@ -0,0 +1,224 @@
+package gossip
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/flushable"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/integration/makefakegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/ibr"
+	"github.com/Fantom-foundation/go-opera/inter/ier"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+type testDelta struct {
+	base   *genesis.DeltaBase
+	epochs []ier.LlrIdxFullEpochRecord
+	blocks []ibr.LlrIdxFullBlockRecord
+}
+
+func (d *testDelta) GetBase() (*genesis.DeltaBase, error) {
+	if d.base == nil {
+		return nil, errors.New("not a delta")
+	}
+	return d.base, nil
+}
+
+type testDeltaEpochs []ier.LlrIdxFullEpochRecord
+
+func (ers testDeltaEpochs) ForEach(fn func(ier.LlrIdxFullEpochRecord) bool) {
+	for _, er := range ers {
+		if !fn(er) {
+			return
+		}
+	}
+}
+
+type testDeltaBlockVotes []inter.LlrSignedBlockVotes
+
+func (bvs testDeltaBlockVotes) ForEach(fn func(inter.LlrSignedBlockVotes) bool) {
+	for _, v := range bvs {
+		if !fn(v) {
+			return
+		}
+	}
+}
+
+type testDeltaEpochVotes []inter.LlrSignedEpochVote
+
+func (evs testDeltaEpochVotes) ForEach(fn func(inter.LlrSignedEpochVote) bool) {
+	for _, ev := range evs {
+		if !fn(ev) {
+			return
+		}
+	}
+}
+
+type testDeltaStateDiffs []genesis.StateDiff
+
+func (diffs testDeltaStateDiffs) ForEach(fn func(genesis.StateDiff) bool) {
+	for _, diff := range diffs {
+		if !fn(diff) {
+			return
+		}
+	}
+}
+
+type testDeltaBlocks []ibr.LlrIdxFullBlockRecord
+
+func (brs testDeltaBlocks) ForEach(fn func(ibr.LlrIdxFullBlockRecord) bool) {
+	for _, br := range brs {
+		if !fn(br) {
+			return
+		}
+	}
+}
+
+// makeTestDelta reads epochs, blocks and LLR votes after the given epoch, in the same order as the genesis export does.
+// The state changes are read from the archive, once the store is closed.
+func makeTestDelta(s *Store, since idx.Epoch) genesis.Genesis {
+	bs, _ := s.GetHistoryBlockEpochState(since)
+	base := s.GetBlock(bs.LastBlock.Idx)
+	delta := &testDelta{
+		base: &genesis.DeltaBase{
+			Epoch:   since,
+			Block:   bs.LastBlock.Idx,
+			Atropos: base.Atropos,
+			Root:    base.Root,
+		},
+	}
+	to := s.GetEpoch()
+	for e := to; e > since; e-- {
+		delta.epochs = append(delta.epochs, ier.LlrIdxFullEpochRecord{LlrFullEpochRecord: *s.GetFullEpochRecord(e), Idx: e})
+	}
+	topBs, _ := s.GetHistoryBlockEpochState(to)
+	for n := topBs.LastBlock.Idx; n > bs.LastBlock.Idx; n-- {
+		delta.blocks = append(delta.blocks, ibr.LlrIdxFullBlockRecord{LlrFullBlockRecord: *s.GetFullBlockRecord(n), Idx: n})
+	}
+	var evs testDeltaEpochVotes
+	for e := since + 1; e <= to; e++ {
+		s.IterateEpochVotesRLP(e.Bytes(), func(raw rlp.RawValue) bool {
+			var ev inter.LlrSignedEpochVote
+			if err := rlp.DecodeBytes(raw, &ev); err != nil {
+				panic(err)
+			}
+			evs = append(evs, ev)
+			return true
+		})
+	}
+	var bvs testDeltaBlockVotes
+	s.IterateOverlappingBlockVotesRLP(since.Bytes(), func(key []byte, raw rlp.RawValue) bool {
+		var v inter.LlrSignedBlockVotes
+		if err := rlp.DecodeBytes(raw, &v); err != nil {
+			panic(err)
+		}
+		bvs = append(bvs, v)
+		return true
+	})
+	return genesis.Genesis{
+		Header: genesis.Header{
+			GenesisID:   *s.GetGenesisID(),
+			NetworkID:   s.GetRules().NetworkID,
+			NetworkName: s.GetRules().Name,
+		},
+		Epochs:       testDeltaEpochs(delta.epochs),
+		Blocks:       testDeltaBlocks(delta.blocks),
+		BlockVotes:   bvs,
+		EpochVotes:   evs,
+		DeltaSection: delta,
+	}
+}
+
+func TestApplyGenesisDelta(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	const (
+		firstEpoch    = idx.Epoch(2)
+		validatorsNum = idx.Validator(3)
+	)
+	env := newTestEnv(firstEpoch, validatorsNum, t)
+
+	// node which stays at the genesis
+	rules := opera.FakeNetRules()
+	rules.Epochs.MaxEpochDuration = inter.Timestamp(maxEpochDuration)
+	rules.Blocks.MaxEmptyBlockSkipPeriod = 0
+	genStore := makefakegenesis.FakeGenesisStoreWithRulesAndStart(validatorsNum, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake), rules, firstEpoch, 2)
+	store, err := NewStore(flushable.NewSyncedPool(memorydb.NewProducer(""), []byte{0}), MemTestStoreConfig(t.TempDir()))
+	require.NoError(err)
+	defer store.Close()
+	require.NoError(store.ApplyGenesis(genStore.Genesis()))
+
+	for i := 0; i < 3; i++ {
+		_, err := env.ApplyTxs(nextEpoch, env.Transfer(1, 2, utils.ToFtm(100)))
+		require.NoError(err)
+		_, err = env.ApplyTxs(sameEpoch, env.Transfer(2, 3, utils.ToFtm(10)))
+		require.NoError(err)
+	}
+	require.Greater(env.store.GetEpoch(), firstEpoch+1)
+
+	// the delta and the expected state are read before the store is closed
+	delta := makeTestDelta(env.store, firstEpoch)
+	base, _ := delta.DeltaSection.GetBase()
+	bs, es := env.store.GetHistoryBlockEpochState(env.store.GetEpoch())
+	lastRoot := env.store.GetBlock(bs.LastBlock.Idx).Root
+	atroposes := make(map[idx.Block]hash.Event)
+	for n := base.Block + 1; n <= bs.LastBlock.Idx; n++ {
+		atroposes[n] = env.store.GetBlock(n).Atropos
+	}
+	evmCfg := env.store.cfg.EVM
+	env.Close()
+	var diffs testDeltaStateDiffs
+	require.NoError(evmstore.NewStore(memorydb.New(), evmCfg).ExportStateDiffs(context.Background(), base.Block+1, bs.LastBlock.Idx, func(diff genesis.StateDiff) error {
+		diffs = append(diffs, diff)
+		return nil
+	}))
+	delta.StateDiffs = diffs
+
+	// base mismatch
+	wrongBase := *base
+	wrongBase.Root[0]++
+	wrong := delta
+	wrong.DeltaSection = &testDelta{base: &wrongBase}
+	require.Error(store.ApplyGenesisDelta(wrong))
+
+	// records, which are not confirmed by the LLR votes
+	wrong = delta
+	wrong.EpochVotes = testDeltaEpochVotes{}
+	require.ErrorContains(store.ApplyGenesisDelta(wrong), "not confirmed by the LLR votes")
+	wrong = delta
+	wrong.BlockVotes = testDeltaBlockVotes{}
+	require.ErrorContains(store.ApplyGenesisDelta(wrong), "not confirmed by the LLR votes")
+	wrongBlocks := append(testDeltaBlocks{}, delta.Blocks.(testDeltaBlocks)...)
+	wrongBlocks[0].GasUsed++
+	wrong = delta
+	wrong.Blocks = wrongBlocks
+	require.ErrorContains(store.ApplyGenesisDelta(wrong), "not confirmed by the LLR votes")
+	require.Equal(base.Block, store.GetLatestBlockIndex())
+
+	require.NoError(store.ApplyGenesisDelta(delta))
+
+	require.Equal(bs.LastBlock.Idx, store.GetLatestBlockIndex())
+	require.Equal(es.Epoch, store.GetEpoch())
+	require.Equal(bs.FinalizedStateRoot, store.GetBlockState().FinalizedStateRoot)
+	require.NoError(store.evm.CheckLiveStateHash(bs.LastBlock.Idx, lastRoot))
+	for n := base.Block + 1; n <= bs.LastBlock.Idx; n++ {
+		require.Equal(atroposes[n], store.GetBlock(n).Atropos)
+	}
+	require.Equal(bs.LastBlock.Idx+1, store.GetLlrState().LowestBlockToFill)
+
+	// already applied
+	require.Error(store.ApplyGenesisDelta(delta))
+}
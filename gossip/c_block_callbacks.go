@@ -22,9 +22,11 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc/verwatcher"
 	"github.com/Fantom-foundation/go-opera/gossip/emitter"
 	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/gossip/sonicmetrics"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 	"github.com/Fantom-foundation/go-opera/utils"
 )
 
@@ -138,6 +140,8 @@ func consensusCallbackBeginBlockFn(
 				if e.AnyMisbehaviourProofs() {
 					mps := store.GetEventPayload(e.ID()).MisbehaviourProofs()
 					for _, mp := range mps {
+						// the proof isn't submitted again
+						store.SetMisbehaviourProofSubmitted(mp)
 						// self-contained parts of proofs are already checked by the checkers
 						if proof := mp.BlockVoteDoublesign; proof != nil {
 							reportCheater(e.Creator(), proof.Pair[0].Signed.Locator.Creator)
@@ -185,6 +189,7 @@ func consensusCallbackBeginBlockFn(
 				for _, em := range *emitters {
 					em.OnEventConfirmed(e)
 				}
+				feed.newDagEvent.Send(store.GetEventPayload(e.ID()))
 				confirmedEventsMeter.Mark(1)
 			},
 			EndBlock: func() (newValidators *pos.Validators) {
@@ -251,7 +256,14 @@ func consensusCallbackBeginBlockFn(
 					})
 				}
 
-				evmProcessor := blockProc.EVMModule.Start(blockCtx, statedb, evmStateReader, onNewLogAll, es.Rules, es.Rules.EvmChainConfig(store.GetUpgradeHeights()))
+				vmConfig := opera.DefaultVMConfig
+				var blockTracer *txtrace.BlockTracer
+				if txIndex && store.evm.TracesIndexingEnabled() {
+					blockTracer = txtrace.NewBlockTracer(common.Hash(blockCtx.Atropos), utils.U64toBig(uint64(blockCtx.Idx)))
+					vmConfig.Debug = true
+					vmConfig.Tracer = blockTracer
+				}
+				evmProcessor := blockProc.EVMModule.Start(blockCtx, statedb, evmStateReader, onNewLogAll, es.Rules, es.Rules.EvmChainConfig(store.GetUpgradeHeights()), vmConfig)
 				executionStart := time.Now()
 
 				// Execute pre-internal transactions
@@ -370,6 +382,9 @@ func consensusCallbackBeginBlockFn(
 								store.evm.IndexLogs(r.Logs...)
 							}
 						}
+						if blockTracer != nil {
+							store.evm.SetTraces(blockCtx.Idx, blockTracer.GetResult(evmBlock.Transactions, allReceipts))
+						}
 					}
 					for _, tx := range append(preInternalTxs, internalTxs...) {
 						store.evm.SetTx(tx.Hash(), tx)
@@ -385,11 +400,13 @@ func consensusCallbackBeginBlockFn(
 					store.SetBlockIndex(block.Atropos, blockCtx.Idx)
 					store.SetBlockEpochState(bs, es)
 					store.EvmStore().SetCachedEvmBlock(blockCtx.Idx, evmBlock)
+					store.UpdateArchiveWindow(blockCtx.Idx, es.Epoch)
 					updateLowestBlockToFill(blockCtx.Idx, store)
 					updateLowestEpochToFill(es.Epoch, store)
 
 					// Update the metrics touched during block processing
 					blockExecutionTimer.Update(time.Since(executionStart))
+					sonicmetrics.BlockExecution.Observe(time.Since(executionStart).Seconds())
 
 					// Update the metrics touched by new block
 					headBlockGauge.Update(int64(blockCtx.Idx))
@@ -5,10 +5,12 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
 
+	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/logger"
 	"github.com/Fantom-foundation/go-opera/utils"
 )
@@ -65,3 +67,37 @@ func TestConsensusCallback(t *testing.T) {
 	}
 
 }
+
+func TestConsensusCallbackDagEvents(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	const validatorsNum = 3
+
+	env := newTestEnv(2, validatorsNum, t)
+	defer env.Close()
+
+	events := make(chan *inter.EventPayload, 1000)
+	sub := env.feed.SubscribeNewDagEvent(events)
+	defer sub.Unsubscribe()
+	var confirmed hash.Events
+	env.callback.onEventConfirmed = func(e inter.EventI) {
+		confirmed.Add(e.ID())
+	}
+	defer func() {
+		env.callback.onEventConfirmed = nil
+	}()
+
+	for i := 0; i < 3; i++ {
+		_, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+		require.NoError(err)
+	}
+
+	// only the confirmed events are notified, in the order of confirmation
+	require.NotEmpty(confirmed)
+	require.Len(events, len(confirmed))
+	for _, id := range confirmed {
+		e := <-events
+		require.Equal(id, e.ID())
+	}
+}
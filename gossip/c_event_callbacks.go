@@ -5,6 +5,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"math/big"
 	"sync/atomic"
+	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/gossip/dagprocessor"
 	"github.com/Fantom-foundation/lachesis-base/hash"
@@ -16,6 +17,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/eventcheck"
 	"github.com/Fantom-foundation/go-opera/eventcheck/epochcheck"
 	"github.com/Fantom-foundation/go-opera/gossip/emitter"
+	"github.com/Fantom-foundation/go-opera/gossip/filters"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 	"github.com/Fantom-foundation/go-opera/utils/concurrent"
@@ -140,6 +142,13 @@ func (s *Service) switchEpochTo(newEpoch idx.Epoch) {
 		em.OnNewEpoch(s.store.GetValidators(), newEpoch)
 	}
 	s.feed.newEpoch.Send(newEpoch)
+	if prevEs := s.store.GetHistoryEpochState(newEpoch - 1); prevEs != nil {
+		es := s.store.GetEpochState()
+		s.feed.newEpochState.Send(filters.NewEpochNotify{
+			EpochState: &es,
+			PrevRules:  prevEs.Rules,
+		})
+	}
 }
 
 func (s *Service) SwitchEpochTo(newEpoch idx.Epoch) error {
@@ -167,6 +176,7 @@ func (s *Service) processEventEpochIndex(e *inter.EventPayload, oldEpoch, newEpo
 	// index DAG heads and last events
 	s.store.SetHeads(oldEpoch, processEventHeads(s.store.GetHeads(oldEpoch), e))
 	s.store.SetLastEvents(oldEpoch, processLastEvent(s.store.GetLastEvents(oldEpoch), e))
+	s.lastSeen.Set(e.Creator(), time.Now())
 	// update highest Lamport
 	if newEpoch != oldEpoch {
 		s.store.SetHighestLamport(0)
@@ -237,11 +247,17 @@ func (s *Service) processEvent(e *inter.EventPayload) error {
 
 	processedEventsMeter.Mark(1)
 
+	doublesign := s.findEventsDoublesign(e)
+
 	err = s.saveAndProcessEvent(e, &es)
 	if err != nil {
 		return err
 	}
 
+	if doublesign != nil {
+		s.addMisbehaviourProof(*doublesign)
+	}
+
 	newEpoch := s.store.GetEpoch()
 
 	s.processEventEpochIndex(e, oldEpoch, newEpoch)
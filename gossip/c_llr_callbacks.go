@@ -76,6 +76,12 @@ func (s *Service) processBlockVotes(bvs inter.LlrSignedBlockVotes) error {
 			b++
 		}
 	})
+	if proof := s.findBlockVotesDoublesign(bvs); proof != nil {
+		s.addMisbehaviourProof(*proof)
+	}
+	if proof := s.findWrongBlockVotes(bvs); proof != nil {
+		s.addMisbehaviourProof(*proof)
+	}
 	s.store.SetBlockVotes(bvs)
 	lBVs := s.store.GetLastBVs()
 	lBVs.Lock()
@@ -154,6 +160,10 @@ func (s *Service) ProcessFullBlockRecord(br ibr.LlrIdxFullBlockRecord) error {
 		return errors.New("block record hash mismatch")
 	}
 
+	if s.handler.syncStatus.LlrSyncing() {
+		return s.applyFullBlockRecord(br)
+	}
+
 	s.store.WriteFullBlockRecord(br)
 	s.engineMu.Lock()
 	defer s.engineMu.Unlock()
@@ -212,6 +222,12 @@ func (s *Service) processEpochVote(ev inter.LlrSignedEpochVote) error {
 	s.store.ModifyLlrState(func(llrs *LlrState) {
 		s.processRawEpochVote(ev.Val.Epoch, ev.Val.Vote, es.Validators.GetIdx(vid), es.Validators, llrs)
 	})
+	if proof := s.findEpochVoteDoublesign(ev); proof != nil {
+		s.addMisbehaviourProof(*proof)
+	}
+	if proof := s.findWrongEpochVote(ev); proof != nil {
+		s.addMisbehaviourProof(*proof)
+	}
 	s.store.SetEpochVote(ev)
 	lEVs := s.store.GetLastEVs()
 	lEVs.Lock()
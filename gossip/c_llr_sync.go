@@ -0,0 +1,93 @@
+package gossip
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/eventcheck"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+	"github.com/Fantom-foundation/go-opera/inter/ibr"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// errLlrSyncStopped is returned for the block records which arrive after the LLR sync got stopped by a mismatch.
+var errLlrSyncStopped = errors.New("LLR sync is stopped")
+
+// applyFullBlockRecord executes the transactions of a decided block record on top of the live state,
+// so that the LLR sync gets the state without processing the events.
+// The records are applied in order, up to the last block of the latest known epoch record,
+// as the rules of a block are known only after its epoch is sealed.
+// If the execution result doesn't match the record, the LLR sync is stopped, as the live state
+// is already modified and may not be continued by either the records or the events.
+// The mismatch is persisted, so the node refuses to start on the diverged state.
+func (s *Service) applyFullBlockRecord(br ibr.LlrIdxFullBlockRecord) error {
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+	if s.handler.syncStatus.LlrSyncFailed() {
+		return errLlrSyncStopped
+	}
+	if !s.handler.syncStatus.AcceptBlockRecords() {
+		// switching to the events processing
+		return eventcheck.ErrUndecidedBR
+	}
+
+	llrs := s.store.GetLlrState()
+	if br.Idx < llrs.LowestBlockToFill {
+		return eventcheck.ErrAlreadyProcessedBR
+	}
+	if br.Idx > llrs.LowestBlockToFill {
+		return eventcheck.ErrUndecidedBR
+	}
+	sealed, _ := s.store.GetHistoryBlockEpochState(llrs.LowestEpochToFill - 1)
+	if sealed == nil || br.Idx > sealed.LastBlock.Idx {
+		return eventcheck.ErrUndecidedBR
+	}
+	epoch := s.store.FindBlockEpoch(br.Idx)
+	es := s.store.GetHistoryEpochState(epoch)
+	if es == nil {
+		return eventcheck.ErrUndecidedBR
+	}
+
+	prev := s.store.GetBlock(br.Idx - 1)
+	if prev == nil {
+		return fmt.Errorf("failed to get block %d", br.Idx-1)
+	}
+	statedb, err := s.store.evm.GetLiveStateDb(prev.Root)
+	if err != nil {
+		return fmt.Errorf("failed to open StateDB: %w", err)
+	}
+	blockCtx := iblockproc.BlockCtx{
+		Idx:     br.Idx,
+		Time:    br.Time,
+		Atropos: br.Atropos,
+	}
+	evmStateReader := &EvmStateReader{
+		ServiceFeed: &s.feed,
+		store:       s.store,
+	}
+	onNewLog := func(l *types.Log) {
+		if s.verWatcher != nil {
+			s.verWatcher.OnNewLog(l)
+		}
+	}
+	evmProcessor := s.blockProcModules.EVMModule.Start(blockCtx, statedb, evmStateReader, onNewLog, es.Rules, es.Rules.EvmChainConfig(s.store.GetUpgradeHeights()), opera.DefaultVMConfig)
+	_ = evmProcessor.Execute(br.Txs)
+	evmBlock, skippedTxs, _ := evmProcessor.Finalize()
+	if len(skippedTxs) != 0 || hash.Hash(evmBlock.Root) != br.Root {
+		// the state is already committed, and the record is decided by the validators
+		s.handler.syncStatus.setLlrSync(llrSyncFailed)
+		s.store.SetLlrSyncFailed(br.Idx)
+		s.commit(false)
+		s.Log.Error("Block record execution mismatch, LLR sync is stopped", "block", br.Idx, "root", evmBlock.Root, "expected", br.Root, "skipped", len(skippedTxs))
+		return fmt.Errorf("%w: block %d execution mismatch", errLlrSyncStopped, br.Idx)
+	}
+
+	s.store.WriteFullBlockRecord(br)
+	s.store.UpdateArchiveWindow(br.Idx, epoch)
+	updateLowestBlockToFill(br.Idx, s.store)
+	s.mayCommit(false)
+	return nil
+}
@@ -0,0 +1,175 @@
+package gossip
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/eventcheck"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/ibr"
+	"github.com/Fantom-foundation/go-opera/inter/ier"
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+// startLlrSync decides and fills the epoch records of the generator on the processor, decides its block records,
+// and returns the latest filled epoch.
+func startLlrSync(t *testing.T, generator, processor *testEnv) idx.Epoch {
+	require := require.New(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := generator.ApplyTxs(sameEpoch, generator.Transfer(1, 2, big.NewInt(1)))
+		require.NoError(err)
+		_, err = generator.ApplyTxs(nextEpoch, generator.Transfer(2, 1, big.NewInt(1)))
+		require.NoError(err)
+	}
+
+	processor.handler.syncStatus.setLlrSync(llrSyncApplying)
+	require.False(processor.handler.syncStatus.AcceptEvents())
+	require.True(processor.handler.syncStatus.AcceptBlockRecords())
+
+	// decide and fill the epoch records
+	evs := fetchEvs(generator)
+	for e := processor.store.GetEpoch() + 1; e <= generator.store.GetEpoch(); e++ {
+		for _, ev := range evs[e] {
+			require.NoError(processor.ProcessEpochVote(*ev))
+		}
+		if processor.store.GetLlrEpochResult(e) == nil {
+			break
+		}
+		er := generator.store.GetFullEpochRecord(e)
+		require.NoError(processor.ProcessFullEpochRecord(ier.LlrIdxFullEpochRecord{LlrFullEpochRecord: *er, Idx: e}))
+	}
+	synced := processor.store.GetLlrState().LowestEpochToFill - 1
+	require.Greater(synced, processor.store.GetEpoch())
+
+	// decide the block records
+	bvs, _ := fetchBvsBlockIdxs(generator)
+	for _, bv := range bvs {
+		_ = processor.ProcessBlockVotes(*bv)
+	}
+	return synced
+}
+
+func TestLlrSync(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	const validatorsNum = 3
+
+	generator := newTestEnv(2, validatorsNum, t)
+	defer generator.Close()
+	processor := newTestEnv(2, validatorsNum, t)
+	defer processor.Close()
+
+	synced := startLlrSync(t, generator, processor)
+	sealed, _ := processor.store.GetHistoryBlockEpochState(synced)
+
+	// apply the block records
+	last := generator.store.GetLatestBlockIndex()
+	// the records are applied in order, and only within the filled epochs
+	for _, b := range []idx.Block{processor.store.GetLatestBlockIndex() + 2, last} {
+		br := ibr.LlrIdxFullBlockRecord{LlrFullBlockRecord: *generator.store.GetFullBlockRecord(b), Idx: b}
+		require.ErrorIs(processor.ProcessFullBlockRecord(br), eventcheck.ErrUndecidedBR)
+	}
+	for b := processor.store.GetLatestBlockIndex() + 1; b <= sealed.LastBlock.Idx; b++ {
+		br := ibr.LlrIdxFullBlockRecord{LlrFullBlockRecord: *generator.store.GetFullBlockRecord(b), Idx: b}
+		require.NoError(processor.ProcessFullBlockRecord(br))
+		require.Equal(generator.store.GetBlock(b).Root, processor.store.GetBlock(b).Root)
+	}
+	require.Equal(synced, processor.handler.llrSyncedEpoch())
+
+	// switch to the events processing
+	require.NoError(processor.SwitchEpochTo(synced))
+	processor.handler.syncStatus.setLlrSync(llrSyncOff)
+	require.True(processor.handler.syncStatus.AcceptEvents())
+	require.Equal(synced, processor.store.GetEpoch())
+	require.Equal(sealed.LastBlock.Idx, processor.store.GetLatestBlockIndex())
+
+	generator.store.ForEachEvent(synced, func(e *inter.EventPayload) bool {
+		processor.engineMu.Lock()
+		defer processor.engineMu.Unlock()
+		require.NoError(processor.processEvent(e))
+		return true
+	})
+	processor.blockProcWg.Wait()
+	require.Equal(generator.store.GetEpoch(), processor.store.GetEpoch())
+	require.Equal(last, processor.store.GetLatestBlockIndex())
+	require.Equal(generator.store.GetBlock(last).Root, processor.store.GetBlock(last).Root)
+}
+
+func TestLlrSyncStopsOnMismatch(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	const validatorsNum = 3
+
+	generator := newTestEnv(2, validatorsNum, t)
+	defer generator.Close()
+	processor := newTestEnv(2, validatorsNum, t)
+	defer processor.Close()
+
+	startLlrSync(t, generator, processor)
+
+	// a record which doesn't match its execution, but is decided by the validators
+	b := processor.store.GetLatestBlockIndex() + 1
+	br := ibr.LlrIdxFullBlockRecord{LlrFullBlockRecord: *generator.store.GetFullBlockRecord(b), Idx: b}
+	br.Root[0] ^= 0xff
+	processor.store.SetLlrBlockResult(b, br.Hash())
+
+	require.ErrorIs(processor.ProcessFullBlockRecord(br), errLlrSyncStopped)
+	require.True(processor.handler.syncStatus.LlrSyncFailed())
+	require.False(processor.handler.syncStatus.AcceptBlockRecords())
+	require.False(processor.handler.syncStatus.AcceptEvents())
+	require.Nil(processor.store.GetBlock(b))
+	require.Equal(b, processor.store.GetLlrState().LowestBlockToFill)
+
+	// the next records aren't applied
+	br = ibr.LlrIdxFullBlockRecord{LlrFullBlockRecord: *generator.store.GetFullBlockRecord(b + 1), Idx: b + 1}
+	require.ErrorIs(processor.ProcessFullBlockRecord(br), errLlrSyncStopped)
+	require.True(processor.handler.tryFinishLlrSync())
+
+	// the node refuses to start on the diverged state
+	failed, ok := processor.store.GetLlrSyncFailed()
+	require.True(ok)
+	require.Equal(b, failed)
+	_, err := newService(DefaultConfig(cachescale.Identity), processor.store, processor.blockProcModules, processor.engine, processor.dagIndexer, nil)
+	require.Error(err)
+}
+
+func TestLlrSyncStopsOnEpochMismatch(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	const validatorsNum = 3
+
+	generator := newTestEnv(2, validatorsNum, t)
+	defer generator.Close()
+	processor := newTestEnv(2, validatorsNum, t)
+	defer processor.Close()
+
+	synced := startLlrSync(t, generator, processor)
+	sealed, es := processor.store.GetHistoryBlockEpochState(synced)
+	for b := processor.store.GetLatestBlockIndex() + 1; b <= sealed.LastBlock.Idx; b++ {
+		br := ibr.LlrIdxFullBlockRecord{LlrFullBlockRecord: *generator.store.GetFullBlockRecord(b), Idx: b}
+		require.NoError(processor.ProcessFullBlockRecord(br))
+	}
+
+	// the epoch record doesn't match the state of the applied block records
+	epoch := processor.store.GetEpoch()
+	sealed.FinalizedStateRoot[0] ^= 0xff
+	processor.store.SetHistoryBlockEpochState(synced, *sealed, *es)
+
+	processor.handler.switchToLlrSyncedEpoch(synced)
+	require.True(processor.handler.syncStatus.LlrSyncFailed())
+	require.False(processor.handler.syncStatus.AcceptEvents())
+	require.Equal(epoch, processor.store.GetEpoch())
+	require.True(processor.handler.tryFinishLlrSync())
+
+	failed, ok := processor.store.GetLlrSyncFailed()
+	require.True(ok)
+	require.Equal(sealed.LastBlock.Idx, failed)
+}
@@ -0,0 +1,240 @@
+package gossip
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/go-opera/eventcheck/basiccheck"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+const (
+	// forkSearchDepth limits the number of self-parents to look through for an event with the same seq
+	forkSearchDepth = 16
+	// maxWrongVotes limits the number of wrong votes which wait for accomplices
+	maxWrongVotes = 1024
+)
+
+type wrongBlockVoteKey struct {
+	Block      idx.Block
+	Epoch      idx.Epoch
+	Vote       hash.Hash
+	WrongEpoch bool
+}
+
+// wrongVotes collects the wrong LLR votes until MinAccomplicesForProof validators sign the same wrong vote,
+// as a single wrong vote may be caused by a software or hardware failure, and isn't punishable.
+type wrongVotes struct {
+	blocks map[wrongBlockVoteKey][]inter.LlrSignedBlockVotes
+	epochs map[inter.LlrEpochVote][]inter.LlrSignedEpochVote
+}
+
+func newWrongVotes() *wrongVotes {
+	return &wrongVotes{
+		blocks: make(map[wrongBlockVoteKey][]inter.LlrSignedBlockVotes),
+		epochs: make(map[inter.LlrEpochVote][]inter.LlrSignedEpochVote),
+	}
+}
+
+// addBlockVotes returns the pals which signed the same wrong vote, once there are enough of them.
+func (w *wrongVotes) addBlockVotes(key wrongBlockVoteKey, bvs inter.LlrSignedBlockVotes) []inter.LlrSignedBlockVotes {
+	pals := w.blocks[key]
+	for _, pal := range pals {
+		if pal.Signed.Locator.Creator == bvs.Signed.Locator.Creator {
+			return nil
+		}
+	}
+	if len(w.blocks) >= maxWrongVotes {
+		w.blocks = make(map[wrongBlockVoteKey][]inter.LlrSignedBlockVotes)
+	}
+	pals = append(pals, bvs)
+	w.blocks[key] = pals
+	if len(pals) < inter.MinAccomplicesForProof {
+		return nil
+	}
+	delete(w.blocks, key)
+	return pals
+}
+
+// addEpochVote returns the pals which signed the same wrong vote, once there are enough of them.
+func (w *wrongVotes) addEpochVote(ev inter.LlrSignedEpochVote) []inter.LlrSignedEpochVote {
+	pals := w.epochs[ev.Val]
+	for _, pal := range pals {
+		if pal.Signed.Locator.Creator == ev.Signed.Locator.Creator {
+			return nil
+		}
+	}
+	if len(w.epochs) >= maxWrongVotes {
+		w.epochs = make(map[inter.LlrEpochVote][]inter.LlrSignedEpochVote)
+	}
+	pals = append(pals, ev)
+	w.epochs[ev.Val] = pals
+	if len(pals) < inter.MinAccomplicesForProof {
+		return nil
+	}
+	delete(w.epochs, ev.Val)
+	return pals
+}
+
+func (s *Service) addMisbehaviourProof(mp inter.MisbehaviourProof) {
+	if s.store.AddMisbehaviourProof(mp) {
+		s.Log.Warn("Misbehaviour is detected", "cheaters", misbehaviourProofCheaters(mp))
+	}
+}
+
+// findEventsDoublesign looks for a connected event of the same creator and seq, i.e. a fork.
+// It must be called before the event is indexed as the last event of its creator.
+func (s *Service) findEventsDoublesign(e *inter.EventPayload) *inter.MisbehaviourProof {
+	id := s.store.GetLastEvent(e.Epoch(), e.Creator())
+	for i := 0; id != nil && i < forkSearchDepth; i++ {
+		other := s.store.GetEvent(*id)
+		if other == nil || other.Seq() < e.Seq() {
+			return nil
+		}
+		if other.Seq() == e.Seq() {
+			if other.ID() == e.ID() {
+				return nil
+			}
+			otherPayload := s.store.GetEventPayload(other.ID())
+			if otherPayload == nil {
+				return nil
+			}
+			return &inter.MisbehaviourProof{
+				EventsDoublesign: &inter.EventsDoublesign{
+					Pair: [2]inter.SignedEventLocator{inter.AsSignedEventLocator(otherPayload), inter.AsSignedEventLocator(e)},
+				},
+			}
+		}
+		id = other.SelfParent()
+	}
+	return nil
+}
+
+// findBlockVotesDoublesign looks for the connected votes of the same validator with a different vote for a block.
+// It must be called before the votes are indexed as the last votes of the validator.
+func (s *Service) findBlockVotesDoublesign(bvs inter.LlrSignedBlockVotes) *inter.MisbehaviourProof {
+	vid := bvs.Signed.Locator.Creator
+	if last := s.store.GetLastBV(vid); last == nil || *last < bvs.Val.Start {
+		// the validator didn't vote for these blocks yet
+		return nil
+	}
+	var proof *inter.MisbehaviourProof
+	// the votes are indexed by epoch and last block, so the overlapping votes are within MaxBlockVotesPerEvent blocks
+	s.store.IterateOverlappingBlockVotesRLP(append(bvs.Val.Epoch.Bytes(), bvs.Val.Start.Bytes()...), func(key []byte, raw rlp.RawValue) bool {
+		if idx.BytesToEpoch(key[:4]) != bvs.Val.Epoch {
+			return false
+		}
+		if idx.BytesToBlock(key[4:12]) > bvs.Val.LastBlock()+basiccheck.MaxBlockVotesPerEvent {
+			return false
+		}
+		var other inter.LlrSignedBlockVotes
+		if err := rlp.DecodeBytes(raw, &other); err != nil {
+			s.Log.Crit("Failed to decode rlp", "err", err, "size", len(raw))
+		}
+		if other.Signed.Locator.Creator != vid || other.Signed.Locator.ID() == bvs.Signed.Locator.ID() {
+			return true
+		}
+		for b := bvs.Val.Start; b <= bvs.Val.LastBlock(); b++ {
+			if b < other.Val.Start || b > other.Val.LastBlock() {
+				continue
+			}
+			if other.Val.Votes[b-other.Val.Start] != bvs.Val.Votes[b-bvs.Val.Start] {
+				proof = &inter.MisbehaviourProof{
+					BlockVoteDoublesign: &inter.BlockVoteDoublesign{
+						Block: b,
+						Pair:  [2]inter.LlrSignedBlockVotes{other, bvs},
+					},
+				}
+				return false
+			}
+		}
+		return true
+	})
+	return proof
+}
+
+// findWrongBlockVotes compares the votes with the known block records.
+func (s *Service) findWrongBlockVotes(bvs inter.LlrSignedBlockVotes) *inter.MisbehaviourProof {
+	// all the votes are signed for the same epoch, so it's enough to check the first and the last blocks
+	for _, b := range []idx.Block{bvs.Val.Start, bvs.Val.LastBlock()} {
+		if b > s.store.GetLatestBlockIndex() {
+			continue
+		}
+		if actual := s.store.FindBlockEpoch(b); actual != 0 && actual != bvs.Val.Epoch {
+			pals := s.wrongVotes.addBlockVotes(wrongBlockVoteKey{Block: b, Epoch: bvs.Val.Epoch, WrongEpoch: true}, bvs)
+			if pals == nil {
+				return nil
+			}
+			proof := &inter.WrongBlockVote{Block: b, WrongEpoch: true}
+			copy(proof.Pals[:], pals)
+			return &inter.MisbehaviourProof{WrongBlockVote: proof}
+		}
+	}
+	for i, vote := range bvs.Val.Votes {
+		b := bvs.Val.Start + idx.Block(i)
+		// compare with the decided result first, as the block record may be expensive to build
+		decided := s.store.GetLlrBlockResult(b)
+		if decided == nil || *decided == vote {
+			continue
+		}
+		actual := s.store.GetBlockRecordHash(b)
+		if actual == nil || *actual == vote {
+			continue
+		}
+		pals := s.wrongVotes.addBlockVotes(wrongBlockVoteKey{Block: b, Vote: vote}, bvs)
+		if pals == nil {
+			continue
+		}
+		proof := &inter.WrongBlockVote{Block: b}
+		copy(proof.Pals[:], pals)
+		return &inter.MisbehaviourProof{WrongBlockVote: proof}
+	}
+	return nil
+}
+
+// findEpochVoteDoublesign looks for a connected vote of the same validator with a different vote for the epoch.
+// It must be called before the vote is indexed as the last vote of the validator.
+func (s *Service) findEpochVoteDoublesign(ev inter.LlrSignedEpochVote) *inter.MisbehaviourProof {
+	vid := ev.Signed.Locator.Creator
+	if last := s.store.GetLastEV(vid); last == nil || *last < ev.Val.Epoch {
+		// the validator didn't vote for this epoch yet
+		return nil
+	}
+	var proof *inter.MisbehaviourProof
+	s.store.IterateEpochVotesRLP(ev.Val.Epoch.Bytes(), func(raw rlp.RawValue) bool {
+		var other inter.LlrSignedEpochVote
+		if err := rlp.DecodeBytes(raw, &other); err != nil {
+			s.Log.Crit("Failed to decode rlp", "err", err, "size", len(raw))
+		}
+		if other.Signed.Locator.Creator == vid && other.Val.Vote != ev.Val.Vote {
+			proof = &inter.MisbehaviourProof{
+				EpochVoteDoublesign: &inter.EpochVoteDoublesign{
+					Pair: [2]inter.LlrSignedEpochVote{other, ev},
+				},
+			}
+			return false
+		}
+		return true
+	})
+	return proof
+}
+
+// findWrongEpochVote compares the vote with the known epoch record.
+func (s *Service) findWrongEpochVote(ev inter.LlrSignedEpochVote) *inter.MisbehaviourProof {
+	decided := s.store.GetLlrEpochResult(ev.Val.Epoch)
+	if decided == nil || *decided == ev.Val.Vote {
+		return nil
+	}
+	actual := s.store.GetFullEpochRecord(ev.Val.Epoch)
+	if actual == nil || actual.Hash() == ev.Val.Vote {
+		return nil
+	}
+	pals := s.wrongVotes.addEpochVote(ev)
+	if pals == nil {
+		return nil
+	}
+	proof := &inter.WrongEpochVote{}
+	copy(proof.Pals[:], pals)
+	return &inter.MisbehaviourProof{WrongEpochVote: proof}
+}
@@ -0,0 +1,30 @@
+package gossip
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// applyStateSnapshot replaces the EVM state by the downloaded snapshot of the epoch,
+// and switches to the epoch, so the node continues with the events of the epoch.
+func (s *Service) applyStateSnapshot(epoch idx.Epoch, path string) error {
+	bs, es := s.store.GetHistoryBlockEpochState(epoch)
+	if bs == nil {
+		return errNonExistingEpoch
+	}
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+	s.blockProcWg.Wait()
+	if epoch <= s.store.GetEpoch() {
+		return errSameEpoch
+	}
+	if err := s.store.evm.ReplaceWorldState(path, bs.LastBlock.Idx, bs.FinalizedStateRoot); err != nil {
+		return err
+	}
+	if err := s.engine.Reset(epoch, es.Validators); err != nil {
+		return err
+	}
+	s.store.SetBlockEpochState(*bs, *es)
+	s.switchEpochTo(epoch)
+	s.commit(true)
+	return nil
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/flushable"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
 	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -24,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
@@ -132,6 +135,10 @@ func (m testConfirmedEventsModule) Start(bs iblockproc.BlockState, es iblockproc
 }
 
 func newTestEnv(firstEpoch idx.Epoch, validatorsNum idx.Validator, tb testing.TB) *testEnv {
+	return newTestEnvWithStoreConfig(firstEpoch, validatorsNum, tb, MemTestStoreConfig(tb.TempDir()))
+}
+
+func newTestEnvWithStoreConfig(firstEpoch idx.Epoch, validatorsNum idx.Validator, tb testing.TB, storeCfg StoreConfig) *testEnv {
 	rules := opera.FakeNetRules()
 	rules.Epochs.MaxEpochDuration = inter.Timestamp(maxEpochDuration)
 	rules.Blocks.MaxEmptyBlockSkipPeriod = 0
@@ -139,9 +146,9 @@ func newTestEnv(firstEpoch idx.Epoch, validatorsNum idx.Validator, tb testing.TB
 	genStore := makefakegenesis.FakeGenesisStoreWithRulesAndStart(validatorsNum, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake), rules, firstEpoch, 2)
 	genesis := genStore.Genesis()
 
-	store, err := NewMemStore(tb)
+	store, err := NewStore(flushable.NewSyncedPool(memorydb.NewProducer(""), []byte{0}), storeCfg)
 	if err != nil {
-		panic(fmt.Errorf("NewMemStore failed; %w", err))
+		panic(fmt.Errorf("NewStore failed; %w", err))
 	}
 	err = store.ApplyGenesis(genesis)
 	if err != nil {
@@ -549,3 +556,16 @@ func (m callmsg) Nonce() uint64                { return 0 }
 func (m callmsg) IsFake() bool                 { return true }
 func (m callmsg) Data() []byte                 { return m.CallMsg.Data }
 func (m callmsg) AccessList() types.AccessList { return nil }
+
+// waitForArchive waits until the archive state of the latest block is available.
+func waitForArchive(t testing.TB, env *testEnv) {
+	last := env.store.GetLatestBlockIndex()
+	for {
+		height, empty, err := env.store.evm.GetArchiveBlockHeight()
+		require.NoError(t, err)
+		if !empty && idx.Block(height) >= last {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 
+	"github.com/Fantom-foundation/go-opera/ethapi/rpclimit"
 	"github.com/Fantom-foundation/go-opera/eventcheck/heavycheck"
 	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
 	"github.com/Fantom-foundation/go-opera/gossip/filters"
@@ -28,6 +29,8 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epprocessor"
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epstream/epstreamleecher"
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epstream/epstreamseeder"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream/snapstreamleecher"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream/snapstreamseeder"
 )
 
 const nominalSize uint = 1
@@ -52,22 +55,26 @@ type (
 		BrProcessor  brprocessor.Config
 		EpProcessor  epprocessor.Config
 
-		DagFetcher       itemsfetcher.Config
-		TxFetcher        itemsfetcher.Config
-		DagStreamLeecher dagstreamleecher.Config
-		DagStreamSeeder  dagstreamseeder.Config
-		BvStreamLeecher  bvstreamleecher.Config
-		BvStreamSeeder   bvstreamseeder.Config
-		BrStreamLeecher  brstreamleecher.Config
-		BrStreamSeeder   brstreamseeder.Config
-		EpStreamLeecher  epstreamleecher.Config
-		EpStreamSeeder   epstreamseeder.Config
+		DagFetcher        itemsfetcher.Config
+		TxFetcher         itemsfetcher.Config
+		DagStreamLeecher  dagstreamleecher.Config
+		DagStreamSeeder   dagstreamseeder.Config
+		BvStreamLeecher   bvstreamleecher.Config
+		BvStreamSeeder    bvstreamseeder.Config
+		BrStreamLeecher   brstreamleecher.Config
+		BrStreamSeeder    brstreamseeder.Config
+		EpStreamLeecher   epstreamleecher.Config
+		EpStreamSeeder    epstreamseeder.Config
+		SnapStreamLeecher snapstreamleecher.Config
+		SnapStreamSeeder  snapstreamseeder.Config
 
 		MaxInitialTxHashesSend   int
 		MaxRandomTxHashesSend    int
 		RandomTxHashesSendPeriod time.Duration
 
 		PeerCache PeerCacheConfig
+
+		PeerReputation PeerReputationConfig
 	}
 
 	// Config for the gossip service.
@@ -114,6 +121,15 @@ type (
 		MaxResponseSize int
 
 		RPCBlockExt bool
+
+		// RPCLimits are the per-client limits of RPC calls over HTTP and WebSocket, by the cost of the calls.
+		RPCLimits rpclimit.Config
+
+		// SyncMode is the way to synchronise the node, "full", "llr" or "snap".
+		SyncMode SyncMode
+
+		// Snapshots are the EVM state snapshots served to the peers for the snap sync.
+		Snapshots SnapshotsConfig
 	}
 
 	StoreCacheConfig struct {
@@ -152,6 +168,37 @@ type PeerCacheConfig struct {
 	MaxQueuedSize  uint64
 }
 
+type PeerReputationConfig struct {
+	// Penalties which are subtracted from the peer's score on a misbehaviour
+	InvalidItemPenalty   int64 // an invalid event, block votes, epoch vote, LLR record or snapshot chunk
+	MisbehaviourPenalty  int64 // an invalid stream request
+	MsgTooLargePenalty   int64 // a message or request exceeding the protocol limits
+	StreamTimeoutPenalty int64 // no response to a stream request within the progress watchdog
+	SlowResponsePenalty  int64 // a stream session exceeding the session watchdog
+	// ChunkReward is added to the peer's score on each stream chunk, once its items are accepted
+	ChunkReward int64
+	MaxScore    int64
+	// BanScore is the score at which the peer gets banned for BanDuration
+	BanScore    int64
+	BanDuration time.Duration
+	// RecoveryPeriod is the period of moving the score by one point towards zero
+	RecoveryPeriod time.Duration
+}
+
+// SnapshotsConfig is the config of the EVM state snapshots, exported at the epoch boundaries
+// from the archive, and served to the peers for the snap sync.
+type SnapshotsConfig struct {
+	// Serve enables the snapshots export, requires the archive
+	Serve bool
+	// Dir is the snapshots directory, by default it's next to the EVM state directory
+	Dir string
+	// EpochPeriod is the period of the snapshot epochs, the snap sync downloads
+	// only the snapshots of epochs which are multiples of the period. It has to match the peers' one.
+	EpochPeriod idx.Epoch
+	// Keep is the number of the latest snapshots kept on the disk
+	Keep int
+}
+
 // DefaultConfig returns the default configurations for the gossip service.
 func DefaultConfig(scale cachescale.Func) Config {
 	cfg := Config{
@@ -209,10 +256,13 @@ func DefaultConfig(scale cachescale.Func) Config {
 			BrStreamSeeder:           brstreamseeder.DefaultConfig(scale),
 			EpStreamLeecher:          epstreamleecher.DefaultConfig(),
 			EpStreamSeeder:           epstreamseeder.DefaultConfig(scale),
+			SnapStreamLeecher:        snapstreamleecher.DefaultConfig(),
+			SnapStreamSeeder:         snapstreamseeder.DefaultConfig(scale),
 			MaxInitialTxHashesSend:   20000,
 			MaxRandomTxHashesSend:    250, // match softLimitItems to fit into one message
 			RandomTxHashesSendPeriod: 1 * time.Second,
 			PeerCache:                DefaultPeerCacheConfig(scale),
+			PeerReputation:           DefaultPeerReputationConfig(),
 		},
 
 		RPCEVMTimeout: 5 * time.Second,
@@ -221,6 +271,7 @@ func DefaultConfig(scale cachescale.Func) Config {
 			MaxGasPrice:      gasprice.DefaultMaxGasPrice,
 			MinGasPrice:      new(big.Int),
 			DefaultCertainty: 0.5 * gasprice.DecimalUnit,
+			HistorySize:      10000,
 		},
 
 		RPCBlockExt: true,
@@ -234,6 +285,12 @@ func DefaultConfig(scale cachescale.Func) Config {
 		JSTracerLimit: 1000,
 
 		MaxResponseSize: 25 * 1024 * 1024,
+
+		RPCLimits: rpclimit.DefaultConfig(),
+
+		SyncMode: FullSync,
+
+		Snapshots: DefaultSnapshotsConfig(),
 	}
 	sessionCfg := cfg.Protocol.DagStreamLeecher.Session
 	cfg.Protocol.DagProcessor.EventsBufferLimit.Num = idx.Event(sessionCfg.ParallelChunksDownload)*
@@ -273,6 +330,24 @@ func (c *Config) Validate() error {
 	if p.DagProcessor.EventsBufferLimit.Size < protocolMaxMsgSize {
 		return fmt.Errorf("EventsBufferLimit.Size has to be at least %d", protocolMaxMsgSize)
 	}
+	if p.PeerReputation.BanScore >= 0 {
+		return fmt.Errorf("PeerReputation.BanScore has to be negative")
+	}
+	if p.PeerReputation.RecoveryPeriod <= 0 {
+		return fmt.Errorf("PeerReputation.RecoveryPeriod has to be positive")
+	}
+	if err := c.SyncMode.Validate(); err != nil {
+		return err
+	}
+	if c.Snapshots.EpochPeriod == 0 {
+		return fmt.Errorf("Snapshots.EpochPeriod has to be positive")
+	}
+	if c.Snapshots.Serve && c.Snapshots.Keep <= 0 {
+		return fmt.Errorf("Snapshots.Keep has to be positive")
+	}
+	if p.SnapStreamLeecher.Session.DefaultChunkItemsSize > protocolMaxMsgSize/2 {
+		return fmt.Errorf("SnapStreamLeecher.Session.DefaultChunkItemsSize has to be at not greater than %d", protocolMaxMsgSize/2)
+	}
 
 	return nil
 }
@@ -313,3 +388,26 @@ func DefaultPeerCacheConfig(scale cachescale.Func) PeerCacheConfig {
 		MaxQueuedSize:  protocolMaxMsgSize*3/4 + 1024 + scale.U64(protocolMaxMsgSize/4),
 	}
 }
+
+func DefaultPeerReputationConfig() PeerReputationConfig {
+	return PeerReputationConfig{
+		InvalidItemPenalty:   50,
+		MisbehaviourPenalty:  20,
+		MsgTooLargePenalty:   40,
+		StreamTimeoutPenalty: 10,
+		SlowResponsePenalty:  3,
+		ChunkReward:          1,
+		MaxScore:             100,
+		BanScore:             -100,
+		BanDuration:          12 * time.Hour,
+		RecoveryPeriod:       time.Minute,
+	}
+}
+
+func DefaultSnapshotsConfig() SnapshotsConfig {
+	return SnapshotsConfig{
+		Serve:       false,
+		EpochPeriod: 100,
+		Keep:        2,
+	}
+}
@@ -15,9 +15,10 @@ import (
 
 // dummyTxPool is a fake, helper transaction pool for testing purposes
 type dummyTxPool struct {
-	txFeed notify.Feed
-	pool   []*types.Transaction        // Collection of all transactions
-	added  chan<- []*types.Transaction // Notification channel for new transactions
+	txFeed  notify.Feed
+	pool    []*types.Transaction        // Collection of all transactions
+	private map[common.Hash]struct{}    // Hashes of the transactions submitted privately
+	added   chan<- []*types.Transaction // Notification channel for new transactions
 
 	signer types.Signer
 
@@ -45,6 +46,26 @@ func (p *dummyTxPool) AddLocal(tx *types.Transaction) error {
 	return p.AddLocals([]*types.Transaction{tx})[0]
 }
 
+func (p *dummyTxPool) AddPrivate(tx *types.Transaction) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.pool = append(p.pool, tx)
+	if p.private == nil {
+		p.private = make(map[common.Hash]struct{})
+	}
+	p.private[tx.Hash()] = struct{}{}
+	return nil
+}
+
+func (p *dummyTxPool) IsPrivate(hash common.Hash) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	_, ok := p.private[hash]
+	return ok
+}
+
 func (p *dummyTxPool) Nonce(addr common.Address) uint64 {
 	return 0
 }
@@ -53,12 +74,55 @@ func (p *dummyTxPool) Stats() (int, int) {
 	return p.Count(), 0
 }
 
+// Content returns all the transactions known to the pool as pending
 func (p *dummyTxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
-	return nil, nil
+	pending, _ := p.Pending(false)
+	return pending, make(map[common.Address]types.Transactions)
 }
 
 func (p *dummyTxPool) ContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
-	return nil, nil
+	pending, _ := p.Pending(false)
+	return pending[addr], nil
+}
+
+func (p *dummyTxPool) RemoveTx(hash common.Hash) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for i, tx := range p.pool {
+		if tx.Hash() == hash {
+			p.pool = append(p.pool[:i], p.pool[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *dummyTxPool) RemoveSender(addr common.Address) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	kept := p.pool[:0]
+	for _, tx := range p.pool {
+		if from, _ := types.Sender(p.signer, tx); from != addr {
+			kept = append(kept, tx)
+		}
+	}
+	removed := len(p.pool) - len(kept)
+	p.pool = kept
+	return removed
+}
+
+func (p *dummyTxPool) QueuedReasons() map[common.Address]map[uint64]error {
+	return nil
+}
+
+func (p *dummyTxPool) Denylist() evmcore.TxDenylist {
+	return evmcore.TxDenylist{}
+}
+
+func (p *dummyTxPool) UpdateDenylist(add, remove evmcore.TxDenylist) (int, error) {
+	return 0, nil
 }
 
 // Pending returns all the transactions known to the pool
@@ -23,6 +23,9 @@ type EmitIntervals struct {
 type ValidatorConfig struct {
 	ID     idx.ValidatorID
 	PubKey validatorpk.PubKey
+	// PubKeys are all the keys of the validator, if it rotates its key.
+	// The emitter signs with the one which is registered for the validator in the current epoch.
+	PubKeys []validatorpk.PubKey `toml:",omitempty"`
 }
 
 type FileConfig struct {
@@ -49,6 +52,11 @@ type Config struct {
 
 	TxsCacheInvalidation time.Duration
 
+	// SlashingProtectionPath is the directory of the slashing-protection DB,
+	// which records every signed event and LLR vote to avoid doublesigning after a crash
+	SlashingProtectionPath string
+
+	// Deprecated: prev action files are read only to migrate them into the slashing-protection DB
 	PrevEmittedEventFile FileConfig
 	PrevBlockVotesFile   FileConfig
 	PrevEpochVoteFile    FileConfig
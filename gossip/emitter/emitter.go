@@ -6,7 +6,6 @@ import (
 	"github.com/Fantom-foundation/go-opera/utils/txtime"
 	"github.com/ethereum/go-ethereum/metrics"
 	"math/rand"
-	"os"
 	"strings"
 	"sync"
 	"time"
@@ -19,11 +18,13 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/Fantom-foundation/go-opera/gossip/emitter/originatedtxs"
+	"github.com/Fantom-foundation/go-opera/gossip/sonicmetrics"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/logger"
 	"github.com/Fantom-foundation/go-opera/tracing"
 	"github.com/Fantom-foundation/go-opera/utils/errlock"
 	"github.com/Fantom-foundation/go-opera/utils/rate"
+	"github.com/Fantom-foundation/go-opera/valkeystore/slashing"
 )
 
 const (
@@ -35,6 +36,7 @@ var (
 	emittedEventsCounter        = metrics.GetOrRegisterCounter("emitter/events", nil)                    // amount of emitted events
 	emittedEventsTxsCounter     = metrics.GetOrRegisterCounter("emitter/txs", nil)                       // amount of txs in emitted events
 	emittedGasCounter           = metrics.GetOrRegisterCounter("emitter/gas", nil)                       // consumed validator gas
+	emittedMPsCounter           = metrics.GetOrRegisterCounter("emitter/mps", nil)                       // amount of misbehaviour proofs in emitted events
 	txsSkippedNoValidatorGas    = metrics.GetOrRegisterCounter("emitter/skipped/novalidatorgas", nil)    // validator does not have enough gas
 	txsSkippedEpochRules        = metrics.GetOrRegisterCounter("emitter/skipped/epochrules", nil)        // tx skipped because of epoch rules (like insufficient gasPrice)
 	txsSkippedConflictingSender = metrics.GetOrRegisterCounter("emitter/skipped/conflictingsender", nil) // tx by given sender in some unconfirmed event
@@ -60,6 +62,8 @@ type Emitter struct {
 	prevEmittedAtBlock idx.Block
 	originatedTxs      *originatedtxs.Buffer
 	pendingGas         uint64
+	// carriedMPs are the misbehaviour proofs of the connected events of the epoch, which aren't submitted again
+	carriedMPs map[hash.Hash]struct{}
 
 	// note: track validators and epoch internally to avoid referring to
 	// validators of a future epoch inside OnEventConnected of last epoch event
@@ -95,10 +99,12 @@ type Emitter struct {
 		poolCount int
 	}
 
-	emittedEventFile *os.File
-	emittedBvsFile   *os.File
-	emittedEvFile    *os.File
-	busyRate         *rate.Gauge
+	slashing   *slashing.Store
+	legacyPrev legacyPrevActions
+	busyRate   *rate.Gauge
+
+	// unpublished is the recorded event, which is retried until it's published
+	unpublished *inter.MutableEventPayload
 
 	logger.Periodic
 }
@@ -131,15 +137,7 @@ func (em *Emitter) init() {
 	validators, epoch := em.world.GetEpochValidators()
 	em.OnNewEpoch(validators, epoch)
 
-	if len(em.config.PrevEmittedEventFile.Path) != 0 {
-		em.emittedEventFile = openPrevActionFile(em.config.PrevEmittedEventFile.Path, em.config.PrevEmittedEventFile.SyncMode)
-	}
-	if len(em.config.PrevBlockVotesFile.Path) != 0 {
-		em.emittedBvsFile = openPrevActionFile(em.config.PrevBlockVotesFile.Path, em.config.PrevBlockVotesFile.SyncMode)
-	}
-	if len(em.config.PrevEpochVoteFile.Path) != 0 {
-		em.emittedEvFile = openPrevActionFile(em.config.PrevEpochVoteFile.Path, em.config.PrevEpochVoteFile.SyncMode)
-	}
+	em.openSlashingProtection()
 	em.busyRate = rate.NewGauge()
 }
 
@@ -185,6 +183,7 @@ func (em *Emitter) Stop() {
 	em.done = nil
 	em.wg.Wait()
 	em.busyRate.Stop()
+	em.closeSlashingProtection()
 }
 
 func (em *Emitter) tick() {
@@ -270,21 +269,18 @@ func (em *Emitter) EmitEvent() (*inter.EventPayload, error) {
 		em.Log.Error("Self-event connection failed", "err", err.Error())
 		return nil, err
 	}
-	// write event ID to avoid doublesigning in future after a crash
-	em.writeLastEmittedEventID(e.ID())
-	if e.EpochVote().Epoch != 0 {
-		em.writeLastEmittedEpochVote(e.EpochVote().Epoch)
-	}
-	if len(e.BlockVotes().Votes) != 0 {
-		em.writeLastEmittedBlockVotes(e.BlockVotes().LastBlock())
-	}
+	em.unpublished = nil
 	// broadcast the event
 	em.world.Broadcast(e)
 
 	// metrics
 	emittedEventsTxsCounter.Inc(int64(e.Txs().Len()))
 	emittedGasCounter.Inc(int64(e.GasPowerUsed()))
+	emittedMPsCounter.Inc(int64(len(e.MisbehaviourProofs())))
 	emittedEventsCounter.Inc(1)
+	validator := sonicmetrics.ValidatorLabel(em.config.Validator.ID)
+	sonicmetrics.EmitterGasPowerLeft.WithLabelValues(validator, "short").Set(float64(e.GasPowerLeft().Gas[inter.ShortTermGas]))
+	sonicmetrics.EmitterGasPowerLeft.WithLabelValues(validator, "long").Set(float64(e.GasPowerLeft().Gas[inter.LongTermGas]))
 
 	em.prevEmittedAtTime = time.Now() // record time after connecting, to add the event processing time
 	em.prevEmittedAtBlock = em.world.GetLatestBlockIndex()
@@ -323,6 +319,11 @@ func (em *Emitter) createEvent(sortedTxs *types.TransactionsByPriceAndNonce) (*i
 		return nil, nil
 	}
 
+	if mutEvent, parentHeaders := em.unpublishedEvent(); mutEvent != nil {
+		// the event is already recorded as signed, so no other event may be emitted in its place
+		return em.signEvent(mutEvent, parentHeaders)
+	}
+
 	var (
 		selfParentSeq  idx.Event
 		selfParentTime inter.Timestamp
@@ -394,6 +395,9 @@ func (em *Emitter) createEvent(sortedTxs *types.TransactionsByPriceAndNonce) (*i
 		}
 	}
 
+	// add misbehaviour proofs
+	em.addMisbehaviourProofs(mutEvent, selfParentHeader)
+
 	// set consensus fields
 	var metric ancestor.Metric
 	err := em.world.Build(mutEvent, func() {
@@ -444,8 +448,42 @@ func (em *Emitter) createEvent(sortedTxs *types.TransactionsByPriceAndNonce) (*i
 	// calc Payload hash
 	mutEvent.SetPayloadHash(inter.CalcPayloadHash(mutEvent))
 
-	// sign
-	bSig, err := em.world.Signer.Sign(em.config.Validator.PubKey, mutEvent.HashToSign().Bytes())
+	// record the event before signing it, so that a conflicting event is never signed even after a crash
+	if err := em.recordEvent(mutEvent.Build()); err != nil {
+		em.Periodic.Error(time.Second, "Refused to sign event", "err", err)
+		return nil, err
+	}
+	em.unpublished = mutEvent
+	return em.signEvent(mutEvent, parentHeaders)
+}
+
+// unpublishedEvent returns the recorded event which isn't published yet, along with its parents,
+// unless it's of the previous epoch or it's already connected.
+func (em *Emitter) unpublishedEvent() (*inter.MutableEventPayload, inter.Events) {
+	mutEvent := em.unpublished
+	if mutEvent == nil {
+		return nil, nil
+	}
+	if mutEvent.Epoch() != em.epoch || em.world.GetEvent(mutEvent.ID()) != nil {
+		em.unpublished = nil
+		return nil, nil
+	}
+	parentHeaders := make(inter.Events, len(mutEvent.Parents()))
+	for i, p := range mutEvent.Parents() {
+		parent := em.world.GetEvent(p)
+		if parent == nil {
+			em.Log.Crit("Emitter: head not found", "mutEvent", p.String())
+		}
+		parentHeaders[i] = parent
+	}
+	return mutEvent, parentHeaders
+}
+
+// signEvent signs and checks the recorded event.
+// The records are never reverted, so the same event is retried until it's published,
+// unless it fails the checks.
+func (em *Emitter) signEvent(mutEvent *inter.MutableEventPayload, parentHeaders inter.Events) (*inter.EventPayload, error) {
+	bSig, err := em.sign(mutEvent.Build())
 	if err != nil {
 		em.Periodic.Error(time.Second, "Failed to sign event", "err", err)
 		return nil, err
@@ -460,6 +498,12 @@ func (em *Emitter) createEvent(sortedTxs *types.TransactionsByPriceAndNonce) (*i
 	// check
 	if err := em.world.Check(event, parentHeaders); err != nil {
 		em.Periodic.Error(time.Second, "Emitted incorrect event", "err", err)
+		// drop the proofs, as any of them may be the reason
+		for _, mp := range event.MisbehaviourProofs() {
+			em.world.DelMisbehaviourProof(mp)
+		}
+		// the event never passes the checks, so it's dropped, but its record is kept
+		em.unpublished = nil
 		return nil, err
 	}
 
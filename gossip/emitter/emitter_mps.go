@@ -0,0 +1,49 @@
+package emitter
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+
+	"github.com/Fantom-foundation/go-opera/eventcheck/epochcheck"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+const (
+	// MaxMisbehaviourProofsPerEvent limits the size of an event with misbehaviour proofs
+	MaxMisbehaviourProofsPerEvent = 8
+)
+
+func misbehaviourProofHash(mp inter.MisbehaviourProof) hash.Hash {
+	return inter.CalcMisbehaviourProofsHash([]inter.MisbehaviourProof{mp})
+}
+
+// addMisbehaviourProofs attaches the pending misbehaviour proofs, as many as the gas power allows.
+// The gas power left of the self-parent is a lower bound of the available gas power.
+// The proofs carried by the connected events of the epoch are skipped, as they may get confirmed yet.
+func (em *Emitter) addMisbehaviourProofs(e *inter.MutableEventPayload, selfParent *inter.Event) {
+	if e.Version() == 0 || selfParent == nil {
+		return
+	}
+	rules := em.world.GetRules()
+	gasLeft := selfParent.GasPowerLeft().Min()
+	gasUsed := epochcheck.CalcGasPowerUsed(e, rules)
+	if gasUsed >= gasLeft {
+		return
+	}
+	limit := uint64(MaxMisbehaviourProofsPerEvent)
+	if mpGas := rules.Economy.Gas.MisbehaviourProofGas; mpGas != 0 && (gasLeft-gasUsed)/mpGas < limit {
+		limit = (gasLeft - gasUsed) / mpGas
+	}
+	if limit == 0 {
+		return
+	}
+	pending := em.world.GetPendingMisbehaviourProofs(int(limit) + len(em.carriedMPs))
+	mps := make([]inter.MisbehaviourProof, 0, len(pending))
+	for _, mp := range pending {
+		if _, ok := em.carriedMPs[misbehaviourProofHash(mp)]; !ok && uint64(len(mps)) < limit {
+			mps = append(mps, mp)
+		}
+	}
+	if len(mps) != 0 {
+		e.SetMisbehaviourProofs(mps)
+	}
+}
@@ -1,6 +1,7 @@
 package emitter
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/emitter/mock"
 	"github.com/Fantom-foundation/go-opera/integration/makefakegenesis"
 	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/opera"
 	"github.com/Fantom-foundation/go-opera/utils/txtime"
 	"github.com/Fantom-foundation/go-opera/vecmt"
@@ -115,4 +117,138 @@ func TestEmitter(t *testing.T) {
 	t.Run("tick", func(t *testing.T) {
 		em.tick()
 	})
+
+	t.Run("retry unpublished event", func(t *testing.T) {
+		require := require.New(t)
+		em.intervals.DoublesignProtection = 0
+
+		me := &inter.MutableEventPayload{}
+		me.SetVersion(1)
+		me.SetEpoch(1)
+		me.SetCreator(cfg.Validator.ID)
+		me.SetSeq(1)
+		me.SetLamport(1)
+		me.SetPayloadHash(inter.CalcPayloadHash(me))
+		em.unpublished = me
+
+		external.EXPECT().GetEvent(gomock.Any()).
+			Return((*inter.Event)(nil)).
+			AnyTimes()
+		signer.EXPECT().Sign(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("signer unavailable"))
+		_, err := em.createEvent(nil)
+		require.Error(err)
+
+		// the same event is retried, rather than a new one
+		signer.EXPECT().Sign(gomock.Any(), gomock.Any()).
+			Return(make([]byte, 64), nil)
+		external.EXPECT().Check(gomock.Any(), gomock.Any()).
+			Return(nil)
+		e, err := em.createEvent(nil)
+		require.NoError(err)
+		require.Equal(me.Build().HashToSign(), e.HashToSign())
+
+		// the event which fails the checks isn't retried
+		signer.EXPECT().Sign(gomock.Any(), gomock.Any()).
+			Return(make([]byte, 64), nil)
+		external.EXPECT().Check(gomock.Any(), gomock.Any()).
+			Return(errors.New("check failed"))
+		_, err = em.createEvent(nil)
+		require.Error(err)
+		require.Nil(em.unpublished)
+		em.unpublished = me
+
+		// the event of the previous epoch isn't retried
+		em.epoch++
+		defer func() { em.epoch-- }()
+		ue, _ := em.unpublishedEvent()
+		require.Nil(ue)
+		require.Nil(em.unpublished)
+	})
+}
+
+func TestEmitter_SelectPubKey(t *testing.T) {
+	require := require.New(t)
+	gValidators := makefakegenesis.GetFakeValidators(3)
+	oldKey, newKey, otherKey := gValidators[0].PubKey, gValidators[1].PubKey, gValidators[2].PubKey
+
+	cfg := DefaultConfig()
+	cfg.Validator.ID = gValidators[0].ID
+	cfg.Validator.PubKey = oldKey
+	cfg.Validator.PubKeys = []validatorpk.PubKey{oldKey, newKey}
+
+	ctrl := gomock.NewController(t)
+	external := mock.NewMockExternal(ctrl)
+	em := NewEmitter(cfg, World{External: external})
+
+	registered := oldKey
+	external.EXPECT().GetEpochPubKeys().
+		DoAndReturn(func() (map[idx.ValidatorID]validatorpk.PubKey, idx.Epoch) {
+			return map[idx.ValidatorID]validatorpk.PubKey{cfg.Validator.ID: registered}, 2
+		}).
+		AnyTimes()
+
+	em.selectPubKey()
+	require.Equal(oldKey, em.config.Validator.PubKey)
+
+	// rotation takes effect
+	registered = newKey
+	em.selectPubKey()
+	require.Equal(newKey, em.config.Validator.PubKey)
+
+	// unknown key isn't switched to
+	registered = otherKey
+	em.selectPubKey()
+	require.Equal(newKey, em.config.Validator.PubKey)
+
+	// key which fails to load isn't switched to
+	var loaded []validatorpk.PubKey
+	em.world.LoadKey = func(pubkey validatorpk.PubKey) error {
+		return errors.New("key not found")
+	}
+	em.selectPubKey()
+	require.Equal(newKey, em.config.Validator.PubKey)
+
+	// unknown key is loaded at runtime
+	em.world.LoadKey = func(pubkey validatorpk.PubKey) error {
+		loaded = append(loaded, pubkey)
+		return nil
+	}
+	em.selectPubKey()
+	require.Equal([]validatorpk.PubKey{otherKey}, loaded)
+	require.Equal(otherKey, em.config.Validator.PubKey)
+
+	// loaded key is configured
+	registered = newKey
+	em.selectPubKey()
+	registered = otherKey
+	em.selectPubKey()
+	require.Equal(otherKey, em.config.Validator.PubKey)
+	require.Len(loaded, 1)
+}
+
+func TestEmitter_SelectPubKey_NotConfigured(t *testing.T) {
+	require := require.New(t)
+	gValidators := makefakegenesis.GetFakeValidators(2)
+	oldKey, newKey := gValidators[0].PubKey, gValidators[1].PubKey
+
+	cfg := DefaultConfig()
+	cfg.Validator.ID = gValidators[0].ID
+	cfg.Validator.PubKey = oldKey
+
+	ctrl := gomock.NewController(t)
+	external := mock.NewMockExternal(ctrl)
+	em := NewEmitter(cfg, World{
+		External: external,
+		LoadKey: func(pubkey validatorpk.PubKey) error {
+			return nil
+		},
+	})
+	external.EXPECT().GetEpochPubKeys().
+		Return(map[idx.ValidatorID]validatorpk.PubKey{cfg.Validator.ID: newKey}, idx.Epoch(2)).
+		AnyTimes()
+
+	em.selectPubKey()
+	require.Equal(newKey, em.config.Validator.PubKey)
+	require.Equal([]validatorpk.PubKey{oldKey, newKey}, em.config.Validator.PubKeys)
 }
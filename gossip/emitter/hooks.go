@@ -5,12 +5,15 @@ import (
 	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/emitter/ancestor"
+	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/inter/pos"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 
+	"github.com/Fantom-foundation/go-opera/gossip/sonicmetrics"
 	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/opera/contracts/emitterdriver"
 	"github.com/Fantom-foundation/go-opera/utils"
 	"github.com/Fantom-foundation/go-opera/utils/adapters/vecmt2dagidx"
@@ -35,10 +38,13 @@ func (em *Emitter) OnNewEpoch(newValidators *pos.Validators, newEpoch idx.Epoch)
 	if !em.isValidator() {
 		return
 	}
+	em.selectPubKey()
 	em.prevEmittedAtTime = em.loadPrevEmitTime()
 
 	em.originatedTxs.Clear()
 	em.pendingGas = 0
+	// the proofs of the events, which didn't get confirmed in the sealed epoch, are submitted again
+	em.carriedMPs = make(map[hash.Hash]struct{})
 
 	em.offlineValidators = make(map[idx.ValidatorID]bool)
 	em.challenges = make(map[idx.ValidatorID]time.Time)
@@ -103,6 +109,9 @@ func (em *Emitter) OnEventConnected(e inter.EventPayloadI) {
 		em.originatedTxs.Inc(addr)
 	}
 	em.pendingGas += e.GasPowerUsed()
+	for _, mp := range e.MisbehaviourProofs() {
+		em.carriedMPs[misbehaviourProofHash(mp)] = struct{}{}
+	}
 	if e.Creator() == em.config.Validator.ID && em.syncStatus.prevLocalEmittedID != e.ID() {
 		// event was emitted by me on another instance
 		em.onNewExternalEvent(e)
@@ -140,6 +149,7 @@ func (em *Emitter) OnEventConfirmed(he inter.EventI) {
 	// record event's time-to-confirm
 	if he.Creator() == em.config.Validator.ID {
 		eventTimeToConfirmTimer.Update(time.Since(he.CreationTime().Time()))
+		sonicmetrics.EventTimeToConfirm.WithLabelValues(sonicmetrics.ValidatorLabel(he.Creator())).Observe(time.Since(he.CreationTime().Time()).Seconds())
 	}
 }
 
@@ -156,3 +166,38 @@ func maxDuration(a, b time.Duration) time.Duration {
 	}
 	return b
 }
+
+// selectPubKey switches to the key which is registered for the validator in the current epoch,
+// so that a rotated key is used starting from the epoch where the rotation takes effect.
+// A key which isn't configured is loaded by the signer, e.g. if it's created after the node start.
+func (em *Emitter) selectPubKey() {
+	if len(em.config.Validator.PubKeys) == 0 && em.world.LoadKey == nil {
+		return
+	}
+	pubkeys, epoch := em.world.GetEpochPubKeys()
+	registered, ok := pubkeys[em.config.Validator.ID]
+	if !ok || registered.Equal(em.config.Validator.PubKey) {
+		return
+	}
+	for _, pubkey := range em.config.Validator.PubKeys {
+		if pubkey.Equal(registered) {
+			em.Log.Info("Switched validator key", "epoch", epoch, "old", em.config.Validator.PubKey.String(), "new", pubkey.String())
+			em.config.Validator.PubKey = pubkey
+			return
+		}
+	}
+	if em.world.LoadKey == nil {
+		em.Log.Warn("Validator key of the epoch is not configured", "epoch", epoch, "pubkey", registered.String())
+		return
+	}
+	if err := em.world.LoadKey(registered); err != nil {
+		em.Log.Warn("Failed to load validator key of the epoch", "epoch", epoch, "pubkey", registered.String(), "err", err)
+		return
+	}
+	em.Log.Info("Switched validator key", "epoch", epoch, "old", em.config.Validator.PubKey.String(), "new", registered.String())
+	if len(em.config.Validator.PubKeys) == 0 {
+		em.config.Validator.PubKeys = []validatorpk.PubKey{em.config.Validator.PubKey}
+	}
+	em.config.Validator.PubKeys = append(em.config.Validator.PubKeys, registered)
+	em.config.Validator.PubKey = registered
+}
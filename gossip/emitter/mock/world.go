@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	inter "github.com/Fantom-foundation/go-opera/inter"
+	state "github.com/Fantom-foundation/go-opera/inter/state"
 	validatorpk "github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	opera "github.com/Fantom-foundation/go-opera/opera"
 	vecmt "github.com/Fantom-foundation/go-opera/vecmt"
@@ -18,7 +19,6 @@ import (
 	common "github.com/ethereum/go-ethereum/common"
 	types "github.com/ethereum/go-ethereum/core/types"
 	gomock "github.com/golang/mock/gomock"
-	"github.com/Fantom-foundation/go-opera/inter/state"
 )
 
 // MockExternal is a mock of External interface.
@@ -98,6 +98,18 @@ func (mr *MockExternalMockRecorder) DagIndex() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagIndex", reflect.TypeOf((*MockExternal)(nil).DagIndex))
 }
 
+// DelMisbehaviourProof mocks base method.
+func (m *MockExternal) DelMisbehaviourProof(arg0 inter.MisbehaviourProof) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DelMisbehaviourProof", arg0)
+}
+
+// DelMisbehaviourProof indicates an expected call of DelMisbehaviourProof.
+func (mr *MockExternalMockRecorder) DelMisbehaviourProof(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelMisbehaviourProof", reflect.TypeOf((*MockExternal)(nil).DelMisbehaviourProof), arg0)
+}
+
 // GetBlockEpoch mocks base method.
 func (m *MockExternal) GetBlockEpoch(arg0 idx.Block) idx.Epoch {
 	m.ctrl.T.Helper()
@@ -126,6 +138,21 @@ func (mr *MockExternalMockRecorder) GetBlockRecordHash(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockRecordHash", reflect.TypeOf((*MockExternal)(nil).GetBlockRecordHash), arg0)
 }
 
+// GetEpochPubKeys mocks base method.
+func (m *MockExternal) GetEpochPubKeys() (map[idx.ValidatorID]validatorpk.PubKey, idx.Epoch) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEpochPubKeys")
+	ret0, _ := ret[0].(map[idx.ValidatorID]validatorpk.PubKey)
+	ret1, _ := ret[1].(idx.Epoch)
+	return ret0, ret1
+}
+
+// GetEpochPubKeys indicates an expected call of GetEpochPubKeys.
+func (mr *MockExternalMockRecorder) GetEpochPubKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEpochPubKeys", reflect.TypeOf((*MockExternal)(nil).GetEpochPubKeys))
+}
+
 // GetEpochRecordHash mocks base method.
 func (m *MockExternal) GetEpochRecordHash(arg0 idx.Epoch) *hash.Hash {
 	m.ctrl.T.Helper()
@@ -295,6 +322,20 @@ func (mr *MockExternalMockRecorder) GetLowestEpochToDecide() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLowestEpochToDecide", reflect.TypeOf((*MockExternal)(nil).GetLowestEpochToDecide))
 }
 
+// GetPendingMisbehaviourProofs mocks base method.
+func (m *MockExternal) GetPendingMisbehaviourProofs(arg0 int) []inter.MisbehaviourProof {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingMisbehaviourProofs", arg0)
+	ret0, _ := ret[0].([]inter.MisbehaviourProof)
+	return ret0
+}
+
+// GetPendingMisbehaviourProofs indicates an expected call of GetPendingMisbehaviourProofs.
+func (mr *MockExternalMockRecorder) GetPendingMisbehaviourProofs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingMisbehaviourProofs", reflect.TypeOf((*MockExternal)(nil).GetPendingMisbehaviourProofs), arg0)
+}
+
 // GetRules mocks base method.
 func (m *MockExternal) GetRules() opera.Rules {
 	m.ctrl.T.Helper()
@@ -454,6 +495,20 @@ func (mr *MockTxPoolMockRecorder) Has(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Has", reflect.TypeOf((*MockTxPool)(nil).Has), arg0)
 }
 
+// IsPrivate mocks base method.
+func (m *MockTxPool) IsPrivate(arg0 common.Hash) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPrivate", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsPrivate indicates an expected call of IsPrivate.
+func (mr *MockTxPoolMockRecorder) IsPrivate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPrivate", reflect.TypeOf((*MockTxPool)(nil).IsPrivate), arg0)
+}
+
 // Pending mocks base method.
 func (m *MockTxPool) Pending(arg0 bool) (map[common.Address]types.Transactions, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,157 @@
+package emitter
+
+import (
+	"os"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/valkeystore"
+	"github.com/Fantom-foundation/go-opera/valkeystore/slashing"
+)
+
+// legacyPrevActions are the last actions read from the deprecated "prev action" files.
+type legacyPrevActions struct {
+	event      *hash.Event
+	blockVotes *idx.Block
+	epochVote  *idx.Epoch
+}
+
+func readPrevActionFile(cfg FileConfig, size int) []byte {
+	if len(cfg.Path) == 0 {
+		return nil
+	}
+	b, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Crit("Failed to read prev action file", "file", cfg.Path, "err", err)
+		}
+		return nil
+	}
+	if len(b) < size {
+		return nil
+	}
+	return b[:size]
+}
+
+// openSlashingProtection opens the slashing-protection DB and migrates the deprecated "prev action" files into it.
+func (em *Emitter) openSlashingProtection() {
+	if len(em.config.SlashingProtectionPath) == 0 {
+		return
+	}
+	store, err := slashing.Open(em.config.SlashingProtectionPath)
+	if err != nil {
+		log.Crit("Failed to open slashing-protection DB", "err", err)
+	}
+	em.slashing = store
+
+	em.legacyPrev = legacyPrevActions{}
+	if b := readPrevActionFile(em.config.PrevEmittedEventFile, 32); b != nil {
+		id := hash.BytesToEvent(b)
+		em.legacyPrev.event = &id
+	}
+	if b := readPrevActionFile(em.config.PrevBlockVotesFile, 8); b != nil {
+		v := idx.BytesToBlock(b)
+		em.legacyPrev.blockVotes = &v
+	}
+	if b := readPrevActionFile(em.config.PrevEpochVoteFile, 4); b != nil {
+		v := idx.BytesToEpoch(b)
+		em.legacyPrev.epochVote = &v
+	}
+	if em.legacyPrev.event == nil || em.lastRecordedEvent() != nil {
+		return
+	}
+	// the legacy file contains only the event ID, so take the rest from the local DB if the event is known
+	if e := em.world.GetEvent(*em.legacyPrev.event); e != nil {
+		err := store.Import([]slashing.EventRecord{{
+			Validator: e.Creator(),
+			Epoch:     e.Epoch(),
+			Seq:       e.Seq(),
+			Lamport:   e.Lamport(),
+			ID:        e.ID(),
+		}}, nil, nil)
+		if err != nil {
+			log.Crit("Failed to migrate prev emitted event", "err", err)
+		}
+		log.Info("Migrated prev emitted event into slashing-protection DB", "id", e.ID())
+		em.legacyPrev.event = nil
+	}
+}
+
+func (em *Emitter) closeSlashingProtection() {
+	if em.slashing == nil {
+		return
+	}
+	if err := em.slashing.Close(); err != nil {
+		log.Error("Failed to close slashing-protection DB", "err", err)
+	}
+	em.slashing = nil
+}
+
+func (em *Emitter) lastRecordedEvent() *hash.Event {
+	if em.slashing == nil {
+		return nil
+	}
+	id, err := em.slashing.LastEvent(em.config.Validator.ID)
+	if err != nil {
+		log.Crit("Failed to read slashing-protection DB", "err", err)
+	}
+	return id
+}
+
+// readLastEmittedEventID returns the last event signed by this validator.
+func (em *Emitter) readLastEmittedEventID() *hash.Event {
+	if id := em.lastRecordedEvent(); id != nil {
+		return id
+	}
+	return em.legacyPrev.event
+}
+
+// readLastBlockVotes returns the last block voted by this validator.
+func (em *Emitter) readLastBlockVotes() *idx.Block {
+	last := em.legacyPrev.blockVotes
+	if em.slashing != nil {
+		b, err := em.slashing.LastBlockVote(em.config.Validator.ID)
+		if err != nil {
+			log.Crit("Failed to read slashing-protection DB", "err", err)
+		}
+		if b != nil && (last == nil || *b > *last) {
+			last = b
+		}
+	}
+	return last
+}
+
+// readLastEpochVote returns the last epoch voted by this validator.
+func (em *Emitter) readLastEpochVote() *idx.Epoch {
+	last := em.legacyPrev.epochVote
+	if em.slashing != nil {
+		e, err := em.slashing.LastEpochVote(em.config.Validator.ID)
+		if err != nil {
+			log.Crit("Failed to read slashing-protection DB", "err", err)
+		}
+		if e != nil && (last == nil || *e > *last) {
+			last = e
+		}
+	}
+	return last
+}
+
+// recordEvent checks the unsigned event against the slashing-protection DB and records it before it gets signed.
+// The records are never reverted, even if the event doesn't get published.
+func (em *Emitter) recordEvent(e *inter.EventPayload) error {
+	if em.slashing == nil {
+		return nil
+	}
+	return em.slashing.CheckAndRecordEvent(e)
+}
+
+// sign signs the event, passing the whole event to signers which apply their own slashing protection.
+func (em *Emitter) sign(e *inter.EventPayload) ([]byte, error) {
+	if signer, ok := em.world.Signer.(valkeystore.EventSignerI); ok {
+		return signer.SignEvent(em.config.Validator.PubKey, e)
+	}
+	return em.world.Signer.Sign(em.config.Validator.PubKey, e.HashToSign().Bytes())
+}
@@ -175,7 +175,8 @@ func (em *Emitter) addTxs(e *inter.MutableEventPayload, sorted *types.Transactio
 			continue
 		}
 		// my turn, i.e. try to not include the same tx simultaneously by different validators
-		if !em.isMyTxTurn(tx.Hash(), sender, tx.Nonce(), time.Now(), em.validators, e.Creator(), em.epoch) {
+		// private txs are known only to this node, so they are always originated by it
+		if !em.world.TxPool.IsPrivate(tx.Hash()) && !em.isMyTxTurn(tx.Hash(), sender, tx.Nonce(), time.Now(), em.validators, e.Creator(), em.epoch) {
 			txsSkippedNotMyTurn.Inc(1)
 			sorted.Pop()
 			continue
@@ -12,6 +12,7 @@ import (
 
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/opera"
 	"github.com/Fantom-foundation/go-opera/valkeystore"
 	"github.com/Fantom-foundation/go-opera/vecmt"
@@ -26,6 +27,7 @@ type (
 	External interface {
 		sync.Locker
 		Reader
+		MisbehaviourProofs
 
 		Check(e *inter.EventPayload, parents inter.Events) error
 		Process(*inter.EventPayload) error
@@ -50,6 +52,9 @@ type (
 		TxPool   TxPool
 		Signer   valkeystore.SignerI
 		TxSigner types.Signer
+		// LoadKey makes the Signer able to sign with a validator key, which isn't configured.
+		// It is nil if the keys can't be loaded at runtime.
+		LoadKey func(pubkey validatorpk.PubKey) error
 	}
 )
 
@@ -64,11 +69,18 @@ type LlrReader interface {
 	GetEpochRecordHash(epoch idx.Epoch) *hash.Hash
 }
 
+// MisbehaviourProofs is a pool of the detected misbehaviour proofs, which aren't submitted yet.
+type MisbehaviourProofs interface {
+	GetPendingMisbehaviourProofs(limit int) []inter.MisbehaviourProof
+	DelMisbehaviourProof(inter.MisbehaviourProof)
+}
+
 // Reader is a callback for getting events from an external storage.
 type Reader interface {
 	LlrReader
 	GetLatestBlockIndex() idx.Block
 	GetEpochValidators() (*pos.Validators, idx.Epoch)
+	GetEpochPubKeys() (map[idx.ValidatorID]validatorpk.PubKey, idx.Epoch)
 	GetEvent(hash.Event) *inter.Event
 	GetEventPayload(hash.Event) *inter.EventPayload
 	GetLastEvent(epoch idx.Epoch, from idx.ValidatorID) *hash.Event
@@ -81,6 +93,9 @@ type TxPool interface {
 	// Has returns an indicator whether txpool has a transaction cached with the
 	// given hash.
 	Has(hash common.Hash) bool
+	// IsPrivate returns an indicator whether the transaction was submitted privately,
+	// so it's known only to this node.
+	IsPrivate(hash common.Hash) bool
 	// Pending should return pending transactions.
 	// The slice should be modifiable by the caller.
 	Pending(enforceTips bool) (map[common.Address]types.Transactions, error)
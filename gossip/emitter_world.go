@@ -10,6 +10,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/emitter"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/utils/wgmutex"
 	"github.com/Fantom-foundation/go-opera/valkeystore"
 	"github.com/Fantom-foundation/go-opera/vecmt"
@@ -77,6 +78,16 @@ func (ew *emitterWorldProc) PeersNum() int {
 	return ew.s.handler.peers.Len()
 }
 
+// GetEpochPubKeys returns the pubkeys of the current epoch validators.
+func (ew *emitterWorldRead) GetEpochPubKeys() (map[idx.ValidatorID]validatorpk.PubKey, idx.Epoch) {
+	es := ew.Store.GetEpochState()
+	pubkeys := make(map[idx.ValidatorID]validatorpk.PubKey, len(es.ValidatorProfiles))
+	for id, profile := range es.ValidatorProfiles {
+		pubkeys[id] = profile.PubKey
+	}
+	return pubkeys, es.Epoch
+}
+
 func (ew *emitterWorldRead) GetHeads(epoch idx.Epoch) hash.Events {
 	return ew.Store.GetHeadsSlice(epoch)
 }
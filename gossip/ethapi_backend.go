@@ -22,12 +22,15 @@ import (
 	"github.com/Fantom-foundation/go-opera/ethapi"
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/gossip/filters"
+	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 	"github.com/Fantom-foundation/go-opera/inter/state"
 	"github.com/Fantom-foundation/go-opera/opera"
 	"github.com/Fantom-foundation/go-opera/topicsdb"
 	"github.com/Fantom-foundation/go-opera/tracing"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 )
 
 // EthAPIBackend implements ethapi.Backend.
@@ -293,6 +296,31 @@ func (b *EthAPIBackend) GetReceiptsByNumber(ctx context.Context, number rpc.Bloc
 	return receipts, nil
 }
 
+// ArchiveBlockHeight returns the range of blocks with queryable archive state.
+func (b *EthAPIBackend) ArchiveBlockHeight(ctx context.Context) (idx.Block, idx.Block, error) {
+	latest, empty, err := b.svc.store.evm.GetArchiveBlockHeight()
+	if err != nil {
+		return 0, 0, err
+	}
+	if empty {
+		return 0, 0, errors.New("archive is empty")
+	}
+	return idx.Block(b.svc.store.evm.GetOldestArchiveBlock()), idx.Block(latest), nil
+}
+
+// GetBlockTraces returns stored transaction traces of the block.
+// The returned bool is false if the block traces are not stored.
+func (b *EthAPIBackend) GetBlockTraces(ctx context.Context, number idx.Block) ([]txtrace.ActionTrace, bool, error) {
+	traces, ok := b.svc.store.evm.GetTraces(number)
+	return traces, ok, nil
+}
+
+// FindTraceBlocks returns blocks of the range containing traces matching the addresses.
+// The returned bool is false if traces of some blocks in the range are not stored.
+func (b *EthAPIBackend) FindTraceBlocks(ctx context.Context, from, to idx.Block, fromAddresses, toAddresses []common.Address) ([]idx.Block, bool, error) {
+	return b.svc.store.evm.FindTraceBlocks(ctx, from, to, fromAddresses, toAddresses)
+}
+
 // GetReceipts retrieves the receipts for all transactions in a given block.
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, block common.Hash) (types.Receipts, error) {
 	number := b.svc.store.GetBlockIndex(hash.Event(block))
@@ -348,6 +376,14 @@ func (b *EthAPIBackend) SubscribeNewBlockNotify(ch chan<- evmcore.ChainHeadNotif
 	return b.svc.feed.SubscribeNewBlock(ch)
 }
 
+func (b *EthAPIBackend) SubscribeNewDagEventsNotify(ch chan<- *inter.EventPayload) notify.Subscription {
+	return b.svc.feed.SubscribeNewDagEvent(ch)
+}
+
+func (b *EthAPIBackend) SubscribeNewEpochNotify(ch chan<- filters.NewEpochNotify) notify.Subscription {
+	return b.svc.feed.SubscribeNewEpochState(ch)
+}
+
 func (b *EthAPIBackend) SubscribeNewTxsNotify(ch chan<- evmcore.NewTxsNotify) notify.Subscription {
 	return b.svc.txpool.SubscribeNewTxsNotify(ch)
 }
@@ -359,15 +395,40 @@ func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	}
 	var txs types.Transactions
 	for _, batch := range pending {
-		txs = append(txs, batch...)
+		txs = append(txs, b.withoutPrivateTxs(batch)...)
 	}
 	return txs, nil
 }
 
 func (b *EthAPIBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	if b.svc.txpool.IsPrivate(hash) {
+		return nil
+	}
 	return b.svc.txpool.Get(hash)
 }
 
+// withoutPrivateTxs filters out private transactions, which must not be revealed until they are included.
+func (b *EthAPIBackend) withoutPrivateTxs(txs types.Transactions) types.Transactions {
+	public := make(types.Transactions, 0, len(txs))
+	for _, tx := range txs {
+		if !b.svc.txpool.IsPrivate(tx.Hash()) {
+			public = append(public, tx)
+		}
+	}
+	return public
+}
+
+// withoutPrivateContent filters out private transactions of the pool content.
+func (b *EthAPIBackend) withoutPrivateContent(content map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	public := make(map[common.Address]types.Transactions, len(content))
+	for addr, txs := range content {
+		if txs = b.withoutPrivateTxs(txs); len(txs) != 0 {
+			public[addr] = txs
+		}
+	}
+	return public
+}
+
 func (b *EthAPIBackend) GetTxPosition(txHash common.Hash) *evmstore.TxPosition {
 	return b.svc.store.evm.GetTxPosition(txHash)
 }
@@ -401,6 +462,14 @@ func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash)
 	return tx, uint64(position.Block), uint64(position.BlockOffset), nil
 }
 
+func (b *EthAPIBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction) error {
+	err := b.svc.txpool.AddPrivate(signedTx)
+	if err == nil {
+		tracing.StartTx(signedTx.Hash(), "EthAPIBackend.SendPrivateTx()")
+	}
+	return err
+}
+
 func (b *EthAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.svc.txpool.Nonce(addr), nil
 }
@@ -410,7 +479,8 @@ func (b *EthAPIBackend) Stats() (pending int, queued int) {
 }
 
 func (b *EthAPIBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
-	return b.svc.txpool.Content()
+	pending, queued := b.svc.txpool.Content()
+	return b.withoutPrivateContent(pending), b.withoutPrivateContent(queued)
 }
 
 // Progress returns current synchronization status of this node
@@ -430,13 +500,38 @@ func (b *EthAPIBackend) Progress() ethapi.PeerProgress {
 }
 
 func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
-	return b.svc.txpool.ContentFrom(addr)
+	pending, queued := b.svc.txpool.ContentFrom(addr)
+	return b.withoutPrivateTxs(pending), b.withoutPrivateTxs(queued)
+}
+
+func (b *EthAPIBackend) TxPoolQueuedReasons() map[common.Address]map[uint64]error {
+	return b.svc.txpool.QueuedReasons()
+}
+
+func (b *EthAPIBackend) RemovePoolTransaction(txHash common.Hash) bool {
+	return b.svc.txpool.RemoveTx(txHash)
+}
+
+func (b *EthAPIBackend) RemovePoolSender(addr common.Address) int {
+	return b.svc.txpool.RemoveSender(addr)
+}
+
+func (b *EthAPIBackend) TxPoolDenylist() evmcore.TxDenylist {
+	return b.svc.txpool.Denylist()
+}
+
+func (b *EthAPIBackend) UpdateTxPoolDenylist(add, remove evmcore.TxDenylist) (int, error) {
+	return b.svc.txpool.UpdateDenylist(add, remove)
 }
 
 func (b *EthAPIBackend) SuggestGasTipCap(ctx context.Context, certainty uint64) *big.Int {
 	return b.svc.gpo.SuggestTip(certainty)
 }
 
+func (b *EthAPIBackend) GasPriceStats(ctx context.Context, limit uint64) []gasprice.Stat {
+	return b.svc.gpo.History(limit)
+}
+
 func (b *EthAPIBackend) EffectiveMinGasPrice(ctx context.Context) *big.Int {
 	return b.svc.gpo.EffectiveMinGasPrice()
 }
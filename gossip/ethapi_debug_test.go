@@ -0,0 +1,126 @@
+package gossip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestDebugTraceCallWithStateOverride(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+	_, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+	require.NoError(err)
+	waitForArchive(t, env)
+
+	api := ethapi.NewPublicDebugAPI(env.EthAPI)
+	ctx := context.Background()
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	var (
+		sender   = common.Address{0x11}
+		contract = common.Address{0x22}
+		// PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		code    = hexutil.Bytes{0x60, 0x00, 0x54, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3}
+		balance = (*hexutil.Big)(utils.ToFtm(10))
+		value   = (*hexutil.Big)(utils.ToFtm(5))
+		gas     = hexutil.Uint64(100000)
+		slot    = common.Hash{31: 0x42}
+	)
+	args := ethapi.TransactionArgs{
+		From:  &sender,
+		To:    &contract,
+		Gas:   &gas,
+		Value: value,
+	}
+
+	// the sender has no balance without the override
+	res, err := api.TraceCall(ctx, args, latest, nil)
+	require.NoError(err)
+	require.True(res.(*ethapi.ExecutionResult).Failed)
+
+	state := map[common.Hash]common.Hash{{}: slot}
+	overrides := ethapi.StateOverride{
+		sender:   {Balance: &balance},
+		contract: {Code: &code, State: &state},
+	}
+	res, err = api.TraceCall(ctx, args, latest, &ethapi.TraceCallConfig{StateOverrides: &overrides})
+	require.NoError(err)
+	result, ok := res.(*ethapi.ExecutionResult)
+	require.True(ok)
+	require.False(result.Failed)
+	require.Equal(common.Bytes2Hex(slot.Bytes()), result.ReturnValue)
+
+	// the overrides aren't persisted
+	stateDb, _, err := env.EthAPI.StateAndHeaderByNumberOrHash(ctx, latest)
+	require.NoError(err)
+	defer stateDb.Release()
+	require.Zero(stateDb.GetBalance(sender).Sign())
+	require.Empty(stateDb.GetCode(contract))
+}
+
+func TestDebugTraceBlockFromRlp(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+	receipts, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+	require.NoError(err)
+	waitForArchive(t, env)
+
+	api := ethapi.NewPublicDebugAPI(env.EthAPI)
+	ctx := context.Background()
+
+	// a block known to the node
+	known, err := env.EthAPI.BlockByNumber(ctx, rpc.BlockNumber(receipts[0].BlockNumber.Int64()))
+	require.NoError(err)
+	raw, err := rlp.EncodeToBytes(known.EthBlock())
+	require.NoError(err)
+	results, err := api.TraceBlock(ctx, raw, nil)
+	require.NoError(err)
+	require.Len(results, 1)
+	require.Equal(receipts[0].TxHash, results[0].TxHash)
+	require.Equal(receipts[0].GasUsed, results[0].Result.(*ethapi.ExecutionResult).Gas)
+
+	// a block which isn't known to the node, executed on top of the state of its parent
+	parent, err := env.EthAPI.BlockByNumber(ctx, rpc.LatestBlockNumber)
+	require.NoError(err)
+	header := *parent.Header()
+	header.Number = new(big.Int).Add(parent.Number, common.Big1)
+	header.ParentHash = parent.Hash
+	tx := env.Transfer(2, 3, utils.ToFtm(1))
+	block := evmcore.NewEvmBlock(&header, []*types.Transaction{tx})
+	raw, err = rlp.EncodeToBytes(block.EthBlock())
+	require.NoError(err)
+
+	results, err = api.TraceBlock(ctx, raw, nil)
+	require.NoError(err)
+	require.Len(results, 1)
+	require.Equal(tx.Hash(), results[0].TxHash)
+	result := results[0].Result.(*ethapi.ExecutionResult)
+	require.False(result.Failed)
+	require.Equal(gasLimit, result.Gas)
+
+	// the parent state must be available
+	header.ParentHash = common.Hash{1}
+	raw, err = rlp.EncodeToBytes(evmcore.NewEvmBlock(&header, []*types.Transaction{tx}).EthBlock())
+	require.NoError(err)
+	_, err = api.TraceBlock(ctx, raw, nil)
+	require.Error(err)
+}
@@ -0,0 +1,240 @@
+package gossip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+var (
+	// increments the slot 0 and returns its new value
+	simCounterCode = hexutil.Bytes{0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x80, 0x60, 0x00, 0x55, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3}
+	// returns the block number, timestamp, base fee and coinbase
+	simBlockInfoCode = hexutil.Bytes{0x43, 0x60, 0x00, 0x52, 0x42, 0x60, 0x20, 0x52, 0x48, 0x60, 0x40, 0x52, 0x41, 0x60, 0x60, 0x52, 0x60, 0x80, 0x60, 0x00, 0xf3}
+	// emits a log with the topic 0x77 and the data 0x2a
+	simLoggerCode = hexutil.Bytes{0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x77, 0x60, 0x20, 0x60, 0x00, 0xa1, 0x00}
+	// reverts with the data 0x2a
+	simReverterCode = hexutil.Bytes{0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xfd}
+)
+
+func simWord(v uint64) []byte {
+	return common.BigToHash(new(big.Int).SetUint64(v)).Bytes()
+}
+
+func TestSimulateV1(t *testing.T) {
+	logger.SetTestMode(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+	_, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+	require.NoError(t, err)
+	waitForArchive(t, env)
+
+	api := ethapi.NewPublicBlockChainAPI(env.EthAPI)
+	ctx := context.Background()
+	base, err := env.EthAPI.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	require.NoError(t, err)
+
+	var (
+		counter   = common.Address{0x01, 0x01}
+		blockInfo = common.Address{0x01, 0x02}
+		emitter   = common.Address{0x01, 0x03}
+		reverter  = common.Address{0x01, 0x04}
+	)
+	overrides := ethapi.StateOverride{
+		counter:   {Code: &simCounterCode},
+		blockInfo: {Code: &simBlockInfoCode},
+		emitter:   {Code: &simLoggerCode},
+		reverter:  {Code: &simReverterCode},
+	}
+	call := func(to common.Address) ethapi.TransactionArgs {
+		return ethapi.TransactionArgs{To: &to}
+	}
+
+	t.Run("chained state", func(t *testing.T) {
+		require := require.New(t)
+		res, err := api.SimulateV1(ctx, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{
+				{StateOverrides: &overrides, Calls: []ethapi.TransactionArgs{call(counter), call(counter)}},
+				{Calls: []ethapi.TransactionArgs{call(counter)}},
+			},
+		}, nil)
+		require.NoError(err)
+		require.Len(res, 2)
+		require.Len(res[0].Calls, 2)
+		require.Equal(simWord(1), []byte(res[0].Calls[0].ReturnValue))
+		require.Equal(simWord(2), []byte(res[0].Calls[1].ReturnValue))
+		require.Len(res[1].Calls, 1)
+		require.Equal(simWord(3), []byte(res[1].Calls[0].ReturnValue))
+
+		require.Equal(base.Number.Uint64()+1, uint64(res[0].Number))
+		require.Equal(base.Hash, res[0].ParentHash)
+		require.Equal(base.Number.Uint64()+2, uint64(res[1].Number))
+		require.Equal(res[0].Hash, res[1].ParentHash)
+		require.Greater(res[1].Timestamp, res[0].Timestamp)
+		require.Equal(res[0].Calls[0].GasUsed+res[0].Calls[1].GasUsed, res[0].GasUsed)
+
+		// the state isn't changed by the simulation
+		stateDb, _, err := env.EthAPI.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+		require.NoError(err)
+		defer stateDb.Release()
+		require.Empty(stateDb.GetCode(counter))
+	})
+
+	t.Run("block overrides", func(t *testing.T) {
+		require := require.New(t)
+		var (
+			number   = (*hexutil.Big)(new(big.Int).Add(base.Number, big.NewInt(10)))
+			time     = hexutil.Uint64(base.Time.Unix() + 100)
+			baseFee  = (*hexutil.Big)(big.NewInt(1e9))
+			coinbase = common.Address{0xcb}
+			balance  = (*hexutil.Big)(utils.ToFtm(1))
+			gasPrice = baseFee
+			sender   = common.Address{0x5e}
+		)
+		withSender := ethapi.StateOverride{
+			blockInfo: {Code: &simBlockInfoCode},
+			sender:    {Balance: &balance},
+		}
+		res, err := api.SimulateV1(ctx, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{{
+				BlockOverrides: &ethapi.BlockOverrides{
+					Number:        number,
+					Time:          &time,
+					BaseFeePerGas: baseFee,
+					FeeRecipient:  &coinbase,
+				},
+				StateOverrides: &withSender,
+				Calls:          []ethapi.TransactionArgs{{From: &sender, To: &blockInfo, GasPrice: gasPrice}},
+			}},
+			Validation: true,
+		}, nil)
+		require.NoError(err)
+		require.Len(res, 1)
+		require.Equal(number.ToInt().Uint64(), uint64(res[0].Number))
+		require.Equal(time, res[0].Timestamp)
+		require.Equal(baseFee.ToInt(), res[0].BaseFeePerGas.ToInt())
+		require.Equal(coinbase, res[0].FeeRecipient)
+
+		ret := []byte(res[0].Calls[0].ReturnValue)
+		require.Len(ret, 128)
+		require.Equal(simWord(number.ToInt().Uint64()), ret[0:32])
+		require.Equal(simWord(uint64(time)), ret[32:64])
+		require.Equal(simWord(baseFee.ToInt().Uint64()), ret[64:96])
+		require.Equal(common.BytesToHash(coinbase.Bytes()).Bytes(), ret[96:128])
+	})
+
+	t.Run("revert and logs", func(t *testing.T) {
+		require := require.New(t)
+		res, err := api.SimulateV1(ctx, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{
+				{StateOverrides: &overrides, Calls: []ethapi.TransactionArgs{call(emitter), call(reverter), call(counter)}},
+			},
+		}, nil)
+		require.NoError(err)
+		require.Len(res, 1)
+		calls := res[0].Calls
+		require.Len(calls, 3)
+
+		require.Equal(hexutil.Uint64(types.ReceiptStatusSuccessful), calls[0].Status)
+		require.Nil(calls[0].Error)
+		require.Len(calls[0].Logs, 1)
+		require.Equal(emitter, calls[0].Logs[0].Address)
+		require.Equal([]common.Hash{common.BigToHash(big.NewInt(0x77))}, calls[0].Logs[0].Topics)
+		require.Equal(simWord(0x2a), calls[0].Logs[0].Data)
+		require.Equal(uint64(res[0].Number), calls[0].Logs[0].BlockNumber)
+		require.Equal(res[0].Hash, calls[0].Logs[0].BlockHash)
+
+		require.Equal(hexutil.Uint64(types.ReceiptStatusFailed), calls[1].Status)
+		require.Equal(simWord(0x2a), []byte(calls[1].ReturnValue))
+		require.Empty(calls[1].Logs)
+		require.NotNil(calls[1].Error)
+		require.Equal(3, calls[1].Error.Code)
+		require.Equal(hexutil.Encode(simWord(0x2a)), calls[1].Error.Data)
+
+		// a reverted call doesn't break the simulation
+		require.Equal(hexutil.Uint64(types.ReceiptStatusSuccessful), calls[2].Status)
+		require.Empty(calls[2].Logs)
+	})
+
+	t.Run("non-increasing number or time", func(t *testing.T) {
+		require := require.New(t)
+		sameNumber := (*hexutil.Big)(new(big.Int).Set(base.Number))
+		_, err := api.SimulateV1(ctx, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{{BlockOverrides: &ethapi.BlockOverrides{Number: sameNumber}}},
+		}, nil)
+		require.ErrorContains(err, "is not above the parent number")
+
+		next := (*hexutil.Big)(new(big.Int).Add(base.Number, big.NewInt(5)))
+		_, err = api.SimulateV1(ctx, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{
+				{BlockOverrides: &ethapi.BlockOverrides{Number: next}},
+				{BlockOverrides: &ethapi.BlockOverrides{Number: next}},
+			},
+		}, nil)
+		require.ErrorContains(err, "block 1: number")
+
+		sameTime := hexutil.Uint64(base.Time.Unix())
+		_, err = api.SimulateV1(ctx, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{{BlockOverrides: &ethapi.BlockOverrides{Time: &sameTime}}},
+		}, nil)
+		require.ErrorContains(err, "is not above the parent timestamp")
+	})
+
+	t.Run("blocks limit", func(t *testing.T) {
+		require := require.New(t)
+		_, err := api.SimulateV1(ctx, ethapi.SimOpts{}, nil)
+		require.Error(err)
+
+		res, err := api.SimulateV1(ctx, ethapi.SimOpts{BlockStateCalls: make([]ethapi.SimBlock, 256)}, nil)
+		require.NoError(err)
+		require.Len(res, 256)
+
+		_, err = api.SimulateV1(ctx, ethapi.SimOpts{BlockStateCalls: make([]ethapi.SimBlock, 257)}, nil)
+		require.ErrorContains(err, "too many blocks")
+	})
+
+	t.Run("gas cap of all the calls", func(t *testing.T) {
+		require := require.New(t)
+		gas := hexutil.Uint64(60000)
+		capped := ethapi.TransactionArgs{To: &counter, Gas: &gas}
+		bn := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+		res, err := ethapi.DoSimulate(ctx, env.EthAPI, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{
+				{StateOverrides: &overrides, Calls: []ethapi.TransactionArgs{capped}},
+			},
+		}, bn, 0, 100000)
+		require.NoError(err)
+		require.Greater(uint64(res[0].GasUsed), uint64(100000-60000))
+
+		// the second call fits the cap alone, but not together with the first one
+		_, err = ethapi.DoSimulate(ctx, env.EthAPI, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{
+				{StateOverrides: &overrides, Calls: []ethapi.TransactionArgs{capped}},
+				{Calls: []ethapi.TransactionArgs{capped}},
+			},
+		}, bn, 0, 100000)
+		require.ErrorContains(err, "block 1: call 0: gas cap 100000 exceeded")
+
+		// the calls without the gas get the rest of the cap
+		res, err = ethapi.DoSimulate(ctx, env.EthAPI, ethapi.SimOpts{
+			BlockStateCalls: []ethapi.SimBlock{
+				{StateOverrides: &overrides, Calls: []ethapi.TransactionArgs{capped}},
+				{Calls: []ethapi.TransactionArgs{call(counter)}},
+			},
+		}, bn, 0, 100000)
+		require.NoError(err)
+		require.Equal(simWord(2), []byte(res[1].Calls[0].ReturnValue))
+	})
+}
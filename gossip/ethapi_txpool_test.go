@@ -0,0 +1,85 @@
+package gossip
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestPrivateTxPoolAPIIsOptIn(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+
+	serve := func(modules ...string) *rpc.Client {
+		server := rpc.NewServer()
+		t.Cleanup(server.Stop)
+		require.NoError(node.RegisterApis(ethapi.GetAPIs(env.EthAPI), modules, server, false))
+		return rpc.DialInProc(server)
+	}
+
+	// the public txpool namespace doesn't expose the admin API
+	for _, modules := range [][]string{nil, {"txpool"}} {
+		client := serve(modules...)
+		var status map[string]interface{}
+		require.NoError(client.Call(&status, "txpool_status"))
+		var list evmcore.TxDenylist
+		require.ErrorContains(client.Call(&list, "txpooladmin_denylist"), "does not exist")
+		require.ErrorContains(client.Call(&list, "txpool_denylist"), "does not exist")
+	}
+
+	client := serve("txpooladmin")
+	var list evmcore.TxDenylist
+	require.NoError(client.Call(&list, "txpooladmin_denylist"))
+}
+
+func TestPrivateTxsAreHiddenFromTxPoolAPI(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+
+	public := env.Transfer(1, 2, utils.ToFtm(1))
+	private := env.Transfer(1, 2, utils.ToFtm(1))
+	sender := env.Address(1)
+	require.NoError(env.EthAPI.SendTx(context.Background(), public))
+	require.NoError(env.EthAPI.SendPrivateTx(context.Background(), private))
+
+	poolApi := ethapi.NewPublicTxPoolAPI(env.EthAPI)
+	content := poolApi.Content()["pending"][sender.Hex()]
+	require.Len(content, 1)
+	require.Equal(public.Hash(), content[fmt.Sprintf("%d", public.Nonce())].Hash)
+
+	contentFrom := poolApi.ContentFrom(sender)["pending"]
+	require.Len(contentFrom, 1)
+	require.Contains(contentFrom, fmt.Sprintf("%d", public.Nonce()))
+
+	inspect := poolApi.Inspect()["pending"][sender.Hex()]
+	require.Len(inspect, 1)
+	require.Contains(inspect, fmt.Sprintf("%d", public.Nonce()))
+
+	txApi := ethapi.NewPublicTransactionPoolAPI(env.EthAPI, new(ethapi.AddrLocker))
+	tx, err := txApi.GetTransactionByHash(context.Background(), public.Hash())
+	require.NoError(err)
+	require.NotNil(tx)
+	tx, err = txApi.GetTransactionByHash(context.Background(), private.Hash())
+	require.NoError(err)
+	require.Nil(tx)
+
+	pending, err := env.EthAPI.GetPoolTransactions()
+	require.NoError(err)
+	require.Len(pending, 1)
+	require.Equal(public.Hash(), pending[0].Hash())
+}
@@ -0,0 +1,476 @@
+package gossip
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+const (
+	// snapshotExt is the file of the chunks of a snapshot, and snapshotIndexExt is the file of their offsets
+	snapshotExt      = ".chunks"
+	snapshotIndexExt = ".chunks.idx"
+	// snapshotDownloadExt is the file of the downloaded chunks, and worldStateExt is the World State dump assembled from them
+	snapshotDownloadExt = ".chunks.part"
+	worldStateExt       = ".fws"
+)
+
+var errInvalidSnapshotChunk = errors.New("EVM state snapshot chunk doesn't match the state root of the epoch")
+
+// evmSnapshots are the EVM state snapshots of the epochs, exported from the archive and served to the peers.
+// The snapshot of an epoch is the World State of the last block of the previous epoch,
+// so its state root is the FinalizedStateRoot of the epoch's block state.
+// The snapshot is split into the chunks with the Merkle range proofs, see evmstore.SnapshotChunk.
+type evmSnapshots struct {
+	cfg   SnapshotsConfig
+	dir   string
+	store *Store
+
+	exporting sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	logger.Instance
+}
+
+func newEvmSnapshots(cfg SnapshotsConfig, dir string, store *Store) *evmSnapshots {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &evmSnapshots{
+		cfg:      cfg,
+		dir:      dir,
+		store:    store,
+		ctx:      ctx,
+		cancel:   cancel,
+		Instance: logger.New("snapshots"),
+	}
+}
+
+func (s *evmSnapshots) path(epoch idx.Epoch) string {
+	return filepath.Join(s.dir, strconv.FormatUint(uint64(epoch), 10)+snapshotExt)
+}
+
+func (s *evmSnapshots) indexPath(epoch idx.Epoch) string {
+	return filepath.Join(s.dir, strconv.FormatUint(uint64(epoch), 10)+snapshotIndexExt)
+}
+
+// Iterate iterates over the chunks of the snapshot of the epoch, starting from the start chunk.
+// Nothing is iterated if the snapshot isn't exported.
+func (s *evmSnapshots) Iterate(epoch idx.Epoch, start uint32, f func(chunk uint32, data []byte) bool) {
+	index, err := os.ReadFile(s.indexPath(epoch))
+	if err != nil {
+		return
+	}
+	file, err := os.Open(s.path(epoch))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	// the index is the offsets of the chunks, followed by the end of the last chunk
+	for chunk := uint64(start); (chunk+2)*8 <= uint64(len(index)); chunk++ {
+		offset := binary.BigEndian.Uint64(index[chunk*8:])
+		end := binary.BigEndian.Uint64(index[(chunk+1)*8:])
+		if end < offset {
+			return
+		}
+		data := make([]byte, end-offset)
+		if _, err := file.ReadAt(data, int64(offset)); err != nil {
+			s.Log.Warn("Failed to read EVM state snapshot", "epoch", epoch, "err", err)
+			return
+		}
+		if !f(uint32(chunk), data) {
+			return
+		}
+	}
+}
+
+// ExportAsync exports the snapshot of the epoch in background, if the epoch is a snapshot epoch.
+// The call is ignored if another snapshot is being exported.
+func (s *evmSnapshots) ExportAsync(epoch idx.Epoch) {
+	if epoch%s.cfg.EpochPeriod != 0 || !s.exporting.TryLock() {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.exporting.Unlock()
+		start := time.Now()
+		if err := s.export(s.ctx, epoch); err != nil {
+			if s.ctx.Err() == nil {
+				s.Log.Error("Failed to export EVM state snapshot", "epoch", epoch, "err", err)
+			}
+			return
+		}
+		s.Log.Info("Exported EVM state snapshot", "epoch", epoch, "elapsed", time.Since(start))
+		s.prune()
+	}()
+}
+
+func (s *evmSnapshots) export(ctx context.Context, epoch idx.Epoch) error {
+	if _, err := os.Stat(s.indexPath(epoch)); err == nil {
+		return nil
+	}
+	bs, _ := s.store.GetHistoryBlockEpochState(epoch)
+	if bs == nil {
+		return errNonExistingEpoch
+	}
+	// wait until the block is written into the archive
+	for {
+		height, empty, err := s.store.evm.GetArchiveBlockHeight()
+		if err != nil {
+			return err
+		}
+		if !empty && height >= uint64(bs.LastBlock.Idx) {
+			break
+		}
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	tmpPath := s.path(epoch) + ".tmp"
+	defer os.Remove(tmpPath)
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriterSize(file, 1024*1024)
+	var (
+		index  []byte
+		offset uint64
+	)
+	root, err := s.store.evm.ExportStateSnapshotChunks(ctx, bs.LastBlock.Idx, snapstream.ChunkSize, func(c *evmstore.SnapshotChunk) error {
+		data, err := rlp.EncodeToBytes(c)
+		if err != nil {
+			return err
+		}
+		index = binary.BigEndian.AppendUint64(index, offset)
+		offset += uint64(len(data))
+		_, err = writer.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if root != bs.FinalizedStateRoot {
+		return fmt.Errorf("exported state root %s doesn't match the epoch state root %s", root, bs.FinalizedStateRoot)
+	}
+	index = binary.BigEndian.AppendUint64(index, offset)
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path(epoch)); err != nil {
+		return err
+	}
+	// the index is written last, so the snapshot is served once it's complete
+	tmpIndexPath := s.indexPath(epoch) + ".tmp"
+	if err := os.WriteFile(tmpIndexPath, index, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpIndexPath, s.indexPath(epoch))
+}
+
+// prune erases the snapshots except the Keep latest ones
+func (s *evmSnapshots) prune() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	var epochs []idx.Epoch
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, snapshotExt) {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(name, snapshotExt), 10, 32)
+		if err != nil {
+			continue
+		}
+		epochs = append(epochs, idx.Epoch(n))
+	}
+	sort.Slice(epochs, func(i, j int) bool {
+		return epochs[i] > epochs[j]
+	})
+	for i := s.cfg.Keep; i < len(epochs); i++ {
+		// the index is erased first, so the snapshot isn't served anymore
+		_ = os.Remove(s.indexPath(epochs[i]))
+		if err := os.Remove(s.path(epochs[i])); err != nil {
+			s.Log.Warn("Failed to erase EVM state snapshot", "epoch", epochs[i], "err", err)
+		}
+	}
+}
+
+// Stop interrupts the snapshot export and waits until it's stopped.
+func (s *evmSnapshots) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// snapshotDownload assembles the EVM state snapshot of an epoch from the chunks, which are received in order.
+// Every chunk is checked against the state root decided by LLR for the epoch once it's received,
+// so an invalid chunk is rejected before it's written. The partially downloaded snapshot is resumed after a restart.
+type snapshotDownload struct {
+	dir string
+
+	mu       sync.Mutex
+	epoch    idx.Epoch
+	root     hash.Hash
+	file     *os.File
+	size     int64
+	next     uint32
+	verifier *evmstore.SnapshotVerifier
+}
+
+func newSnapshotDownload(dir string) *snapshotDownload {
+	return &snapshotDownload{
+		dir: dir,
+	}
+}
+
+func (d *snapshotDownload) path(epoch idx.Epoch) string {
+	return filepath.Join(d.dir, strconv.FormatUint(uint64(epoch), 10)+snapshotDownloadExt)
+}
+
+func (d *snapshotDownload) worldStatePath(epoch idx.Epoch) string {
+	return filepath.Join(d.dir, strconv.FormatUint(uint64(epoch), 10)+worldStateExt)
+}
+
+// Start starts downloading the snapshot of the epoch, resuming the download if it was interrupted.
+// The downloads of other epochs are erased.
+func (d *snapshotDownload) Start(epoch idx.Epoch, root hash.Hash) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.close()
+
+	if err := os.MkdirAll(d.dir, 0700); err != nil {
+		return err
+	}
+	for _, ext := range []string{snapshotDownloadExt, worldStateExt} {
+		stale, _ := filepath.Glob(filepath.Join(d.dir, "*"+ext+"*"))
+		for _, path := range stale {
+			if path != d.path(epoch) {
+				_ = os.Remove(path)
+			}
+		}
+	}
+	file, err := os.OpenFile(d.path(epoch), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	d.epoch = epoch
+	d.root = root
+	d.file = file
+	if err := d.resume(); err != nil {
+		d.close()
+		return err
+	}
+	return nil
+}
+
+// resume restores the download state from the file, keeping only the chunks, which are verified again.
+func (d *snapshotDownload) resume() error {
+	d.next = 0
+	d.size = 0
+	d.verifier = evmstore.NewSnapshotVerifier(d.root)
+
+	reader := bufio.NewReaderSize(io.NewSectionReader(d.file, 0, math.MaxInt64), 1024*1024)
+	_ = d.iterate(reader, func(c *evmstore.SnapshotChunk, size int64) error {
+		if err := d.verifier.Verify(c); err != nil {
+			return err
+		}
+		d.next++
+		d.size += size
+		return nil
+	})
+	// drop the chunks, which aren't verified
+	return d.file.Truncate(d.size)
+}
+
+// iterate reads the chunks written into the file, each of them is prefixed by its size.
+func (d *snapshotDownload) iterate(reader io.Reader, f func(c *evmstore.SnapshotChunk, size int64) error) error {
+	var size [4]byte
+	for {
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		data := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return err
+		}
+		c := new(evmstore.SnapshotChunk)
+		if err := rlp.DecodeBytes(data, c); err != nil {
+			return err
+		}
+		if err := f(c, int64(len(size)+len(data))); err != nil {
+			return err
+		}
+	}
+}
+
+// Write writes the chunk of the snapshot, received from a peer. Returns false if the chunk isn't the next one to fill.
+func (d *snapshotDownload) Write(chunk snapstream.Chunk) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil || d.verifier.Complete() || chunk.Locator.Epoch != d.epoch || chunk.Locator.Chunk != d.next {
+		return false, nil
+	}
+	c := new(evmstore.SnapshotChunk)
+	if err := rlp.DecodeBytes(chunk.Data, c); err != nil {
+		return false, fmt.Errorf("%w: %v", errInvalidSnapshotChunk, err)
+	}
+	if err := d.verifier.Verify(c); err != nil {
+		return false, fmt.Errorf("%w: %v", errInvalidSnapshotChunk, err)
+	}
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(chunk.Data)))
+	data = append(data, chunk.Data...)
+	if _, err := d.file.WriteAt(data, d.size); err != nil {
+		// the verifier has accepted the chunk, so the download is restarted from the file
+		return false, errors.Join(err, d.resume())
+	}
+	d.size += int64(len(data))
+	d.next++
+	if d.verifier.Complete() {
+		if err := d.file.Sync(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// WorldState assembles the World State dump from the downloaded snapshot, and returns its path.
+func (d *snapshotDownload) WorldState() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil || !d.verifier.Complete() {
+		return "", errors.New("EVM state snapshot isn't downloaded")
+	}
+	path := d.worldStatePath(d.epoch)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	tmpPath := path + ".tmp"
+	defer os.Remove(tmpPath)
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	writer := bufio.NewWriterSize(file, 1024*1024)
+	err = evmstore.WriteWorldState(writer, d.root, func(f func(c *evmstore.SnapshotChunk) error) error {
+		return d.iterate(bufio.NewReaderSize(io.NewSectionReader(d.file, 0, d.size), 1024*1024), func(c *evmstore.SnapshotChunk, _ int64) error {
+			return f(c)
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmpPath, path)
+}
+
+// Epoch returns the epoch of the snapshot being downloaded, or 0 if there's no download.
+func (d *snapshotDownload) Epoch() idx.Epoch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.epoch
+}
+
+// Target returns the epoch of the snapshot to download, or 0 if the snapshot is downloaded.
+func (d *snapshotDownload) Target() idx.Epoch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.complete() {
+		return 0
+	}
+	return d.epoch
+}
+
+// Complete returns true if the snapshot is downloaded.
+func (d *snapshotDownload) Complete() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.file != nil && d.complete()
+}
+
+func (d *snapshotDownload) complete() bool {
+	return d.verifier != nil && d.verifier.Complete()
+}
+
+// LowestChunkToFill returns the next chunk to download.
+func (d *snapshotDownload) LowestChunkToFill() uint32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.next
+}
+
+// IsWritten returns true if the chunk is written or isn't needed anymore.
+func (d *snapshotDownload) IsWritten(chunk snapstream.Locator) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return chunk.Epoch != d.epoch || chunk.Chunk < d.next || d.complete()
+}
+
+// Drop erases the snapshot.
+func (d *snapshotDownload) Drop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file != nil {
+		d.close()
+		_ = os.Remove(d.path(d.epoch))
+		_ = os.Remove(d.worldStatePath(d.epoch))
+	}
+	d.epoch = 0
+	d.next = 0
+	d.size = 0
+	d.verifier = nil
+}
+
+// Close closes the snapshot file, keeping the downloaded chunks.
+func (d *snapshotDownload) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.close()
+}
+
+func (d *snapshotDownload) close() {
+	if d.file != nil {
+		_ = d.file.Close()
+		d.file = nil
+	}
+}
@@ -0,0 +1,233 @@
+package gossip
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	carmen "github.com/Fantom-foundation/Carmen/go/state"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+// snapshotChunks returns the chunks of the snapshot of a small EVM state, and the state root
+func snapshotChunks(t *testing.T, epoch idx.Epoch) (hash.Hash, []snapstream.Chunk) {
+	cfg := evmstore.LiteStoreConfig()
+	cfg.StateDb.Directory = t.TempDir()
+	store := evmstore.NewStore(memorydb.New(), cfg)
+	require.NoError(t, store.Open())
+	defer store.Close()
+
+	live, err := store.GetLiveStateDb(hash.Hash(types.EmptyRootHash))
+	require.NoError(t, err)
+	live.BeginBlock(1)
+	for i := 1; i <= 50; i++ {
+		addr := common.Address{byte(i)}
+		live.CreateAccount(addr)
+		live.AddBalance(addr, big.NewInt(int64(i)))
+		if i%5 == 0 {
+			live.SetCode(addr, []byte{0x60, byte(i)})
+			for j := 1; j <= 2*i; j++ {
+				live.SetState(addr, common.Hash{byte(j)}, common.Hash{31: byte(j)})
+			}
+		}
+	}
+	live.Finalise()
+	live.EndBlock(1)
+	root, err := live.Commit(true)
+	require.NoError(t, err)
+	for {
+		height, empty, err := store.GetArchiveBlockHeight()
+		require.NoError(t, err)
+		if !empty && height >= 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var chunks []snapstream.Chunk
+	_, err = store.ExportStateSnapshotChunks(context.Background(), 1, 1000, func(c *evmstore.SnapshotChunk) error {
+		data, err := rlp.EncodeToBytes(c)
+		chunks = append(chunks, snapstream.Chunk{
+			Locator: snapstream.Locator{Epoch: epoch, Chunk: uint32(len(chunks))},
+			Data:    data,
+		})
+		return err
+	})
+	require.NoError(t, err)
+	return hash.Hash(root), chunks
+}
+
+func TestSnapshotDownload(t *testing.T) {
+	logger.SetTestMode(t)
+	dir := t.TempDir()
+	root, chunks := snapshotChunks(t, 5)
+	require.Greater(t, len(chunks), 3)
+
+	d := newSnapshotDownload(dir)
+	require.NoError(t, d.Start(5, root))
+	require.Equal(t, uint32(0), d.LowestChunkToFill())
+	require.Equal(t, idx.Epoch(5), d.Target())
+
+	// the chunks are written in order
+	ok, err := d.Write(chunks[1])
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = d.Write(chunks[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, d.IsWritten(snapstream.Locator{Epoch: 5, Chunk: 0}))
+	require.False(t, d.IsWritten(snapstream.Locator{Epoch: 5, Chunk: 1}))
+
+	// the download is resumed after a restart
+	d.Close()
+	d = newSnapshotDownload(dir)
+	require.NoError(t, d.Start(5, root))
+	require.Equal(t, uint32(1), d.LowestChunkToFill())
+	_, err = d.WorldState()
+	require.Error(t, err)
+	for _, c := range chunks[1:] {
+		ok, err = d.Write(c)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.True(t, d.Complete())
+	require.Equal(t, idx.Epoch(0), d.Target())
+
+	// the complete download is resumed as well
+	d.Close()
+	d = newSnapshotDownload(dir)
+	require.NoError(t, d.Start(5, root))
+	require.True(t, d.Complete())
+	require.Equal(t, uint32(len(chunks)), d.LowestChunkToFill())
+
+	// the World State assembled from the chunks matches the state root
+	path, err := d.WorldState()
+	require.NoError(t, err)
+	cfg := evmstore.LiteStoreConfig()
+	cfg.StateDb.Directory = t.TempDir()
+	cfg.StateDb.Archive = carmen.NoArchive
+	imported := evmstore.NewStore(memorydb.New(), cfg)
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, imported.ImportLiveWorldState(file))
+	require.NoError(t, file.Close())
+	require.NoError(t, imported.Open())
+	require.NoError(t, imported.CheckLiveStateHash(1, root))
+	require.NoError(t, imported.Close())
+
+	d.Drop()
+	_, err = os.Stat(d.path(5))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestSnapshotDownloadWrongChunk(t *testing.T) {
+	logger.SetTestMode(t)
+	dir := t.TempDir()
+	root, chunks := snapshotChunks(t, 5)
+
+	// the chunks of another state are rejected
+	d := newSnapshotDownload(dir)
+	require.NoError(t, d.Start(5, hash.HexToHash("0x01")))
+	_, err := d.Write(chunks[0])
+	require.ErrorIs(t, err, errInvalidSnapshotChunk)
+	require.Equal(t, uint32(0), d.LowestChunkToFill())
+	d.Drop()
+
+	require.NoError(t, d.Start(5, root))
+	ok, err := d.Write(chunks[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// a chunk, which doesn't match the state root, is rejected once it's received
+	var c evmstore.SnapshotChunk
+	require.NoError(t, rlp.DecodeBytes(chunks[1].Data, &c))
+	require.NotEmpty(t, c.Accounts)
+	c.Accounts[0].Balance = big.NewInt(1000)
+	wrong := chunks[1]
+	wrong.Data, err = rlp.EncodeToBytes(&c)
+	require.NoError(t, err)
+	_, err = d.Write(wrong)
+	require.ErrorIs(t, err, errInvalidSnapshotChunk)
+	wrong.Data = []byte{0xC0}
+	_, err = d.Write(wrong)
+	require.ErrorIs(t, err, errInvalidSnapshotChunk)
+	// a chunk can't be skipped
+	wrong = chunks[2]
+	wrong.Locator.Chunk = 1
+	_, err = d.Write(wrong)
+	require.ErrorIs(t, err, errInvalidSnapshotChunk)
+	require.Equal(t, uint32(1), d.LowestChunkToFill())
+
+	// the chunks, which are corrupted on the disk, are downloaded again after a restart
+	for _, c := range chunks[1:3] {
+		ok, err = d.Write(c)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	d.Close()
+	file, err := os.OpenFile(d.path(5), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte{0xFF}, int64(4+len(chunks[0].Data)+4+len(chunks[1].Data)-1))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	d = newSnapshotDownload(dir)
+	defer d.Close()
+	require.NoError(t, d.Start(5, root))
+	require.Equal(t, uint32(1), d.LowestChunkToFill())
+	for _, c := range chunks[1:] {
+		ok, err = d.Write(c)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.True(t, d.Complete())
+}
+
+func TestEvmSnapshotsIterate(t *testing.T) {
+	dir := t.TempDir()
+	s := newEvmSnapshots(DefaultSnapshotsConfig(), dir, nil)
+	chunks := [][]byte{{1, 2, 3}, {4}, {5, 6}}
+	var (
+		data  []byte
+		index []byte
+	)
+	for _, c := range chunks {
+		index = binary.BigEndian.AppendUint64(index, uint64(len(data)))
+		data = append(data, c...)
+	}
+	index = binary.BigEndian.AppendUint64(index, uint64(len(data)))
+	require.NoError(t, os.WriteFile(s.path(5), data, 0600))
+
+	// the snapshot isn't served without the index
+	iterate := func(epoch idx.Epoch, start uint32) [][]byte {
+		var got [][]byte
+		s.Iterate(epoch, start, func(chunk uint32, b []byte) bool {
+			require.Equal(t, start+uint32(len(got)), chunk)
+			got = append(got, b)
+			return true
+		})
+		return got
+	}
+	require.Empty(t, iterate(5, 0))
+	require.NoError(t, os.WriteFile(s.indexPath(5), index, 0600))
+
+	require.Equal(t, chunks, iterate(5, 0))
+	require.Equal(t, chunks[1:], iterate(5, 1))
+	require.Empty(t, iterate(5, 3))
+	require.Empty(t, iterate(6, 0))
+}
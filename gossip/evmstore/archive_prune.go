@@ -0,0 +1,203 @@
+package evmstore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	cc "github.com/Fantom-foundation/Carmen/go/common"
+	"github.com/Fantom-foundation/Carmen/go/database/mpt"
+	io2 "github.com/Fantom-foundation/Carmen/go/database/mpt/io"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// pruningSuffix is the suffix of the directory, where the pruned archive is built
+	pruningSuffix = ".pruning"
+	// replacedSuffix is the suffix of the directory, where the old archive is kept until the pruned one is in place
+	replacedSuffix = ".replaced"
+)
+
+// PruneArchive rebuilds the Carmen archive in the given directory without the states
+// of the blocks before the oldest block, so the disk space of the pruned states is reclaimed.
+// The state of the oldest block is exported into a new archive, and the changes of every following
+// block are copied over and checked against the original state hashes.
+// The original archive is replaced only when the new one is complete. The archive must not be in use.
+func PruneArchive(ctx context.Context, directory string, oldest uint64) error {
+	if err := recoverArchiveReplace(directory); err != nil {
+		return err
+	}
+	info, err := io2.CheckMptDirectoryAndGetInfo(directory)
+	if err != nil {
+		return fmt.Errorf("failed to read carmen archive: %w", err)
+	}
+	if info.Mode != mpt.Immutable {
+		return fmt.Errorf("the database in the archive directory is not an archive")
+	}
+	src, err := mpt.OpenArchiveTrie(directory, info.Config, mpt.NodeCacheConfig{}, mpt.ArchiveConfig{})
+	if err != nil {
+		return fmt.Errorf("failed to open carmen archive: %w", err)
+	}
+	height, empty, err := src.GetBlockHeight()
+	if err != nil {
+		return errors.Join(err, src.Close())
+	}
+	if empty || oldest > height {
+		return errors.Join(fmt.Errorf("the oldest block %d is above the archive block height %d", oldest, height), src.Close())
+	}
+
+	tmpDir := directory + pruningSuffix
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return errors.Join(err, src.Close())
+	}
+	if err := os.MkdirAll(tmpDir, 0700); err != nil {
+		return errors.Join(err, src.Close())
+	}
+	err = copyArchive(ctx, src, tmpDir, info.Config, oldest, height)
+	if err = errors.Join(err, src.Close()); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return err
+	}
+
+	if err := replaceArchive(directory); err != nil {
+		return err
+	}
+	log.Info("Archive pruned", "oldest", oldest, "height", height)
+	return nil
+}
+
+// replaceArchive moves the complete pruned archive into place. The old archive is renamed aside first
+// and removed last, so a crash at any step leaves either archive, and the replacement is completed
+// by recoverArchiveReplace on the next start.
+func replaceArchive(directory string) error {
+	if err := os.Rename(directory, directory+replacedSuffix); err != nil {
+		return fmt.Errorf("failed to move the old archive aside: %w", err)
+	}
+	return recoverArchiveReplace(directory)
+}
+
+// recoverArchiveReplace completes the archive replacement interrupted by a crash, if there is any.
+func recoverArchiveReplace(directory string) error {
+	replaced := directory + replacedSuffix
+	if _, err := os.Stat(replaced); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(directory); os.IsNotExist(err) {
+		next := directory + pruningSuffix
+		if _, err := os.Stat(next); os.IsNotExist(err) {
+			// keep the old archive, if the pruned one is lost
+			next = replaced
+		}
+		if err := os.Rename(next, directory); err != nil {
+			return fmt.Errorf("failed to move the archive into place: %w", err)
+		}
+	}
+	if err := os.RemoveAll(replaced); err != nil {
+		return fmt.Errorf("failed to remove the old archive: %w", err)
+	}
+	return nil
+}
+
+// copyArchive initializes the archive in the given directory by the state of the oldest block
+// and copies the changes of the following blocks up to the height from the source archive.
+func copyArchive(ctx context.Context, src *mpt.ArchiveTrie, directory string, config mpt.MptConfig, oldest, height uint64) error {
+	log.Info("Exporting archive state", "block", oldest)
+	reader, writer := io.Pipe()
+	bufReader := bufio.NewReaderSize(reader, 100*1024*1024) // 100 MiB
+	bufWriter := bufio.NewWriterSize(writer, 100*1024*1024) // 100 MiB
+
+	var exportErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		exportErr = io2.ExportBlockFromOnlineArchive(ctx, io2.NewLog(), src, bufWriter, oldest)
+		if exportErr == nil {
+			exportErr = bufWriter.Flush()
+		}
+		writer.CloseWithError(exportErr)
+	}()
+	err := io2.InitializeArchive(io2.NewLog(), directory, bufReader, oldest)
+	reader.Close() // unblock the export if the import has failed
+	wg.Wait()
+	if err := errors.Join(err, exportErr); err != nil {
+		return fmt.Errorf("failed to initialize the pruned archive: %w", err)
+	}
+
+	dst, err := mpt.OpenArchiveTrie(directory, config, mpt.NodeCacheConfig{}, mpt.ArchiveConfig{})
+	if err != nil {
+		return err
+	}
+	err = copyArchiveBlocks(ctx, src, dst, oldest, height)
+	return errors.Join(err, dst.Close())
+}
+
+func copyArchiveBlocks(ctx context.Context, src, dst *mpt.ArchiveTrie, oldest, height uint64) error {
+	if err := checkArchiveHash(src, dst, oldest); err != nil {
+		return err
+	}
+	for block := oldest + 1; block <= height; block++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		diff, err := src.GetDiffForBlock(block)
+		if err != nil {
+			return fmt.Errorf("failed to get changes of block %d: %w", block, err)
+		}
+		update := diffToUpdate(src, diff)
+		if err := update.Normalize(); err != nil {
+			return fmt.Errorf("invalid changes of block %d: %w", block, err)
+		}
+		if err := dst.Add(block, update, nil); err != nil {
+			return fmt.Errorf("failed to add block %d: %w", block, err)
+		}
+		if err := checkArchiveHash(src, dst, block); err != nil {
+			return err
+		}
+		if block%10000 == 0 {
+			log.Info("Copying archive blocks", "block", block, "height", height)
+		}
+	}
+	return nil
+}
+
+func diffToUpdate(src *mpt.ArchiveTrie, diff mpt.Diff) cc.Update {
+	update := cc.Update{}
+	for addr, account := range diff {
+		if account.Reset {
+			update.AppendDeleteAccount(addr)
+		}
+		if account.Balance != nil {
+			update.AppendBalanceUpdate(addr, *account.Balance)
+		}
+		if account.Nonce != nil {
+			update.AppendNonceUpdate(addr, *account.Nonce)
+		}
+		if account.Code != nil {
+			update.AppendCodeUpdate(addr, src.GetCodeForHash(*account.Code))
+		}
+		for key, value := range account.Storage {
+			update.AppendSlotUpdate(addr, key, value)
+		}
+	}
+	return update
+}
+
+func checkArchiveHash(src, dst *mpt.ArchiveTrie, block uint64) error {
+	expected, err := src.GetHash(block)
+	if err != nil {
+		return err
+	}
+	actual, err := dst.GetHash(block)
+	if err != nil {
+		return err
+	}
+	if expected != actual {
+		return fmt.Errorf("hash of the pruned archive state is incorrect: blockNum: %d expected: %x actual: %x", block, expected, actual)
+	}
+	return nil
+}
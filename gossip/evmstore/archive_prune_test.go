@@ -0,0 +1,224 @@
+package evmstore
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+func TestPruneArchive(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	cfg := LiteStoreConfig()
+	cfg.StateDb.Directory = t.TempDir()
+	db := memorydb.New()
+	store := NewStore(db, cfg)
+	require.NoError(store.Open())
+
+	var (
+		eoa       = common.Address{1}
+		contract  = common.Address{2}
+		destroyed = common.Address{3}
+		slot1     = common.Hash{1}
+		slot2     = common.Hash{2}
+	)
+	live := CreateCarmenStateDb(store.liveStateDb)
+	roots := make(map[uint64]common.Hash)
+	for block := uint64(1); block <= 6; block++ {
+		live.BeginBlock(block)
+		switch block {
+		case 1:
+			live.CreateAccount(destroyed)
+			live.AddBalance(destroyed, big.NewInt(7))
+			live.SetState(destroyed, slot1, common.Hash{31: 1})
+		case 2:
+			live.CreateAccount(eoa)
+			live.AddBalance(eoa, big.NewInt(1000))
+			live.CreateAccount(contract)
+			live.SetCode(contract, []byte{0x60, 0x00})
+			live.SetState(contract, slot1, common.Hash{31: 1})
+		case 4:
+			live.SetNonce(eoa, 1)
+			live.SubBalance(eoa, big.NewInt(100))
+			live.SetState(contract, slot1, common.Hash{})
+			live.SetState(contract, slot2, common.Hash{31: 2})
+			live.Suicide(destroyed)
+		case 6:
+			live.SetCode(contract, []byte{0x60, 0x01})
+		}
+		live.Finalise()
+		live.EndBlock(block)
+		root, err := live.Commit(true)
+		require.NoError(err)
+		roots[block] = root
+	}
+	waitForArchive(t, store, 6)
+	store.SetOldestArchiveBlock(3)
+	require.NoError(store.Close())
+
+	require.NoError(PruneArchive(context.Background(), filepath.Join(cfg.StateDb.Directory, "archive"), 3))
+	require.Error(PruneArchive(context.Background(), filepath.Join(cfg.StateDb.Directory, "archive"), 7))
+
+	// the archive window is persisted
+	store = NewStore(db, cfg)
+	require.NoError(store.Open())
+	defer store.Close()
+	require.Equal(uint64(3), store.GetOldestArchiveBlock())
+
+	height, empty, err := store.GetArchiveBlockHeight()
+	require.NoError(err)
+	require.False(empty)
+	require.Equal(uint64(6), height)
+
+	for block := uint64(3); block <= 6; block++ {
+		stateDb, err := store.GetRpcStateDb(new(big.Int).SetUint64(block), roots[block])
+		require.NoError(err, "block %d", block)
+		switch block {
+		case 3:
+			require.Equal(big.NewInt(1000), stateDb.GetBalance(eoa))
+			require.Equal(common.Hash{31: 1}, stateDb.GetState(contract, slot1))
+			require.True(stateDb.Exist(destroyed))
+		case 6:
+			require.Equal(big.NewInt(900), stateDb.GetBalance(eoa))
+			require.Equal(uint64(1), stateDb.GetNonce(eoa))
+			require.Equal(common.Hash{}, stateDb.GetState(contract, slot1))
+			require.Equal(common.Hash{31: 2}, stateDb.GetState(contract, slot2))
+			require.Equal([]byte{0x60, 0x01}, stateDb.GetCode(contract))
+			require.False(stateDb.Exist(destroyed))
+		}
+		stateDb.Release()
+	}
+
+	// the states before the oldest block are removed from the archive
+	_, err = store.GetRpcStateDb(big.NewInt(2), roots[2])
+	require.ErrorIs(err, ErrStateUnavailable)
+	store.oldestArchiveBlock.Store(0)
+	_, err = store.GetRpcStateDb(big.NewInt(2), roots[2])
+	require.Error(err)
+}
+
+func TestRecoverArchiveReplace(t *testing.T) {
+	// the directories contain a file named by the archive they hold
+	for name, test := range map[string]struct {
+		archive, replaced, pruning string
+		expected                   string
+	}{
+		"no replace":         {archive: "old", pruning: "new", expected: "old"},
+		"old moved aside":    {replaced: "old", pruning: "new", expected: "new"},
+		"new moved in place": {archive: "new", replaced: "old", expected: "new"},
+		"new archive lost":   {replaced: "old", expected: "old"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			dir := filepath.Join(t.TempDir(), "archive")
+			for path, content := range map[string]string{
+				dir:                  test.archive,
+				dir + replacedSuffix: test.replaced,
+				dir + pruningSuffix:  test.pruning,
+			} {
+				if content != "" {
+					require.NoError(os.MkdirAll(path, 0700))
+					require.NoError(os.WriteFile(filepath.Join(path, content), nil, 0600))
+				}
+			}
+			require.NoError(recoverArchiveReplace(dir))
+			require.FileExists(filepath.Join(dir, test.expected))
+			require.NoDirExists(dir + replacedSuffix)
+		})
+	}
+}
+
+func TestArchivePruning(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	cfg := LiteStoreConfig()
+	cfg.StateDb.Directory = t.TempDir()
+	cfg.ArchiveVisibleBlocks = 3
+	db := memorydb.New()
+	archiveDir := filepath.Join(cfg.StateDb.Directory, "archive")
+
+	var (
+		store *Store
+		live  state.StateDB
+		roots = make(map[uint64]common.Hash)
+		addr  = common.Address{1}
+	)
+	open := func() {
+		store = NewStore(db, cfg)
+		require.NoError(store.Open())
+		live = CreateCarmenStateDb(store.liveStateDb)
+	}
+	commit := func(from, to uint64) {
+		for block := from; block <= to; block++ {
+			live.BeginBlock(block)
+			live.AddBalance(addr, big.NewInt(int64(block)))
+			live.Finalise()
+			live.EndBlock(block)
+			root, err := live.Commit(true)
+			require.NoError(err)
+			roots[block] = root
+			waitForArchive(t, store, block)
+			if block > cfg.ArchiveVisibleBlocks {
+				store.SetOldestArchiveBlock(idx.Block(block - cfg.ArchiveVisibleBlocks + 1))
+			}
+		}
+	}
+
+	// the pruning starts once the hidden states take as many blocks as the visible ones
+	open()
+	commit(1, 5)
+	for i := 0; ; i++ {
+		if base, ok := store.getArchiveBlockKey(archivePruningKey); ok {
+			require.Equal(uint64(5), base)
+			break
+		}
+		require.Less(i, 1000, "pruned archive not initialized")
+		time.Sleep(10 * time.Millisecond)
+	}
+	commit(6, 6)
+	require.NoError(store.Close())
+
+	// the pruned archive is kept while the archive window is before its base block
+	open()
+	require.DirExists(archiveDir + pruningSuffix)
+	commit(7, 8)
+	require.NoError(store.Close())
+
+	// the pruned archive replaces the archive on the start
+	open()
+	defer store.Close()
+	require.NoDirExists(archiveDir + pruningSuffix)
+	require.NoDirExists(archiveDir + replacedSuffix)
+	base, ok := store.getArchiveBlockKey(archiveBaseKey)
+	require.True(ok)
+	require.Equal(uint64(5), base)
+	_, ok = store.getArchiveBlockKey(archivePruningKey)
+	require.False(ok)
+	commit(9, 9)
+
+	for block := uint64(7); block <= 9; block++ {
+		stateDb, err := store.GetRpcStateDb(new(big.Int).SetUint64(block), roots[block])
+		require.NoError(err, "block %d", block)
+		require.Equal(big.NewInt(int64(block*(block+1)/2)), stateDb.GetBalance(addr))
+		stateDb.Release()
+	}
+	store.oldestArchiveBlock.Store(0)
+	stateDb, err := store.GetRpcStateDb(big.NewInt(5), roots[5])
+	require.NoError(err)
+	stateDb.Release()
+	_, err = store.GetRpcStateDb(big.NewInt(4), roots[4])
+	require.Error(err)
+}
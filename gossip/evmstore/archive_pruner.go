@@ -0,0 +1,394 @@
+package evmstore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	cc "github.com/Fantom-foundation/Carmen/go/common"
+	"github.com/Fantom-foundation/Carmen/go/database/mpt"
+	io2 "github.com/Fantom-foundation/Carmen/go/database/mpt/io"
+	carmen "github.com/Fantom-foundation/Carmen/go/state"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+var (
+	// archiveBaseKey is the first block of the pruned archive, there are no states of the preceding blocks
+	archiveBaseKey = []byte("b")
+	// archivePruningKey is the base block of the pruned archive, which is being built while the node runs
+	archivePruningKey = []byte("p")
+)
+
+// prunableState passes the updates, applied onto the live state, to the archive pruner while it runs.
+type prunableState struct {
+	carmen.State
+
+	mu        sync.Mutex
+	lastBlock uint64
+	pruner    *archivePruner
+}
+
+func (s *prunableState) Apply(block uint64, update cc.Update) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.State.Apply(block, update); err != nil {
+		return err
+	}
+	s.lastBlock = block
+	if s.pruner != nil {
+		s.pruner.add(block, update)
+	}
+	return nil
+}
+
+type archiveUpdate struct {
+	block  uint64
+	update cc.Update
+}
+
+// archivePruner builds the pruned archive in the background while the node runs.
+// The archive is initialized by the state of the base block, exported from the current archive,
+// and the updates of the following blocks are added as they're applied onto the live state.
+// The updates are queued in memory until the initialization is done.
+type archivePruner struct {
+	dir  string
+	base uint64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []archiveUpdate
+	archive *mpt.ArchiveTrie
+	stopped bool
+	err     error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startArchivePruner runs the pruner, init opens the pruned archive in the directory
+func startArchivePruner(dir string, base uint64, init func(ctx context.Context) (*mpt.ArchiveTrie, error)) *archivePruner {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &archivePruner{
+		dir:    dir,
+		base:   base,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	go func() {
+		defer close(p.done)
+		archive, err := init(ctx)
+		p.mu.Lock()
+		p.archive, p.err = archive, err
+		p.mu.Unlock()
+		if err == nil {
+			p.loop()
+		}
+	}()
+	return p
+}
+
+func (p *archivePruner) add(block uint64, update cc.Update) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return
+	}
+	// the update may be reused by the caller
+	p.queue = append(p.queue, archiveUpdate{
+		block: block,
+		update: cc.Update{
+			DeletedAccounts: append([]cc.Address(nil), update.DeletedAccounts...),
+			CreatedAccounts: append([]cc.Address(nil), update.CreatedAccounts...),
+			Balances:        append([]cc.BalanceUpdate(nil), update.Balances...),
+			Nonces:          append([]cc.NonceUpdate(nil), update.Nonces...),
+			Codes:           append([]cc.CodeUpdate(nil), update.Codes...),
+			Slots:           append([]cc.SlotUpdate(nil), update.Slots...),
+		},
+	})
+	p.cond.Signal()
+}
+
+// loop adds the queued updates to the pruned archive until the pruner is stopped and the queue is drained
+func (p *archivePruner) loop() {
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.stopped {
+			p.cond.Wait()
+		}
+		queue := p.queue
+		p.queue = nil
+		stopped := p.stopped
+		p.mu.Unlock()
+
+		for _, u := range queue {
+			if err := p.archive.Add(u.block, u.update, nil); err != nil {
+				p.mu.Lock()
+				p.err = fmt.Errorf("failed to add block %d to the pruned archive: %w", u.block, err)
+				p.queue = nil
+				p.mu.Unlock()
+				return
+			}
+		}
+		if stopped {
+			return
+		}
+	}
+}
+
+// stop waits for the queued updates to be added, and closes the pruned archive
+func (p *archivePruner) stop() error {
+	p.cancel()
+	p.mu.Lock()
+	p.stopped = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	<-p.done
+
+	err := p.err
+	if p.archive != nil {
+		err = errors.Join(err, p.archive.Close())
+	}
+	return err
+}
+
+// initPrunedArchive exports the archive state of the base block into the new archive in the directory,
+// the Store archive has to reach the block first.
+func (s *Store) initPrunedArchive(ctx context.Context, dir string, base uint64) (*mpt.ArchiveTrie, error) {
+	for {
+		height, empty, err := s.carmenState.GetArchiveBlockHeight()
+		if err != nil {
+			return nil, err
+		}
+		if !empty && height >= base {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	archiveState, err := s.carmenState.GetArchiveState(base)
+	if err != nil {
+		return nil, err
+	}
+	defer archiveState.Close()
+	expected, err := archiveState.GetHash()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s.Log.Info("Exporting archive state for the pruned archive", "block", base)
+	reader, writer := io.Pipe()
+	bufReader := bufio.NewReaderSize(reader, 100*1024*1024) // 100 MiB
+	bufWriter := bufio.NewWriterSize(writer, 100*1024*1024) // 100 MiB
+
+	var exportErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, exportErr = archiveState.Export(ctx, bufWriter)
+		if exportErr == nil {
+			exportErr = bufWriter.Flush()
+		}
+		writer.CloseWithError(exportErr)
+	}()
+	err = io2.InitializeArchive(io2.NewLog(), dir, bufReader, base)
+	reader.Close() // unblock the export if the import has failed
+	wg.Wait()
+	if err := errors.Join(err, exportErr); err != nil {
+		return nil, fmt.Errorf("failed to initialize the pruned archive: %w", err)
+	}
+
+	archive, err := openArchiveTrie(dir)
+	if err != nil {
+		return nil, err
+	}
+	if actual, err := archive.GetHash(base); err != nil || actual != expected {
+		err = errors.Join(err, fmt.Errorf("hash of the pruned archive state is incorrect: blockNum: %d expected: %x actual: %x", base, expected, actual))
+		return nil, errors.Join(err, archive.Close())
+	}
+	// the pruning survives restarts from now on
+	if err := s.table.ArchiveWindow.Put(archivePruningKey, idx.Block(base).Bytes()); err != nil {
+		return nil, errors.Join(err, archive.Close())
+	}
+	s.Log.Info("Pruned archive initialized", "block", base)
+	return archive, nil
+}
+
+func openArchiveTrie(dir string) (*mpt.ArchiveTrie, error) {
+	info, err := io2.CheckMptDirectoryAndGetInfo(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read carmen archive: %w", err)
+	}
+	if info.Mode != mpt.Immutable {
+		return nil, fmt.Errorf("the database in the archive directory is not an archive")
+	}
+	return mpt.OpenArchiveTrie(dir, info.Config, mpt.NodeCacheConfig{}, mpt.ArchiveConfig{})
+}
+
+func archiveHeight(dir string) (uint64, error) {
+	archive, err := openArchiveTrie(dir)
+	if err != nil {
+		return 0, err
+	}
+	height, empty, err := archive.GetBlockHeight()
+	if err = errors.Join(err, archive.Close()); err != nil {
+		return 0, err
+	}
+	if empty {
+		return 0, fmt.Errorf("the archive is empty")
+	}
+	return height, nil
+}
+
+func (s *Store) archivePruningEnabled() bool {
+	return s.table.ArchiveWindow != nil && s.ArchiveWindowEnabled() && s.parameters.Archive != carmen.NoArchive
+}
+
+func (s *Store) getArchiveBlockKey(key []byte) (uint64, bool) {
+	b, err := s.table.ArchiveWindow.Get(key)
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if b == nil {
+		return 0, false
+	}
+	return uint64(idx.BytesToBlock(b)), true
+}
+
+// finishArchivePruning replaces the archive by the pruned one on the start of the node, once the archive window
+// has moved past the base block of the pruned archive. The pruned archive, which is behind the archive,
+// is dropped, as it can't catch up.
+func (s *Store) finishArchivePruning(archiveDir string) error {
+	if s.table.ArchiveWindow == nil {
+		return nil
+	}
+	dir := archiveDir + pruningSuffix
+	base, ok := s.getArchiveBlockKey(archivePruningKey)
+	if !ok {
+		// the leftover of a pruning, which was interrupted before the pruned archive was initialized
+		return os.RemoveAll(dir)
+	}
+	if !s.archivePruningEnabled() {
+		return s.dropArchivePruning(archiveDir)
+	}
+	height, err := archiveHeight(archiveDir)
+	if err != nil {
+		return err
+	}
+	if prunedHeight, err := archiveHeight(dir); err != nil || prunedHeight != height {
+		s.Log.Warn("Dropping the pruned archive", "base", base, "height", prunedHeight, "archive", height, "err", err)
+		return s.dropArchivePruning(archiveDir)
+	}
+	if s.GetOldestArchiveBlock() < base {
+		// keep building the pruned archive after the start
+		return nil
+	}
+	if err := replaceArchive(archiveDir); err != nil {
+		return err
+	}
+	if err := s.table.ArchiveWindow.Put(archiveBaseKey, idx.Block(base).Bytes()); err != nil {
+		return err
+	}
+	if err := s.table.ArchiveWindow.Delete(archivePruningKey); err != nil {
+		return err
+	}
+	s.Log.Info("Archive pruned", "oldest", base, "height", height)
+	return nil
+}
+
+// SetArchivePruned records the archive, pruned offline by PruneArchive, has no states before the oldest block.
+// The pruned archive being built while the node runs is dropped, as PruneArchive removes it.
+func (s *Store) SetArchivePruned(oldest uint64) error {
+	if err := s.table.ArchiveWindow.Delete(archivePruningKey); err != nil {
+		return err
+	}
+	return s.table.ArchiveWindow.Put(archiveBaseKey, idx.Block(oldest).Bytes())
+}
+
+func (s *Store) dropArchivePruning(archiveDir string) error {
+	if err := s.table.ArchiveWindow.Delete(archivePruningKey); err != nil {
+		return err
+	}
+	return os.RemoveAll(archiveDir + pruningSuffix)
+}
+
+// resumeArchivePruning continues building the pruned archive after the start of the node
+func (s *Store) resumeArchivePruning() {
+	if s.table.ArchiveWindow == nil {
+		return
+	}
+	base, ok := s.getArchiveBlockKey(archivePruningKey)
+	if !ok {
+		return
+	}
+	dir := filepath.Join(s.parameters.Directory, "archive") + pruningSuffix
+	s.prunable.pruner = startArchivePruner(dir, base, func(context.Context) (*mpt.ArchiveTrie, error) {
+		return openArchiveTrie(dir)
+	})
+}
+
+// mayStartArchivePruning starts building the pruned archive, when the archive states hidden by the archive window
+// take at least as many blocks as the visible ones. The pruned archive starts at the latest block,
+// and it replaces the archive on a start of the node, once the archive window moves past the block.
+func (s *Store) mayStartArchivePruning(oldest uint64) {
+	if s.prunable == nil || !s.archivePruningEnabled() {
+		return
+	}
+	s.prunable.mu.Lock()
+	defer s.prunable.mu.Unlock()
+	if s.prunable.pruner != nil {
+		return
+	}
+	base, _ := s.getArchiveBlockKey(archiveBaseKey)
+	last := s.prunable.lastBlock
+	if oldest <= base || last < oldest || oldest-base < last-oldest+1 {
+		return
+	}
+	archiveDir := filepath.Join(s.parameters.Directory, "archive")
+	if err := os.RemoveAll(archiveDir + pruningSuffix); err != nil {
+		s.Log.Error("Failed to start archive pruning", "err", err)
+		return
+	}
+	s.Log.Info("Archive pruning started", "block", last)
+	dir := archiveDir + pruningSuffix
+	s.prunable.pruner = startArchivePruner(dir, last, func(ctx context.Context) (*mpt.ArchiveTrie, error) {
+		archive, err := s.initPrunedArchive(ctx, dir, last)
+		if err != nil && ctx.Err() == nil {
+			s.Log.Error("Failed to initialize the pruned archive", "err", err)
+		}
+		return archive, err
+	})
+}
+
+// stopArchivePruning stops adding the blocks to the pruned archive, the pruned archive is dropped if it has failed
+func (s *Store) stopArchivePruning() {
+	if s.prunable == nil {
+		return
+	}
+	s.prunable.mu.Lock()
+	pruner := s.prunable.pruner
+	s.prunable.pruner = nil
+	s.prunable.mu.Unlock()
+	if pruner == nil {
+		return
+	}
+	if err := pruner.stop(); err != nil {
+		s.Log.Error("Archive pruning failed", "err", err)
+		if err := s.dropArchivePruning(filepath.Join(s.parameters.Directory, "archive")); err != nil {
+			s.Log.Error("Failed to drop the pruned archive", "err", err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package evmstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// ErrStateUnavailable is returned for archive states older than the archive window.
+var ErrStateUnavailable = errors.New("state unavailable")
+
+// StateUnavailableError is returned when the archive state of the requested block
+// is out of the archive window.
+type StateUnavailableError struct {
+	Block  uint64
+	Oldest uint64
+}
+
+func (e *StateUnavailableError) Error() string {
+	return fmt.Sprintf("state unavailable: state of block %d is out of the archive window, the oldest available block is %d", e.Block, e.Oldest)
+}
+
+// Is makes the error matching ErrStateUnavailable.
+func (e *StateUnavailableError) Is(target error) bool {
+	return target == ErrStateUnavailable
+}
+
+// ErrorCode returns the JSON-RPC error code for unavailable history.
+func (e *StateUnavailableError) ErrorCode() int {
+	return 4444
+}
+
+// ArchiveWindowEnabled returns true if the archive states older than the archive window are hidden.
+func (s *Store) ArchiveWindowEnabled() bool {
+	return s.cfg.ArchiveVisibleBlocks != 0 || s.cfg.ArchiveVisibleEpochs != 0
+}
+
+var oldestArchiveBlockKey = []byte("o")
+
+// GetOldestArchiveBlock returns the oldest block with queryable archive state.
+func (s *Store) GetOldestArchiveBlock() uint64 {
+	return s.oldestArchiveBlock.Load()
+}
+
+// SetOldestArchiveBlock moves the archive window, so the archive states older than the given block are not queryable anymore.
+// The window never moves backwards, and it is persisted, so it survives restarts of the node.
+// Note: Carmen archive doesn't support online removal of historic states, so the hidden states
+// occupy the disk space until the pruned archive, which is built while the node runs, replaces
+// the archive on a start of the node, or until the archive is rebuilt offline by PruneArchive.
+func (s *Store) SetOldestArchiveBlock(oldest idx.Block) {
+	s.oldestArchiveBlockMu.Lock()
+	defer s.oldestArchiveBlockMu.Unlock()
+	if uint64(oldest) <= s.oldestArchiveBlock.Load() {
+		return
+	}
+	s.oldestArchiveBlock.Store(uint64(oldest))
+	if err := s.table.ArchiveWindow.Put(oldestArchiveBlockKey, oldest.Bytes()); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+	s.mayStartArchivePruning(uint64(oldest))
+}
+
+func (s *Store) loadOldestArchiveBlock() {
+	b, err := s.table.ArchiveWindow.Get(oldestArchiveBlockKey)
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if b != nil {
+		s.oldestArchiveBlock.Store(uint64(idx.BytesToBlock(b)))
+	}
+}
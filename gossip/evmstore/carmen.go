@@ -2,16 +2,24 @@ package evmstore
 
 import (
 	"errors"
+	"fmt"
 	cc "github.com/Fantom-foundation/Carmen/go/common"
 	"github.com/Fantom-foundation/Carmen/go/common/amount"
+	"github.com/Fantom-foundation/Carmen/go/common/immutable"
+	"github.com/Fantom-foundation/Carmen/go/common/witness"
 	carmen "github.com/Fantom-foundation/Carmen/go/state"
 	"github.com/Fantom-foundation/go-opera/inter/state"
 	"github.com/ethereum/go-ethereum/common"
-	ethstate "github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"math/big"
 )
 
+// ErrProofNotSupported is returned when a proof is requested from the live (writable) state.
+var ErrProofNotSupported = errors.New("witness proofs are available for archive states only")
+
+// ErrIncompleteProof is returned when the witness proof misses the nodes on the path to the account or the slot.
+var ErrIncompleteProof = errors.New("witness proof is incomplete")
+
 func CreateCarmenStateDb(carmenStateDb carmen.VmStateDB) state.StateDB {
 	return &CarmenStateDB{
 		db: carmenStateDb,
@@ -120,20 +128,67 @@ func (c *CarmenStateDB) GetState(addr common.Address, hash common.Hash) common.H
 	return common.Hash(c.db.GetState(cc.Address(addr), cc.Key(hash)))
 }
 
+// GetProof returns the RLP-encoded MPT nodes proving the account at addr
+// (or its absence) against the state root of this StateDB.
+// The nodes are not ordered - a verifier should index them by their Keccak256 hash.
 func (c *CarmenStateDB) GetProof(addr common.Address) ([][]byte, error) {
-	panic("not supported")
+	proof, err := c.createWitnessProof(addr)
+	if err != nil {
+		return nil, err
+	}
+	return witnessElementsToBytes(proof.GetElements()), nil
 }
 
-func (c *CarmenStateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byte, error) {
-	panic("not supported")
+// GetStorageProof returns the RLP-encoded MPT nodes proving the storage slot
+// of the account at addr against the account storage root.
+func (c *CarmenStateDB) GetStorageProof(addr common.Address, key common.Hash) ([][]byte, error) {
+	proof, err := c.createWitnessProof(addr, cc.Key(key))
+	if err != nil {
+		return nil, err
+	}
+	elements, _, complete := proof.GetStorageElements(c.db.GetHash(), cc.Address(addr), cc.Key(key))
+	if !complete {
+		return nil, ErrIncompleteProof
+	}
+	return witnessElementsToBytes(elements), nil
 }
 
-func (c *CarmenStateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
-	return common.Hash(c.db.GetCommittedState(cc.Address(addr), cc.Key(hash)))
+// GetStorageRoot returns the root hash of the account storage trie,
+// or an empty hash if the account does not exist.
+func (c *CarmenStateDB) GetStorageRoot(addr common.Address) (common.Hash, error) {
+	proof, err := c.createWitnessProof(addr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	_, storageRoot, complete := proof.GetStorageElements(c.db.GetHash(), cc.Address(addr))
+	if !complete {
+		return common.Hash{}, ErrIncompleteProof
+	}
+	return common.Hash(storageRoot), nil
 }
 
-func (c *CarmenStateDB) StorageTrie(addr common.Address) ethstate.Trie {
-	panic("not supported")
+func (c *CarmenStateDB) createWitnessProof(addr common.Address, keys ...cc.Key) (witness.Proof, error) {
+	db, ok := c.db.(carmen.NonCommittableStateDB)
+	if !ok {
+		return nil, ErrProofNotSupported
+	}
+	proof, err := db.CreateWitnessProof(cc.Address(addr), keys...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create witness proof; %w", err)
+	}
+	return proof, nil
+}
+
+func witnessElementsToBytes(elements []immutable.Bytes) [][]byte {
+	res := make([][]byte, len(elements))
+	for i, element := range elements {
+		res[i] = element.ToBytes()
+	}
+	return res
+}
+
+func (c *CarmenStateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	return common.Hash(c.db.GetCommittedState(cc.Address(addr), cc.Key(hash)))
 }
 
 func (c *CarmenStateDB) HasSuicided(addr common.Address) bool {
@@ -166,8 +221,10 @@ func (c *CarmenStateDB) SubBalance(addr common.Address, amountInt *big.Int) {
 	c.db.SubBalance(cc.Address(addr), am)
 }
 
+// SetBalance overrides the balance of the account - used for RPC state overrides.
 func (c *CarmenStateDB) SetBalance(addr common.Address, amount *big.Int) {
-	panic("not supported")
+	diff := new(big.Int).Sub(amount, c.GetBalance(addr))
+	c.AddBalance(addr, diff)
 }
 
 func (c *CarmenStateDB) SetNonce(addr common.Address, nonce uint64) {
@@ -182,8 +239,17 @@ func (c *CarmenStateDB) SetState(addr common.Address, key, value common.Hash) {
 	c.db.SetState(cc.Address(addr), cc.Key(key), cc.Value(value))
 }
 
+// SetStorage replaces the whole storage of the account - used for RPC state overrides.
 func (c *CarmenStateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
-	panic("not supported")
+	// re-creating the account clears its storage, but resets nonce and code too
+	nonce := c.GetNonce(addr)
+	code := c.GetCode(addr)
+	c.CreateAccount(addr)
+	c.SetNonce(addr, nonce)
+	c.SetCode(addr, code)
+	for key, value := range storage {
+		c.SetState(addr, key, value)
+	}
 }
 
 func (c *CarmenStateDB) Suicide(addr common.Address) bool {
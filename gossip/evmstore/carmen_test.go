@@ -0,0 +1,179 @@
+package evmstore
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+func archiveStore(t *testing.T) *Store {
+	cfg := LiteStoreConfig()
+	cfg.StateDb.Directory = t.TempDir()
+	store := NewStore(memorydb.New(), cfg)
+	require.NoError(t, store.Open())
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+	return store
+}
+
+func waitForArchive(t *testing.T, store *Store, block uint64) {
+	for i := 0; i < 100; i++ {
+		height, empty, err := store.GetArchiveBlockHeight()
+		require.NoError(t, err)
+		if !empty && height >= block {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("block %d not archived", block)
+}
+
+func proofResult(t *testing.T, stateDb state.StateDB, addr common.Address, keys ...common.Hash) *ethapi.AccountResult {
+	res := &ethapi.AccountResult{
+		Address:     addr,
+		Balance:     (*hexutil.Big)(stateDb.GetBalance(addr)),
+		Nonce:       hexutil.Uint64(stateDb.GetNonce(addr)),
+		CodeHash:    crypto.Keccak256Hash(nil),
+		StorageHash: types.EmptyRootHash,
+	}
+	if stateDb.Exist(addr) {
+		storageRoot, err := stateDb.GetStorageRoot(addr)
+		require.NoError(t, err)
+		res.StorageHash = storageRoot
+		res.CodeHash = stateDb.GetCodeHash(addr)
+	}
+	proof, err := stateDb.GetProof(addr)
+	require.NoError(t, err)
+	for _, node := range proof {
+		res.AccountProof = append(res.AccountProof, hexutil.Encode(node))
+	}
+	for _, key := range keys {
+		storageProof, err := stateDb.GetStorageProof(addr, key)
+		require.NoError(t, err)
+		slot := ethapi.StorageResult{
+			Key:   key.Hex(),
+			Value: (*hexutil.Big)(stateDb.GetState(addr, key).Big()),
+		}
+		for _, node := range storageProof {
+			slot.Proof = append(slot.Proof, hexutil.Encode(node))
+		}
+		res.StorageProof = append(res.StorageProof, slot)
+	}
+	return res
+}
+
+func TestCarmenStateDB_Proofs(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+	store := archiveStore(t)
+
+	var (
+		eoa      = common.Address{1}
+		contract = common.Address{2}
+		missing  = common.Address{3}
+		slot     = common.Hash{1}
+		empty    = common.Hash{2}
+	)
+
+	live := CreateCarmenStateDb(store.liveStateDb)
+	live.BeginBlock(1)
+	live.CreateAccount(eoa)
+	live.AddBalance(eoa, big.NewInt(1000))
+	live.SetNonce(eoa, 5)
+	live.CreateAccount(contract)
+	live.SetCode(contract, []byte{0x60, 0x00})
+	live.SetNonce(contract, 1)
+	live.SetState(contract, slot, common.Hash{31: 0x42})
+	live.Finalise()
+	live.EndBlock(1)
+	root, err := live.Commit(true)
+	require.NoError(err)
+
+	_, err = live.GetProof(eoa)
+	require.ErrorIs(err, ErrProofNotSupported)
+
+	waitForArchive(t, store, 1)
+	stateDb, err := store.GetRpcStateDb(big.NewInt(1), root)
+	require.NoError(err)
+	defer stateDb.Release()
+
+	res := proofResult(t, stateDb, eoa)
+	require.NoError(ethapi.VerifyProof(root, res))
+	require.Equal(types.EmptyRootHash, res.StorageHash)
+
+	res = proofResult(t, stateDb, contract, slot, empty)
+	require.NoError(ethapi.VerifyProof(root, res))
+	require.NotEqual(types.EmptyRootHash, res.StorageHash)
+
+	res = proofResult(t, stateDb, missing)
+	require.NoError(ethapi.VerifyProof(root, res))
+
+	// tampered results must not verify
+	res = proofResult(t, stateDb, eoa)
+	res.Balance = (*hexutil.Big)(big.NewInt(1001))
+	require.Error(ethapi.VerifyProof(root, res))
+
+	res = proofResult(t, stateDb, contract, slot)
+	res.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(1))
+	require.Error(ethapi.VerifyProof(root, res))
+
+	res = proofResult(t, stateDb, missing)
+	res.Nonce = 1
+	require.Error(ethapi.VerifyProof(root, res))
+
+	require.Error(ethapi.VerifyProof(common.Hash{1}, proofResult(t, stateDb, eoa)))
+}
+
+func TestCarmenStateDB_StateOverrides(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+	store := archiveStore(t)
+
+	var (
+		addr  = common.Address{1}
+		slotA = common.Hash{1}
+		slotB = common.Hash{2}
+	)
+
+	live := CreateCarmenStateDb(store.liveStateDb)
+	live.BeginBlock(1)
+	live.CreateAccount(addr)
+	live.AddBalance(addr, big.NewInt(1000))
+	live.SetNonce(addr, 5)
+	live.SetCode(addr, []byte{0x60, 0x00})
+	live.SetState(addr, slotA, common.Hash{1})
+	live.Finalise()
+	live.EndBlock(1)
+	root, err := live.Commit(true)
+	require.NoError(err)
+
+	waitForArchive(t, store, 1)
+	stateDb, err := store.GetRpcStateDb(big.NewInt(1), root)
+	require.NoError(err)
+	defer stateDb.Release()
+
+	stateDb.SetBalance(addr, big.NewInt(10))
+	require.Equal(big.NewInt(10), stateDb.GetBalance(addr))
+	stateDb.SetBalance(addr, big.NewInt(2000))
+	require.Equal(big.NewInt(2000), stateDb.GetBalance(addr))
+
+	stateDb.SetStorage(addr, map[common.Hash]common.Hash{slotB: {2}})
+	require.Equal(common.Hash{}, stateDb.GetState(addr, slotA))
+	require.Equal(common.Hash{2}, stateDb.GetState(addr, slotB))
+	require.Equal(uint64(5), stateDb.GetNonce(addr))
+	require.Equal([]byte{0x60, 0x00}, stateDb.GetCode(addr))
+	require.Equal(big.NewInt(2000), stateDb.GetBalance(addr))
+	require.NoError(stateDb.Error())
+}
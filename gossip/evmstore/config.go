@@ -31,6 +31,12 @@ type (
 		DisableLogsIndexing bool
 		// Disables storing of txs positions
 		DisableTxHashesIndexing bool
+		// Enables storing of txs traces for the trace_* API
+		EnableTracesIndexing bool
+		// Number of the latest blocks with queryable archive state (0 keeps all)
+		ArchiveVisibleBlocks uint64
+		// Number of the latest epochs with queryable archive state (0 keeps all)
+		ArchiveVisibleEpochs uint64
 	}
 )
 
@@ -0,0 +1,174 @@
+package evmstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	cc "github.com/Fantom-foundation/Carmen/go/common"
+	"github.com/Fantom-foundation/Carmen/go/common/amount"
+	"github.com/Fantom-foundation/Carmen/go/database/mpt"
+	io2 "github.com/Fantom-foundation/Carmen/go/database/mpt/io"
+	carmen "github.com/Fantom-foundation/Carmen/go/state"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+)
+
+// ExportStateDiffs passes the state changes of the blocks from..to, read from the archive, to f in ascending order.
+// The Store must be closed during the call.
+func (s *Store) ExportStateDiffs(ctx context.Context, from, to idx.Block, f func(genesis.StateDiff) error) error {
+	archiveDir := filepath.Join(s.parameters.Directory, "archive")
+	info, err := io2.CheckMptDirectoryAndGetInfo(archiveDir)
+	if err != nil {
+		return fmt.Errorf("failed to read carmen archive: %w", err)
+	}
+	if info.Mode != mpt.Immutable {
+		return fmt.Errorf("the database in the archive directory is not an archive")
+	}
+	archive, err := mpt.OpenArchiveTrie(archiveDir, info.Config, mpt.NodeCacheConfig{}, mpt.ArchiveConfig{})
+	if err != nil {
+		return fmt.Errorf("failed to open carmen archive: %w", err)
+	}
+	err = exportStateDiffs(ctx, archive, from, to, f)
+	return errors.Join(err, archive.Close())
+}
+
+func exportStateDiffs(ctx context.Context, archive *mpt.ArchiveTrie, from, to idx.Block, f func(genesis.StateDiff) error) error {
+	height, empty, err := archive.GetBlockHeight()
+	if err != nil {
+		return err
+	}
+	if empty || uint64(to) > height {
+		return fmt.Errorf("the block %d is above the archive block height %d", to, height)
+	}
+	for block := from; block <= to; block++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		diff, err := archive.GetDiffForBlock(uint64(block))
+		if err != nil {
+			return fmt.Errorf("failed to get changes of block %d: %w", block, err)
+		}
+		if err := f(makeStateDiff(archive, block, diff)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeStateDiff converts the archive diff of the block, the changes are sorted to make the export deterministic.
+func makeStateDiff(archive *mpt.ArchiveTrie, block idx.Block, diff mpt.Diff) genesis.StateDiff {
+	addrs := make([]cc.Address, 0, len(diff))
+	for addr := range diff {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+
+	res := genesis.StateDiff{Block: block}
+	for _, addr := range addrs {
+		account := diff[addr]
+		address := common.Address(addr)
+		if account.Reset {
+			res.Deleted = append(res.Deleted, address)
+		}
+		if account.Balance != nil {
+			res.Balances = append(res.Balances, genesis.BalanceDiff{Address: address, Balance: account.Balance.ToBig()})
+		}
+		if account.Nonce != nil {
+			res.Nonces = append(res.Nonces, genesis.NonceDiff{Address: address, Nonce: account.Nonce.ToUint64()})
+		}
+		if account.Code != nil {
+			res.Codes = append(res.Codes, genesis.CodeDiff{Address: address, Code: archive.GetCodeForHash(*account.Code)})
+		}
+		keys := make([]cc.Key, 0, len(account.Storage))
+		for key := range account.Storage {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i][:], keys[j][:]) < 0
+		})
+		for _, key := range keys {
+			res.Slots = append(res.Slots, genesis.SlotDiff{Address: address, Key: common.Hash(key), Value: common.Hash(account.Storage[key])})
+		}
+	}
+	return res
+}
+
+func stateDiffToUpdate(diff genesis.StateDiff) (cc.Update, error) {
+	update := cc.Update{}
+	for _, addr := range diff.Deleted {
+		update.AppendDeleteAccount(cc.Address(addr))
+	}
+	for _, b := range diff.Balances {
+		balance, err := amount.NewFromBigInt(b.Balance)
+		if err != nil {
+			return update, err
+		}
+		update.AppendBalanceUpdate(cc.Address(b.Address), balance)
+	}
+	for _, n := range diff.Nonces {
+		update.AppendNonceUpdate(cc.Address(n.Address), cc.ToNonce(n.Nonce))
+	}
+	for _, c := range diff.Codes {
+		update.AppendCodeUpdate(cc.Address(c.Address), c.Code)
+	}
+	for _, slot := range diff.Slots {
+		update.AppendSlotUpdate(cc.Address(slot.Address), cc.Key(slot.Key), cc.Value(slot.Value))
+	}
+	return update, update.Normalize()
+}
+
+// ApplyStateDiffs applies the state changes of the blocks from..to onto the live state, the diffs of other blocks are skipped.
+// The state hash after every block is passed to check, which stops the import if an error is returned.
+// The EvmStore has to be open.
+func (s *Store) ApplyStateDiffs(diffs genesis.StateDiffs, from, to idx.Block, check func(idx.Block, hash.Hash) error) (err error) {
+	if s.carmenState == nil {
+		return fmt.Errorf("unable to apply state diffs - EvmStore is not open")
+	}
+	// the live StateDB caches don't reflect the changes applied to the state directly
+	defer func() {
+		s.liveStateDb = carmen.CreateStateDBUsing(s.carmenState)
+	}()
+
+	next := from
+	diffs.ForEach(func(diff genesis.StateDiff) bool {
+		if diff.Block < next || diff.Block > to {
+			return true
+		}
+		if diff.Block != next {
+			err = fmt.Errorf("state changes of block %d are missing", next)
+			return false
+		}
+		update, err2 := stateDiffToUpdate(diff)
+		if err2 != nil {
+			err = fmt.Errorf("invalid state changes of block %d: %w", diff.Block, err2)
+			return false
+		}
+		if err = s.carmenState.Apply(uint64(diff.Block), update); err != nil {
+			err = fmt.Errorf("failed to apply state changes of block %d: %w", diff.Block, err)
+			return false
+		}
+		root, err2 := s.carmenState.GetHash()
+		if err2 != nil {
+			err = err2
+			return false
+		}
+		if err = check(diff.Block, hash.Hash(root)); err != nil {
+			return false
+		}
+		next++
+		return true
+	})
+	if err == nil && next <= to {
+		err = fmt.Errorf("state changes of block %d are missing", next)
+	}
+	return err
+}
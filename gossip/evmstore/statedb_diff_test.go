@@ -0,0 +1,121 @@
+package evmstore
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	carmen "github.com/Fantom-foundation/Carmen/go/state"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+)
+
+type testStateDiffs []genesis.StateDiff
+
+func (diffs testStateDiffs) ForEach(fn func(genesis.StateDiff) bool) {
+	for _, diff := range diffs {
+		if !fn(diff) {
+			return
+		}
+	}
+}
+
+func TestStateDiffs(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	cfg := LiteStoreConfig()
+	cfg.StateDb.Directory = t.TempDir()
+	store := NewStore(memorydb.New(), cfg)
+	require.NoError(store.Open())
+
+	var (
+		eoa       = common.Address{1}
+		contract  = common.Address{2}
+		destroyed = common.Address{3}
+	)
+	live := CreateCarmenStateDb(store.liveStateDb)
+	roots := make(map[idx.Block]hash.Hash)
+	for block := uint64(1); block <= 4; block++ {
+		live.BeginBlock(block)
+		switch block {
+		case 1:
+			live.CreateAccount(destroyed)
+			live.AddBalance(destroyed, big.NewInt(7))
+			live.SetState(destroyed, common.Hash{1}, common.Hash{31: 1})
+		case 2:
+			live.CreateAccount(eoa)
+			live.AddBalance(eoa, big.NewInt(1000))
+			live.CreateAccount(contract)
+			live.SetCode(contract, []byte{0x60, 0x00})
+			live.SetState(contract, common.Hash{1}, common.Hash{31: 1})
+			live.SetState(contract, common.Hash{2}, common.Hash{31: 2})
+		case 4:
+			live.SetNonce(eoa, 1)
+			live.SubBalance(eoa, big.NewInt(100))
+			live.SetState(contract, common.Hash{1}, common.Hash{})
+			live.Suicide(destroyed)
+		}
+		live.Finalise()
+		live.EndBlock(block)
+		root, err := live.Commit(true)
+		require.NoError(err)
+		roots[idx.Block(block)] = hash.Hash(root)
+	}
+	waitForArchive(t, store, 4)
+	require.NoError(store.Close())
+
+	var diffs testStateDiffs
+	require.NoError(store.ExportStateDiffs(context.Background(), 1, 4, func(diff genesis.StateDiff) error {
+		diffs = append(diffs, diff)
+		return nil
+	}))
+	require.Len(diffs, 4)
+	require.Empty(diffs[2].Balances)
+	require.Equal([]common.Address{destroyed}, diffs[3].Deleted)
+	require.Error(store.ExportStateDiffs(context.Background(), 1, 5, func(genesis.StateDiff) error { return nil }))
+
+	// the changes are applied onto another live state
+	cfg = LiteStoreConfig()
+	cfg.StateDb.Directory = t.TempDir()
+	cfg.StateDb.Archive = carmen.NoArchive
+	imported := NewStore(memorydb.New(), cfg)
+	require.NoError(imported.Open())
+	defer imported.Close()
+	var applied []idx.Block
+	require.NoError(imported.ApplyStateDiffs(diffs, 1, 2, func(block idx.Block, root hash.Hash) error {
+		require.Equal(roots[block], root)
+		applied = append(applied, block)
+		return nil
+	}))
+	require.NoError(imported.CheckLiveStateHash(2, roots[2]))
+
+	// the applied blocks are skipped, and the missing ones are reported
+	require.Error(imported.ApplyStateDiffs(diffs[:3], 3, 4, func(block idx.Block, root hash.Hash) error {
+		require.Equal(roots[block], root)
+		applied = append(applied, block)
+		return nil
+	}))
+	errMismatch := errors.New("mismatch")
+	require.ErrorIs(imported.ApplyStateDiffs(diffs, 4, 4, func(idx.Block, hash.Hash) error {
+		return errMismatch
+	}), errMismatch)
+	require.Equal([]idx.Block{1, 2, 3}, applied)
+
+	// the live StateDB reflects the applied changes
+	stateDb, err := imported.GetLiveStateDb(roots[4])
+	require.NoError(err)
+	require.Equal(big.NewInt(900), stateDb.GetBalance(eoa))
+	require.Equal(uint64(1), stateDb.GetNonce(eoa))
+	require.Equal([]byte{0x60, 0x00}, stateDb.GetCode(contract))
+	require.Equal(common.Hash{}, stateDb.GetState(contract, common.Hash{1}))
+	require.Equal(common.Hash{31: 2}, stateDb.GetState(contract, common.Hash{2}))
+	require.False(stateDb.Exist(destroyed))
+}
@@ -0,0 +1,177 @@
+package evmstore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	cc "github.com/Fantom-foundation/Carmen/go/common"
+	carmen "github.com/Fantom-foundation/Carmen/go/state"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/topicsdb"
+)
+
+// worldStateMagic is the prefix of the Fantom World State dump,
+// followed by the format version, and the 'H' tag with the hash type and the state root
+var worldStateMagic = []byte("Fantom-World-State")
+
+// ErrInvalidWorldState is returned if the World State data can't be imported or doesn't match the state root
+var ErrInvalidWorldState = errors.New("invalid world state data")
+
+// ExportStateSnapshot exports Fantom World State data of the given block from the archive.
+// It is the same as the live state genesis section at the block. Returns the state root.
+// The Store must be open, and the archive must be enabled.
+func (s *Store) ExportStateSnapshot(ctx context.Context, block idx.Block, out io.Writer) (hash.Hash, error) {
+	if s.carmenState == nil {
+		return hash.Hash{}, fmt.Errorf("unable to export state snapshot - EvmStore is not open")
+	}
+	archiveState, err := s.carmenState.GetArchiveState(uint64(block))
+	if err != nil {
+		return hash.Hash{}, fmt.Errorf("unable to get archive state: %w", err)
+	}
+	defer archiveState.Close()
+
+	root, err := archiveState.Export(ctx, out)
+	if err != nil {
+		return hash.Hash{}, fmt.Errorf("failed to export archive state of block %d; %w", block, err)
+	}
+	return hash.Hash(root), nil
+}
+
+// ReplaceWorldState replaces the live state and the archive by the World State data of the given block,
+// read from the file. The data is imported into a temporary directory and checked against the state root
+// before the current state is replaced, so the current state is kept if the data is invalid.
+// The Store must be open, and must not be used during the call.
+func (s *Store) ReplaceWorldState(path string, block idx.Block, root hash.Hash) error {
+	if s.liveStateDb == nil {
+		return fmt.Errorf("unable to replace world state - EvmStore is not open")
+	}
+	tmpDir, err := os.MkdirTemp(s.parameters.Directory, "opera-tmp-import-snapshot")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary dir for world state import; %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tmp := &Store{
+		parameters: s.parameters,
+		Instance:   s.Instance,
+	}
+	tmp.parameters.Directory = tmpDir
+	importFile := func(f func(r io.Reader) error) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return f(bufio.NewReaderSize(file, 1024*1024))
+	}
+	s.Log.Info("Importing World State snapshot", "block", block, "root", root)
+	if err := importFile(tmp.ImportLiveWorldState); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWorldState, err)
+	}
+	if err := importFile(func(r io.Reader) error {
+		return tmp.InitializeArchiveWorldState(r, uint64(block))
+	}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWorldState, err)
+	}
+
+	// check the imported state
+	tmpState, err := carmen.NewState(tmp.parameters)
+	if err != nil {
+		return fmt.Errorf("failed to open imported world state; %v", err)
+	}
+	stateHash, err := tmpState.GetHash()
+	if closeErr := tmpState.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get hash of imported world state; %v", err)
+	}
+	if cc.Hash(root) != stateHash {
+		return fmt.Errorf("%w: hash of the imported world state is incorrect: blockNum: %d expected: %x actual: %x", ErrInvalidWorldState, block, root, stateHash)
+	}
+
+	// replace the current state, the pruned archive of the current state is useless
+	s.stopArchivePruning()
+	if err := s.liveStateDb.Close(); err != nil {
+		return fmt.Errorf("failed to close State DB: %w", err)
+	}
+	s.carmenState = nil
+	s.liveStateDb = nil
+	s.prunable = nil
+	if s.table.ArchiveWindow != nil {
+		if err := s.dropArchivePruning(filepath.Join(s.parameters.Directory, "archive")); err != nil {
+			return err
+		}
+	}
+	for _, dir := range []string{"live", "archive"} {
+		if err := os.RemoveAll(filepath.Join(s.parameters.Directory, dir)); err != nil {
+			return err
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, dir)); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(filepath.Join(tmpDir, dir), filepath.Join(s.parameters.Directory, dir)); err != nil {
+			return err
+		}
+	}
+	if err := s.Open(); err != nil {
+		return err
+	}
+	if err := s.CheckLiveStateHash(block, root); err != nil {
+		return err
+	}
+	// the archive has no states before the block
+	if s.table.ArchiveWindow != nil {
+		if err := s.table.ArchiveWindow.Put(archiveBaseKey, block.Bytes()); err != nil {
+			return err
+		}
+	}
+	s.SetOldestArchiveBlock(block)
+	return nil
+}
+
+// NewLiveStateCopy creates a live state in the directory from the archive state of the given block.
+// Unlike the archive states, the copy is committable, so the state roots of the following blocks
+// can be recomputed on it. The returned Store has the live state only, it must be closed by the caller.
+func (s *Store) NewLiveStateCopy(ctx context.Context, block idx.Block, dir string) (*Store, error) {
+	if s.carmenState == nil {
+		return nil, fmt.Errorf("unable to copy live state - EvmStore is not open")
+	}
+	cp := &Store{
+		parameters: s.parameters,
+		EvmLogs:    topicsdb.NewDummy(),
+		Instance:   s.Instance,
+	}
+	cp.parameters.Directory = dir
+	cp.parameters.Archive = carmen.NoArchive
+
+	reader, writer := io.Pipe()
+	var exportErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bufWriter := bufio.NewWriterSize(writer, 1024*1024)
+		_, exportErr = s.ExportStateSnapshot(ctx, block, bufWriter)
+		if exportErr == nil {
+			exportErr = bufWriter.Flush()
+		}
+		writer.CloseWithError(exportErr)
+	}()
+	err := cp.ImportLiveWorldState(bufio.NewReaderSize(reader, 1024*1024))
+	reader.Close() // unblock the export if the import has failed
+	<-done
+	if err := errors.Join(err, exportErr); err != nil {
+		return nil, fmt.Errorf("failed to copy state of block %d: %w", block, err)
+	}
+	if err := cp.Open(); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
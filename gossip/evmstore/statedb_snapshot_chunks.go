@@ -0,0 +1,628 @@
+package evmstore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	cc "github.com/Fantom-foundation/Carmen/go/common"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	gstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/Fantom-foundation/go-opera/inter/state"
+)
+
+// SnapshotAccount is an account of the World State snapshot, along with the root of its storage,
+// so the account can be proven against the state root.
+type SnapshotAccount struct {
+	Address     common.Address
+	Nonce       uint64
+	Balance     *big.Int
+	CodeHash    common.Hash
+	StorageRoot common.Hash
+}
+
+// SnapshotSlot is a non-empty storage slot of the World State snapshot
+type SnapshotSlot struct {
+	Key   common.Hash
+	Value common.Hash
+}
+
+// SnapshotStorage is a range of the storage slots of an account, along with its Merkle range proof
+// against the storage root. The proof is empty if the range is the whole storage of the account.
+type SnapshotStorage struct {
+	Slots []SnapshotSlot
+	Proof [][]byte
+}
+
+// SnapshotChunk is a part of the World State snapshot, which is proven against the state root on its own
+// by the Merkle range proofs, given the end of the previous chunk. The accounts and the slots are ordered
+// by the hashes of the addresses and the keys, as the leaves of the tries.
+type SnapshotChunk struct {
+	// Continued continues the storage of the last account of the previous chunk, if it's incomplete
+	Continued *SnapshotStorage `rlp:"nil"`
+	Accounts  []SnapshotAccount
+	// AccountProof is the Merkle range proof of the Accounts, it's empty if the Accounts are the whole state
+	AccountProof [][]byte
+	// Storage are the storage ranges of the Accounts with a non-empty storage root, in order.
+	// The storage of the last account may be left for the next chunk.
+	Storage []SnapshotStorage
+	// Codes are the codes of the Accounts, which aren't sent in the previous chunks
+	Codes [][]byte
+}
+
+const (
+	snapshotAccountSize = 1 + common.AddressLength + 32 + 8 + 2*common.HashLength
+	snapshotSlotSize    = 1 + 2*common.HashLength
+)
+
+var errInvalidSnapshotChunk = errors.New("invalid snapshot chunk")
+
+// ExportStateSnapshotChunks exports the World State of the given block from the archive as the chunks
+// of about the given size, along with their Merkle proofs. Returns the state root.
+// The Store must be open, and the archive must be enabled.
+func (s *Store) ExportStateSnapshotChunks(ctx context.Context, block idx.Block, size int, f func(c *SnapshotChunk) error) (hash.Hash, error) {
+	if s.liveStateDb == nil {
+		return hash.Hash{}, fmt.Errorf("unable to export state snapshot - EvmStore is not open")
+	}
+	archiveDb, err := s.liveStateDb.GetArchiveStateDB(uint64(block))
+	if err != nil {
+		return hash.Hash{}, fmt.Errorf("unable to get archive state: %w", err)
+	}
+	stateDb := CreateCarmenStateDb(archiveDb)
+	defer stateDb.Release()
+
+	reader, writer := io.Pipe()
+	var (
+		root      hash.Hash
+		exportErr error
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bufWriter := bufio.NewWriterSize(writer, 1024*1024)
+		root, exportErr = s.ExportStateSnapshot(ctx, block, bufWriter)
+		if exportErr == nil {
+			exportErr = bufWriter.Flush()
+		}
+		writer.CloseWithError(exportErr)
+	}()
+	chunker := &snapshotChunker{
+		state: stateDb,
+		size:  size,
+		f:     f,
+		codes: make(map[common.Hash]struct{}),
+	}
+	err = readWorldState(bufio.NewReaderSize(reader, 1024*1024), chunker.addAccount, chunker.addSlot)
+	if err == nil {
+		err = chunker.finish()
+	}
+	reader.CloseWithError(err) // unblock the export if the chunking has failed
+	<-done
+	if err := errors.Join(err, exportErr); err != nil {
+		return hash.Hash{}, fmt.Errorf("failed to export state snapshot chunks of block %d: %w", block, err)
+	}
+	return root, nil
+}
+
+// readWorldState reads the accounts and the storage slots of the World State dump, skipping the codes.
+func readWorldState(in io.Reader, onAccount func(acc SnapshotAccount) error, onSlot func(slot SnapshotSlot) error) error {
+	header := make([]byte, len(worldStateMagic)+1)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return err
+	}
+	if !bytes.Equal(header[:len(worldStateMagic)], worldStateMagic) {
+		return errors.New("not a world state dump")
+	}
+	var (
+		tag      [1]byte
+		balance  [32]byte
+		nonce    cc.Nonce
+		codeLen  [2]byte
+		slot     SnapshotSlot
+		stateTag [1 + common.HashLength]byte
+	)
+	for {
+		if _, err := io.ReadFull(in, tag[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch tag[0] {
+		case 'H':
+			if _, err := io.ReadFull(in, stateTag[:]); err != nil {
+				return err
+			}
+		case 'C':
+			if _, err := io.ReadFull(in, codeLen[:]); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(io.Discard, in, int64(binary.BigEndian.Uint16(codeLen[:]))); err != nil {
+				return err
+			}
+		case 'A':
+			acc := SnapshotAccount{StorageRoot: types.EmptyRootHash}
+			if _, err := io.ReadFull(in, acc.Address[:]); err != nil {
+				return err
+			}
+			if _, err := io.ReadFull(in, balance[:]); err != nil {
+				return err
+			}
+			if _, err := io.ReadFull(in, nonce[:]); err != nil {
+				return err
+			}
+			if _, err := io.ReadFull(in, acc.CodeHash[:]); err != nil {
+				return err
+			}
+			acc.Balance = new(big.Int).SetBytes(balance[:])
+			acc.Nonce = nonce.ToUint64()
+			if err := onAccount(acc); err != nil {
+				return err
+			}
+		case 'S':
+			if _, err := io.ReadFull(in, slot.Key[:]); err != nil {
+				return err
+			}
+			if _, err := io.ReadFull(in, slot.Value[:]); err != nil {
+				return err
+			}
+			if err := onSlot(slot); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected world state entry %c", tag[0])
+		}
+	}
+}
+
+// snapshotChunker splits the accounts and the slots of the World State dump into the chunks,
+// and makes the Merkle range proofs of the chunks from the state.
+type snapshotChunker struct {
+	state state.StateDB
+	size  int
+	f     func(c *SnapshotChunk) error
+	// codes are the hashes of the codes sent in the chunks
+	codes map[common.Hash]struct{}
+
+	chunk     SnapshotChunk
+	chunkSize int
+	// prev is the last account of the previous chunks
+	prev *common.Address
+	// account is the last account, slots are its slots in the current chunk,
+	// and prevSlot is its last slot in the previous chunks
+	account        *SnapshotAccount
+	accountInChunk bool
+	slots          []SnapshotSlot
+	prevSlot       *common.Hash
+}
+
+func (c *snapshotChunker) addAccount(acc SnapshotAccount) error {
+	if err := c.closeStorage(true); err != nil {
+		return err
+	}
+	if c.chunkSize >= c.size {
+		if err := c.flush(false); err != nil {
+			return err
+		}
+	}
+	c.chunk.Accounts = append(c.chunk.Accounts, acc)
+	c.chunkSize += snapshotAccountSize
+	c.account = &acc
+	c.accountInChunk = true
+	c.prevSlot = nil
+	if _, ok := c.codes[acc.CodeHash]; !ok && !bytes.Equal(acc.CodeHash[:], emptyCodeHash) {
+		code := c.state.GetCode(acc.Address)
+		if crypto.Keccak256Hash(code) != acc.CodeHash {
+			return fmt.Errorf("missing code of account %x", acc.Address)
+		}
+		c.chunk.Codes = append(c.chunk.Codes, code)
+		c.chunkSize += len(code)
+		c.codes[acc.CodeHash] = struct{}{}
+	}
+	return nil
+}
+
+func (c *snapshotChunker) addSlot(slot SnapshotSlot) error {
+	if c.account == nil {
+		return errors.New("storage slot without an account")
+	}
+	if len(c.slots) == 0 && c.prevSlot == nil {
+		// the first slot of the account, which is in the current chunk
+		root, err := c.state.GetStorageRoot(c.account.Address)
+		if err != nil {
+			return err
+		}
+		c.account.StorageRoot = root
+		c.chunk.Accounts[len(c.chunk.Accounts)-1].StorageRoot = root
+	}
+	if c.chunkSize >= c.size {
+		if err := c.closeStorage(false); err != nil {
+			return err
+		}
+		if err := c.flush(false); err != nil {
+			return err
+		}
+	}
+	c.slots = append(c.slots, slot)
+	c.chunkSize += snapshotSlotSize
+	return nil
+}
+
+// closeStorage adds the storage range of the slots of the last account in the current chunk.
+func (c *snapshotChunker) closeStorage(complete bool) error {
+	if c.account == nil || len(c.slots) == 0 {
+		return nil
+	}
+	r := SnapshotStorage{Slots: c.slots}
+	if c.prevSlot != nil || !complete {
+		keys := []common.Hash{c.slots[0].Key, c.slots[len(c.slots)-1].Key}
+		if c.prevSlot != nil {
+			keys = append(keys, *c.prevSlot)
+		}
+		nodes := newProofNodes()
+		for _, key := range keys {
+			proof, err := c.state.GetStorageProof(c.account.Address, key)
+			if err != nil {
+				return err
+			}
+			nodes.add(proof)
+		}
+		r.Proof = nodes.list
+	}
+	if c.accountInChunk {
+		c.chunk.Storage = append(c.chunk.Storage, r)
+	} else {
+		c.chunk.Continued = &r
+	}
+	if !complete {
+		key := c.slots[len(c.slots)-1].Key
+		c.prevSlot = &key
+	}
+	c.slots = nil
+	return nil
+}
+
+func (c *snapshotChunker) flush(last bool) error {
+	if n := len(c.chunk.Accounts); n != 0 {
+		if c.prev != nil || !last {
+			addrs := []common.Address{c.chunk.Accounts[0].Address, c.chunk.Accounts[n-1].Address}
+			if c.prev != nil {
+				addrs = append(addrs, *c.prev)
+			}
+			nodes := newProofNodes()
+			for _, addr := range addrs {
+				proof, err := c.state.GetProof(addr)
+				if err != nil {
+					return err
+				}
+				nodes.add(proof)
+			}
+			c.chunk.AccountProof = nodes.list
+		}
+		prev := c.chunk.Accounts[n-1].Address
+		c.prev = &prev
+	}
+	if err := c.f(&c.chunk); err != nil {
+		return err
+	}
+	c.chunk = SnapshotChunk{}
+	c.chunkSize = 0
+	c.accountInChunk = false
+	return nil
+}
+
+func (c *snapshotChunker) finish() error {
+	if err := c.closeStorage(true); err != nil {
+		return err
+	}
+	return c.flush(true)
+}
+
+// proofNodes is a set of the MPT nodes of the Merkle proofs
+type proofNodes struct {
+	seen map[common.Hash]struct{}
+	list [][]byte
+}
+
+func newProofNodes() *proofNodes {
+	return &proofNodes{seen: make(map[common.Hash]struct{})}
+}
+
+func (p *proofNodes) add(nodes [][]byte) {
+	for _, node := range nodes {
+		h := crypto.Keccak256Hash(node)
+		if _, ok := p.seen[h]; !ok {
+			p.seen[h] = struct{}{}
+			p.list = append(p.list, node)
+		}
+	}
+}
+
+// SnapshotVerifier verifies the chunks of the World State snapshot against the state root, in order.
+type SnapshotVerifier struct {
+	root common.Hash
+	// origin is the lowest hashed address of the next accounts, nil once all the accounts are verified
+	origin []byte
+	// pending is the account, whose storage is incomplete, slotOrigin is the lowest hashed key of its next slots
+	pending    *SnapshotAccount
+	slotOrigin []byte
+	// codes are the hashes of the verified codes
+	codes map[common.Hash]struct{}
+}
+
+// NewSnapshotVerifier returns the verifier of the snapshot chunks against the state root.
+func NewSnapshotVerifier(root hash.Hash) *SnapshotVerifier {
+	return &SnapshotVerifier{
+		root:   common.Hash(root),
+		origin: make([]byte, common.HashLength),
+		codes: map[common.Hash]struct{}{
+			common.BytesToHash(emptyCodeHash): {},
+		},
+	}
+}
+
+// Complete returns true if the whole snapshot is verified.
+func (v *SnapshotVerifier) Complete() bool {
+	return v.origin == nil && v.pending == nil
+}
+
+// Verify checks the chunk, which follows the previously verified chunks.
+// The verifier isn't changed if the chunk is invalid.
+func (v *SnapshotVerifier) Verify(c *SnapshotChunk) error {
+	if v.Complete() {
+		return fmt.Errorf("%w: the snapshot is complete", errInvalidSnapshotChunk)
+	}
+	var (
+		origin     = v.origin
+		pending    = v.pending
+		slotOrigin = v.slotOrigin
+	)
+	if (pending != nil) != (c.Continued != nil) {
+		return fmt.Errorf("%w: unexpected storage continuation", errInvalidSnapshotChunk)
+	}
+	if pending != nil {
+		next, err := verifyStorageRange(pending.StorageRoot, slotOrigin, c.Continued)
+		if err != nil {
+			return err
+		}
+		if next != nil {
+			if len(c.Accounts) != 0 || len(c.Storage) != 0 || len(c.Codes) != 0 {
+				return fmt.Errorf("%w: accounts after an incomplete storage", errInvalidSnapshotChunk)
+			}
+			v.slotOrigin = next
+			return nil
+		}
+		pending, slotOrigin = nil, nil
+	}
+	if origin == nil || (c.Continued != nil && len(c.Accounts) == 0) {
+		// the chunk completes the storage of the previous chunk
+		if len(c.Accounts) != 0 || len(c.AccountProof) != 0 || len(c.Storage) != 0 || len(c.Codes) != 0 {
+			return fmt.Errorf("%w: accounts after the last account", errInvalidSnapshotChunk)
+		}
+		v.pending, v.slotOrigin = nil, nil
+		return nil
+	}
+
+	// accounts
+	keys := make([][]byte, len(c.Accounts))
+	values := make([][]byte, len(c.Accounts))
+	for i, acc := range c.Accounts {
+		if acc.Balance == nil {
+			return fmt.Errorf("%w: no balance", errInvalidSnapshotChunk)
+		}
+		value, err := rlp.EncodeToBytes(&gstate.Account{
+			Nonce:    acc.Nonce,
+			Balance:  acc.Balance,
+			Root:     acc.StorageRoot,
+			CodeHash: acc.CodeHash.Bytes(),
+		})
+		if err != nil {
+			return err
+		}
+		keys[i] = crypto.Keccak256(acc.Address[:])
+		values[i] = value
+	}
+	more, err := verifyRange(v.root, origin, keys, values, c.AccountProof)
+	if err != nil {
+		return err
+	}
+	if more {
+		origin = nextKey(keys[len(keys)-1])
+	} else {
+		origin = nil
+	}
+
+	// storage
+	storage := c.Storage
+	for i := range c.Accounts {
+		acc := &c.Accounts[i]
+		if acc.StorageRoot == types.EmptyRootHash {
+			continue
+		}
+		if pending != nil {
+			return fmt.Errorf("%w: account after an incomplete storage", errInvalidSnapshotChunk)
+		}
+		if len(storage) == 0 {
+			// the storage of the last account is left for the next chunk
+			pending, slotOrigin = acc, make([]byte, common.HashLength)
+			continue
+		}
+		next, err := verifyStorageRange(acc.StorageRoot, make([]byte, common.HashLength), &storage[0])
+		if err != nil {
+			return err
+		}
+		storage = storage[1:]
+		if next != nil {
+			pending, slotOrigin = acc, next
+		}
+	}
+	if len(storage) != 0 {
+		return fmt.Errorf("%w: storage without an account", errInvalidSnapshotChunk)
+	}
+	if pending != nil && pending != &c.Accounts[len(c.Accounts)-1] {
+		return fmt.Errorf("%w: account after an incomplete storage", errInvalidSnapshotChunk)
+	}
+
+	// codes
+	missing := make(map[common.Hash]struct{})
+	for _, acc := range c.Accounts {
+		if _, ok := v.codes[acc.CodeHash]; !ok {
+			missing[acc.CodeHash] = struct{}{}
+		}
+	}
+	for _, code := range c.Codes {
+		h := crypto.Keccak256Hash(code)
+		if _, ok := missing[h]; !ok {
+			return fmt.Errorf("%w: unexpected code %s", errInvalidSnapshotChunk, h)
+		}
+		delete(missing, h)
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("%w: missing code", errInvalidSnapshotChunk)
+	}
+	for _, code := range c.Codes {
+		v.codes[crypto.Keccak256Hash(code)] = struct{}{}
+	}
+	if pending != nil {
+		acc := *pending
+		pending = &acc
+	}
+	v.origin, v.pending, v.slotOrigin = origin, pending, slotOrigin
+	return nil
+}
+
+// verifyStorageRange verifies the range of the storage slots, starting from the origin, against the storage root.
+// Returns the lowest hashed key of the following slots, or nil if the storage is complete.
+func verifyStorageRange(root common.Hash, origin []byte, r *SnapshotStorage) ([]byte, error) {
+	keys := make([][]byte, len(r.Slots))
+	values := make([][]byte, len(r.Slots))
+	for i, slot := range r.Slots {
+		value, err := rlp.EncodeToBytes(bytes.TrimLeft(slot.Value[:], "\x00"))
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = crypto.Keccak256(slot.Key[:])
+		values[i] = value
+	}
+	more, err := verifyRange(root, origin, keys, values, r.Proof)
+	if err != nil || !more {
+		return nil, err
+	}
+	return nextKey(keys[len(keys)-1]), nil
+}
+
+// verifyRange verifies the range of the trie leaves, starting from the origin, against the root.
+// Returns true if the trie has more leaves after the range.
+func verifyRange(root common.Hash, origin []byte, keys, values [][]byte, proof [][]byte) (more bool, err error) {
+	if len(keys) != 0 && bytes.Compare(keys[0], origin) < 0 {
+		return false, fmt.Errorf("%w: range below the origin", errInvalidSnapshotChunk)
+	}
+	defer func() {
+		// the prover panics on some malformed proofs
+		if r := recover(); r != nil {
+			more, err = false, fmt.Errorf("%w: %v", errInvalidSnapshotChunk, r)
+		}
+	}()
+	if len(proof) == 0 {
+		// the range is the whole trie
+		more, err = trie.VerifyRangeProof(root, origin, nil, keys, values, nil)
+	} else {
+		db := memorydb.New()
+		for _, node := range proof {
+			if err := db.Put(crypto.Keccak256(node), node); err != nil {
+				return false, err
+			}
+		}
+		last := origin
+		if len(keys) != 0 {
+			last = keys[len(keys)-1]
+		}
+		more, err = trie.VerifyRangeProof(root, origin, last, keys, values, db)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", errInvalidSnapshotChunk, err)
+	}
+	return more, nil
+}
+
+// nextKey returns the key following the given one, or nil if there is no following key.
+func nextKey(key []byte) []byte {
+	next := common.CopyBytes(key)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next
+		}
+	}
+	return nil
+}
+
+// WriteWorldState writes the World State dump of the state root from the verified snapshot chunks.
+// The chunks are iterated twice, as the codes precede the accounts in the dump.
+func WriteWorldState(out io.Writer, root hash.Hash, iterate func(f func(c *SnapshotChunk) error) error) error {
+	header := append(append([]byte{}, worldStateMagic...), 1, 'H', 0)
+	if _, err := out.Write(append(header, root.Bytes()...)); err != nil {
+		return err
+	}
+	err := iterate(func(c *SnapshotChunk) error {
+		for _, code := range c.Codes {
+			if _, err := out.Write([]byte{'C', byte(len(code) >> 8), byte(len(code))}); err != nil {
+				return err
+			}
+			if _, err := out.Write(code); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	writeSlots := func(r *SnapshotStorage) error {
+		for _, slot := range r.Slots {
+			if _, err := out.Write(append(append([]byte{'S'}, slot.Key[:]...), slot.Value[:]...)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return iterate(func(c *SnapshotChunk) error {
+		if c.Continued != nil {
+			if err := writeSlots(c.Continued); err != nil {
+				return err
+			}
+		}
+		storage := c.Storage
+		for _, acc := range c.Accounts {
+			var balance [32]byte
+			nonce := cc.ToNonce(acc.Nonce)
+			entry := append([]byte{'A'}, acc.Address[:]...)
+			entry = append(entry, acc.Balance.FillBytes(balance[:])...)
+			entry = append(entry, nonce[:]...)
+			entry = append(entry, acc.CodeHash[:]...)
+			if _, err := out.Write(entry); err != nil {
+				return err
+			}
+			if acc.StorageRoot != types.EmptyRootHash && len(storage) != 0 {
+				if err := writeSlots(&storage[0]); err != nil {
+					return err
+				}
+				storage = storage[1:]
+			}
+		}
+		return nil
+	})
+}
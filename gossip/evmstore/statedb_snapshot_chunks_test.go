@@ -0,0 +1,175 @@
+package evmstore
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	carmen "github.com/Fantom-foundation/Carmen/go/state"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+func snapshotStore(t *testing.T) (*Store, hash.Hash) {
+	store := archiveStore(t)
+	live := CreateCarmenStateDb(store.liveStateDb)
+	live.BeginBlock(1)
+	for i := 1; i <= 20; i++ {
+		addr := common.Address{byte(i)}
+		live.CreateAccount(addr)
+		live.AddBalance(addr, big.NewInt(int64(1000*i)))
+		live.SetNonce(addr, uint64(i))
+		if i%4 == 0 {
+			// the contracts share the codes
+			live.SetCode(addr, []byte{0x60, byte(i % 8)})
+			for j := 1; j <= 3*i; j++ {
+				live.SetState(addr, common.Hash{byte(j)}, common.Hash{31: byte(i + j)})
+			}
+		}
+	}
+	live.Finalise()
+	live.EndBlock(1)
+	root, err := live.Commit(true)
+	require.NoError(t, err)
+	waitForArchive(t, store, 1)
+	return store, hash.Hash(root)
+}
+
+func exportSnapshotChunks(t *testing.T, store *Store, size int) []SnapshotChunk {
+	var chunks []SnapshotChunk
+	_, err := store.ExportStateSnapshotChunks(context.Background(), 1, size, func(c *SnapshotChunk) error {
+		// the chunks are sent over the network
+		b, err := rlp.EncodeToBytes(c)
+		require.NoError(t, err)
+		var chunk SnapshotChunk
+		require.NoError(t, rlp.DecodeBytes(b, &chunk))
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	require.NoError(t, err)
+	return chunks
+}
+
+func TestSnapshotChunks(t *testing.T) {
+	logger.SetTestMode(t)
+	store, root := snapshotStore(t)
+
+	for _, size := range []int{1, 300, 1000, 1 << 20} {
+		chunks := exportSnapshotChunks(t, store, size)
+		if size == 1<<20 {
+			require.Len(t, chunks, 1)
+			require.Empty(t, chunks[0].AccountProof)
+		}
+
+		v := NewSnapshotVerifier(root)
+		for i := range chunks {
+			require.False(t, v.Complete())
+			require.NoError(t, v.Verify(&chunks[i]), "size %d, chunk %d", size, i)
+		}
+		require.True(t, v.Complete())
+		require.Error(t, v.Verify(&SnapshotChunk{}))
+
+		// the World State assembled from the chunks is imported
+		var dump bytes.Buffer
+		require.NoError(t, WriteWorldState(&dump, root, func(f func(c *SnapshotChunk) error) error {
+			for i := range chunks {
+				if err := f(&chunks[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+		imported := NewStore(memorydb.New(), LiteStoreConfig())
+		imported.parameters.Directory = filepath.Join(t.TempDir(), "imported")
+		imported.parameters.Archive = carmen.NoArchive
+		require.NoError(t, imported.ImportLiveWorldState(&dump))
+		require.NoError(t, imported.Open())
+		require.NoError(t, imported.CheckLiveStateHash(1, root))
+		require.NoError(t, imported.Close())
+	}
+}
+
+func TestSnapshotChunksInvalid(t *testing.T) {
+	logger.SetTestMode(t)
+	store, root := snapshotStore(t)
+	chunks := exportSnapshotChunks(t, store, 300)
+	require.Greater(t, len(chunks), 3)
+
+	// verifyFrom verifies the chunks before the i-th one, and returns the error of the modified i-th chunk
+	verifyFrom := func(i int, modify func(c *SnapshotChunk)) error {
+		v := NewSnapshotVerifier(root)
+		for j := 0; j < i; j++ {
+			require.NoError(t, v.Verify(&chunks[j]))
+		}
+		b, err := rlp.EncodeToBytes(&chunks[i])
+		require.NoError(t, err)
+		var c SnapshotChunk
+		require.NoError(t, rlp.DecodeBytes(b, &c))
+		modify(&c)
+		err = v.Verify(&c)
+		if err == nil {
+			return nil
+		}
+		require.ErrorIs(t, err, errInvalidSnapshotChunk)
+		// the verifier isn't changed by the invalid chunk
+		require.NoError(t, v.Verify(&chunks[i]))
+		return err
+	}
+
+	for i, c := range chunks {
+		require.NoError(t, verifyFrom(i, func(*SnapshotChunk) {}))
+		if len(c.Accounts) != 0 {
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				c.Accounts[0].Balance.Add(c.Accounts[0].Balance, big.NewInt(1))
+			}), "chunk %d", i)
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				c.Accounts = c.Accounts[1:]
+			}), "chunk %d", i)
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				c.AccountProof = nil
+			}), "chunk %d", i)
+		}
+		if i+1 < len(chunks) && len(chunks[i+1].Accounts) != 0 {
+			// a chunk can't be skipped
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				*c = chunks[i+1]
+			}), "chunk %d", i)
+		}
+		if len(c.Codes) != 0 {
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				c.Codes = nil
+			}), "chunk %d", i)
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				c.Codes[0] = append(c.Codes[0], 0)
+			}), "chunk %d", i)
+		}
+		if len(c.Storage) != 0 {
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				s := &c.Storage[0]
+				s.Slots[len(s.Slots)-1].Value[0]++
+			}), "chunk %d", i)
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				s := &c.Storage[0]
+				s.Slots = s.Slots[:len(s.Slots)-1]
+			}), "chunk %d", i)
+		}
+		if c.Continued != nil {
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				c.Continued.Slots = c.Continued.Slots[1:]
+			}), "chunk %d", i)
+			require.Error(t, verifyFrom(i, func(c *SnapshotChunk) {
+				c.Continued = nil
+			}), "chunk %d", i)
+		}
+	}
+
+	// the chunks of another state are rejected
+	require.Error(t, NewSnapshotVerifier(hash.Hash{1}).Verify(&chunks[0]))
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 )
 
 const nominalSize uint = 1
@@ -26,6 +28,14 @@ type Store struct {
 		Receipts    kvdb.Store `table:"r"`
 		TxPositions kvdb.Store `table:"x"`
 		Txs         kvdb.Store `table:"X"`
+
+		// Trace API tables
+		Traces         kvdb.Store `table:"y"`
+		TracedBlocks   kvdb.Store `table:"z"`
+		TraceAddresses kvdb.Store `table:"Y"`
+
+		// Archive window tables
+		ArchiveWindow kvdb.Store `table:"W"`
 	}
 
 	EvmLogs  topicsdb.Index
@@ -43,6 +53,11 @@ type Store struct {
 	parameters carmen.Parameters
 	carmenState carmen.State
 	liveStateDb carmen.StateDB
+	prunable    *prunableState
+
+	// oldest block with queryable archive state, older states are hidden by the archive window
+	oldestArchiveBlock   atomic.Uint64
+	oldestArchiveBlockMu sync.Mutex
 }
 
 // NewStore creates store over key-value db.
@@ -56,6 +71,7 @@ func NewStore(mainDB kvdb.Store, cfg StoreConfig) *Store {
 	}
 
 	table.MigrateTables(&s.table, s.mainDB)
+	s.loadOldestArchiveBlock()
 
 	if cfg.DisableLogsIndexing {
 		s.EvmLogs = topicsdb.NewDummy()
@@ -73,16 +89,23 @@ func (s *Store) Open() error {
 	if err != nil {
 		return err
 	}
-	s.carmenState, err = carmen.NewState(s.parameters)
+	state, err := carmen.NewState(s.parameters)
 	if err != nil {
 		return fmt.Errorf("failed to create carmen state; %s", err)
 	}
+	s.prunable = &prunableState{State: state}
+	if height, empty, err := state.GetArchiveBlockHeight(); err == nil && !empty {
+		s.prunable.lastBlock = height
+	}
+	s.carmenState = s.prunable
 	s.liveStateDb = carmen.CreateStateDBUsing(s.carmenState)
+	s.resumeArchivePruning()
 	return nil
 }
 
 // Close closes underlying database.
 func (s *Store) Close() error {
+	s.stopArchivePruning()
 	// set all table/cache fields to nil
 	table.MigrateTables(&s.table, nil)
 	table.MigrateCaches(&s.cache, func() interface{} {
@@ -99,6 +122,7 @@ func (s *Store) Close() error {
 		s.Log.Info("State DB closed")
 		s.carmenState = nil
 		s.liveStateDb = nil
+		s.prunable = nil
 	}
 	return nil
 }
@@ -136,13 +160,20 @@ func (s *Store) initCarmen() error {
 	if err != nil {
 		return fmt.Errorf("failed to create carmen dir \"%s\"; %v", params.Directory, err)
 	}
+	// complete the archive pruning before the archive is checked
+	archiveDir := filepath.Join(params.Directory, "archive")
+	if err := recoverArchiveReplace(archiveDir); err != nil {
+		return err
+	}
+	if err := s.finishArchivePruning(archiveDir); err != nil {
+		return fmt.Errorf("failed to finish archive pruning; %w", err)
+	}
 	if s.cfg.SkipArchiveCheck {
 		return nil // skip the following check (like for verification)
 	}
 	liveDir := filepath.Join(params.Directory, "live")
 	liveInfo, err := os.Stat(liveDir)
 	liveExists := err == nil && liveInfo.IsDir()
-	archiveDir := filepath.Join(params.Directory, "archive")
 	archiveInfo, err := os.Stat(archiveDir)
 	archiveExists := err == nil && archiveInfo.IsDir()
 
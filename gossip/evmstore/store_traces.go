@@ -0,0 +1,187 @@
+package evmstore
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Fantom-foundation/go-opera/txtrace"
+)
+
+const (
+	traceFromAddress byte = iota
+	traceToAddress
+)
+
+// TracesIndexingEnabled returns true if the transaction traces are supposed to be stored during the block processing.
+func (s *Store) TracesIndexingEnabled() bool {
+	return s.cfg.EnableTracesIndexing
+}
+
+// SetTraces stores the transaction traces of the block
+// and indexes the block by from/to addresses of the traces.
+func (s *Store) SetTraces(n idx.Block, traces []txtrace.ActionTrace) {
+	if traces == nil {
+		traces = []txtrace.ActionTrace{}
+	}
+	buf, err := json.Marshal(traces)
+	if err != nil {
+		s.Log.Crit("Failed to encode traces", "err", err)
+	}
+	if err := s.table.Traces.Put(n.Bytes(), buf); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+	for _, trace := range traces {
+		if trace.Action == nil {
+			continue
+		}
+		if trace.Action.From != nil {
+			s.indexTraceAddress(*trace.Action.From, traceFromAddress, n)
+		}
+		if trace.Action.To != nil {
+			s.indexTraceAddress(*trace.Action.To, traceToAddress, n)
+		}
+	}
+	if err := s.table.TracedBlocks.Put(n.Bytes(), []byte{}); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// GetTraces returns the stored transaction traces of the block.
+// The returned bool is false if traces of the block are not stored.
+func (s *Store) GetTraces(n idx.Block) ([]txtrace.ActionTrace, bool) {
+	buf, err := s.table.Traces.Get(n.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return nil, false
+	}
+	var traces []txtrace.ActionTrace
+	if err := json.Unmarshal(buf, &traces); err != nil {
+		s.Log.Crit("Failed to decode traces", "err", err, "size", len(buf))
+	}
+	return traces, true
+}
+
+// HasTraces returns true if the transaction traces of the block are stored.
+func (s *Store) HasTraces(n idx.Block) bool {
+	ok, err := s.table.TracedBlocks.Has(n.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	return ok
+}
+
+// FindTraceBlocks returns ordered blocks of the range, which contain traces
+// from one of fromAddresses and to one of toAddresses. Empty addresses list matches any address.
+// The returned bool is false if traces of some blocks in the range are not stored,
+// as the result would be incomplete.
+func (s *Store) FindTraceBlocks(ctx context.Context, from, to idx.Block, fromAddresses, toAddresses []common.Address) ([]idx.Block, bool, error) {
+	if from == 0 {
+		from = 1 // genesis block is not traceable
+	}
+	if to < from {
+		return []idx.Block{}, true, nil
+	}
+	all, err := s.tracedBlocks(ctx, from, to)
+	if err != nil {
+		return nil, false, err
+	}
+	if idx.Block(len(all)) != to-from+1 {
+		return nil, false, nil
+	}
+	if len(fromAddresses) == 0 && len(toAddresses) == 0 {
+		return all, true, nil
+	}
+
+	var matched map[idx.Block]struct{}
+	if len(fromAddresses) != 0 {
+		matched, err = s.addressesTraceBlocks(ctx, fromAddresses, traceFromAddress, from, to)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if len(toAddresses) != 0 {
+		toMatched, err := s.addressesTraceBlocks(ctx, toAddresses, traceToAddress, from, to)
+		if err != nil {
+			return nil, false, err
+		}
+		if matched == nil {
+			matched = toMatched
+		} else {
+			for n := range matched {
+				if _, ok := toMatched[n]; !ok {
+					delete(matched, n)
+				}
+			}
+		}
+	}
+
+	blocks := make([]idx.Block, 0, len(matched))
+	for n := range matched {
+		blocks = append(blocks, n)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i] < blocks[j]
+	})
+	return blocks, true, nil
+}
+
+func (s *Store) indexTraceAddress(addr common.Address, kind byte, n idx.Block) {
+	if err := s.table.TraceAddresses.Put(traceAddressKey(addr, kind, n), []byte{}); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+func (s *Store) tracedBlocks(ctx context.Context, from, to idx.Block) ([]idx.Block, error) {
+	it := s.table.TracedBlocks.NewIterator(nil, from.Bytes())
+	defer it.Release()
+	blocks := make([]idx.Block, 0)
+	for it.Next() {
+		n := idx.BytesToBlock(it.Key())
+		if n > to {
+			break
+		}
+		blocks = append(blocks, n)
+		if len(blocks)%1000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return blocks, it.Error()
+}
+
+func (s *Store) addressesTraceBlocks(ctx context.Context, addresses []common.Address, kind byte, from, to idx.Block) (map[idx.Block]struct{}, error) {
+	blocks := make(map[idx.Block]struct{})
+	for _, addr := range addresses {
+		prefix := append(addr.Bytes(), kind)
+		it := s.table.TraceAddresses.NewIterator(prefix, from.Bytes())
+		for it.Next() {
+			n := idx.BytesToBlock(it.Key()[len(prefix):])
+			if n > to {
+				break
+			}
+			blocks[n] = struct{}{}
+			if ctx.Err() != nil {
+				it.Release()
+				return nil, ctx.Err()
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+func traceAddressKey(addr common.Address, kind byte, n idx.Block) []byte {
+	key := make([]byte, 0, common.AddressLength+1+8)
+	key = append(key, addr.Bytes()...)
+	key = append(key, kind)
+	return append(key, n.Bytes()...)
+}
@@ -350,17 +350,8 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 		// Block filter requested, construct a single-shot filter
 		filter = NewBlockFilter(api.backend, api.config, *crit.BlockHash, crit.Addresses, crit.Topics)
 	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if crit.FromBlock != nil {
-			begin = crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if crit.ToBlock != nil {
-			end = crit.ToBlock.Int64()
-		}
 		// Construct the range filter
-		filter = NewRangeFilter(api.backend, api.config, begin, end, crit.Addresses, crit.Topics)
+		filter = api.newRangeFilter(crit)
 	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
@@ -370,6 +361,80 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	return returnLogs(logs), err
 }
 
+// newRangeFilter converts the RPC block numbers into internal representations and creates the range filter.
+func (api *PublicFilterAPI) newRangeFilter(crit FilterCriteria) *Filter {
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	return NewRangeFilter(api.backend, api.config, begin, end, crit.Addresses, crit.Topics)
+}
+
+// LogsCursor is the position in the chain to resume a paginated logs search from.
+type LogsCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	TxIndex     hexutil.Uint   `json:"transactionIndex"`
+	Index       hexutil.Uint   `json:"logIndex"`
+}
+
+// skips returns true if the log is positioned before the cursor.
+func (c *LogsCursor) skips(l *types.Log) bool {
+	if l.BlockNumber != uint64(c.BlockNumber) {
+		return l.BlockNumber < uint64(c.BlockNumber)
+	}
+	if l.TxIndex != uint(c.TxIndex) {
+		return l.TxIndex < uint(c.TxIndex)
+	}
+	return l.Index < uint(c.Index)
+}
+
+// LogsPage is a page of the paginated logs search.
+type LogsPage struct {
+	Logs []*types.Log `json:"logs"`
+	// Cursor is the position of the next page, or nil if there are no more logs
+	Cursor *LogsCursor `json:"cursor"`
+}
+
+const (
+	defaultLogsPageLimit = 1000
+	maxLogsPageLimit     = 10000
+	// logsPageWindow is the initial blocks range of an indexed page search
+	logsPageWindow = 1000
+)
+
+// GetLogsPage returns a page of logs matching the given argument, starting from the cursor position
+// or from the beginning of the blocks range if the cursor is omitted.
+// The logs are ordered by position. The cursor of the result is passed to get the next page,
+// until the result has no cursor. The limit is 1000 logs by default.
+// The block range isn't limited by the range limits of the node, as the limits are applied to each page.
+// It's a separate method rather than optional eth_getLogs arguments, because eth_getLogs
+// returns a bare array of logs, which has no room for the cursor, and existing clients
+// depend on that result type.
+func (api *PublicFilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, cursor *LogsCursor, limit *hexutil.Uint64) (*LogsPage, error) {
+	if crit.BlockHash != nil {
+		return nil, errors.New("pagination of the block hash filter is not supported")
+	}
+	n := defaultLogsPageLimit
+	if limit != nil {
+		if *limit == 0 || *limit > maxLogsPageLimit {
+			return nil, fmt.Errorf("page limit must be within 1..%d", maxLogsPageLimit)
+		}
+		n = int(*limit)
+	}
+	logs, next, err := api.newRangeFilter(crit).LogsPage(ctx, cursor, n)
+	if err != nil {
+		return nil, err
+	}
+	return &LogsPage{
+		Logs:   returnLogs(logs),
+		Cursor: next,
+	}, nil
+}
+
 // UninstallFilter removes the filter with the given filter id.
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_uninstallfilter
@@ -0,0 +1,139 @@
+package filters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// DagEvents send a notification each time a DAG event is confirmed by the node.
+// The notification contains the event header and hashes of the event transactions.
+// Events are skipped for a client which doesn't keep up with the notifications.
+func (api *PublicFilterAPI) DagEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan *inter.EventPayload, dagEventsChanSize)
+		eventsSub := api.events.SubscribeDagEvents(events)
+
+		for {
+			select {
+			case e := <-events:
+				fields, _ := inter.RPCMarshalEventPayload(e, true, false)
+				_ = notifier.Notify(rpcSub.ID, fields)
+			case <-rpcSub.Err():
+				eventsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				eventsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// Epochs send a notification each time an epoch is sealed.
+// The notification contains the new validator set, rules of the new epoch and
+// the rules fields which differ from the sealed epoch.
+func (api *PublicFilterAPI) Epochs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		epochs := make(chan NewEpochNotify, epochsChanSize)
+		epochsSub := api.events.SubscribeEpochs(epochs)
+
+		for {
+			select {
+			case e := <-epochs:
+				_ = notifier.Notify(rpcSub.ID, RPCMarshalNewEpoch(e))
+			case <-rpcSub.Err():
+				epochsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				epochsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// RPCMarshalNewEpoch converts the new epoch notification to the RPC output.
+func RPCMarshalNewEpoch(e NewEpochNotify) map[string]interface{} {
+	es := e.EpochState
+	validators := map[hexutil.Uint64]interface{}{}
+	for _, vid := range es.Validators.IDs() {
+		validators[hexutil.Uint64(vid)] = map[string]interface{}{
+			"weight": (*hexutil.Big)(es.ValidatorProfiles[vid].Weight),
+			"pubkey": es.ValidatorProfiles[vid].PubKey.String(),
+		}
+	}
+	return map[string]interface{}{
+		"epoch":      hexutil.Uint64(es.Epoch),
+		"epochStart": hexutil.Uint64(es.EpochStart),
+		"validators": validators,
+		"rules":      es.Rules,
+		"rulesDiff":  RulesDiff(e.PrevRules, es.Rules),
+	}
+}
+
+// RulesDiff returns the rules fields which differ, keyed by the dot-separated field path.
+// Each value holds the previous and the new field values.
+func RulesDiff(prev, next opera.Rules) map[string]interface{} {
+	diff := map[string]interface{}{}
+	diffFields(nil, rulesFields(prev), rulesFields(next), diff)
+	return diff
+}
+
+// rulesFields converts the rules into a generic JSON tree, numbers are kept as json.Number to not lose precision.
+func rulesFields(r opera.Rules) interface{} {
+	b, _ := json.Marshal(&r)
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var fields interface{}
+	_ = dec.Decode(&fields)
+	return fields
+}
+
+func diffFields(path []string, prev, next interface{}, diff map[string]interface{}) {
+	prevMap, prevOk := prev.(map[string]interface{})
+	nextMap, nextOk := next.(map[string]interface{})
+	if prevOk && nextOk {
+		for k := range prevMap {
+			diffFields(append(path, k), prevMap[k], nextMap[k], diff)
+		}
+		for k := range nextMap {
+			if _, ok := prevMap[k]; !ok {
+				diffFields(append(path, k), nil, nextMap[k], diff)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(prev, next) {
+		diff[strings.Join(path, ".")] = map[string]interface{}{
+			"prev": prev,
+			"new":  next,
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package filters
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/drivertype"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+func TestDagEventsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend = newTestBackend()
+		api     = NewPublicFilterAPI(backend, testConfig())
+		events  = make(chan *inter.EventPayload, dagEventsChanSize)
+		sub     = api.events.SubscribeDagEvents(events)
+	)
+	defer sub.Unsubscribe()
+
+	me := &inter.MutableEventPayload{}
+	me.SetEpoch(2)
+	me.SetCreator(3)
+	me.SetLamport(4)
+	me.SetFrame(5)
+	e := me.Build()
+	go backend.eventsFeed.Send(e)
+
+	select {
+	case got := <-events:
+		require.Equal(t, e.ID(), got.ID())
+	case <-time.After(time.Second):
+		t.Fatal("DAG event wasn't notified")
+	}
+
+	fields, err := inter.RPCMarshalEventPayload(e, true, false)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(3), fields["creator"])
+	require.Equal(t, hexutil.Uint64(5), fields["frame"])
+	require.Empty(t, fields["transactions"])
+}
+
+func TestDagEventsSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend = newTestBackend()
+		api     = NewPublicFilterAPI(backend, testConfig())
+		events  = make(chan *inter.EventPayload, 1)
+		sub     = api.events.SubscribeDagEvents(events)
+	)
+	defer sub.Unsubscribe()
+
+	// the sender isn't blocked by a subscriber which doesn't read the events
+	sent := make(chan struct{})
+	go func() {
+		for lamport := idx.Lamport(1); lamport <= 2*dagEventsChanSize; lamport++ {
+			me := &inter.MutableEventPayload{}
+			me.SetLamport(lamport)
+			backend.eventsFeed.Send(me.Build())
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("DAG events sending is blocked by the subscriber")
+	}
+
+	got := <-events
+	require.Equal(t, idx.Lamport(1), got.Lamport())
+}
+
+func TestEpochsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend = newTestBackend()
+		api     = NewPublicFilterAPI(backend, testConfig())
+		epochs  = make(chan NewEpochNotify, epochsChanSize)
+		sub     = api.events.SubscribeEpochs(epochs)
+	)
+	defer sub.Unsubscribe()
+
+	prevRules := opera.FakeNetRules()
+	rules := prevRules.Copy()
+	rules.Economy.MinGasPrice = new(big.Int).Add(prevRules.Economy.MinGasPrice, big.NewInt(1))
+	rules.Blocks.MaxBlockGas++
+
+	builder := pos.NewBuilder()
+	builder.Set(1, 100)
+	es := &iblockproc.EpochState{
+		Epoch:      7,
+		Validators: builder.Build(),
+		ValidatorProfiles: iblockproc.ValidatorProfiles{
+			1: drivertype.Validator{
+				Weight: big.NewInt(100),
+				PubKey: validatorpk.PubKey{Type: validatorpk.Types.Secp256k1, Raw: []byte{1, 2}},
+			},
+		},
+		Rules: rules,
+	}
+	go backend.epochsFeed.Send(NewEpochNotify{EpochState: es, PrevRules: prevRules})
+
+	var got NewEpochNotify
+	select {
+	case got = <-epochs:
+		require.Equal(t, idx.Epoch(7), got.EpochState.Epoch)
+	case <-time.After(time.Second):
+		t.Fatal("epoch wasn't notified")
+	}
+
+	fields := RPCMarshalNewEpoch(got)
+	require.Equal(t, hexutil.Uint64(7), fields["epoch"])
+	validators := fields["validators"].(map[hexutil.Uint64]interface{})
+	require.Len(t, validators, 1)
+	require.Equal(t, "0xc00102", validators[1].(map[string]interface{})["pubkey"])
+
+	diff := fields["rulesDiff"].(map[string]interface{})
+	require.Len(t, diff, 2)
+	require.Contains(t, diff, "Economy.MinGasPrice")
+	require.Contains(t, diff, "Blocks.MaxBlockGas")
+	require.Empty(t, RulesDiff(prevRules, prevRules))
+}
@@ -21,10 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	notify "github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
@@ -32,6 +34,7 @@ import (
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/topicsdb"
 )
 
@@ -46,6 +49,8 @@ type Backend interface {
 	SubscribeNewBlockNotify(ch chan<- evmcore.ChainHeadNotify) notify.Subscription
 	SubscribeNewTxsNotify(chan<- evmcore.NewTxsNotify) notify.Subscription
 	SubscribeLogsNotify(ch chan<- []*types.Log) notify.Subscription
+	SubscribeNewDagEventsNotify(ch chan<- *inter.EventPayload) notify.Subscription
+	SubscribeNewEpochNotify(ch chan<- NewEpochNotify) notify.Subscription
 
 	EvmLogIndex() topicsdb.Index
 
@@ -113,28 +118,136 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		return f.blockLogs(ctx, header.Hash)
 	}
 	// Figure out the limits of the filter range
+	begin, end, ok := f.blocksRange(ctx)
+	if !ok {
+		return nil, nil
+	}
+	if begin > end {
+		return []*types.Log{}, nil
+	}
+
+	if f.unindexed() {
+		return f.unindexedLogs(ctx, begin, end)
+	} else {
+		return f.indexedLogs(ctx, begin, end)
+	}
+}
+
+// blocksRange resolves the range interval of the filter against the latest block.
+func (f *Filter) blocksRange(ctx context.Context) (begin, end idx.Block, ok bool) {
 	header, _ := f.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
 	if header == nil {
-		return nil, nil
+		return 0, 0, false
 	}
 	head := idx.Block(header.Number.Uint64())
 
-	begin := idx.Block(f.begin)
+	begin = idx.Block(f.begin)
 	if f.begin < 0 {
 		begin = head
 	}
-	end := idx.Block(f.end)
+	end = idx.Block(f.end)
 	if f.end < 0 {
 		end = head
 	}
-	if begin > end {
-		return []*types.Log{}, nil
+	return begin, end, true
+}
+
+// unindexed returns true if the filter matches any log, so the index cannot be used.
+func (f *Filter) unindexed() bool {
+	return isEmpty(f.topics) && len(f.addresses) == 0
+}
+
+// LogsPage searches the range of the filter for matching log entries, starting from the cursor position.
+// It returns up to the limit of the logs ordered by position, and the cursor of the next page,
+// which is nil if the range is exhausted. A page may contain less than the limit of logs,
+// even none, while the cursor is not nil, so the search is resumed until it returns no cursor.
+// The range limits of the config are applied to the blocks searched by a page, not to the whole range.
+func (f *Filter) LogsPage(ctx context.Context, cursor *LogsCursor, limit int) ([]*types.Log, *LogsCursor, error) {
+	if f.block != common.Hash(hash.Zero) {
+		return nil, nil, errors.New("pagination of the block hash filter is not supported")
+	}
+	if limit <= 0 {
+		return nil, nil, errors.New("page limit must be positive")
+	}
+	begin, end, ok := f.blocksRange(ctx)
+	if !ok || begin > end {
+		return []*types.Log{}, nil, nil
+	}
+	if cursor != nil {
+		if idx.Block(cursor.BlockNumber) > end {
+			return []*types.Log{}, nil, nil
+		}
+		if idx.Block(cursor.BlockNumber) > begin {
+			begin = idx.Block(cursor.BlockNumber)
+		}
 	}
 
-	if isEmpty(f.topics) && len(f.addresses) == 0 {
-		return f.unindexedLogs(ctx, begin, end)
+	var (
+		logs       []*types.Log
+		window     idx.Block
+		rangeLimit idx.Block
+	)
+	if f.unindexed() {
+		// every block is read, so a page is limited by the unindexed range
+		rangeLimit = f.config.UnindexedLogsBlockRangeLimit
+		window = rangeLimit
 	} else {
-		return f.indexedLogs(ctx, begin, end)
+		// the window is widened while it isn't filled, to quickly pass sparse ranges
+		rangeLimit = f.config.IndexedLogsBlockRangeLimit
+		window = logsPageWindow
+	}
+	if rangeLimit == 0 {
+		rangeLimit = 1
+	}
+	if window > rangeLimit {
+		window = rangeLimit
+	}
+	pageEnd := end
+	if end-begin >= rangeLimit {
+		pageEnd = begin + rangeLimit - 1
+	}
+	for from := begin; ; {
+		to := pageEnd
+		if pageEnd-from >= window {
+			to = from + window - 1
+		}
+		var (
+			found []*types.Log
+			err   error
+		)
+		if f.unindexed() {
+			found, err = f.unindexedLogs(ctx, from, to)
+		} else {
+			found, err = f.indexedLogs(ctx, from, to)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		sortLogs(found)
+		for _, l := range found {
+			if cursor == nil || !cursor.skips(l) {
+				logs = append(logs, l)
+			}
+		}
+		if len(logs) > limit {
+			logs = logs[:limit]
+			last := logs[limit-1]
+			return logs, &LogsCursor{
+				BlockNumber: hexutil.Uint64(last.BlockNumber),
+				TxIndex:     hexutil.Uint(last.TxIndex),
+				Index:       hexutil.Uint(last.Index + 1),
+			}, nil
+		}
+		if to >= end {
+			return logs, nil, nil
+		}
+		if to >= pageEnd || len(logs) == limit {
+			return logs, &LogsCursor{BlockNumber: hexutil.Uint64(to + 1)}, nil
+		}
+		from = to + 1
+		if len(found) == 0 && window < rangeLimit/2 {
+			window *= 2
+		}
 	}
 }
 
@@ -143,7 +256,11 @@ func (f *Filter) indexedLogs(ctx context.Context, begin, end idx.Block) ([]*type
 	if end-begin > f.config.IndexedLogsBlockRangeLimit {
 		return nil, fmt.Errorf("too wide blocks range, the limit is %d", f.config.IndexedLogsBlockRangeLimit)
 	}
+	return f.findInIndex(ctx, begin, end)
+}
 
+// findInIndex returns the logs matching the filter criteria from the topics index.
+func (f *Filter) findInIndex(ctx context.Context, begin, end idx.Block) ([]*types.Log, error) {
 	addresses := make([]common.Hash, len(f.addresses))
 	for i, addr := range f.addresses {
 		addresses[i] = addr.Hash()
@@ -177,6 +294,14 @@ func (f *Filter) unindexedLogs(ctx context.Context, begin, end idx.Block) (logs
 		return nil, fmt.Errorf("too wide blocks range, the limit is %d", f.config.UnindexedLogsBlockRangeLimit)
 	}
 
+	// the index matches the wildcard criteria by reading all the log records of the blocks,
+	// which is cheaper than reading the receipts, the receipts are read if the logs aren't indexed
+	logs, err = f.findInIndex(ctx, begin, end)
+	if err != topicsdb.ErrLogsNotRecorded {
+		return
+	}
+	logs, err = nil, nil
+
 	var (
 		header *evmcore.EvmHeader
 		found  []*types.Log
@@ -278,6 +403,20 @@ Logs:
 	return ret
 }
 
+// sortLogs orders the logs by their position in the chain.
+func sortLogs(logs []*types.Log) {
+	sort.Slice(logs, func(i, j int) bool {
+		a, b := logs[i], logs[j]
+		if a.BlockNumber != b.BlockNumber {
+			return a.BlockNumber < b.BlockNumber
+		}
+		if a.TxIndex != b.TxIndex {
+			return a.TxIndex < b.TxIndex
+		}
+		return a.Index < b.Index
+	})
+}
+
 func isEmpty(topics [][]common.Hash) bool {
 	for _, tt := range topics {
 		if len(tt) > 0 {
@@ -34,6 +34,9 @@ import (
 	"github.com/ethereum/go-ethereum/trie"
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+	"github.com/Fantom-foundation/go-opera/opera"
 )
 
 // Type determines the kind of filter and is used to put the filter in to
@@ -54,6 +57,10 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// DagEventsSubscription queries for DAG events that are confirmed
+	DagEventsSubscription
+	// EpochsSubscription queries for epochs that are sealed
+	EpochsSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -67,12 +74,24 @@ const (
 	logsChanSize = 10
 	// blocksChanSize is the size of channel listening to BlocksEvent.
 	blocksChanSize = 10
+	// dagEventsChanSize is the size of channel listening to DAG events.
+	dagEventsChanSize = 100
+	// epochsChanSize is the size of channel listening to NewEpochNotify.
+	epochsChanSize = 10
 )
 
 var (
 	ErrInvalidSubscriptionID = errors.New("invalid id")
 )
 
+// NewEpochNotify is posted when an epoch is sealed.
+type NewEpochNotify struct {
+	// EpochState is the state of the new epoch
+	EpochState *iblockproc.EpochState
+	// PrevRules are the rules of the sealed epoch
+	PrevRules opera.Rules
+}
+
 type subscription struct {
 	id        rpc.ID
 	typ       Type
@@ -81,6 +100,8 @@ type subscription struct {
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
 	headers   chan *types.Header
+	events    chan *inter.EventPayload
+	epochs    chan NewEpochNotify
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -91,16 +112,20 @@ type EventSystem struct {
 	backend Backend
 
 	// Subscriptions
-	txsSub    notify.Subscription // Subscription for new transaction notify
-	logsSub   notify.Subscription // Subscription for new log notify
-	blocksSub notify.Subscription // Subscription for new chain notify
+	txsSub       notify.Subscription // Subscription for new transaction notify
+	logsSub      notify.Subscription // Subscription for new log notify
+	blocksSub    notify.Subscription // Subscription for new chain notify
+	dagEventsSub notify.Subscription // Subscription for new DAG event notify
+	epochsSub    notify.Subscription // Subscription for new epoch notify
 
 	// Channels
-	install   chan *subscription           // install filter for event notification
-	uninstall chan *subscription           // remove filter for event notification
-	txsCh     chan evmcore.NewTxsNotify    // Channel to receive new transactions notify
-	logsCh    chan []*types.Log            // Channel to receive new log notify
-	blocksCh  chan evmcore.ChainHeadNotify // Channel to receive new chain notify
+	install     chan *subscription           // install filter for event notification
+	uninstall   chan *subscription           // remove filter for event notification
+	txsCh       chan evmcore.NewTxsNotify    // Channel to receive new transactions notify
+	logsCh      chan []*types.Log            // Channel to receive new log notify
+	blocksCh    chan evmcore.ChainHeadNotify // Channel to receive new chain notify
+	dagEventsCh chan *inter.EventPayload     // Channel to receive new DAG event notify
+	epochsCh    chan NewEpochNotify          // Channel to receive new epoch notify
 }
 
 // NewEventSystem creates a new manager that listens for event on the given chans,
@@ -110,21 +135,25 @@ type EventSystem struct {
 // The returned manager has a loop that needs to be stopped with the Stop function.
 func NewEventSystem(backend Backend) *EventSystem {
 	m := &EventSystem{
-		backend:   backend,
-		install:   make(chan *subscription),
-		uninstall: make(chan *subscription),
-		blocksCh:  make(chan evmcore.ChainHeadNotify, blocksChanSize),
-		txsCh:     make(chan evmcore.NewTxsNotify, txChanSize),
-		logsCh:    make(chan []*types.Log, logsChanSize),
+		backend:     backend,
+		install:     make(chan *subscription),
+		uninstall:   make(chan *subscription),
+		blocksCh:    make(chan evmcore.ChainHeadNotify, blocksChanSize),
+		txsCh:       make(chan evmcore.NewTxsNotify, txChanSize),
+		logsCh:      make(chan []*types.Log, logsChanSize),
+		dagEventsCh: make(chan *inter.EventPayload, dagEventsChanSize),
+		epochsCh:    make(chan NewEpochNotify, epochsChanSize),
 	}
 
 	// Subscribe events
 	m.blocksSub = m.backend.SubscribeNewBlockNotify(m.blocksCh)
 	m.txsSub = m.backend.SubscribeNewTxsNotify(m.txsCh)
 	m.logsSub = m.backend.SubscribeLogsNotify(m.logsCh)
+	m.dagEventsSub = m.backend.SubscribeNewDagEventsNotify(m.dagEventsCh)
+	m.epochsSub = m.backend.SubscribeNewEpochNotify(m.epochsCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.blocksSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.blocksSub == nil || m.dagEventsSub == nil || m.epochsSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -160,6 +189,8 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.logs:
 			case <-sub.f.hashes:
 			case <-sub.f.headers:
+			case <-sub.f.events:
+			case <-sub.f.epochs:
 			}
 		}
 
@@ -299,6 +330,40 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 	return es.subscribe(sub)
 }
 
+// SubscribeDagEvents creates a subscription that writes DAG events that are
+// confirmed by the node. Events are dropped when the channel is full.
+func (es *EventSystem) SubscribeDagEvents(events chan *inter.EventPayload) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       DagEventsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   make(chan *types.Header),
+		events:    events,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeEpochs creates a subscription that writes states of new epochs
+// each time an epoch is sealed. Epochs are dropped when the channel is full.
+func (es *EventSystem) SubscribeEpochs(epochs chan NewEpochNotify) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       EpochsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   make(chan *types.Header),
+		epochs:    epochs,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 // broadcast event to filters that match criteria.
@@ -324,6 +389,23 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		for _, f := range filters[PendingTransactionsSubscription] {
 			f.hashes <- hashes
 		}
+	case *inter.EventPayload:
+		// a slow subscriber misses DAG events instead of stalling the event processing
+		for _, f := range filters[DagEventsSubscription] {
+			select {
+			case f.events <- e:
+			default:
+				log.Debug("DAG event is dropped for a slow subscriber", "id", f.id)
+			}
+		}
+	case NewEpochNotify:
+		for _, f := range filters[EpochsSubscription] {
+			select {
+			case f.epochs <- e:
+			default:
+				log.Debug("Epoch is dropped for a slow subscriber", "id", f.id)
+			}
+		}
 	case evmcore.ChainHeadNotify:
 		h := e.Block.EthHeader()
 		h.GasLimit = 0xffffffffffff // don't use too much bits here to avoid parsing issues
@@ -361,6 +443,8 @@ func (es *EventSystem) eventLoop() {
 		es.blocksSub.Unsubscribe()
 		es.txsSub.Unsubscribe()
 		es.logsSub.Unsubscribe()
+		es.dagEventsSub.Unsubscribe()
+		es.epochsSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -377,6 +461,10 @@ func (es *EventSystem) eventLoop() {
 			es.broadcast(index, ev)
 		case ev := <-es.blocksCh:
 			es.broadcast(index, ev)
+		case ev := <-es.dagEventsCh:
+			es.broadcast(index, ev)
+		case ev := <-es.epochsCh:
+			es.broadcast(index, ev)
 
 		case f := <-es.install:
 			index[f.typ][f.id] = f
@@ -393,6 +481,10 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.blocksSub.Err():
 			return
+		case <-es.dagEventsSub.Err():
+			return
+		case <-es.epochsSub.Err():
+			return
 		}
 	}
 }
@@ -34,6 +34,7 @@ import (
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/topicsdb"
 )
 
@@ -43,6 +44,8 @@ type testBackend struct {
 	blocksFeed *notify.Feed
 	txsFeed    *notify.Feed
 	logsFeed   *notify.Feed
+	eventsFeed *notify.Feed
+	epochsFeed *notify.Feed
 }
 
 func newTestBackend() *testBackend {
@@ -52,6 +55,8 @@ func newTestBackend() *testBackend {
 		blocksFeed: new(notify.Feed),
 		txsFeed:    new(notify.Feed),
 		logsFeed:   new(notify.Feed),
+		eventsFeed: new(notify.Feed),
+		epochsFeed: new(notify.Feed),
 	}
 }
 
@@ -132,6 +137,14 @@ func (b *testBackend) SubscribeNewBlockNotify(ch chan<- evmcore.ChainHeadNotify)
 	return b.blocksFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeNewDagEventsNotify(ch chan<- *inter.EventPayload) notify.Subscription {
+	return b.eventsFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeNewEpochNotify(ch chan<- NewEpochNotify) notify.Subscription {
+	return b.epochsFeed.Subscribe(ch)
+}
+
 func (b *testBackend) EvmLogIndex() topicsdb.Index {
 	return b.logIndex
 }
@@ -28,6 +28,7 @@ import (
 
 	"github.com/Fantom-foundation/go-opera/topicsdb"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -263,3 +264,143 @@ func TestFilters(t *testing.T) {
 	}
 
 }
+
+func TestFiltersPage(t *testing.T) {
+	var (
+		backend = newTestBackend()
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key1.PublicKey)
+		hash1   = common.BytesToHash([]byte("topic1"))
+	)
+
+	genesis := core.GenesisBlockForTesting(backend.db, addr, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), backend.db, 1000, func(i int, gen *core.BlockGen) {
+		if i%7 != 0 {
+			return
+		}
+		tx := types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{
+			{BlockNumber: uint64(i + 1), TxHash: tx.Hash(), Index: 0, Address: addr, Topics: []common.Hash{hash1}},
+			{BlockNumber: uint64(i + 1), TxHash: tx.Hash(), Index: 1, Address: addr},
+		}
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(tx)
+		backend.MustPushLogs(receipt.Logs...)
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(backend.db, block)
+		rawdb.WriteCanonicalHash(backend.db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(backend.db, block.Hash())
+		rawdb.WriteReceipts(backend.db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+
+	readPages := func(filter *Filter, limit int) (all []*types.Log, pages int) {
+		var cursor *LogsCursor
+		for {
+			logs, next, err := filter.LogsPage(context.Background(), cursor, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(logs) > limit {
+				t.Fatalf("expected at most %d logs, got %d", limit, len(logs))
+			}
+			all = append(all, logs...)
+			pages++
+			if next == nil {
+				return
+			}
+			cursor = next
+		}
+	}
+	checkOrdered := func(logs []*types.Log) {
+		for i := 1; i < len(logs); i++ {
+			prev, cur := logs[i-1], logs[i]
+			if prev.BlockNumber > cur.BlockNumber || prev.BlockNumber == cur.BlockNumber && prev.Index >= cur.Index {
+				t.Fatalf("log %d/%d is not after %d/%d", cur.BlockNumber, cur.Index, prev.BlockNumber, prev.Index)
+			}
+		}
+	}
+
+	// address only, served from the index
+	filter := NewRangeFilter(backend, testConfig(), 0, -1, []common.Address{addr}, nil)
+	expect, err := filter.Logs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expect) != 2*(1000/7+1) {
+		t.Fatal("expected", 2*(1000/7+1), "logs, got", len(expect))
+	}
+	for _, limit := range []int{1, 3, 1000, 10000} {
+		got, _ := readPages(filter, limit)
+		if len(got) != len(expect) {
+			t.Fatal("limit", limit, "expected", len(expect), "logs, got", len(got))
+		}
+		checkOrdered(got)
+	}
+
+	// address and topic within a range
+	filter = NewRangeFilter(backend, testConfig(), 100, 200, []common.Address{addr}, [][]common.Hash{{hash1}})
+	got, _ := readPages(filter, 5)
+	if len(got) != 14 {
+		t.Fatal("expected 14 logs, got", len(got))
+	}
+	checkOrdered(got)
+	if got[0].BlockNumber != 106 || got[len(got)-1].BlockNumber != 197 {
+		t.Fatal("unexpected range", got[0].BlockNumber, got[len(got)-1].BlockNumber)
+	}
+
+	// the range wider than the indexed range limit is paginated, each page is limited by the range
+	cfg := testConfig()
+	cfg.IndexedLogsBlockRangeLimit = 100
+	filter = NewRangeFilter(backend, cfg, 0, -1, []common.Address{addr}, nil)
+	if _, err := filter.Logs(context.Background()); err == nil {
+		t.Fatal("expected the range limit error")
+	}
+	got, pages := readPages(filter, 10000)
+	if len(got) != len(expect) {
+		t.Fatal("expected", len(expect), "logs, got", len(got))
+	}
+	if pages != 11 {
+		t.Fatal("expected 11 pages, got", pages)
+	}
+	checkOrdered(got)
+
+	// eth_getLogsPage
+	api := NewPublicFilterAPI(backend, cfg)
+	limit := hexutil.Uint64(100)
+	crit := FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{addr}}
+	var cursor *LogsCursor
+	got = nil
+	for {
+		p, err := api.GetLogsPage(context.Background(), crit, cursor, &limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p.Logs...)
+		if p.Cursor == nil {
+			break
+		}
+		cursor = p.Cursor
+	}
+	if len(got) != len(expect) {
+		t.Fatal("expected", len(expect), "logs, got", len(got))
+	}
+	checkOrdered(got)
+	if _, err := api.GetLogs(context.Background(), crit); err == nil {
+		t.Fatal("expected the range limit error of eth_getLogs")
+	}
+
+	// no criteria, each page is limited by the unindexed range
+	cfg = testConfig()
+	cfg.UnindexedLogsBlockRangeLimit = 100
+	filter = NewRangeFilter(backend, cfg, 1, 1000, nil, nil)
+	got, pages = readPages(filter, 10000)
+	if len(got) != 2*(1000/7+1) {
+		t.Fatal("expected", 2*(1000/7+1), "logs, got", len(got))
+	}
+	if pages != 10 {
+		t.Fatal("expected 10 pages, got", pages)
+	}
+	checkOrdered(got)
+}
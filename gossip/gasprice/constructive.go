@@ -4,11 +4,15 @@ import (
 	"math/big"
 
 	"github.com/Fantom-foundation/lachesis-base/utils/piecefunc"
+
+	"github.com/Fantom-foundation/go-opera/opera"
 )
 
 func (gpo *Oracle) maxTotalGasPower() *big.Int {
-	rules := gpo.backend.GetRules()
+	return maxTotalGasPowerOf(gpo.backend.GetRules())
+}
 
+func maxTotalGasPowerOf(rules opera.Rules) *big.Int {
 	allocBn := new(big.Int).SetUint64(rules.Economy.LongGasPower.AllocPerSec)
 	periodBn := new(big.Int).SetUint64(uint64(rules.Economy.LongGasPower.MaxAllocPeriod))
 	maxTotalGasPowerBn := new(big.Int).Mul(allocBn, periodBn)
@@ -21,9 +25,12 @@ func (gpo *Oracle) effectiveMinGasPrice() *big.Int {
 }
 
 func (gpo *Oracle) constructiveGasPrice(gasOffestAbs uint64, gasOffestRatio uint64, adjustedMinPrice *big.Int) *big.Int {
-	max := gpo.maxTotalGasPower()
+	return gpo.constructiveGasPriceAt(gpo.backend.TotalGasPowerLeft(), gpo.maxTotalGasPower(), gasOffestAbs, gasOffestRatio, adjustedMinPrice)
+}
 
-	current64 := gpo.backend.TotalGasPowerLeft()
+// constructiveGasPriceAt calculates the constructive gas price for the given gas power left and its maximum
+func (gpo *Oracle) constructiveGasPriceAt(gasPowerLeft uint64, max *big.Int, gasOffestAbs uint64, gasOffestRatio uint64, adjustedMinPrice *big.Int) *big.Int {
+	current64 := gasPowerLeft
 	if current64 > gasOffestAbs {
 		current64 -= gasOffestAbs
 	} else {
@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	lru "github.com/hashicorp/golang-lru"
 
+	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/opera"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -50,6 +51,8 @@ type Config struct {
 	MaxGasPrice      *big.Int `toml:",omitempty"`
 	MinGasPrice      *big.Int `toml:",omitempty"`
 	DefaultCertainty uint64   `toml:",omitempty"`
+	// HistorySize is the number of blocks to keep the oracle's stats for, zero disables the history
+	HistorySize uint64 `toml:",omitempty"`
 }
 
 type Reader interface {
@@ -84,6 +87,8 @@ type Oracle struct {
 	eCache effectiveMinGasPriceCache
 	tCache *lru.Cache
 
+	history *history
+
 	wg   sync.WaitGroup
 	quit chan struct{}
 }
@@ -125,6 +130,15 @@ func (gpo *Oracle) Start(backend Reader) {
 		defer gpo.wg.Done()
 		gpo.txpoolStatsLoop()
 	}()
+	if gpo.history != nil {
+		heads := make(chan evmcore.ChainHeadNotify, historyHeadsChanSize)
+		sub := gpo.history.subscribe(heads)
+		gpo.wg.Add(1)
+		go func() {
+			defer gpo.wg.Done()
+			gpo.historyLoop(heads, sub)
+		}()
+	}
 }
 
 func (gpo *Oracle) Stop() {
@@ -132,13 +146,37 @@ func (gpo *Oracle) Stop() {
 	gpo.wg.Wait()
 }
 
-func (gpo *Oracle) suggestTip(certainty uint64) *big.Int {
-	minPrice := gpo.backend.GetRules().Economy.MinGasPrice
+// tipState is the state of the oracle which the suggested tips are calculated from
+type tipState struct {
+	pool             txpoolStat
+	gasPowerLeft     uint64
+	maxGasPower      *big.Int
+	minPrice         *big.Int
+	adjustedMinPrice *big.Int
+	minGasTip        *big.Int
+}
+
+func (gpo *Oracle) tipState(pool txpoolStat) tipState {
+	rules := gpo.backend.GetRules()
+	minPrice := rules.Economy.MinGasPrice
 	pendingMinPrice := gpo.backend.GetPendingRules().Economy.MinGasPrice
-	adjustedMinGasPrice := math.BigMax(minPrice, pendingMinPrice)
+	return tipState{
+		pool:             pool,
+		gasPowerLeft:     gpo.backend.TotalGasPowerLeft(),
+		maxGasPower:      maxTotalGasPowerOf(rules),
+		minPrice:         minPrice,
+		adjustedMinPrice: math.BigMax(minPrice, pendingMinPrice),
+		minGasTip:        gpo.backend.MinGasTip(),
+	}
+}
+
+func (gpo *Oracle) suggestTip(certainty uint64) *big.Int {
+	return gpo.suggestTipOf(certainty, gpo.tipState(gpo.c.load()))
+}
 
-	reactive := gpo.reactiveGasPrice(certainty)
-	constructive := gpo.constructiveGasPrice(gpo.c.totalGas(), 0.005*DecimalUnit+certainty/25, adjustedMinGasPrice)
+func (gpo *Oracle) suggestTipOf(certainty uint64, s tipState) *big.Int {
+	reactive := s.pool.gasPriceForGasAbove(certaintyToGasAbove(certainty))
+	constructive := gpo.constructiveGasPriceAt(s.gasPowerLeft, s.maxGasPower, s.pool.totalGas, 0.005*DecimalUnit+certainty/25, s.adjustedMinPrice)
 
 	combined := math.BigMax(reactive, constructive)
 	if combined.Cmp(gpo.cfg.MinGasPrice) < 0 {
@@ -148,10 +186,9 @@ func (gpo *Oracle) suggestTip(certainty uint64) *big.Int {
 		combined = gpo.cfg.MaxGasPrice
 	}
 
-	tip := new(big.Int).Sub(combined, minPrice)
-	minGasTip := gpo.backend.MinGasTip()
-	if tip.Cmp(minGasTip) < 0 {
-		return minGasTip
+	tip := new(big.Int).Sub(combined, s.minPrice)
+	if tip.Cmp(s.minGasTip) < 0 {
+		return s.minGasTip
 	}
 	return tip
 }
@@ -2,14 +2,19 @@ package gasprice
 
 import (
 	"math/big"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
+	notify "github.com/ethereum/go-ethereum/event"
 	"github.com/stretchr/testify/require"
 
+	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/opera"
 )
 
@@ -327,3 +332,65 @@ func TestOracle_reactiveGasPrice(t *testing.T) {
 	require.Equal(t, "0", gpo.reactiveGasPrice(0.8*DecimalUnit).String())
 	require.Equal(t, "0", gpo.reactiveGasPrice(DecimalUnit).String())
 }
+
+func TestOracle_History(t *testing.T) {
+	backend := &TestBackend{
+		block:             1,
+		totalGasPowerLeft: 0,
+		rules:             opera.FakeNetRules(),
+		pendingRules:      opera.FakeNetRules(),
+	}
+	db := memorydb.New()
+	var feed notify.Feed
+	subscribe := func(ch chan<- evmcore.ChainHeadNotify) notify.Subscription {
+		return feed.Subscribe(ch)
+	}
+	newBlock := func(b idx.Block) {
+		feed.Send(evmcore.ChainHeadNotify{Block: &evmcore.EvmBlock{EvmHeader: evmcore.EvmHeader{Number: big.NewInt(int64(b))}}})
+	}
+	waitBlock := func(gpo *Oracle, b idx.Block) {
+		require.Eventually(t, func() bool {
+			last := gpo.History(1)
+			return len(last) == 1 && last[0].Block == b
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	gpo := NewOracle(Config{HistorySize: 3})
+	gpo.SetHistoryStore(db, subscribe)
+	gpo.Start(backend)
+	require.Empty(t, gpo.History(10))
+
+	// a stat is recorded once per block
+	for b := idx.Block(1); b <= 5; b++ {
+		newBlock(b)
+		newBlock(b)
+	}
+	waitBlock(gpo, 5)
+	gpo.Stop()
+	stats := gpo.History(10)
+	require.Len(t, stats, 3)
+	for i, s := range stats {
+		require.Equal(t, idx.Block(3+i), s.Block)
+		require.Len(t, s.Tips, len(HistoryCertainties))
+		require.Equal(t, backend.rules.Economy.MinGasPrice.String(), s.MinGasPrice.String())
+	}
+	require.Len(t, gpo.History(2), 2)
+	// recording doesn't activate the frequent calculation of the txpool statistic
+	require.Equal(t, uint32(0), atomic.LoadUint32(&gpo.c.activated))
+
+	// the history survives restarts, stale entries are skipped if the size is changed
+	gpo = NewOracle(Config{HistorySize: 5})
+	gpo.SetHistoryStore(db, subscribe)
+	gpo.Start(backend)
+	defer gpo.Stop()
+	stats = gpo.History(10)
+	require.Len(t, stats, 1)
+	require.Equal(t, idx.Block(3), stats[0].Block)
+	newBlock(5)
+	newBlock(6)
+	waitBlock(gpo, 6)
+	stats = gpo.History(10)
+	require.Len(t, stats, 2)
+	require.Equal(t, idx.Block(3), stats[0].Block)
+	require.Equal(t, idx.Block(6), stats[1].Block)
+}
@@ -0,0 +1,209 @@
+package gasprice
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/common/bigendian"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	notify "github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// historyHeadsChanSize is the size of channel listening to the new blocks
+const historyHeadsChanSize = 10
+
+// HistoryCertainties are the certainty levels at which the suggested tips are recorded.
+var HistoryCertainties = []uint64{
+	0,
+	0.1 * DecimalUnit,
+	0.2 * DecimalUnit,
+	0.3 * DecimalUnit,
+	0.4 * DecimalUnit,
+	0.5 * DecimalUnit,
+	0.6 * DecimalUnit,
+	0.7 * DecimalUnit,
+	0.8 * DecimalUnit,
+	0.9 * DecimalUnit,
+	DecimalUnit,
+}
+
+// Stat is a snapshot of the oracle's internal state, taken once per block.
+type Stat struct {
+	Time  inter.Timestamp
+	Block idx.Block
+	// PoolGas is the gas of the txpool transactions which the percentiles are calculated from
+	PoolGas uint64
+	// PoolPercentiles are the averaged gas prices of the txpool, for each 1/percentilesPerStat of maxGasToIndex
+	PoolPercentiles []*big.Int
+	// GasPowerLeft is the total gas power left of the validators
+	GasPowerLeft         uint64
+	MinGasPrice          *big.Int
+	EffectiveMinGasPrice *big.Int
+	// Tips are the suggested tips for each of HistoryCertainties
+	Tips []*big.Int
+}
+
+// history is a bounded ring of stats, persisted into a key-value store.
+type history struct {
+	db   kvdb.Store
+	size uint64
+
+	mu        sync.RWMutex
+	next      uint64 // sequence number of the next stat
+	lastBlock idx.Block
+
+	subscribe func(chan<- evmcore.ChainHeadNotify) notify.Subscription
+}
+
+// historyNextKey stores the sequence number of the next stat and the block of the last one
+var historyNextKey = []byte("n")
+
+func historyKey(pos uint64) []byte {
+	return append([]byte("s"), bigendian.Uint64ToBytes(pos)...)
+}
+
+func newHistory(db kvdb.Store, size uint64) *history {
+	h := &history{
+		db:   db,
+		size: size,
+	}
+	if b, err := db.Get(historyNextKey); err != nil {
+		log.Error("Failed to read gas price history", "err", err)
+	} else if len(b) == 16 {
+		h.next = bigendian.BytesToUint64(b[:8])
+		h.lastBlock = idx.Block(bigendian.BytesToUint64(b[8:]))
+	}
+	return h
+}
+
+// add appends the stat, overwriting the oldest one if the ring is full
+func (h *history) add(s Stat) error {
+	b, err := rlp.EncodeToBytes(&s)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// the sequence number is stored to detect stale entries if the size was changed
+	err = h.db.Put(historyKey(h.next%h.size), append(bigendian.Uint64ToBytes(h.next), b...))
+	if err != nil {
+		return err
+	}
+	h.next++
+	h.lastBlock = s.Block
+	return h.db.Put(historyNextKey, append(bigendian.Uint64ToBytes(h.next), bigendian.Uint64ToBytes(uint64(h.lastBlock))...))
+}
+
+// last returns up to the limit of the latest stats, from older to newer
+func (h *history) last(limit uint64) []Stat {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if limit > h.size {
+		limit = h.size
+	}
+	if limit > h.next {
+		limit = h.next
+	}
+	res := make([]Stat, 0, limit)
+	for seq := h.next - limit; seq < h.next; seq++ {
+		b, err := h.db.Get(historyKey(seq % h.size))
+		if err != nil {
+			log.Error("Failed to read gas price history", "err", err)
+			return res
+		}
+		if len(b) < 8 || bigendian.BytesToUint64(b[:8]) != seq {
+			continue
+		}
+		var s Stat
+		if err := rlp.DecodeBytes(b[8:], &s); err != nil {
+			log.Error("Failed to decode gas price history", "seq", seq, "err", err)
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// SetHistoryStore enables recording of the oracle's stats into the store, once per new block
+// notified by the subscription. The number of stored stats is bounded by the configured HistorySize.
+// Must be called before Start.
+func (gpo *Oracle) SetHistoryStore(db kvdb.Store, subscribe func(chan<- evmcore.ChainHeadNotify) notify.Subscription) {
+	if gpo.cfg.HistorySize == 0 {
+		return
+	}
+	gpo.history = newHistory(db, gpo.cfg.HistorySize)
+	gpo.history.subscribe = subscribe
+}
+
+// History returns up to the limit of the latest oracle's stats, from older to newer.
+func (gpo *Oracle) History(limit uint64) []Stat {
+	if gpo.history == nil {
+		return []Stat{}
+	}
+	return gpo.history.last(limit)
+}
+
+func (gpo *Oracle) historyLoop(heads <-chan evmcore.ChainHeadNotify, sub notify.Subscription) {
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case head := <-heads:
+			gpo.recordHistory(idx.Block(head.Block.NumberU64()))
+		case <-sub.Err():
+			return
+		case <-gpo.quit:
+			return
+		}
+	}
+}
+
+// calcStat takes the stat from the txpool statistic, which is calculated by the stats loop,
+// and from the backend state, which is read only once for all the suggested tips.
+// It doesn't activate the frequent calculation of the txpool statistic.
+func (gpo *Oracle) calcStat(block idx.Block) Stat {
+	avg, ok := gpo.c.avg.Load().(txpoolStat)
+	ts := gpo.tipState(avg)
+	s := Stat{
+		Time:                 inter.Timestamp(time.Now().UnixNano()),
+		Block:                block,
+		GasPowerLeft:         ts.gasPowerLeft,
+		MinGasPrice:          ts.minPrice,
+		EffectiveMinGasPrice: gpo.constructiveGasPriceAt(ts.gasPowerLeft, ts.maxGasPower, 0, 0, ts.minPrice),
+		Tips:                 make([]*big.Int, len(HistoryCertainties)),
+	}
+	if ok {
+		s.PoolGas = avg.totalGas
+		s.PoolPercentiles = make([]*big.Int, len(avg.percentiles))
+		for i, p := range avg.percentiles {
+			if p == nil {
+				p = new(big.Int)
+			}
+			s.PoolPercentiles[i] = p
+		}
+	}
+	for i, certainty := range HistoryCertainties {
+		s.Tips[i] = gpo.suggestTipOf(certainty, ts)
+	}
+	return s
+}
+
+// recordHistory records the oracle's stats of the new block, unless the block is already recorded
+func (gpo *Oracle) recordHistory(block idx.Block) {
+	gpo.history.mu.RLock()
+	recorded := gpo.history.next != 0 && block <= gpo.history.lastBlock
+	gpo.history.mu.RUnlock()
+	if recorded {
+		return
+	}
+	if err := gpo.history.add(gpo.calcStat(block)); err != nil {
+		log.Error("Failed to write gas price history", "err", err)
+	}
+}
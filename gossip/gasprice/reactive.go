@@ -154,13 +154,18 @@ func (c *circularTxpoolStats) calcAvg() txpoolStat {
 	return res
 }
 
-func (c *circularTxpoolStats) getGasPriceForGasAbove(gas uint64) *big.Int {
+// load returns the average statistic, and activates the frequent calculation of it
+func (c *circularTxpoolStats) load() txpoolStat {
 	atomic.StoreUint32(&c.activated, 1)
-	avg_c := c.avg.Load()
-	if avg_c == nil {
-		return new(big.Int)
-	}
-	avg := avg_c.(txpoolStat)
+	avg, _ := c.avg.Load().(txpoolStat)
+	return avg
+}
+
+func (c *circularTxpoolStats) getGasPriceForGasAbove(gas uint64) *big.Int {
+	return c.load().gasPriceForGasAbove(gas)
+}
+
+func (avg txpoolStat) gasPriceForGasAbove(gas uint64) *big.Int {
 	if avg.totalGas == 0 {
 		return new(big.Int)
 	}
@@ -195,13 +200,7 @@ func addBigI(a, b *big.Int) *big.Int {
 }
 
 func (c *circularTxpoolStats) totalGas() uint64 {
-	atomic.StoreUint32(&c.activated, 1)
-	avgC := c.avg.Load()
-	if avgC == nil {
-		return 0
-	}
-	avg := avgC.(txpoolStat)
-	return avg.totalGas
+	return c.load().totalGas
 }
 
 // calcTxpoolStat retrieves txpool transactions and calculates statistics
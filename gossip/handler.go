@@ -7,10 +7,13 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"math"
 	"math/rand"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	carmen "github.com/Fantom-foundation/Carmen/go/state"
+
 	"github.com/Fantom-foundation/go-opera/eventcheck"
 	"github.com/Fantom-foundation/go-opera/eventcheck/bvallcheck"
 	"github.com/Fantom-foundation/go-opera/eventcheck/epochcheck"
@@ -33,6 +36,10 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epstream"
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epstream/epstreamleecher"
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epstream/epstreamseeder"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream/snapstreamleecher"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream/snapstreamseeder"
+	"github.com/Fantom-foundation/go-opera/gossip/sonicmetrics"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/ibr"
 	"github.com/Fantom-foundation/go-opera/inter/ier"
@@ -63,12 +70,32 @@ const (
 	txChanSize = 4096
 )
 
+var (
+	errStreamTimeout = errors.New("no response to the stream request")
+	errSlowResponse  = errors.New("stream session is too slow")
+)
+
 var (
 	broadcastedTxsCounter = metrics.GetOrRegisterCounter("p2p_txs_broadcasted", nil)
 )
 
+// protocolError is a violation of the protocol by a peer
+type protocolError struct {
+	code errCode
+	msg  string
+}
+
+func (e *protocolError) Error() string {
+	return fmt.Sprintf("%v - %v", e.code, e.msg)
+}
+
 func errResp(code errCode, format string, v ...interface{}) error {
-	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
+	return &protocolError{code, fmt.Sprintf(format, v...)}
+}
+
+func isErrCode(err error, code errCode) bool {
+	var perr *protocolError
+	return errors.As(err, &perr) && perr.code == code
 }
 
 func checkLenLimits(size int, v interface{}) error {
@@ -93,6 +120,7 @@ type processCallback struct {
 	BR               func(ibr.LlrIdxFullBlockRecord) error
 	EV               func(inter.LlrSignedEpochVote) error
 	ER               func(ier.LlrIdxFullEpochRecord) error
+	StateSnapshot    func(idx.Epoch, string) error
 }
 
 // handlerConfig is the collection of initialization parameters to create a full
@@ -116,7 +144,8 @@ type handler struct {
 	txpool   TxPool
 	maxPeers int
 
-	peers *peerSet
+	peers      *peerSet
+	reputation *peerReputation
 
 	txsCh  chan evmcore.NewTxsNotify
 	txsSub notify.Subscription
@@ -138,6 +167,13 @@ type handler struct {
 	epSeeder    *epstreamseeder.Seeder
 	epProcessor *epprocessor.Processor
 
+	snapLeecher  *snapstreamleecher.Leecher
+	snapSeeder   *snapstreamseeder.Seeder
+	snapshots    *evmSnapshots
+	snapDownload *snapshotDownload
+	// snapMaxEpoch is the max epoch of the snapshot to download, lowered if no peer has the snapshot
+	snapMaxEpoch idx.Epoch
+
 	process processCallback
 
 	txFetcher *itemsfetcher.Fetcher
@@ -189,6 +225,7 @@ func newHandler(
 		process:              c.process,
 		checkers:             c.checkers,
 		peers:                newPeerSet(),
+		reputation:           newPeerReputation(c.config.Protocol.PeerReputation, c.s),
 		engineMu:             c.engineMu,
 		txsyncCh:             make(chan *txsync),
 		quitSync:             make(chan struct{}),
@@ -197,6 +234,12 @@ func newHandler(
 		Instance: logger.New("PM"),
 	}
 	h.started.Add(1)
+	if c.config.SyncMode == LlrSync {
+		h.syncStatus.setLlrSync(llrSyncApplying)
+	}
+	if c.config.SyncMode == SnapSync {
+		h.syncStatus.setSnapSync(snapSyncFetching)
+	}
 
 	h.dagFetcher = itemsfetcher.New(h.config.Protocol.DagFetcher, itemsfetcher.Callback{
 		OnlyInterested: func(ids []interface{}) []interface{} {
@@ -226,7 +269,7 @@ func newHandler(
 			return p.RequestEventsStream(r)
 		},
 		Suspend: func(_ string) bool {
-			return h.dagFetcher.Overloaded() || h.dagProcessor.Overloaded()
+			return h.dagFetcher.Overloaded() || h.dagProcessor.Overloaded() || !h.syncStatus.AcceptEvents()
 		},
 		PeerEpoch: func(peer string) idx.Epoch {
 			p := h.peers.Peer(peer)
@@ -235,6 +278,8 @@ func newHandler(
 			}
 			return p.GetProgress().Epoch
 		},
+		PeerScore:   h.peerScore,
+		PeerTimeout: h.onPeerTimeout,
 	})
 	h.dagSeeder = dagstreamseeder.New(h.config.Protocol.DagStreamSeeder, dagstreamseeder.Callbacks{
 		ForEachEvent: c.s.ForEachEventRLP,
@@ -271,6 +316,8 @@ func newHandler(
 			}
 			return p.GetProgress().LastBlockIdx
 		},
+		PeerScore:   h.peerScore,
+		PeerTimeout: h.onPeerTimeout,
 	})
 	h.bvSeeder = bvstreamseeder.New(h.config.Protocol.BvStreamSeeder, bvstreamseeder.Callbacks{
 		Iterate: h.store.IterateOverlappingBlockVotesRLP,
@@ -312,6 +359,8 @@ func newHandler(
 			}
 			return p.GetProgress().LastBlockIdx
 		},
+		PeerScore:   h.peerScore,
+		PeerTimeout: h.onPeerTimeout,
 	})
 	h.brSeeder = brstreamseeder.New(h.config.Protocol.BrStreamSeeder, brstreamseeder.Callbacks{
 		Iterate: h.store.IterateFullBlockRecordsRLP,
@@ -350,17 +399,63 @@ func newHandler(
 			}
 			return p.GetProgress().Epoch
 		},
+		PeerScore:   h.peerScore,
+		PeerTimeout: h.onPeerTimeout,
 	})
 	h.epSeeder = epstreamseeder.New(h.config.Protocol.EpStreamSeeder, epstreamseeder.Callbacks{
 		Iterate: h.store.IterateEpochPacksRLP,
 	})
 
+	snapshotsDir := h.config.Snapshots.Dir
+	if snapshotsDir == "" {
+		snapshotsDir = filepath.Join(filepath.Dir(h.store.cfg.EVM.StateDb.Directory), "snapshots")
+	}
+	if h.config.Snapshots.Serve && h.store.cfg.EVM.StateDb.Archive == carmen.NoArchive {
+		h.Log.Warn("EVM state snapshots are not served, as the archive is disabled")
+		h.config.Snapshots.Serve = false
+	}
+	h.snapshots = newEvmSnapshots(h.config.Snapshots, snapshotsDir, h.store)
+	h.snapDownload = newSnapshotDownload(snapshotsDir)
+	h.snapLeecher = snapstreamleecher.New(h.config.Protocol.SnapStreamLeecher, snapstreamleecher.Callbacks{
+		TargetEpoch: func() idx.Epoch {
+			if !h.syncStatus.AcceptSnapshotChunks() {
+				return 0
+			}
+			return h.snapDownload.Target()
+		},
+		LowestChunkToFill: h.snapDownload.LowestChunkToFill,
+		IsProcessed:       h.snapDownload.IsWritten,
+		RequestChunk: func(peer string, r snapstream.Request) error {
+			p := h.peers.Peer(peer)
+			if p == nil {
+				return errNotRegistered
+			}
+			return p.RequestSnapsStream(r)
+		},
+		Suspend: func(_ string) bool {
+			return false
+		},
+		PeerEpoch: func(peer string) idx.Epoch {
+			p := h.peers.Peer(peer)
+			if p == nil || p.Useless() {
+				return 0
+			}
+			return p.GetProgress().Epoch
+		},
+		PeerScore:   h.peerScore,
+		PeerTimeout: h.onPeerTimeout,
+	})
+	h.snapSeeder = snapstreamseeder.New(h.config.Protocol.SnapStreamSeeder, snapstreamseeder.Callbacks{
+		Iterate: h.snapshots.Iterate,
+	})
+
 	return h, nil
 }
 
 func (h *handler) peerMisbehaviour(peer string, err error) bool {
 	if eventcheck.IsBan(err) {
 		log.Warn("Dropping peer due to a misbehaviour", "peer", peer, "err", err)
+		h.penalizePeer(peer, h.config.Protocol.PeerReputation.MisbehaviourPenalty, err)
 		h.removePeer(peer)
 		return true
 	}
@@ -432,6 +527,7 @@ func (h *handler) makeDagProcessor(checkers *eventcheck.Checkers) *dagprocessor.
 			Released: func(e dag.Event, peer string, err error) {
 				if eventcheck.IsBan(err) {
 					log.Warn("Incoming event rejected", "event", e.ID().String(), "creator", e.Creator(), "err", err)
+					h.penalizePeer(peer, h.config.Protocol.PeerReputation.InvalidItemPenalty, err)
 					h.removePeer(peer)
 				}
 			},
@@ -476,6 +572,7 @@ func (h *handler) makeBvProcessor(checkers *eventcheck.Checkers) *bvprocessor.Pr
 			Released: func(bvs inter.LlrSignedBlockVotes, peer string, err error) {
 				if eventcheck.IsBan(err) {
 					log.Warn("Incoming BVs rejected", "BVs", bvs.Signed.Locator.ID(), "creator", bvs.Signed.Locator.Creator, "err", err)
+					h.penalizePeer(peer, h.config.Protocol.PeerReputation.InvalidItemPenalty, err)
 					h.removePeer(peer)
 				}
 			},
@@ -493,6 +590,7 @@ func (h *handler) makeBrProcessor() *brprocessor.Processor {
 			Released: func(br ibr.LlrIdxFullBlockRecord, peer string, err error) {
 				if eventcheck.IsBan(err) {
 					log.Warn("Incoming BR rejected", "block", br.Idx, "err", err)
+					h.penalizePeer(peer, h.config.Protocol.PeerReputation.InvalidItemPenalty, err)
 					h.removePeer(peer)
 				}
 			},
@@ -521,12 +619,14 @@ func (h *handler) makeEpProcessor(checkers *eventcheck.Checkers) *epprocessor.Pr
 			ReleasedEV: func(ev inter.LlrSignedEpochVote, peer string, err error) {
 				if eventcheck.IsBan(err) {
 					log.Warn("Incoming EV rejected", "event", ev.Signed.Locator.ID(), "creator", ev.Signed.Locator.Creator, "err", err)
+					h.penalizePeer(peer, h.config.Protocol.PeerReputation.InvalidItemPenalty, err)
 					h.removePeer(peer)
 				}
 			},
 			ReleasedER: func(er ier.LlrIdxFullEpochRecord, peer string, err error) {
 				if eventcheck.IsBan(err) {
 					log.Warn("Incoming ER rejected", "epoch", er.Idx, "err", err)
+					h.penalizePeer(peer, h.config.Protocol.PeerReputation.InvalidItemPenalty, err)
 					h.removePeer(peer)
 				}
 			},
@@ -567,6 +667,62 @@ func (h *handler) removePeer(id string) {
 	}
 }
 
+// penalizePeer decreases the reputation of the peer, disconnecting the peer if it gets banned
+func (h *handler) penalizePeer(id string, penalty int64, reason error) {
+	nodeID, err := enode.ParseID(id)
+	if err != nil {
+		return
+	}
+	log.Debug("Penalizing peer", "peer", id, "penalty", penalty, "err", reason)
+	if !h.reputation.Penalize(nodeID, penalty) {
+		return
+	}
+	peer := h.peers.Peer(id)
+	if peer != nil && !peer.Peer.Info().Network.Trusted {
+		log.Warn("Banning peer due to a low reputation", "peer", id, "err", reason)
+		peer.Peer.Disconnect(p2p.DiscUselessPeer)
+	}
+}
+
+// rewardAccepted returns a processing callback, which rewards the peer for a chunk if the chunk's last item
+// is stored once the chunk is processed. Returns nil if the item is known already, so known chunks aren't rewarded.
+func (h *handler) rewardAccepted(id string, stored func() bool) func() {
+	if stored() {
+		return nil
+	}
+	return func() {
+		if stored() {
+			h.rewardPeer(id)
+		}
+	}
+}
+
+// rewardPeer increases the reputation of the peer for a useful response
+func (h *handler) rewardPeer(id string) {
+	nodeID, err := enode.ParseID(id)
+	if err != nil {
+		return
+	}
+	h.reputation.Reward(nodeID)
+}
+
+func (h *handler) peerScore(id string) int64 {
+	nodeID, err := enode.ParseID(id)
+	if err != nil {
+		return 0
+	}
+	return h.reputation.Score(nodeID)
+}
+
+func (h *handler) onPeerTimeout(id string, noProgress bool) {
+	cfg := h.config.Protocol.PeerReputation
+	if noProgress {
+		h.penalizePeer(id, cfg.StreamTimeoutPenalty, errStreamTimeout)
+	} else {
+		h.penalizePeer(id, cfg.SlowResponsePenalty, errSlowResponse)
+	}
+}
+
 func (h *handler) unregisterPeer(id string) {
 	// Short circuit if the peer was already removed
 	peer := h.peers.Peer(id)
@@ -584,9 +740,12 @@ func (h *handler) unregisterPeer(id string) {
 	_ = h.brSeeder.UnregisterPeer(id)
 	_ = h.bvLeecher.UnregisterPeer(id)
 	_ = h.bvSeeder.UnregisterPeer(id)
+	_ = h.snapLeecher.UnregisterPeer(id)
+	_ = h.snapSeeder.UnregisterPeer(id)
 	if err := h.peers.UnregisterPeer(id); err != nil {
 		log.Error("Peer removal failed", "peer", id, "err", err)
 	}
+	h.reputation.Disconnected()
 }
 
 func (h *handler) Start(maxPeers int) {
@@ -612,6 +771,16 @@ func (h *handler) Start(maxPeers int) {
 		go h.progressBroadcastLoop()
 		go h.onNewEpochLoop()
 	}
+	if h.syncStatus.LlrSyncing() {
+		h.Log.Info("Starting LLR sync", "epoch", h.store.GetEpoch(), "block", h.store.GetLatestBlockIndex())
+		h.loopsWg.Add(1)
+		go h.llrSyncLoop()
+	}
+	if h.syncStatus.SnapSyncing() {
+		h.Log.Info("Starting snap sync", "epoch", h.store.GetEpoch(), "block", h.store.GetLatestBlockIndex())
+		h.loopsWg.Add(1)
+		go h.snapSyncLoop()
+	}
 
 	// start sync handlers
 	go h.txsyncLoop()
@@ -634,12 +803,18 @@ func (h *handler) Start(maxPeers int) {
 	h.brProcessor.Start()
 	h.brSeeder.Start()
 	h.brLeecher.Start()
+
+	h.snapSeeder.Start()
+	h.snapLeecher.Start()
 	h.started.Done()
 }
 
 func (h *handler) Stop() {
 	log.Info("Stopping Fantom protocol")
 
+	h.snapLeecher.Stop()
+	h.snapSeeder.Stop()
+
 	h.brLeecher.Stop()
 	h.brSeeder.Stop()
 	h.brProcessor.Stop()
@@ -669,6 +844,8 @@ func (h *handler) Stop() {
 
 	// Wait for the subscription loops to come down.
 	h.loopsWg.Wait()
+	h.snapshots.Stop()
+	h.snapDownload.Close()
 
 	h.msgSemaphore.Terminate()
 	// Quit the sync loop.
@@ -684,6 +861,7 @@ func (h *handler) Stop() {
 	// Wait for all peer handler goroutines to come down.
 	h.wg.Wait()
 	h.peerWG.Wait()
+	h.reputation.Flush()
 
 	log.Info("Fantom protocol stopped")
 }
@@ -724,6 +902,10 @@ func isUseless(node *enode.Node, name string) bool {
 // handle is the callback invoked to manage the life cycle of a peer. When
 // this function terminates, the peer is disconnected.
 func (h *handler) handle(p *peer) error {
+	if !p.Peer.Info().Network.Trusted && h.reputation.Banned(p.ID()) {
+		p.Log().Trace("Rejecting peer as banned")
+		return p2p.DiscUselessPeer
+	}
 	useless := isUseless(p.Node(), p.Name())
 	if !p.Peer.Info().Network.Trusted && useless && h.peers.UselessNum() >= h.maxPeers/10 {
 		// don't allow more than 10% of useless peers
@@ -766,7 +948,7 @@ func (h *handler) handle(p *peer) error {
 		p.Log().Warn("Leecher peer registration failed", "err", err)
 		return err
 	}
-	if p.RunningCap(ProtocolName, []uint{FTM63}) {
+	if p.RunningCap(ProtocolName, []uint{FTM63, FTM64}) {
 		if err := h.epLeecher.RegisterPeer(p.id); err != nil {
 			p.Log().Warn("Leecher peer registration failed", "err", err)
 			return err
@@ -780,6 +962,12 @@ func (h *handler) handle(p *peer) error {
 			return err
 		}
 	}
+	if p.RunningCap(ProtocolName, []uint{FTM64}) {
+		if err := h.snapLeecher.RegisterPeer(p.id); err != nil {
+			p.Log().Warn("Leecher peer registration failed", "err", err)
+			return err
+		}
+	}
 	defer h.unregisterPeer(p.id)
 
 	// Propagate existing transactions. new transactions appearing
@@ -790,6 +978,9 @@ func (h *handler) handle(p *peer) error {
 	for {
 		if err := h.handleMsg(p); err != nil {
 			p.Log().Debug("Message handling failed", "err", err)
+			if isErrCode(err, ErrMsgTooLarge) {
+				h.penalizePeer(p.id, h.config.Protocol.PeerReputation.MsgTooLargePenalty, err)
+			}
 			return err
 		}
 	}
@@ -853,6 +1044,9 @@ func (h *handler) handleTxs(p *peer, txs types.Transactions) {
 }
 
 func (h *handler) handleEventHashes(p *peer, announces hash.Events) {
+	if !h.syncStatus.AcceptEvents() {
+		return
+	}
 	// Mark the hashes as present at the remote node
 	for _, id := range announces {
 		p.MarkEvent(id)
@@ -879,7 +1073,10 @@ func (h *handler) handleEventHashes(p *peer, announces hash.Events) {
 	_ = h.dagFetcher.NotifyAnnounces(p.id, eventIDsToInterfaces(notTooHigh), time.Now(), requestEvents)
 }
 
-func (h *handler) handleEvents(p *peer, events dag.Events, ordered bool) {
+func (h *handler) handleEvents(p *peer, events dag.Events, ordered bool, done func()) {
+	if !h.syncStatus.AcceptEvents() {
+		return
+	}
 	// Mark the hashes as present at the remote node
 	now := time.Now()
 	for _, e := range events {
@@ -914,7 +1111,7 @@ func (h *handler) handleEvents(p *peer, events dag.Events, ordered bool) {
 	notifyAnnounces := func(ids hash.Events) {
 		_ = h.dagFetcher.NotifyAnnounces(peer.id, eventIDsToInterfaces(ids), now, requestEvents)
 	}
-	_ = h.dagProcessor.Enqueue(peer.id, notTooHigh, ordered, notifyAnnounces, nil)
+	_ = h.dagProcessor.Enqueue(peer.id, notTooHigh, ordered, notifyAnnounces, done)
 }
 
 // handleMsg is invoked whenever an inbound message is received from a remote
@@ -1000,7 +1197,7 @@ func (h *handler) handleMsg(p *peer) error {
 		txs := make(types.Transactions, 0, len(requests))
 		for _, txid := range requests {
 			tx := h.txpool.Get(txid)
-			if tx == nil {
+			if tx == nil || h.txpool.IsPrivate(txid) {
 				continue
 			}
 			txs = append(txs, tx)
@@ -1018,7 +1215,7 @@ func (h *handler) handleMsg(p *peer) error {
 			return err
 		}
 		_ = h.dagFetcher.NotifyReceived(eventIDsToInterfaces(events.IDs()))
-		h.handleEvents(p, events.Bases(), events.Len() > 1)
+		h.handleEvents(p, events.Bases(), events.Len() > 1, nil)
 
 	case msg.Code == NewEventIDsMsg:
 		var announces hash.Events
@@ -1100,10 +1297,12 @@ func (h *handler) handleMsg(p *peer) error {
 			last = chunk.IDs[len(chunk.IDs)-1]
 		}
 		if len(chunk.Events) != 0 {
-			h.handleEvents(p, chunk.Events.Bases(), true)
 			last = chunk.Events[len(chunk.Events)-1].ID()
+			h.handleEvents(p, chunk.Events.Bases(), true, h.rewardAccepted(p.id, func() bool {
+				return h.store.HasEvent(last)
+			}))
 		}
-
+		sonicmetrics.StreamReceived(sonicmetrics.StreamDag, len(chunk.Events)+len(chunk.IDs))
 		_ = h.dagLeecher.NotifyChunkReceived(chunk.SessionID, last, chunk.Done)
 
 	case msg.Code == RequestBVsStream:
@@ -1141,14 +1340,17 @@ func (h *handler) handleMsg(p *peer) error {
 
 		var last bvstreamleecher.BVsID
 		if len(chunk.BVs) != 0 {
-			_ = h.bvProcessor.Enqueue(p.id, chunk.BVs, nil)
 			last = bvstreamleecher.BVsID{
 				Epoch:     chunk.BVs[len(chunk.BVs)-1].Val.Epoch,
 				LastBlock: chunk.BVs[len(chunk.BVs)-1].Val.LastBlock(),
 				ID:        chunk.BVs[len(chunk.BVs)-1].Signed.Locator.ID(),
 			}
+			_ = h.bvProcessor.Enqueue(p.id, chunk.BVs, h.rewardAccepted(p.id, func() bool {
+				return h.store.HasBlockVotes(last.Epoch, last.LastBlock, last.ID)
+			}))
 		}
 
+		sonicmetrics.StreamReceived(sonicmetrics.StreamBlockVotes, len(chunk.BVs))
 		_ = h.bvLeecher.NotifyChunkReceived(chunk.SessionID, last, chunk.Done)
 
 	case msg.Code == RequestBRsStream:
@@ -1191,10 +1393,13 @@ func (h *handler) handleMsg(p *peer) error {
 
 		var last idx.Block
 		if len(chunk.BRs) != 0 {
-			_ = h.brProcessor.Enqueue(p.id, chunk.BRs, msgSize, nil)
 			last = chunk.BRs[len(chunk.BRs)-1].Idx
+			_ = h.brProcessor.Enqueue(p.id, chunk.BRs, msgSize, h.rewardAccepted(p.id, func() bool {
+				return h.store.HasBlock(last)
+			}))
 		}
 
+		sonicmetrics.StreamReceived(sonicmetrics.StreamBlockRecords, len(chunk.BRs))
 		_ = h.brLeecher.NotifyChunkReceived(chunk.SessionID, last, chunk.Done)
 
 	case msg.Code == RequestEPsStream:
@@ -1233,12 +1438,75 @@ func (h *handler) handleMsg(p *peer) error {
 
 		var last idx.Epoch
 		if len(chunk.EPs) != 0 {
-			_ = h.epProcessor.Enqueue(p.id, chunk.EPs, msgSize, nil)
 			last = chunk.EPs[len(chunk.EPs)-1].Record.Idx
+			_ = h.epProcessor.Enqueue(p.id, chunk.EPs, msgSize, h.rewardAccepted(p.id, func() bool {
+				return h.store.HasHistoryBlockEpochState(last)
+			}))
 		}
 
+		sonicmetrics.StreamReceived(sonicmetrics.StreamEpochPacks, len(chunk.EPs))
 		_ = h.epLeecher.NotifyChunkReceived(chunk.SessionID, last, chunk.Done)
 
+	case msg.Code == RequestSnapsStream:
+		var request snapstream.Request
+		if err := msg.Decode(&request); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if request.Limit.Num > hardLimitItems-1 {
+			return errResp(ErrMsgTooLarge, "%v", msg)
+		}
+		if request.Limit.Size > protocolMaxMsgSize*2/3 {
+			return errResp(ErrMsgTooLarge, "%v", msg)
+		}
+
+		pid := p.id
+		_, peerErr := h.snapSeeder.NotifyRequestReceived(snapstreamseeder.Peer{
+			ID:        pid,
+			SendChunk: p.SendSnapsStream,
+			Misbehaviour: func(err error) {
+				h.peerMisbehaviour(pid, err)
+			},
+		}, request)
+		if peerErr != nil {
+			return peerErr
+		}
+
+	case msg.Code == SnapsStreamResponse:
+		if !h.syncStatus.AcceptSnapshotChunks() {
+			break
+		}
+
+		var chunk snapsChunk
+		if err := msg.Decode(&chunk); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if err := checkLenLimits(len(chunk.Chunks)+1, chunk); err != nil {
+			return err
+		}
+
+		var last snapstream.Locator
+		accepted := false
+		for _, c := range chunk.Chunks {
+			written, err := h.snapDownload.Write(c)
+			if err != nil {
+				if errors.Is(err, errInvalidSnapshotChunk) {
+					h.penalizePeer(p.id, h.config.Protocol.PeerReputation.InvalidItemPenalty, err)
+				}
+				return err
+			}
+			accepted = accepted || written
+			last = c.Locator
+		}
+		if accepted {
+			h.rewardPeer(p.id)
+		} else if len(chunk.Chunks) == 0 && chunk.Done && !h.snapDownload.Complete() {
+			// the peer doesn't have the snapshot
+			h.snapLeecher.MarkMissing(p.id, h.snapDownload.Epoch())
+		}
+
+		sonicmetrics.StreamReceived(sonicmetrics.StreamSnapshots, len(chunk.Chunks))
+		_ = h.snapLeecher.NotifyChunkReceived(chunk.SessionID, last, chunk.Done)
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -1395,6 +1663,9 @@ func (h *handler) onNewEpochLoop() {
 		case myEpoch := <-h.newEpochsCh:
 			h.dagProcessor.Clear()
 			h.dagLeecher.OnNewEpoch(myEpoch)
+			if h.config.Snapshots.Serve && h.syncStatus.MaybeSynced() {
+				h.snapshots.ExportAsync(myEpoch)
+			}
 		// Err() channel will be closed when unsubscribing.
 		case <-h.newEpochsSub.Err():
 			return
@@ -0,0 +1,92 @@
+package gossip
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/gossip/sonicmetrics"
+)
+
+// validatorsLastSeen is the local time of processing the last event of each validator.
+// Unlike the event creation time, it isn't controlled by the validator.
+// It isn't persisted, so it's the zero time for the validators which have no events processed since the start.
+type validatorsLastSeen struct {
+	mu   sync.Mutex
+	seen map[idx.ValidatorID]time.Time
+}
+
+func (v *validatorsLastSeen) Set(id idx.ValidatorID, t time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.seen == nil {
+		v.seen = make(map[idx.ValidatorID]time.Time)
+	}
+	v.seen[id] = t
+}
+
+func (v *validatorsLastSeen) Get(id idx.ValidatorID) time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.seen[id]
+}
+
+// metricsState reads the consensus state for the Prometheus metrics.
+func (s *Service) metricsState() sonicmetrics.State {
+	es := s.store.GetEpochState()
+	llrs := s.store.GetLlrState()
+	state := sonicmetrics.State{
+		Epoch:                  es.Epoch,
+		Lamport:                s.store.GetHighestLamport(),
+		Block:                  s.store.GetLatestBlockIndex(),
+		LlrLowestEpochToDecide: llrs.LowestEpochToDecide,
+		LlrLowestEpochToFill:   llrs.LowestEpochToFill,
+		LlrLowestBlockToDecide: llrs.LowestBlockToDecide,
+		LlrLowestBlockToFill:   llrs.LowestBlockToFill,
+	}
+
+	if lastEvents := s.store.GetLastEvents(es.Epoch); lastEvents != nil {
+		lastEvents.RLock()
+		ids := make(map[idx.ValidatorID]*sonicmetrics.ValidatorState, len(lastEvents.Val))
+		for vid, id := range lastEvents.Val {
+			e := s.store.GetEvent(id)
+			if e == nil {
+				continue
+			}
+			ids[vid] = &sonicmetrics.ValidatorState{
+				ID:       vid,
+				LastSeen: s.lastSeen.Get(vid),
+				Frame:    e.Frame(),
+				Lamport:  e.Lamport(),
+			}
+		}
+		lastEvents.RUnlock()
+		for _, vid := range es.Validators.SortedIDs() {
+			if v, ok := ids[vid]; ok {
+				state.Validators = append(state.Validators, *v)
+			}
+		}
+	}
+
+	if height, empty, err := s.store.evm.GetArchiveBlockHeight(); err == nil && !empty {
+		state.ArchiveBlock = idx.Block(height)
+		state.ArchiveEnabled = true
+	}
+	return state
+}
+
+// registerMetrics registers the collector of the consensus state.
+func (s *Service) registerMetrics() {
+	s.metricsCollector = sonicmetrics.NewCollector(s.metricsState)
+	if err := sonicmetrics.Registry.Register(s.metricsCollector); err != nil {
+		s.Log.Warn("Failed to register metrics", "err", err)
+		s.metricsCollector = nil
+	}
+}
+
+func (s *Service) unregisterMetrics() {
+	if s.metricsCollector != nil {
+		sonicmetrics.Registry.Unregister(s.metricsCollector)
+	}
+}
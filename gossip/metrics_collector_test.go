@@ -0,0 +1,28 @@
+package gossip
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsStateLastSeen(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+
+	start := time.Now()
+	_, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, big.NewInt(1)))
+	require.NoError(err)
+
+	// the local processing time is reported, rather than the event creation time
+	state := env.metricsState()
+	require.Len(state.Validators, 3)
+	for _, v := range state.Validators {
+		require.False(v.LastSeen.Before(start), v.ID)
+		require.False(v.LastSeen.After(time.Now()), v.ID)
+	}
+}
@@ -839,3 +839,79 @@ func TestMisbehaviourProofsWrongVote(t *testing.T) {
 	require.False(env.store.GetValidators().Exists(1))
 	require.False(env.store.GetValidators().Exists(2))
 }
+
+func TestMisbehaviourProofsAutoSubmission(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	const validatorsNum = 3
+
+	env := newTestEnv(2, validatorsNum, t)
+	defer env.Close()
+
+	// move epoch further
+	_, err := env.ApplyTxs(nextEpoch, env.Transfer(1, 1, common.Big0))
+	require.NoError(err)
+	_, err = env.ApplyTxs(nextEpoch, env.Transfer(1, 1, common.Big0))
+	require.NoError(err)
+
+	// validator 2 signs different votes for the same block
+	block := env.store.GetLatestBlockIndex()
+	votes := []inter.LlrBlockVotes{
+		{
+			Start: block - 1,
+			Epoch: env.store.GetEpoch() - 1,
+			Votes: []hash.Hash{hash.Zero, hash.HexToHash("0x01")},
+		},
+		{
+			Start: block,
+			Epoch: env.store.GetEpoch() - 1,
+			Votes: []hash.Hash{hash.HexToHash("0x02")},
+		},
+	}
+	for i, v := range votes {
+		e := &inter.MutableEventPayload{}
+		e.SetVersion(1)
+		e.SetBlockVotes(v)
+		e.SetEpoch(env.store.GetEpoch() - idx.Epoch(i))
+		e.SetCreator(2)
+		e.SetPayloadHash(inter.CalcPayloadHash(e))
+
+		sig, err := env.signer.Sign(env.pubkeys[1], e.HashToSign().Bytes())
+		require.NoError(err)
+		sSig := inter.Signature{}
+		copy(sSig[:], sig)
+		e.SetSig(sSig)
+
+		require.NoError(env.ProcessBlockVotes(inter.AsSignedBlockVotes(e)))
+	}
+
+	pending := env.store.GetPendingMisbehaviourProofs(10)
+	require.Len(pending, 1)
+	require.NotNil(pending[0].BlockVoteDoublesign)
+	require.Equal(block, pending[0].BlockVoteDoublesign.Block)
+	require.False(env.store.AddMisbehaviourProof(pending[0]), "the same proof is added twice")
+
+	submitted := 0
+	env.callback.onEventConfirmed = func(e inter.EventI) {
+		if e.AnyMisbehaviourProofs() {
+			submitted++
+		}
+	}
+	defer func() {
+		env.callback.onEventConfirmed = nil
+	}()
+
+	// the proof gets submitted by the emitters, and the cheater is excluded in the next epoch
+	_, err = env.ApplyTxs(nextEpoch, env.Transfer(1, 1, common.Big0))
+	require.NoError(err)
+	_, err = env.ApplyTxs(nextEpoch, env.Transfer(1, 1, common.Big0))
+	require.NoError(err)
+
+	require.Equal(1, submitted)
+	require.True(env.store.IsCheaterReported(2))
+	require.Empty(env.store.GetPendingMisbehaviourProofs(10))
+	require.False(env.store.AddMisbehaviourProof(pending[0]), "the cheater is already reported")
+	require.Equal(idx.Validator(2), env.store.GetValidators().Len())
+	require.False(env.store.GetValidators().Exists(2))
+}
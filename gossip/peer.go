@@ -22,6 +22,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/blockvotes/bvstream"
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/dag/dagstream"
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epstream"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
 	"github.com/Fantom-foundation/go-opera/inter"
 )
 
@@ -473,6 +474,14 @@ func (p *peer) RequestEPsStream(r epstream.Request) error {
 	return p2p.Send(p.rw, RequestEPsStream, r)
 }
 
+func (p *peer) SendSnapsStream(r snapstream.Response) error {
+	return p2p.Send(p.rw, SnapsStreamResponse, r)
+}
+
+func (p *peer) RequestSnapsStream(r snapstream.Request) error {
+	return p2p.Send(p.rw, RequestSnapsStream, r)
+}
+
 func (p *peer) SendEventsStream(r dagstream.Response, ids hash.Events) error {
 	// Mark all the event hash as known, but ensure we don't overflow our limits
 	for _, id := range ids {
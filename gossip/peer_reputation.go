@@ -0,0 +1,175 @@
+package gossip
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// peerReputation tracks the scores of the peers. A score decreases by weighted penalties on misbehaviours,
+// increases on useful responses and gradually returns to zero over time. The peers with too low scores get
+// banned temporarily. Penalties and bans are persisted, so they survive restarts.
+type peerReputation struct {
+	cfg   PeerReputationConfig
+	store *Store
+	now   func() time.Time
+
+	mu    sync.Mutex
+	peers map[enode.ID]*PeerReputation
+}
+
+func newPeerReputation(cfg PeerReputationConfig, store *Store) *peerReputation {
+	r := &peerReputation{
+		cfg:   cfg,
+		store: store,
+		now:   time.Now,
+		peers: make(map[enode.ID]*PeerReputation),
+	}
+	store.ForEachPeerReputation(func(id enode.ID, rep PeerReputation) bool {
+		r.peers[id] = &rep
+		return true
+	})
+	return r
+}
+
+// recoverScore moves the score towards zero by one point per each passed RecoveryPeriod
+func (r *peerReputation) recoverScore(rep *PeerReputation, now time.Time) {
+	steps := int64(now.Sub(rep.Updated) / r.cfg.RecoveryPeriod)
+	if steps <= 0 {
+		return
+	}
+	if rep.Score > 0 {
+		if steps > rep.Score {
+			steps = rep.Score
+		}
+		rep.Score -= steps
+	} else {
+		if steps > -rep.Score {
+			steps = -rep.Score
+		}
+		rep.Score += steps
+	}
+	if rep.Score == 0 {
+		rep.Updated = now
+	} else {
+		rep.Updated = rep.Updated.Add(time.Duration(steps) * r.cfg.RecoveryPeriod)
+	}
+}
+
+// get returns the actual reputation of the peer, creating a neutral one if the peer is unknown.
+// r.mu must be locked.
+func (r *peerReputation) get(id enode.ID, now time.Time) *PeerReputation {
+	rep, ok := r.peers[id]
+	if !ok {
+		rep = &PeerReputation{Updated: now}
+		r.peers[id] = rep
+	}
+	r.recoverScore(rep, now)
+	return rep
+}
+
+// Penalize decreases the score of the peer, banning the peer if the score drops to BanScore.
+// Returns true if the peer is banned.
+func (r *peerReputation) Penalize(id enode.ID, penalty int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	rep := r.get(id, now)
+	rep.Score -= penalty
+	if rep.Score <= r.cfg.BanScore {
+		rep.Score = r.cfg.BanScore
+		if !rep.Banned(now) {
+			rep.BannedUntil = now.Add(r.cfg.BanDuration)
+		}
+	}
+	r.store.SetPeerReputation(id, *rep)
+	return rep.Banned(now)
+}
+
+// Reward increases the score of the peer for a useful response.
+func (r *peerReputation) Reward(id enode.ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := r.get(id, r.now())
+	rep.Score += r.cfg.ChunkReward
+	if rep.Score > r.cfg.MaxScore {
+		rep.Score = r.cfg.MaxScore
+	}
+}
+
+// Score returns the actual score of the peer.
+func (r *peerReputation) Score(id enode.ID) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep, ok := r.peers[id]
+	if !ok {
+		return 0
+	}
+	r.recoverScore(rep, r.now())
+	return rep.Score
+}
+
+// Banned returns true if the peer is banned.
+func (r *peerReputation) Banned(id enode.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep, ok := r.peers[id]
+	return ok && rep.Banned(r.now())
+}
+
+// Reputations returns the actual reputations of all the known peers.
+func (r *peerReputation) Reputations() map[enode.ID]PeerReputation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	res := make(map[enode.ID]PeerReputation, len(r.peers))
+	for id, rep := range r.peers {
+		r.recoverScore(rep, now)
+		res[id] = *rep
+	}
+	return res
+}
+
+// Disconnected forgets the peers which are neutral and not banned once a peer disconnects,
+// so only the peers with an actual reputation are kept. The disconnected peers with a score
+// are forgotten on the following disconnections, once their scores return to zero.
+func (r *peerReputation) Disconnected() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for id, rep := range r.peers {
+		r.forgetNeutral(id, rep, now)
+	}
+}
+
+// Flush persists the reputations, forgetting the peers which are neutral and not banned.
+func (r *peerReputation) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for id, rep := range r.peers {
+		if !r.forgetNeutral(id, rep, now) {
+			r.store.SetPeerReputation(id, *rep)
+		}
+	}
+}
+
+// forgetNeutral forgets the peer if its actual score is zero and it's not banned.
+// A neutral reputation is recreated on demand, so nothing is lost. r.mu must be locked.
+func (r *peerReputation) forgetNeutral(id enode.ID, rep *PeerReputation, now time.Time) bool {
+	r.recoverScore(rep, now)
+	if rep.Score != 0 || rep.Banned(now) {
+		return false
+	}
+	delete(r.peers, id)
+	r.store.DelPeerReputation(id)
+	return true
+}
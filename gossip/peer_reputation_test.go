@@ -0,0 +1,112 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerReputation(t *testing.T) {
+	require := require.New(t)
+
+	store, err := NewMemStore(t)
+	require.NoError(err)
+	defer store.Close()
+
+	cfg := DefaultPeerReputationConfig()
+	now := time.Unix(1700000000, 0)
+	newReputation := func() *peerReputation {
+		r := newPeerReputation(cfg, store)
+		r.now = func() time.Time {
+			return now
+		}
+		return r
+	}
+	r := newReputation()
+
+	good := enode.ID{1}
+	bad := enode.ID{2}
+
+	// rewards are capped
+	for i := int64(0); i < cfg.MaxScore+10; i++ {
+		r.Reward(good)
+	}
+	require.Equal(cfg.MaxScore, r.Score(good))
+
+	// penalties accumulate until the ban
+	require.False(r.Penalize(bad, cfg.InvalidItemPenalty))
+	require.Equal(-cfg.InvalidItemPenalty, r.Score(bad))
+	require.False(r.Banned(bad))
+	require.True(r.Penalize(bad, cfg.InvalidItemPenalty))
+	require.True(r.Banned(bad))
+	require.Equal(cfg.BanScore, r.Score(bad))
+
+	// scores return to zero over time
+	now = now.Add(10 * cfg.RecoveryPeriod)
+	require.Equal(cfg.MaxScore-10, r.Score(good))
+	require.Equal(cfg.BanScore+10, r.Score(bad))
+
+	// the ban and the scores survive the restart
+	r.Flush()
+	r = newReputation()
+	require.True(r.Banned(bad))
+	require.Equal(cfg.BanScore+10, r.Score(bad))
+	require.Equal(cfg.MaxScore-10, r.Score(good))
+
+	// the ban expires
+	now = now.Add(cfg.BanDuration)
+	require.False(r.Banned(bad))
+	require.Equal(int64(0), r.Score(bad))
+
+	// neutral peers are forgotten
+	r.Flush()
+	r = newReputation()
+	require.Len(r.Reputations(), 0)
+
+	// neutral peers are forgotten on disconnections, the ones with a score are kept until it returns to zero
+	for i := byte(3); i < 100; i++ {
+		r.Reward(enode.ID{i})
+		now = now.Add(cfg.RecoveryPeriod)
+	}
+	r.Reward(good)
+	require.False(r.Penalize(bad, cfg.MisbehaviourPenalty))
+	require.Len(r.Reputations(), 99)
+	r.Disconnected()
+	require.Len(r.Reputations(), 2)
+	now = now.Add(time.Duration(cfg.MisbehaviourPenalty) * cfg.RecoveryPeriod)
+	r.Disconnected()
+	require.Len(r.Reputations(), 0)
+	r.Flush()
+	r = newReputation()
+	require.Len(r.Reputations(), 0)
+}
+
+func TestRewardAcceptedChunks(t *testing.T) {
+	require := require.New(t)
+
+	store, err := NewMemStore(t)
+	require.NoError(err)
+	defer store.Close()
+
+	h := &handler{reputation: newPeerReputation(DefaultPeerReputationConfig(), store)}
+	id := enode.ID{1}
+	reward := DefaultPeerReputationConfig().ChunkReward
+
+	// a known chunk isn't rewarded
+	require.Nil(h.rewardAccepted(id.String(), func() bool { return true }))
+
+	// a rejected chunk isn't rewarded
+	stored := false
+	done := h.rewardAccepted(id.String(), func() bool { return stored })
+	done()
+	require.Zero(h.peerScore(id.String()))
+
+	// an accepted chunk is rewarded once it's processed
+	done = h.rewardAccepted(id.String(), func() bool { return stored })
+	require.Zero(h.peerScore(id.String()))
+	stored = true
+	done()
+	require.Equal(reward, h.peerScore(id.String()))
+}
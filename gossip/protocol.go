@@ -10,6 +10,7 @@ import (
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/emitter"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/ibr"
 	"github.com/Fantom-foundation/go-opera/inter/iep"
@@ -19,17 +20,18 @@ import (
 const (
 	FTM62           = 62
 	FTM63           = 63
-	ProtocolVersion = FTM63
+	FTM64           = 64
+	ProtocolVersion = FTM64
 )
 
 // ProtocolName is the official short name of the protocol used during capability negotiation.
 const ProtocolName = "opera"
 
 // ProtocolVersions are the supported versions of the protocol (first is primary).
-var ProtocolVersions = []uint{FTM62, FTM63}
+var ProtocolVersions = []uint{FTM62, FTM63, FTM64}
 
 // protocolLengths are the number of implemented message corresponding to different protocol versions.
-var protocolLengths = map[uint]uint64{FTM62: EventsStreamResponse + 1, FTM63: EPsStreamResponse + 1}
+var protocolLengths = map[uint]uint64{FTM62: EventsStreamResponse + 1, FTM63: EPsStreamResponse + 1, FTM64: SnapsStreamResponse + 1}
 
 const protocolMaxMsgSize = inter.ProtocolMaxMsgSize // Maximum cap on the size of a protocol message
 
@@ -68,6 +70,11 @@ const (
 	BRsStreamResponse = 13
 	RequestEPsStream  = 14
 	EPsStreamResponse = 15
+
+	// Request a range of the EVM state snapshot chunks
+	RequestSnapsStream = 16
+	// Contains the requested snapshot chunks by RequestSnapsStream
+	SnapsStreamResponse = 17
 )
 
 type errCode int
@@ -110,6 +117,8 @@ type TxPool interface {
 	AddRemotes([]*types.Transaction) []error
 	AddLocals(txs []*types.Transaction) []error
 	AddLocal(tx *types.Transaction) error
+	// AddPrivate should add the transaction, which must never be announced to peers.
+	AddPrivate(tx *types.Transaction) error
 
 	Get(common.Hash) *types.Transaction
 
@@ -121,6 +130,12 @@ type TxPool interface {
 	Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	ContentFrom(addr common.Address) (types.Transactions, types.Transactions)
 	GasPrice() *big.Int
+
+	RemoveTx(hash common.Hash) bool
+	RemoveSender(addr common.Address) int
+	QueuedReasons() map[common.Address]map[uint64]error
+	Denylist() evmcore.TxDenylist
+	UpdateDenylist(add, remove evmcore.TxDenylist) (int, error)
 }
 
 // handshakeData is the network packet for the initial handshake message
@@ -163,3 +178,9 @@ type epsChunk struct {
 	Done      bool
 	EPs       []iep.LlrEpochPack
 }
+
+type snapsChunk struct {
+	SessionID uint32
+	Done      bool
+	Chunks    []snapstream.Chunk
+}
@@ -1,7 +1,6 @@
 package dagstreamleecher
 
 import (
-	"math/rand"
 	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/gossip/basestream/basestreamleecher"
@@ -11,6 +10,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/dag/dagstream"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/peerselect"
 )
 
 // Leecher is responsible for requesting events based on lexicographic event streams
@@ -60,6 +60,12 @@ type Callbacks struct {
 	RequestChunk func(peer string, r dagstream.Request) error
 	Suspend      func(peer string) bool
 	PeerEpoch    func(peer string) idx.Epoch
+
+	// PeerScore returns the reputation of the peer, the peers with a higher score are preferred
+	PeerScore func(peer string) int64
+	// PeerTimeout is called when the session is terminated due to the peer's slowness,
+	// noProgress is true if the peer hasn't responded within the progress watchdog
+	PeerTimeout func(peer string, noProgress bool)
 }
 
 type sessionState struct {
@@ -78,6 +84,9 @@ func (d *Leecher) shouldTerminateSession() bool {
 
 	noProgress := time.Since(d.session.lastReceived) >= d.cfg.BaseProgressWatchdog*time.Duration(d.session.try+5)/5
 	stuck := time.Since(d.session.startTime) >= d.cfg.BaseSessionWatchdog*time.Duration(d.session.try+5)/5
+	if (stuck || noProgress) && d.callback.PeerTimeout != nil && !d.callback.Suspend(d.session.peer) {
+		d.callback.PeerTimeout(d.session.peer, noProgress)
+	}
 	return stuck || noProgress
 }
 
@@ -139,7 +148,7 @@ func getSessionID(epoch idx.Epoch, try uint32) uint32 {
 }
 
 func (d *Leecher) startSession(candidates []string) {
-	peer := candidates[rand.Intn(len(candidates))]
+	peer := peerselect.Pick(candidates, d.callback.PeerScore)
 
 	typ := dagstream.RequestIDs
 	if d.callback.PeerEpoch(peer) > d.epoch && d.emptyState && d.session.try == 0 {
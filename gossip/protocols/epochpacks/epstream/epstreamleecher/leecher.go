@@ -1,7 +1,6 @@
 package epstreamleecher
 
 import (
-	"math/rand"
 	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/gossip/basestream/basestreamleecher"
@@ -9,6 +8,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 
 	"github.com/Fantom-foundation/go-opera/gossip/protocols/epochpacks/epstream"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/peerselect"
 )
 
 // Leecher is responsible for requesting EPs based on lexicographic EPs streams
@@ -55,6 +55,12 @@ type Callbacks struct {
 	RequestChunk func(peer string, r epstream.Request) error
 	Suspend      func(peer string) bool
 	PeerEpoch    func(peer string) idx.Epoch
+
+	// PeerScore returns the reputation of the peer, the peers with a higher score are preferred
+	PeerScore func(peer string) int64
+	// PeerTimeout is called when the session is terminated due to the peer's slowness,
+	// noProgress is true if the peer hasn't responded within the progress watchdog
+	PeerTimeout func(peer string, noProgress bool)
 }
 
 type sessionState struct {
@@ -77,6 +83,9 @@ func (d *Leecher) shouldTerminateSession() bool {
 
 	noProgress := time.Since(d.session.lastReceived) >= d.cfg.BaseProgressWatchdog*time.Duration(d.session.try+5)/5
 	stuck := time.Since(d.session.startTime) >= d.cfg.BaseSessionWatchdog*time.Duration(d.session.try+5)/5
+	if (stuck || noProgress) && d.callback.PeerTimeout != nil && !d.callback.Suspend(d.session.peer) {
+		d.callback.PeerTimeout(d.session.peer, noProgress)
+	}
 	return stuck || noProgress
 }
 
@@ -127,7 +136,7 @@ func getSessionID(epoch idx.Epoch, try uint32) uint32 {
 }
 
 func (d *Leecher) startSession(candidates []string) {
-	peer := candidates[rand.Intn(len(candidates))]
+	peer := peerselect.Pick(candidates, d.callback.PeerScore)
 
 	start := d.callback.LowestEpochToFetch()
 	end := d.callback.MaxEpochToFetch()
@@ -0,0 +1,44 @@
+package peerselect
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Pick selects a random session peer among the candidates, preferring the peers with a higher score.
+// The chance of a peer is proportional to the rank of its score, so that the peers with a low score
+// still get sessions sometimes and may recover their reputation.
+func Pick(candidates []string, score func(peer string) int64) string {
+	if score == nil || len(candidates) == 1 {
+		return candidates[rand.Intn(len(candidates))]
+	}
+	scores := make(map[string]int64, len(candidates))
+	sorted := make([]string, len(candidates))
+	for i, peer := range candidates {
+		scores[peer] = score(peer)
+		sorted[i] = peer
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scores[sorted[i]] < scores[sorted[j]]
+	})
+
+	// peers with equal scores get equal ranks
+	ranks := make([]int, len(sorted))
+	total := 0
+	rank := 0
+	for i, peer := range sorted {
+		if i == 0 || scores[peer] != scores[sorted[i-1]] {
+			rank++
+		}
+		ranks[i] = rank
+		total += rank
+	}
+	r := rand.Intn(total)
+	for i, rank := range ranks {
+		if r < rank {
+			return sorted[i]
+		}
+		r -= rank
+	}
+	return sorted[len(sorted)-1]
+}
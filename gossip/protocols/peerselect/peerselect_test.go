@@ -0,0 +1,31 @@
+package peerselect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPick(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("a", Pick([]string{"a"}, nil))
+	require.Contains([]string{"a", "b"}, Pick([]string{"a", "b"}, nil))
+
+	scores := map[string]int64{
+		"bad":     -50,
+		"neutral": 0,
+		"good":    100,
+	}
+	score := func(peer string) int64 {
+		return scores[peer]
+	}
+	picked := map[string]int{}
+	for i := 0; i < 6000; i++ {
+		picked[Pick([]string{"good", "bad", "neutral"}, score)]++
+	}
+	// the chances are 3:1:2
+	require.Greater(picked["good"], picked["neutral"])
+	require.Greater(picked["neutral"], picked["bad"])
+	require.Greater(picked["bad"], 0)
+}
@@ -0,0 +1,42 @@
+package snapstreamleecher
+
+import (
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/gossip/basestream/basestreamleecher/basepeerleecher"
+)
+
+type Config struct {
+	Session              basepeerleecher.EpochDownloaderConfig
+	RecheckInterval      time.Duration
+	BaseProgressWatchdog time.Duration
+	BaseSessionWatchdog  time.Duration
+	MinSessionRestart    time.Duration
+	MaxSessionRestart    time.Duration
+}
+
+// DefaultConfig returns default leecher config
+func DefaultConfig() Config {
+	return Config{
+		Session: basepeerleecher.EpochDownloaderConfig{
+			DefaultChunkItemsNum:   16,
+			DefaultChunkItemsSize:  4 * 1024 * 1024,
+			ParallelChunksDownload: 4,
+			RecheckInterval:        10 * time.Millisecond,
+		},
+		RecheckInterval:      time.Second,
+		BaseProgressWatchdog: time.Second * 10,
+		BaseSessionWatchdog:  time.Minute * 30,
+		MinSessionRestart:    time.Second * 5,
+		MaxSessionRestart:    time.Minute * 5,
+	}
+}
+
+// LiteConfig returns default leecher config for tests
+func LiteConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Session.DefaultChunkItemsSize /= 10
+	cfg.Session.DefaultChunkItemsNum /= 10
+	cfg.Session.ParallelChunksDownload = cfg.Session.ParallelChunksDownload/2 + 1
+	return cfg
+}
@@ -0,0 +1,247 @@
+package snapstreamleecher
+
+import (
+	"math"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/gossip/basestream/basestreamleecher"
+	"github.com/Fantom-foundation/lachesis-base/gossip/basestream/basestreamleecher/basepeerleecher"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/peerselect"
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
+)
+
+// Leecher is responsible for requesting the chunks of an EVM state snapshot based on snapshot streams
+type Leecher struct {
+	*basestreamleecher.BaseLeecher
+
+	// Callbacks
+	callback Callbacks
+
+	cfg Config
+
+	// State
+	session sessionState
+
+	// missing are the peers which have reported that they don't have the snapshot of the epoch
+	missing map[string]idx.Epoch
+
+	paused bool
+}
+
+// New creates a snapshot downloader to request the chunks of an EVM state snapshot based on snapshot streams
+func New(cfg Config, callback Callbacks) *Leecher {
+	l := &Leecher{
+		cfg:      cfg,
+		callback: callback,
+		missing:  make(map[string]idx.Epoch),
+	}
+	l.BaseLeecher = basestreamleecher.New(cfg.RecheckInterval, basestreamleecher.Callbacks{
+		SelectSessionPeerCandidates: l.selectSessionPeerCandidates,
+		ShouldTerminateSession:      l.shouldTerminateSession,
+		StartSession:                l.startSession,
+		TerminateSession:            l.terminateSession,
+		OngoingSession: func() bool {
+			return l.session.agent != nil
+		},
+		OngoingSessionPeer: func() string {
+			return l.session.peer
+		},
+	})
+	return l
+}
+
+type Callbacks struct {
+	// TargetEpoch returns the epoch of the snapshot to download, or 0 if there's nothing to download
+	TargetEpoch func() idx.Epoch
+	// LowestChunkToFill returns the first chunk of the target snapshot which isn't downloaded yet
+	LowestChunkToFill func() uint32
+	IsProcessed       func(lastChunk snapstream.Locator) bool
+
+	RequestChunk func(peer string, r snapstream.Request) error
+	Suspend      func(peer string) bool
+	PeerEpoch    func(peer string) idx.Epoch
+
+	// PeerScore returns the reputation of the peer, the peers with a higher score are preferred
+	PeerScore func(peer string) int64
+	// PeerTimeout is called when the session is terminated due to the peer's slowness,
+	// noProgress is true if the peer hasn't responded within the progress watchdog
+	PeerTimeout func(peer string, noProgress bool)
+}
+
+type sessionState struct {
+	agent        *basepeerleecher.BasePeerLeecher
+	peer         string
+	startTime    time.Time
+	endTime      time.Time
+	lastReceived time.Time
+	try          uint32
+
+	sessionID uint32
+
+	epoch             idx.Epoch
+	lowestChunkToFill uint32
+}
+
+func (d *Leecher) shouldTerminateSession() bool {
+	if d.paused || d.session.agent.Stopped() || d.session.epoch != d.callback.TargetEpoch() {
+		return true
+	}
+
+	noProgress := time.Since(d.session.lastReceived) >= d.cfg.BaseProgressWatchdog*time.Duration(d.session.try+5)/5
+	stuck := time.Since(d.session.startTime) >= d.cfg.BaseSessionWatchdog*time.Duration(d.session.try+5)/5
+	if (stuck || noProgress) && d.callback.PeerTimeout != nil && !d.callback.Suspend(d.session.peer) {
+		d.callback.PeerTimeout(d.session.peer, noProgress)
+	}
+	return stuck || noProgress
+}
+
+func (d *Leecher) terminateSession() {
+	// force the snapshot download to end
+	if d.session.agent != nil {
+		d.session.agent.Terminate()
+		d.session.agent = nil
+		d.session.endTime = time.Now()
+		if d.callback.LowestChunkToFill() >= d.session.lowestChunkToFill+d.cfg.Session.DefaultChunkItemsNum {
+			// reset the counter of unsuccessful sync attempts
+			d.session.try = 0
+		}
+	}
+}
+
+func (d *Leecher) Pause() {
+	d.Mu.Lock()
+	defer d.Mu.Unlock()
+	d.paused = true
+	d.terminateSession()
+}
+
+func (d *Leecher) Resume() {
+	d.Mu.Lock()
+	defer d.Mu.Unlock()
+	d.paused = false
+}
+
+// knowledgeablePeers returns the peers which may have the snapshot of the epoch
+func (d *Leecher) knowledgeablePeers(epoch idx.Epoch) []string {
+	peers := make([]string, 0, len(d.Peers))
+	for p := range d.Peers {
+		if d.missing[p] == epoch {
+			continue
+		}
+		if d.callback.PeerEpoch(p) > epoch {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+func (d *Leecher) selectSessionPeerCandidates() []string {
+	if d.paused {
+		return nil
+	}
+	epoch := d.callback.TargetEpoch()
+	if epoch == 0 {
+		return nil
+	}
+	knowledgeablePeers := d.knowledgeablePeers(epoch)
+	sinceEnd := time.Since(d.session.endTime)
+	waitUntilProcessed := d.session.try == 0 || sinceEnd > d.cfg.MinSessionRestart
+	if waitUntilProcessed && len(knowledgeablePeers) > 0 {
+		return knowledgeablePeers
+	}
+	return nil
+}
+
+func getSessionID(epoch idx.Epoch, chunk uint32, try uint32) uint32 {
+	return (uint32(epoch) << 20) ^ (chunk << 8) ^ try
+}
+
+func (d *Leecher) startSession(candidates []string) {
+	peer := peerselect.Pick(candidates, d.callback.PeerScore)
+
+	epoch := d.callback.TargetEpoch()
+	start := d.callback.LowestChunkToFill()
+	session := snapstream.Session{
+		ID:    getSessionID(epoch, start, d.session.try),
+		Start: snapstream.Locator{Epoch: epoch, Chunk: start},
+		Stop:  snapstream.Locator{Epoch: epoch, Chunk: math.MaxUint32},
+	}
+
+	d.session.agent = basepeerleecher.New(&d.Wg, d.cfg.Session, basepeerleecher.EpochDownloaderCallbacks{
+		IsProcessed: func(id interface{}) bool {
+			lastChunk := id.(snapstream.Locator)
+			return d.callback.IsProcessed(lastChunk)
+		},
+		RequestChunks: func(maxNum uint32, maxSize uint64, chunks uint32) error {
+			return d.callback.RequestChunk(peer,
+				snapstream.Request{
+					Session:   session,
+					Limit:     snapstream.Metric{Num: maxNum, Size: maxSize},
+					Type:      0,
+					MaxChunks: chunks,
+				})
+		},
+		Suspend: func() bool {
+			return d.callback.Suspend(peer)
+		},
+		Done: func() bool {
+			return d.callback.TargetEpoch() != epoch
+		},
+	})
+
+	now := time.Now()
+	d.session.startTime = now
+	d.session.lastReceived = now
+	d.session.endTime = now
+	d.session.try++
+	d.session.peer = peer
+	d.session.sessionID = session.ID
+	d.session.epoch = epoch
+	d.session.lowestChunkToFill = start
+
+	d.session.agent.Start()
+}
+
+// MarkMissing marks that the peer doesn't have the snapshot of the epoch,
+// so the snapshot won't be requested from the peer anymore
+func (d *Leecher) MarkMissing(peer string, epoch idx.Epoch) {
+	d.Mu.Lock()
+	defer d.Mu.Unlock()
+	d.missing[peer] = epoch
+	if d.session.agent != nil && d.session.peer == peer && d.session.epoch == epoch {
+		d.terminateSession()
+	}
+}
+
+// Stalled returns true if none of the peers, which are ahead of the snapshot epoch, has the snapshot
+func (d *Leecher) Stalled(epoch idx.Epoch) bool {
+	d.Mu.Lock()
+	defer d.Mu.Unlock()
+	ahead := 0
+	for p := range d.Peers {
+		if d.callback.PeerEpoch(p) > epoch {
+			ahead++
+		}
+	}
+	return ahead > 0 && len(d.knowledgeablePeers(epoch)) == 0
+}
+
+func (d *Leecher) NotifyChunkReceived(sessionID uint32, lastChunk snapstream.Locator, done bool) error {
+	d.Mu.Lock()
+	defer d.Mu.Unlock()
+	if d.session.agent == nil {
+		return nil
+	}
+	if d.session.sessionID != sessionID {
+		return nil
+	}
+
+	d.session.lastReceived = time.Now()
+	if done {
+		d.terminateSession()
+		return nil
+	}
+	return d.session.agent.NotifyChunkReceived(lastChunk)
+}
@@ -0,0 +1,63 @@
+package snapstreamleecher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
+)
+
+func TestLeecherMissingSnapshot(t *testing.T) {
+	requests := make(chan string, 1000)
+	config := LiteConfig()
+	config.RecheckInterval = time.Millisecond
+	config.Session.RecheckInterval = time.Millisecond
+	peerEpochs := map[string]idx.Epoch{
+		"a": 10,
+		"b": 20,
+		"c": 5,
+	}
+	leecher := New(config, Callbacks{
+		TargetEpoch: func() idx.Epoch {
+			return 8
+		},
+		LowestChunkToFill: func() uint32 {
+			return 0
+		},
+		IsProcessed: func(lastChunk snapstream.Locator) bool {
+			return false
+		},
+		RequestChunk: func(peer string, r snapstream.Request) error {
+			require.Equal(t, idx.Epoch(8), r.Session.Start.Epoch)
+			requests <- peer
+			return nil
+		},
+		Suspend: func(peer string) bool {
+			return false
+		},
+		PeerEpoch: func(peer string) idx.Epoch {
+			return peerEpochs[peer]
+		},
+	})
+	for peer := range peerEpochs {
+		require.NoError(t, leecher.RegisterPeer(peer))
+	}
+	leecher.Start()
+	defer leecher.Stop()
+
+	// the peer behind the snapshot epoch isn't requested
+	peer := <-requests
+	require.NotEqual(t, "c", peer)
+	require.False(t, leecher.Stalled(8))
+
+	leecher.MarkMissing("a", 8)
+	require.False(t, leecher.Stalled(8))
+	leecher.MarkMissing("b", 8)
+	require.True(t, leecher.Stalled(8))
+	// a snapshot of another epoch may be requested from the peers
+	require.False(t, leecher.Stalled(7))
+}
@@ -0,0 +1,89 @@
+package snapstreamseeder
+
+import (
+	"errors"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/lachesis-base/gossip/basestream"
+	"github.com/Fantom-foundation/lachesis-base/gossip/basestream/basestreamseeder"
+
+	"github.com/Fantom-foundation/go-opera/gossip/protocols/snapshots/snapstream"
+)
+
+var (
+	ErrWrongType        = errors.New("wrong request type")
+	ErrWrongSelectorLen = errors.New("session selector spans multiple snapshots")
+)
+
+type Seeder struct {
+	*basestreamseeder.BaseSeeder
+}
+
+type Callbacks struct {
+	// Iterate iterates over the chunks of the EVM state snapshot of the epoch, starting from the start chunk.
+	// Nothing is iterated if the snapshot isn't available.
+	Iterate func(epoch idx.Epoch, start uint32, f func(chunk uint32, data []byte) bool)
+}
+
+type Peer struct {
+	ID           string
+	SendChunk    func(snapstream.Response) error
+	Misbehaviour func(error)
+}
+
+func New(cfg Config, callbacks Callbacks) *Seeder {
+	return &Seeder{
+		BaseSeeder: basestreamseeder.New(basestreamseeder.Config(cfg), basestreamseeder.Callbacks{
+			ForEachItem: func(start basestream.Locator, _ basestream.RequestType, onKey func(key basestream.Locator) bool, onAppended func(items basestream.Payload) bool) basestream.Payload {
+				res := &snapstream.Payload{
+					Chunks: []snapstream.Chunk{},
+					Size:   0,
+				}
+				st := start.(snapstream.Locator)
+				callbacks.Iterate(st.Epoch, st.Chunk, func(chunk uint32, data []byte) bool {
+					key := snapstream.Locator{
+						Epoch: st.Epoch,
+						Chunk: chunk,
+					}
+					if !onKey(key) {
+						return false
+					}
+					res.AddChunk(key, data)
+					return onAppended(res)
+				})
+				return res
+			},
+		}),
+	}
+}
+
+func (s *Seeder) NotifyRequestReceived(peer Peer, r snapstream.Request) (err error, peerErr error) {
+	if r.Type != 0 {
+		return nil, ErrWrongType
+	}
+	if r.Session.Start.Epoch != r.Session.Stop.Epoch {
+		return nil, ErrWrongSelectorLen
+	}
+	return s.BaseSeeder.NotifyRequestReceived(basestreamseeder.Peer{
+		ID: peer.ID,
+		SendChunk: func(response basestream.Response) error {
+			return peer.SendChunk(snapstream.Response{
+				SessionID: response.SessionID,
+				Done:      response.Done,
+				Payload:   response.Payload.(*snapstream.Payload).Chunks,
+			})
+		},
+		Misbehaviour: peer.Misbehaviour,
+	}, basestream.Request{
+		Session: basestream.Session{
+			ID:    r.Session.ID,
+			Start: r.Session.Start,
+			Stop:  r.Session.Stop,
+		},
+		Type:           r.Type,
+		MaxPayloadNum:  r.Limit.Num,
+		MaxPayloadSize: r.Limit.Size,
+		MaxChunks:      r.MaxChunks,
+	})
+}
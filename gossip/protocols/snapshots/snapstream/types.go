@@ -0,0 +1,103 @@
+package snapstream
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/lachesis-base/gossip/basestream"
+)
+
+// ChunkSize is the approximate size of the accounts, the storage slots and the codes of a snapshot chunk,
+// not counting its Merkle proofs
+const ChunkSize = 256 * 1024
+
+type Request struct {
+	Session   Session
+	Limit     Metric
+	Type      basestream.RequestType
+	MaxChunks uint32
+}
+
+type Response struct {
+	SessionID uint32
+	Done      bool
+	Payload   []Chunk
+}
+
+type Session struct {
+	ID    uint32
+	Start Locator
+	Stop  Locator
+}
+
+// Locator is the position of a chunk in the EVM state snapshot of an epoch
+type Locator struct {
+	Epoch idx.Epoch
+	Chunk uint32
+}
+
+func (l Locator) Compare(b basestream.Locator) int {
+	bl := b.(Locator)
+	if l.Epoch != bl.Epoch {
+		if l.Epoch < bl.Epoch {
+			return -1
+		}
+		return 1
+	}
+	if l.Chunk == bl.Chunk {
+		return 0
+	}
+	if l.Chunk < bl.Chunk {
+		return -1
+	}
+	return 1
+}
+
+func (l Locator) Inc() basestream.Locator {
+	return Locator{
+		Epoch: l.Epoch,
+		Chunk: l.Chunk + 1,
+	}
+}
+
+// Chunk is a piece of the EVM state snapshot, which is proven against the state root of the epoch
+// given the previous chunks, see evmstore.SnapshotChunk
+type Chunk struct {
+	Locator Locator
+	Data    []byte
+}
+
+type Payload struct {
+	Chunks []Chunk
+	Size   uint64
+}
+
+func (p *Payload) AddChunk(id Locator, data []byte) {
+	p.Chunks = append(p.Chunks, Chunk{
+		Locator: id,
+		Data:    data,
+	})
+	p.Size += uint64(len(data))
+}
+
+func (p Payload) Len() int {
+	return len(p.Chunks)
+}
+
+func (p Payload) TotalSize() uint64 {
+	return p.Size
+}
+
+func (p Payload) TotalMemSize() int {
+	return int(p.Size) + len(p.Chunks)*32
+}
+
+type Metric struct {
+	Num  uint32
+	Size uint64
+}
+
+func (m Metric) String() string {
+	return fmt.Sprintf("{Num=%d,Size=%d}", m.Num, m.Size)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/Fantom-foundation/go-opera/ethapi"
 	"github.com/Fantom-foundation/go-opera/eventcheck"
@@ -60,6 +61,8 @@ type ServiceFeed struct {
 	newEmittedEvent notify.Feed
 	newBlock        notify.Feed
 	newLogs         notify.Feed
+	newDagEvent     notify.Feed
+	newEpochState   notify.Feed
 }
 
 func (f *ServiceFeed) SubscribeNewEpoch(ch chan<- idx.Epoch) notify.Subscription {
@@ -78,6 +81,14 @@ func (f *ServiceFeed) SubscribeNewLogs(ch chan<- []*types.Log) notify.Subscripti
 	return f.scope.Track(f.newLogs.Subscribe(ch))
 }
 
+func (f *ServiceFeed) SubscribeNewDagEvent(ch chan<- *inter.EventPayload) notify.Subscription {
+	return f.scope.Track(f.newDagEvent.Subscribe(ch))
+}
+
+func (f *ServiceFeed) SubscribeNewEpochState(ch chan<- filters.NewEpochNotify) notify.Subscription {
+	return f.scope.Track(f.newEpochState.Subscribe(ch))
+}
+
 type BlockProc struct {
 	SealerModule     blockproc.SealerModule
 	TxListenerModule blockproc.TxListenerModule
@@ -119,6 +130,7 @@ type Service struct {
 	gasPowerCheckReader GasPowerCheckReader
 	checkers            *eventcheck.Checkers
 	uniqueEventIDs      uniqueID
+	wrongVotes          *wrongVotes
 
 	// version watcher
 	verWatcher *verwatcher.VerWarcher
@@ -145,6 +157,9 @@ type Service struct {
 
 	procLogger *proclogger.Logger
 
+	metricsCollector prometheus.Collector
+	lastSeen         validatorsLastSeen
+
 	stopped   bool
 	haltCheck func(oldEpoch, newEpoch idx.Epoch, time time.Time) bool
 
@@ -188,12 +203,18 @@ func newService(config Config, store *Store, blockProc BlockProc, engine lachesi
 		dagIndexer:         dagIndexer,
 		engineMu:           new(sync.RWMutex),
 		uniqueEventIDs:     uniqueID{new(big.Int)},
+		wrongVotes:         newWrongVotes(),
 		procLogger:         proclogger.NewLogger(),
 		Instance:           logger.New("gossip-service"),
 	}
 
 	svc.blockProcTasks = workers.New(new(sync.WaitGroup), svc.blockProcTasksDone, 1)
 
+	// the live state, diverged from the decided records by the LLR sync, may not be continued
+	if block, failed := svc.store.GetLlrSyncFailed(); failed {
+		return nil, fmt.Errorf("the state doesn't match the LLR record of block %d, the node has to be synced from scratch", block)
+	}
+
 	// load epoch DB
 	svc.store.loadEpochStore(svc.store.GetEpoch())
 	es := svc.store.getEpochStore(svc.store.GetEpoch())
@@ -213,6 +234,9 @@ func newService(config Config, store *Store, blockProc BlockProc, engine lachesi
 	netVerStore.GetNetworkVersion()
 	netVerStore.GetMissedVersion()
 
+	// hide archive states out of the archive window
+	svc.store.UpdateArchiveWindow(svc.store.GetLatestBlockIndex(), svc.store.GetEpoch())
+
 	// create GPO
 	svc.gpo = gasprice.NewOracle(svc.config.GPO)
 
@@ -255,6 +279,7 @@ func newService(config Config, store *Store, blockProc BlockProc, engine lachesi
 			BR:               svc.ProcessFullBlockRecord,
 			EV:               svc.ProcessEpochVote,
 			ER:               svc.ProcessFullEpochRecord,
+			StateSnapshot:    svc.applyStateSnapshot,
 		},
 	})
 	if err != nil {
@@ -345,6 +370,12 @@ func (s *Service) RegisterEmitter(em *emitter.Emitter) {
 // MakeProtocols constructs the P2P protocol definitions for `opera`.
 func MakeProtocols(svc *Service, backend *handler, disc enode.Iterator) []p2p.Protocol {
 	protocols := make([]p2p.Protocol, len(ProtocolVersions))
+	if disc != nil {
+		// don't dial the banned peers
+		disc = enode.Filter(disc, func(n *enode.Node) bool {
+			return !backend.reputation.Banned(n.ID())
+		})
+	}
 	for i, version := range ProtocolVersions {
 		version := version // Closure
 
@@ -408,6 +439,11 @@ func (s *Service) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   s.netRPCService,
 			Public:    true,
+		}, {
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewPrivateAdminAPI(s),
+			Public:    false,
 		},
 	}...)
 
@@ -428,6 +464,7 @@ func (s *Service) APIs() []rpc.API {
 
 // Start method invoked when the node is ready to start the service.
 func (s *Service) Start() error {
+	s.gpo.SetHistoryStore(s.store.table.GasPriceHistory, s.feed.SubscribeNewBlock)
 	s.gpo.Start(&GPOBackend{s.store, s.txpool})
 	// start tflusher before starting snapshots generation
 	s.tflusher.Start()
@@ -449,6 +486,7 @@ func (s *Service) Start() error {
 	}
 
 	s.verWatcher.Start()
+	s.registerMetrics()
 
 	if s.haltCheck != nil && s.haltCheck(s.store.GetEpoch(), s.store.GetEpoch(), s.store.GetBlockState().LastBlock.Time.Time()) {
 		// halt syncing
@@ -466,6 +504,7 @@ func (s *Service) WaitBlockEnd() {
 // Stop method invoked when the node terminates the service.
 func (s *Service) Stop() error {
 	defer log.Info("Fantom service stopped")
+	s.unregisterMetrics()
 	s.verWatcher.Stop()
 	for _, em := range s.emitters {
 		em.Stop()
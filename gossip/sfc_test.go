@@ -44,6 +44,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/contract/driverauth100"
 	"github.com/Fantom-foundation/go-opera/gossip/contract/netinit100"
 	"github.com/Fantom-foundation/go-opera/gossip/contract/sfc100"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/logger"
 	"github.com/Fantom-foundation/go-opera/opera/contracts/driver"
 	"github.com/Fantom-foundation/go-opera/opera/contracts/driverauth"
@@ -224,3 +225,28 @@ func circleTransfers(t *testing.T, env *testEnv, count uint64) {
 		)
 	}
 }
+
+func TestSFCUpdateValidatorPubkey(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+
+	sfc10, err := sfc100.NewContract(sfc.ContractAddress, env)
+	require.NoError(err)
+	validator := idx.ValidatorID(2)
+	pubkey := validatorpk.PubKey{Type: validatorpk.Types.Secp256k1, Raw: append([]byte{0x04}, make([]byte, 64)...)}
+	prev := env.store.GetEpochState().ValidatorProfiles[validator].PubKey
+
+	// the call of sonictool validator rotate, sent from the validator auth address
+	tx, err := sfc10.UpdateValidatorPubkey(env.Pay(validator), pubkey.Bytes())
+	require.NoError(err)
+	rr, err := env.ApplyTxs(nextEpoch, tx)
+	require.NoError(err)
+	require.Equal(1, rr.Len())
+
+	// the genesis SFC doesn't implement the method, the rotation needs an SFC version which does
+	require.Equal(types.ReceiptStatusFailed, rr[0].Status)
+	require.Equal(prev, env.store.GetEpochState().ValidatorProfiles[validator].PubKey)
+}
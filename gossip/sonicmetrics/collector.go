@@ -0,0 +1,111 @@
+package sonicmetrics
+
+import (
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ValidatorState is the state of a validator, by its last event of the current epoch.
+type ValidatorState struct {
+	ID       idx.ValidatorID
+	LastSeen time.Time // local time of processing the last event, zero if no event is processed since the start
+	Frame    idx.Frame
+	Lamport  idx.Lamport
+}
+
+// State is the consensus state of the node, which is read on each scrape.
+type State struct {
+	Epoch      idx.Epoch
+	Lamport    idx.Lamport
+	Block      idx.Block
+	Validators []ValidatorState
+
+	LlrLowestEpochToDecide idx.Epoch
+	LlrLowestEpochToFill   idx.Epoch
+	LlrLowestBlockToDecide idx.Block
+	LlrLowestBlockToFill   idx.Block
+
+	// ArchiveBlock is the last block of the archive state, if ArchiveEnabled
+	ArchiveBlock   idx.Block
+	ArchiveEnabled bool
+}
+
+func newDesc(subsystem, name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, labels, nil)
+}
+
+var (
+	epochDesc             = newDesc("consensus", "epoch", "Current epoch.")
+	frameDesc             = newDesc("consensus", "frame", "Highest frame of the current epoch events.")
+	lamportDesc           = newDesc("consensus", "lamport", "Highest lamport time of the events.")
+	blockDesc             = newDesc("chain", "block", "Last processed block.")
+	validatorLastSeenDesc = newDesc("consensus", "validator_last_seen_timestamp_seconds", "Local time of processing the last event of the validator in the current epoch.", "validator")
+	validatorFrameDesc    = newDesc("consensus", "validator_frame", "Frame of the last event of the validator in the current epoch.", "validator")
+	validatorLamportDesc  = newDesc("consensus", "validator_lamport", "Lamport time of the last event of the validator in the current epoch.", "validator")
+	llrEpochDesc          = newDesc("llr", "lowest_epoch", "Lowest epoch which isn't decided or filled by the LLR votes yet.", "progress")
+	llrBlockDesc          = newDesc("llr", "lowest_block", "Lowest block which isn't decided or filled by the LLR votes yet.", "progress")
+	archiveBlockDesc      = newDesc("archive", "block", "Last block of the archive state.")
+	archiveLagDesc        = newDesc("archive", "lag_blocks", "Number of processed blocks missing in the archive state.")
+)
+
+type collector struct {
+	read func() State
+}
+
+// NewCollector returns the collector of the consensus state, which is read on each scrape.
+func NewCollector(read func() State) prometheus.Collector {
+	return &collector{read: read}
+}
+
+// Describe implements prometheus.Collector.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		epochDesc, frameDesc, lamportDesc, blockDesc,
+		validatorLastSeenDesc, validatorFrameDesc, validatorLamportDesc,
+		llrEpochDesc, llrBlockDesc, archiveBlockDesc, archiveLagDesc,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.read()
+	gauge := func(desc *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, labels...)
+	}
+
+	var frame idx.Frame
+	for _, v := range s.Validators {
+		label := ValidatorLabel(v.ID)
+		// the time is unknown for the events processed before the restart
+		if !v.LastSeen.IsZero() {
+			gauge(validatorLastSeenDesc, float64(v.LastSeen.UnixNano())/1e9, label)
+		}
+		gauge(validatorFrameDesc, float64(v.Frame), label)
+		gauge(validatorLamportDesc, float64(v.Lamport), label)
+		if frame < v.Frame {
+			frame = v.Frame
+		}
+	}
+	gauge(epochDesc, float64(s.Epoch))
+	gauge(frameDesc, float64(frame))
+	gauge(lamportDesc, float64(s.Lamport))
+	gauge(blockDesc, float64(s.Block))
+
+	gauge(llrEpochDesc, float64(s.LlrLowestEpochToDecide), "decide")
+	gauge(llrEpochDesc, float64(s.LlrLowestEpochToFill), "fill")
+	gauge(llrBlockDesc, float64(s.LlrLowestBlockToDecide), "decide")
+	gauge(llrBlockDesc, float64(s.LlrLowestBlockToFill), "fill")
+
+	if s.ArchiveEnabled {
+		gauge(archiveBlockDesc, float64(s.ArchiveBlock))
+		lag := idx.Block(0)
+		if s.Block > s.ArchiveBlock {
+			lag = s.Block - s.ArchiveBlock
+		}
+		gauge(archiveLagDesc, float64(lag))
+	}
+}
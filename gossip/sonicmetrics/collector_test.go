@@ -0,0 +1,95 @@
+package sonicmetrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	require := require.New(t)
+
+	state := State{
+		Epoch:   5,
+		Lamport: 100,
+		Block:   20,
+		Validators: []ValidatorState{
+			{ID: 1, LastSeen: time.Unix(1000, 0), Frame: 7, Lamport: 99},
+			{ID: 2, LastSeen: time.Unix(1001, 0), Frame: 8, Lamport: 100},
+			{ID: 3, Frame: 6, Lamport: 98}, // not seen since the start
+		},
+		LlrLowestEpochToDecide: 4,
+		LlrLowestEpochToFill:   3,
+		LlrLowestBlockToDecide: 19,
+		LlrLowestBlockToFill:   18,
+	}
+	c := NewCollector(func() State { return state })
+
+	expected := `
+# HELP sonic_consensus_frame Highest frame of the current epoch events.
+# TYPE sonic_consensus_frame gauge
+sonic_consensus_frame 8
+# HELP sonic_consensus_validator_last_seen_timestamp_seconds Local time of processing the last event of the validator in the current epoch.
+# TYPE sonic_consensus_validator_last_seen_timestamp_seconds gauge
+sonic_consensus_validator_last_seen_timestamp_seconds{validator="1"} 1000
+sonic_consensus_validator_last_seen_timestamp_seconds{validator="2"} 1001
+# HELP sonic_llr_lowest_block Lowest block which isn't decided or filled by the LLR votes yet.
+# TYPE sonic_llr_lowest_block gauge
+sonic_llr_lowest_block{progress="decide"} 19
+sonic_llr_lowest_block{progress="fill"} 18
+`
+	require.NoError(testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"sonic_consensus_frame", "sonic_consensus_validator_last_seen_timestamp_seconds", "sonic_llr_lowest_block"))
+	// the archive metrics are exported only if the archive is enabled
+	require.Equal(0, testutil.CollectAndCount(c, "sonic_archive_lag_blocks"))
+
+	state.ArchiveEnabled = true
+	state.ArchiveBlock = 15
+	require.Equal(float64(5), gaugeValue(t, c, "sonic_archive_lag_blocks"))
+}
+
+func TestRegistryCollector(t *testing.T) {
+	require := require.New(t)
+
+	// the go-ethereum metrics are no-op if disabled
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	reg := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("chain/txs", reg).Inc(3)
+	metrics.NewRegisteredGauge("chain-txs", reg).Update(4)
+	metrics.NewRegisteredTimer("chain/execution", reg).Update(time.Second)
+
+	c := NewRegistryCollector(reg)
+	// the names are the same after the sanitization, only the first one is exported
+	require.Equal(1, testutil.CollectAndCount(c, "chain_txs"))
+	require.Equal(float64(4), gaugeValue(t, c, "chain_txs"))
+
+	r := prometheus.NewRegistry()
+	r.MustRegister(c)
+	families, err := r.Gather()
+	require.NoError(err)
+	require.Len(families, 2)
+	require.Equal("chain_execution", families[0].GetName())
+	require.Equal(uint64(1), families[0].GetMetric()[0].GetSummary().GetSampleCount())
+}
+
+func gaugeValue(t *testing.T, c prometheus.Collector, name string) float64 {
+	r := prometheus.NewRegistry()
+	r.MustRegister(c)
+	families, err := r.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() == name {
+			return f.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
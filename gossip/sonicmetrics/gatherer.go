@@ -0,0 +1,79 @@
+package sonicmetrics
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+var summaryQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// registryCollector exports a go-ethereum metrics registry in the same way as its Prometheus handler does,
+// i.e. counters, gauges and meters as gauges, histograms and timers as summaries.
+type registryCollector struct {
+	reg metrics.Registry
+}
+
+// NewRegistryCollector returns the collector of the go-ethereum metrics registry.
+func NewRegistryCollector(reg metrics.Registry) prometheus.Collector {
+	return &registryCollector{reg: reg}
+}
+
+// Describe implements prometheus.Collector. The collector is unchecked, as the registry is dynamic.
+func (c *registryCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c *registryCollector) Collect(ch chan<- prometheus.Metric) {
+	var names []string
+	c.reg.Each(func(name string, _ interface{}) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	// different names may be the same after the sanitization, only the first one is exported
+	exported := make(map[string]bool, len(names))
+	for _, name := range names {
+		promName := invalidNameChars.ReplaceAllString(name, "_")
+		if exported[promName] {
+			continue
+		}
+		exported[promName] = true
+		desc := prometheus.NewDesc(promName, name, nil, nil)
+
+		switch m := c.reg.Get(name).(type) {
+		case metrics.Counter:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(m.Count()))
+		case metrics.Gauge:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(m.Value()))
+		case metrics.GaugeFloat64:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, m.Value())
+		case metrics.Meter:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(m.Count()))
+		case metrics.Histogram:
+			s := m.Snapshot()
+			ch <- prometheus.MustNewConstSummary(desc, uint64(s.Count()), float64(s.Sum()), quantiles(s.Percentiles(summaryQuantiles)))
+		case metrics.Timer:
+			s := m.Snapshot()
+			ch <- prometheus.MustNewConstSummary(desc, uint64(s.Count()), float64(s.Sum()), quantiles(s.Percentiles(summaryQuantiles)))
+		}
+	}
+}
+
+func quantiles(values []float64) map[float64]float64 {
+	res := make(map[float64]float64, len(summaryQuantiles))
+	for i, q := range summaryQuantiles {
+		res[q] = values[i]
+	}
+	return res
+}
+
+// Gatherer returns the gatherer of the Sonic metrics and the go-ethereum default registry.
+func Gatherer() prometheus.Gatherer {
+	eth := prometheus.NewRegistry()
+	eth.MustRegister(NewRegistryCollector(metrics.DefaultRegistry))
+	return prometheus.Gatherers{Registry, eth}
+}
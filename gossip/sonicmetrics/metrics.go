@@ -0,0 +1,89 @@
+// Package sonicmetrics provides the Prometheus-native metrics of the node, with labels.
+// The metrics of go-ethereum registry are exported along with them, see Gatherer.
+package sonicmetrics
+
+import (
+	"strconv"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "sonic"
+
+// Registry is the registry of the Sonic metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// EmitterGasPowerLeft is the gas power left of the validator after its last emitted event.
+	EmitterGasPowerLeft = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "emitter",
+		Name:      "gas_power_left",
+		Help:      "Gas power left of the validator after its last emitted event.",
+	}, []string{"validator", "term"})
+
+	// EventTimeToConfirm is the time from the creation of an emitted event till its confirmation.
+	EventTimeToConfirm = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "emitter",
+		Name:      "event_time_to_confirm_seconds",
+		Help:      "Time from the creation of an emitted event till its confirmation.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 0.75, 1, 1.5, 2, 3, 5, 10, 30},
+	}, []string{"validator"})
+
+	// BlockExecution is the time of the block transactions execution.
+	BlockExecution = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "chain",
+		Name:      "block_execution_seconds",
+		Help:      "Time of the block transactions execution.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+
+	// StreamChunks is the number of chunks received by the leecher of a protocol stream.
+	StreamChunks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "p2p",
+		Name:      "stream_chunks_received_total",
+		Help:      "Number of chunks received by the leecher of a protocol stream.",
+	}, []string{"stream"})
+
+	// StreamItems is the number of items received by the leecher of a protocol stream.
+	StreamItems = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "p2p",
+		Name:      "stream_items_received_total",
+		Help:      "Number of items received by the leecher of a protocol stream.",
+	}, []string{"stream"})
+)
+
+// The protocol streams, the values of the stream label.
+const (
+	StreamDag          = "dag"
+	StreamBlockVotes   = "bvs"
+	StreamBlockRecords = "brs"
+	StreamEpochPacks   = "eps"
+	StreamSnapshots    = "snaps"
+)
+
+func init() {
+	Registry.MustRegister(
+		EmitterGasPowerLeft,
+		EventTimeToConfirm,
+		BlockExecution,
+		StreamChunks,
+		StreamItems,
+	)
+}
+
+// ValidatorLabel formats the validator ID as the validator label value.
+func ValidatorLabel(id idx.ValidatorID) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// StreamReceived records a chunk of items received by the leecher of the stream.
+func StreamReceived(stream string, items int) {
+	StreamChunks.WithLabelValues(stream).Inc()
+	StreamItems.WithLabelValues(stream).Add(float64(items))
+}
@@ -41,12 +41,14 @@ type Store struct {
 
 		// P2P-only
 		HighestLamport kvdb.Store `table:"l"`
+		PeerReputation kvdb.Store `table:"N"`
 
 		// Network version
 		NetworkVersion kvdb.Store `table:"V"`
 
 		// API-only
-		BlockHashes kvdb.Store `table:"B"`
+		BlockHashes     kvdb.Store `table:"B"`
+		GasPriceHistory kvdb.Store `table:"O"`
 
 		LlrState           kvdb.Store `table:"S"`
 		LlrBlockResults    kvdb.Store `table:"R"`
@@ -57,6 +59,9 @@ type Store struct {
 		LlrEpochVoteIndex  kvdb.Store `table:"I"`
 		LlrLastBlockVotes  kvdb.Store `table:"G"`
 		LlrLastEpochVote   kvdb.Store `table:"F"`
+
+		// Misbehaviour proofs to submit by the emitter
+		MisbehaviourProofs kvdb.Store `table:"M"`
 	}
 
 	prevFlushTime time.Time
@@ -0,0 +1,43 @@
+package gossip
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// UpdateArchiveWindow moves the archive window, so only the archive states
+// of the configured number of the latest blocks or epochs are queryable.
+// If both limits are configured, the wider window is used.
+func (s *Store) UpdateArchiveWindow(head idx.Block, epoch idx.Epoch) {
+	cfg := s.cfg.EVM
+	if cfg.ArchiveVisibleBlocks == 0 && cfg.ArchiveVisibleEpochs == 0 {
+		return
+	}
+	var (
+		oldest idx.Block
+		set    bool
+	)
+	keep := func(n idx.Block) {
+		if !set || n < oldest {
+			oldest = n
+		}
+		set = true
+	}
+	if cfg.ArchiveVisibleBlocks != 0 {
+		if uint64(head) < cfg.ArchiveVisibleBlocks {
+			keep(0)
+		} else {
+			keep(head - idx.Block(cfg.ArchiveVisibleBlocks) + 1)
+		}
+	}
+	if cfg.ArchiveVisibleEpochs != 0 {
+		if uint64(epoch) <= cfg.ArchiveVisibleEpochs {
+			keep(0)
+		} else if bs, _ := s.GetHistoryBlockEpochState(epoch - idx.Epoch(cfg.ArchiveVisibleEpochs) + 1); bs != nil {
+			// the first block of the epoch follows the last block of the previous epoch
+			keep(bs.LastBlock.Idx + 1)
+		} else {
+			keep(0)
+		}
+	}
+	s.evm.SetOldestArchiveBlock(oldest)
+}
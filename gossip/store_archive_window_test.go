@@ -0,0 +1,87 @@
+package gossip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestArchiveWindow_Blocks(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	cfg := MemTestStoreConfig(t.TempDir())
+	cfg.EVM.ArchiveVisibleBlocks = 3
+	env := newTestEnvWithStoreConfig(2, 3, t, cfg)
+	defer env.Close()
+
+	first := env.store.GetLatestBlockIndex() + 1
+	for i := 0; i < 5; i++ {
+		_, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+		require.NoError(err)
+	}
+	waitForArchive(t, env)
+
+	last := env.store.GetLatestBlockIndex()
+	oldest := last - 2
+	require.Equal(uint64(oldest), env.store.evm.GetOldestArchiveBlock())
+
+	for n := first; n <= last; n++ {
+		block := env.store.GetBlock(n)
+		statedb, err := env.store.evm.GetRpcStateDb(big.NewInt(int64(n)), common.Hash(block.Root))
+		if n < oldest {
+			require.ErrorIs(err, evmstore.ErrStateUnavailable, "block %d", n)
+			continue
+		}
+		require.NoError(err, "block %d", n)
+		statedb.Release()
+	}
+
+	res, err := ethapi.NewPublicBlockChainAPI(env.EthAPI).GetArchiveBlockHeight(context.Background())
+	require.NoError(err)
+	require.Equal(hexutil.Uint64(oldest), res.Oldest)
+	require.Equal(hexutil.Uint64(last), res.Latest)
+
+	// the window never moves backwards
+	env.store.evm.SetOldestArchiveBlock(1)
+	require.Equal(uint64(oldest), env.store.evm.GetOldestArchiveBlock())
+}
+
+func TestArchiveWindow_Epochs(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	cfg := MemTestStoreConfig(t.TempDir())
+	cfg.EVM.ArchiveVisibleEpochs = 1
+	env := newTestEnvWithStoreConfig(2, 3, t, cfg)
+	defer env.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err := env.ApplyTxs(nextEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+		require.NoError(err)
+	}
+	receipts, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+	require.NoError(err)
+	waitForArchive(t, env)
+
+	// only the states of the current epoch are queryable
+	epoch := env.store.GetEpoch()
+	bs, _ := env.store.GetHistoryBlockEpochState(epoch)
+	require.NotNil(bs)
+	oldest := bs.LastBlock.Idx + 1
+	require.Equal(uint64(oldest), env.store.evm.GetOldestArchiveBlock())
+	require.Less(uint64(oldest), receipts[0].BlockNumber.Uint64()+1)
+
+	block := env.store.GetBlock(oldest - 1)
+	_, err = env.store.evm.GetRpcStateDb(big.NewInt(int64(oldest-1)), common.Hash(block.Root))
+	require.ErrorIs(err, evmstore.ErrStateUnavailable)
+}
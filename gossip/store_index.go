@@ -0,0 +1,64 @@
+package gossip
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/utils/signers/gsignercache"
+)
+
+// ReindexBlock regenerates the EVM logs index and/or the transaction positions of an already processed block
+// from the stored block, its events and receipts, the same as they are indexed during the block processing.
+// It allows to rebuild the indexes for blocks processed while the indexing was disabled.
+// Indexing a block again is harmless, so the rebuild may be interrupted and resumed.
+func (s *Store) ReindexBlock(n idx.Block, logs, txHashes bool) error {
+	block := s.GetBlock(n)
+	if block == nil {
+		return fmt.Errorf("block %d not found", n)
+	}
+	txs := s.GetBlockTxs(n, block)
+
+	if txHashes {
+		// memorize event position of each tx, internal txs and txs of LLR-imported blocks have none
+		positions := make(map[common.Hash]evmstore.TxPosition, len(txs))
+		for _, id := range block.Events {
+			e := s.GetEventPayload(id)
+			if e == nil {
+				return fmt.Errorf("event %s of block %d not found", id.String(), n)
+			}
+			for i, tx := range e.Txs() {
+				// if tx was met in multiple events, then assign to first ordered event
+				if _, ok := positions[tx.Hash()]; ok {
+					continue
+				}
+				positions[tx.Hash()] = evmstore.TxPosition{
+					Event:       id,
+					EventOffset: uint32(i),
+				}
+			}
+		}
+		for i, tx := range txs {
+			position := positions[tx.Hash()]
+			position.Block = n
+			position.BlockOffset = uint32(i)
+			s.evm.SetTxPosition(tx.Hash(), position)
+		}
+	}
+
+	if logs && len(txs) != 0 {
+		signer := gsignercache.Wrap(types.MakeSigner(s.GetEvmChainConfig(), new(big.Int).SetUint64(uint64(n))))
+		receipts := s.evm.GetReceipts(n, signer, common.Hash(block.Atropos), txs)
+		if len(receipts) != len(txs) {
+			return fmt.Errorf("receipts of block %d not found", n)
+		}
+		for _, r := range receipts {
+			s.evm.IndexLogs(r.Logs...)
+		}
+	}
+	return nil
+}
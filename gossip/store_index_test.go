@@ -0,0 +1,77 @@
+package gossip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/contract/ballot"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+	"github.com/Fantom-foundation/go-opera/utils/signers/gsignercache"
+)
+
+func TestStoreReindexBlock(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+
+	_, tx, _, err := ballot.DeployBallot(env.Pay(1), env, [][32]byte{ballotOption("Option 1")})
+	require.NoError(err)
+	receipts, err := env.ApplyTxs(nextEpoch, tx)
+	require.NoError(err)
+	_, err = env.ApplyTxs(nextEpoch, env.Transfer(2, 3, utils.ToFtm(1)))
+	require.NoError(err)
+
+	first := idx.Block(receipts[0].BlockNumber.Uint64())
+	last := env.store.GetLatestBlockIndex()
+
+	// remember the indexes built during the block processing and corrupt the tx positions
+	positions := make(map[common.Hash]evmstore.TxPosition)
+	logs := make(map[common.Address][]*types.Log)
+	for n := first; n <= last; n++ {
+		block := env.store.GetBlock(n)
+		txs := env.store.GetBlockTxs(n, block)
+		for _, tx := range txs {
+			position := env.store.evm.GetTxPosition(tx.Hash())
+			require.NotNil(position)
+			positions[tx.Hash()] = *position
+			env.store.evm.SetTxPosition(tx.Hash(), evmstore.TxPosition{Event: hash.FakeEvent()})
+		}
+		signer := gsignercache.Wrap(types.MakeSigner(env.store.GetEvmChainConfig(), receipts[0].BlockNumber))
+		for _, r := range env.store.evm.GetReceipts(n, signer, common.Hash(block.Atropos), txs) {
+			for _, l := range r.Logs {
+				logs[l.Address] = append(logs[l.Address], l)
+			}
+		}
+	}
+	require.NotEmpty(positions)
+	require.NotEmpty(logs)
+
+	for n := first; n <= last; n++ {
+		require.NoError(env.store.ReindexBlock(n, true, true))
+	}
+	for txid, expected := range positions {
+		require.Equal(expected, *env.store.evm.GetTxPosition(txid), txid.String())
+	}
+	// logs are indexed only once
+	for addr, expected := range logs {
+		found, err := env.store.evm.EvmLogs.FindInBlocks(context.Background(), first, last, [][]common.Hash{{common.BytesToHash(addr.Bytes())}})
+		require.NoError(err)
+		require.Equal(len(expected), len(found), addr.String())
+		for i := range expected {
+			require.Equal(expected[i].TxHash, found[i].TxHash)
+			require.Equal(expected[i].Index, found[i].Index)
+			require.Equal(expected[i].Data, found[i].Data)
+		}
+	}
+	require.Error(env.store.ReindexBlock(last+1, true, true))
+}
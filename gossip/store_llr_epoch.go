@@ -23,7 +23,7 @@ func (s *Store) HasEpochVote(epoch idx.Epoch, id hash.Event) bool {
 	return ok
 }
 
-func (s *Store) iterateEpochVotesRLP(prefix []byte, f func(ev rlp.RawValue) bool) {
+func (s *Store) IterateEpochVotesRLP(prefix []byte, f func(ev rlp.RawValue) bool) {
 	it := s.table.LlrEpochVotes.NewIterator(prefix, nil)
 	defer it.Release()
 	for it.Next() {
@@ -88,7 +88,7 @@ func (s *Store) IterateEpochPacksRLP(start idx.Epoch, f func(epoch idx.Epoch, ep
 			break
 		}
 		evs := make([]rlp.RawValue, 0, 20)
-		s.iterateEpochVotesRLP(key, func(ev rlp.RawValue) bool {
+		s.IterateEpochVotesRLP(key, func(ev rlp.RawValue) bool {
 			evs = append(evs, ev)
 			return len(evs) < maxEpochPackVotes
 		})
@@ -37,6 +37,29 @@ func (s *Store) GetLlrState() LlrState {
 	return *v
 }
 
+// llrSyncFailedKey is the block, at which the LLR sync got stopped by a mismatch with the decided records
+var llrSyncFailedKey = []byte("f")
+
+// SetLlrSyncFailed marks the live state as diverged from the decided records at the block.
+// The mark survives restarts, as the live state may not be continued by either the records or the events.
+func (s *Store) SetLlrSyncFailed(block idx.Block) {
+	if err := s.table.LlrState.Put(llrSyncFailedKey, block.Bytes()); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// GetLlrSyncFailed returns the block, at which the LLR sync got stopped by a mismatch, if there is any.
+func (s *Store) GetLlrSyncFailed() (idx.Block, bool) {
+	b, err := s.table.LlrState.Get(llrSyncFailedKey)
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if b == nil {
+		return 0, false
+	}
+	return idx.BytesToBlock(b), true
+}
+
 // FlushLlrState stores the LLR state in DB
 func (s *Store) FlushLlrState() {
 	s.rlp.Set(s.table.LlrState, []byte{}, s.GetLlrState())
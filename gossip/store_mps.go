@@ -0,0 +1,191 @@
+package gossip
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/go-opera/eventcheck/basiccheck"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+const (
+	// maxPendingMisbehaviourProofs limits the number of collected proofs which aren't submitted yet
+	maxPendingMisbehaviourProofs = 256
+
+	pendingMPPrefix       = 'p'
+	reportedCheaterPrefix = 'r'
+)
+
+func pendingMPKey(mp inter.MisbehaviourProof) []byte {
+	return append([]byte{pendingMPPrefix}, misbehaviourProofHash(mp).Bytes()...)
+}
+
+func reportedCheaterKey(vid idx.ValidatorID) []byte {
+	return append([]byte{reportedCheaterPrefix}, vid.Bytes()...)
+}
+
+// misbehaviourProofCheaters returns the validators which may get punished by the proof.
+func misbehaviourProofCheaters(mp inter.MisbehaviourProof) []idx.ValidatorID {
+	cheaters := make([]idx.ValidatorID, 0, inter.MinAccomplicesForProof)
+	if proof := mp.EventsDoublesign; proof != nil {
+		cheaters = append(cheaters, proof.Pair[0].Locator.Creator)
+	}
+	if proof := mp.BlockVoteDoublesign; proof != nil {
+		cheaters = append(cheaters, proof.Pair[0].Signed.Locator.Creator)
+	}
+	if proof := mp.WrongBlockVote; proof != nil {
+		for _, pal := range proof.Pals {
+			cheaters = append(cheaters, pal.Signed.Locator.Creator)
+		}
+	}
+	if proof := mp.EpochVoteDoublesign; proof != nil {
+		cheaters = append(cheaters, proof.Pair[0].Signed.Locator.Creator)
+	}
+	if proof := mp.WrongEpochVote; proof != nil {
+		for _, pal := range proof.Pals {
+			cheaters = append(cheaters, pal.Signed.Locator.Creator)
+		}
+	}
+	return cheaters
+}
+
+// misbehaviourProofEpoch returns the lowest epoch of the misbehaviour, which defines the liability period.
+func misbehaviourProofEpoch(mp inter.MisbehaviourProof) idx.Epoch {
+	epochs := make([]idx.Epoch, 0, 2)
+	if proof := mp.EventsDoublesign; proof != nil {
+		epochs = append(epochs, proof.Pair[0].Locator.Epoch)
+	}
+	if proof := mp.BlockVoteDoublesign; proof != nil {
+		epochs = append(epochs, proof.Pair[0].Val.Epoch, proof.Pair[1].Val.Epoch)
+	}
+	if proof := mp.WrongBlockVote; proof != nil {
+		for _, pal := range proof.Pals {
+			epochs = append(epochs, pal.Val.Epoch)
+		}
+	}
+	if proof := mp.EpochVoteDoublesign; proof != nil {
+		epochs = append(epochs, proof.Pair[0].Val.Epoch)
+	}
+	if proof := mp.WrongEpochVote; proof != nil {
+		for _, pal := range proof.Pals {
+			epochs = append(epochs, pal.Val.Epoch)
+		}
+	}
+	var lowest idx.Epoch
+	for i, epoch := range epochs {
+		if i == 0 || epoch < lowest {
+			lowest = epoch
+		}
+	}
+	return lowest
+}
+
+func misbehaviourProofHash(mp inter.MisbehaviourProof) hash.Hash {
+	return inter.CalcMisbehaviourProofsHash([]inter.MisbehaviourProof{mp})
+}
+
+// isMisbehaviourProofRelevant returns false if all the cheaters of the proof are already reported,
+// or if the proof is too old to get accepted.
+func (s *Store) isMisbehaviourProofRelevant(mp inter.MisbehaviourProof) bool {
+	if s.GetEpoch() > misbehaviourProofEpoch(mp)+basiccheck.MaxLiableEpochs {
+		return false
+	}
+	for _, cheater := range misbehaviourProofCheaters(mp) {
+		if !s.IsCheaterReported(cheater) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddMisbehaviourProof adds the proof to the pending ones, unless its cheaters are already reported.
+// Returns true if the proof is added.
+func (s *Store) AddMisbehaviourProof(mp inter.MisbehaviourProof) bool {
+	if !s.isMisbehaviourProofRelevant(mp) {
+		return false
+	}
+	key := pendingMPKey(mp)
+	if ok, _ := s.table.MisbehaviourProofs.Has(key); ok {
+		return false
+	}
+	pending := 0
+	s.iteratePendingMisbehaviourProofsRLP(func(_ []byte, _ rlp.RawValue) bool {
+		pending++
+		return pending < maxPendingMisbehaviourProofs
+	})
+	if pending >= maxPendingMisbehaviourProofs {
+		return false
+	}
+	s.rlp.Set(s.table.MisbehaviourProofs, key, &mp)
+	return true
+}
+
+func (s *Store) iteratePendingMisbehaviourProofsRLP(f func(key []byte, mp rlp.RawValue) bool) {
+	it := s.table.MisbehaviourProofs.NewIterator([]byte{pendingMPPrefix}, nil)
+	defer it.Release()
+	for it.Next() {
+		if !f(it.Key(), it.Value()) {
+			break
+		}
+	}
+}
+
+// GetPendingMisbehaviourProofs returns up to limit relevant proofs which aren't submitted yet.
+// Irrelevant proofs are erased.
+func (s *Store) GetPendingMisbehaviourProofs(limit int) []inter.MisbehaviourProof {
+	mps := make([]inter.MisbehaviourProof, 0, limit)
+	var irrelevant [][]byte
+	s.iteratePendingMisbehaviourProofsRLP(func(key []byte, raw rlp.RawValue) bool {
+		if len(mps) >= limit {
+			return false
+		}
+		var mp inter.MisbehaviourProof
+		if err := rlp.DecodeBytes(raw, &mp); err != nil {
+			s.Log.Crit("Failed to decode rlp", "err", err, "size", len(raw))
+		}
+		if s.isMisbehaviourProofRelevant(mp) {
+			mps = append(mps, mp)
+		} else {
+			irrelevant = append(irrelevant, append([]byte{}, key...))
+		}
+		return true
+	})
+	for _, key := range irrelevant {
+		if err := s.table.MisbehaviourProofs.Delete(key); err != nil {
+			s.Log.Crit("Failed to delete key", "err", err)
+		}
+	}
+	return mps
+}
+
+// DelMisbehaviourProof erases the proof from the pending ones.
+func (s *Store) DelMisbehaviourProof(mp inter.MisbehaviourProof) {
+	if err := s.table.MisbehaviourProofs.Delete(pendingMPKey(mp)); err != nil {
+		s.Log.Crit("Failed to delete key", "err", err)
+	}
+}
+
+// SetMisbehaviourProofSubmitted records the cheaters of a confirmed proof as reported, and erases the proof from the pending ones.
+func (s *Store) SetMisbehaviourProofSubmitted(mp inter.MisbehaviourProof) {
+	for _, cheater := range misbehaviourProofCheaters(mp) {
+		s.SetCheaterReported(cheater)
+	}
+	s.DelMisbehaviourProof(mp)
+}
+
+// SetCheaterReported records that a misbehaviour proof against the validator is already submitted.
+func (s *Store) SetCheaterReported(vid idx.ValidatorID) {
+	if err := s.table.MisbehaviourProofs.Put(reportedCheaterKey(vid), []byte{}); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// IsCheaterReported returns true if a misbehaviour proof against the validator is already submitted.
+func (s *Store) IsCheaterReported(vid idx.ValidatorID) bool {
+	ok, err := s.table.MisbehaviourProofs.Has(reportedCheaterKey(vid))
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	return ok
+}
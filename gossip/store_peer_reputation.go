@@ -0,0 +1,71 @@
+package gossip
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// PeerReputation is the reputation of a P2P peer.
+type PeerReputation struct {
+	Score       int64
+	Updated     time.Time
+	BannedUntil time.Time
+}
+
+// Banned returns true if the peer is banned at the given moment.
+func (r PeerReputation) Banned(now time.Time) bool {
+	return now.Before(r.BannedUntil)
+}
+
+func (r PeerReputation) Bytes() []byte {
+	b := make([]byte, 24)
+	binary.BigEndian.PutUint64(b[0:8], uint64(r.Score))
+	binary.BigEndian.PutUint64(b[8:16], uint64(r.Updated.Unix()))
+	binary.BigEndian.PutUint64(b[16:24], uint64(r.BannedUntil.Unix()))
+	return b
+}
+
+func peerReputationFromBytes(b []byte) (PeerReputation, bool) {
+	if len(b) != 24 {
+		return PeerReputation{}, false
+	}
+	return PeerReputation{
+		Score:       int64(binary.BigEndian.Uint64(b[0:8])),
+		Updated:     time.Unix(int64(binary.BigEndian.Uint64(b[8:16])), 0),
+		BannedUntil: time.Unix(int64(binary.BigEndian.Uint64(b[16:24])), 0),
+	}, true
+}
+
+// SetPeerReputation stores the reputation of the peer.
+func (s *Store) SetPeerReputation(id enode.ID, r PeerReputation) {
+	if err := s.table.PeerReputation.Put(id.Bytes(), r.Bytes()); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// DelPeerReputation erases the reputation of the peer.
+func (s *Store) DelPeerReputation(id enode.ID) {
+	if err := s.table.PeerReputation.Delete(id.Bytes()); err != nil {
+		s.Log.Crit("Failed to delete key", "err", err)
+	}
+}
+
+// ForEachPeerReputation iterates over the stored reputations of the peers.
+func (s *Store) ForEachPeerReputation(f func(id enode.ID, r PeerReputation) bool) {
+	it := s.table.PeerReputation.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		r, ok := peerReputationFromBytes(it.Value())
+		if !ok || len(it.Key()) != len(enode.ID{}) {
+			s.Log.Error("Malformed peer reputation", "key", it.Key())
+			continue
+		}
+		var id enode.ID
+		copy(id[:], it.Key())
+		if !f(id, r) {
+			break
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gossip
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter/state"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// ReplayBlock re-executes an already processed block on top of its parent archive state.
+// The tracer is optional. The returned state contains changes made by the block
+// and has to be released by the caller.
+func (s *Store) ReplayBlock(n idx.Block, tracer vm.Tracer) (*evmcore.EvmBlock, types.Receipts, state.StateDB, error) {
+	if n == 0 {
+		return nil, nil, nil, fmt.Errorf("genesis block is not replayable")
+	}
+	parent := (&EvmStateReader{store: s}).GetHeader(common.Hash{}, uint64(n-1))
+	if parent == nil {
+		return nil, nil, nil, fmt.Errorf("block %d not found", n-1)
+	}
+
+	statedb, err := s.evm.GetRpcStateDb(parent.Number, parent.Root)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot get state for block %d: %w", n, err)
+	}
+	block, receipts, err := s.ReplayBlockOn(n, statedb, tracer)
+	if err != nil {
+		statedb.Release()
+		return nil, nil, nil, err
+	}
+	return block, receipts, statedb, nil
+}
+
+// ReplayBlockOn re-executes an already processed block on top of the given state of its parent,
+// with the chain config and the VM config of the consensus block processing. The tracer is optional.
+// The block isn't begun, ended nor committed on the state, it's up to the caller.
+func (s *Store) ReplayBlockOn(n idx.Block, statedb state.StateDB, tracer vm.Tracer) (*evmcore.EvmBlock, types.Receipts, error) {
+	if n == 0 {
+		return nil, nil, fmt.Errorf("genesis block is not replayable")
+	}
+	reader := &EvmStateReader{store: s}
+	block := reader.GetBlock(common.Hash{}, uint64(n))
+	if block == nil {
+		return nil, nil, fmt.Errorf("block %d not found", n)
+	}
+	epoch := s.GetBlock(n).Atropos.Epoch()
+	es := s.GetHistoryEpochState(epoch)
+	if es == nil {
+		return nil, nil, fmt.Errorf("epoch %d of block %d not found", epoch, n)
+	}
+
+	vmConfig := opera.DefaultVMConfig
+	if tracer != nil {
+		vmConfig.Debug = true
+		vmConfig.Tracer = tracer
+	}
+
+	var gasUsed uint64
+	receipts, _, _, err := evmcore.NewStateProcessor(es.Rules.EvmChainConfig(s.GetUpgradeHeights()), reader).Process(block, statedb, vmConfig, &gasUsed, func(*types.Log) {})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot replay block %d: %w", n, err)
+	}
+	// the block is cached by the reader, so it's copied before being modified
+	replayed := *block
+	replayed.GasUsed = gasUsed
+	return &replayed, receipts, nil
+}
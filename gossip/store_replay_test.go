@@ -0,0 +1,99 @@
+package gossip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/contract/ballot"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+	"github.com/Fantom-foundation/go-opera/utils/signers/gsignercache"
+)
+
+func TestReplayBlock(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+
+	_, tx, _, err := ballot.DeployBallot(env.Pay(1), env, [][32]byte{ballotOption("Option 1")})
+	require.NoError(err)
+	deployReceipts, err := env.ApplyTxs(nextEpoch, tx)
+	require.NoError(err)
+	_, err = env.ApplyTxs(sameEpoch, env.Transfer(2, 3, utils.ToFtm(1)))
+	require.NoError(err)
+
+	last := env.store.GetLatestBlockIndex()
+	waitForArchive(t, env)
+
+	_, _, _, err = env.store.ReplayBlock(0, nil)
+	require.Error(err)
+
+	for n := idx.Block(deployReceipts[0].BlockNumber.Uint64()); n <= last; n++ {
+		block, receipts, statedb, err := env.store.ReplayBlock(n, nil)
+		require.NoError(err)
+
+		signer := gsignercache.Wrap(types.MakeSigner(env.store.GetEvmChainConfig(), block.Number))
+		stored := env.store.evm.GetReceipts(n, signer, block.Hash, block.Transactions)
+		require.Equal(len(stored), len(receipts), "block %d", n)
+		for i := range stored {
+			require.Equal(stored[i].Status, receipts[i].Status, "block %d tx %d", n, i)
+			require.Equal(stored[i].GasUsed, receipts[i].GasUsed, "block %d tx %d", n, i)
+			require.Equal(stored[i].ContractAddress, receipts[i].ContractAddress, "block %d tx %d", n, i)
+			require.Equal(len(stored[i].Logs), len(receipts[i].Logs), "block %d tx %d", n, i)
+		}
+		require.Equal(env.store.GetBlock(n).GasUsed, block.GasUsed, "block %d", n)
+
+		// the replayed state matches the archive state of the block
+		expected, err := env.store.evm.GetRpcStateDb(block.Number, block.Root)
+		require.NoError(err)
+		for i := idx.ValidatorID(1); i <= 3; i++ {
+			addr := env.Address(i)
+			require.Equal(expected.GetBalance(addr), statedb.GetBalance(addr), "block %d", n)
+			require.Equal(expected.GetNonce(addr), statedb.GetNonce(addr), "block %d", n)
+		}
+		expected.Release()
+		statedb.Release()
+	}
+}
+
+func TestReplayBlockOnLiveStateCopy(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv(2, 3, t)
+	defer env.Close()
+
+	first := env.store.GetLatestBlockIndex() + 1
+	_, tx, _, err := ballot.DeployBallot(env.Pay(1), env, [][32]byte{ballotOption("Option 1")})
+	require.NoError(err)
+	_, err = env.ApplyTxs(nextEpoch, tx)
+	require.NoError(err)
+	_, err = env.ApplyTxs(sameEpoch, env.Transfer(2, 3, utils.ToFtm(1)))
+	require.NoError(err)
+
+	last := env.store.GetLatestBlockIndex()
+	waitForArchive(t, env)
+
+	live, err := env.store.evm.NewLiveStateCopy(context.Background(), first-1, t.TempDir())
+	require.NoError(err)
+	defer live.Close()
+
+	// the replayed blocks reproduce the state roots of the block records
+	for n := first; n <= last; n++ {
+		statedb, err := live.GetLiveStateDb(env.store.GetBlock(n - 1).Root)
+		require.NoError(err, "block %d", n)
+		statedb.BeginBlock(uint64(n))
+		block, _, err := env.store.ReplayBlockOn(n, statedb, nil)
+		require.NoError(err)
+		statedb.EndBlock(uint64(n))
+		root, err := statedb.Commit(true)
+		require.NoError(err)
+		require.Equal(block.Root, root, "block %d", n)
+	}
+}
@@ -0,0 +1,37 @@
+package gossip
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+)
+
+// TestMainDBTablePrefixes checks that the tables the gossip and EVM stores
+// create in the shared main DB don't use the same prefix.
+// The topicsdb tables are not covered: they share the "r" prefix with the
+// receipts table since genesis and are told apart only by the key length.
+func TestMainDBTablePrefixes(t *testing.T) {
+	owners := map[string]string{}
+	for _, store := range []reflect.Type{
+		reflect.TypeOf(Store{}),
+		reflect.TypeOf(evmstore.Store{}),
+	} {
+		tables, ok := store.FieldByName("table")
+		if !ok {
+			t.Fatalf("%s has no tables", store)
+		}
+		for i := 0; i < tables.Type.NumField(); i++ {
+			field := tables.Type.Field(i)
+			prefix, ok := field.Tag.Lookup("table")
+			if !ok {
+				continue
+			}
+			name := store.String() + "." + field.Name
+			if owner, exists := owners[prefix]; exists {
+				t.Errorf("table prefix %q is used by both %s and %s", prefix, owner, name)
+			}
+			owners[prefix] = name
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package gossip
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/txtrace"
+	"github.com/Fantom-foundation/go-opera/utils/signers/gsignercache"
+)
+
+// IndexBlockTraces replays an already processed block on top of its parent archive state
+// and stores the transaction traces, the same as they are stored during the block processing.
+// It allows to backfill the traces store for blocks processed before the traces indexing was enabled.
+func (s *Store) IndexBlockTraces(n idx.Block) error {
+	if n == 0 {
+		return nil // genesis block is not traceable
+	}
+	header := (&EvmStateReader{store: s}).GetHeader(common.Hash{}, uint64(n))
+	if header == nil {
+		return fmt.Errorf("block %d not found", n)
+	}
+	tracer := txtrace.NewBlockTracer(header.Hash, header.Number)
+	block, receipts, statedb, err := s.ReplayBlock(n, tracer)
+	if err != nil {
+		return err
+	}
+	statedb.Release()
+
+	// prefer stored receipts to get the same transaction positions as the RPC replay
+	signer := gsignercache.Wrap(types.MakeSigner(s.GetEvmChainConfig(), block.Number))
+	if stored := s.evm.GetReceipts(n, signer, block.Hash, block.Transactions); len(stored) == len(block.Transactions) {
+		receipts = stored
+	}
+	s.evm.SetTraces(n, tracer.GetResult(block.Transactions, receipts))
+	return nil
+}
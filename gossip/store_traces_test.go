@@ -0,0 +1,139 @@
+package gossip
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/gossip/contract/ballot"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/txtrace"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreTraces(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	cfg := MemTestStoreConfig(t.TempDir())
+	cfg.EVM.EnableTracesIndexing = true
+	env := newTestEnvWithStoreConfig(2, 3, t, cfg)
+	defer env.Close()
+
+	_, tx, _, err := ballot.DeployBallot(env.Pay(1), env, [][32]byte{ballotOption("Option 1")})
+	require.NoError(err)
+	deployReceipts, err := env.ApplyTxs(nextEpoch, tx)
+	require.NoError(err)
+	transferReceipts, err := env.ApplyTxs(sameEpoch, env.Transfer(2, 3, utils.ToFtm(1)))
+	require.NoError(err)
+
+	deployBlock := idx.Block(deployReceipts[0].BlockNumber.Uint64())
+	last := env.store.GetLatestBlockIndex()
+	waitForArchive(t, env)
+
+	// traces returned from the store match the replayed ones
+	ctx := context.Background()
+	storedApi := ethapi.NewPublicTxTraceAPI(env.EthAPI)
+	replayApi := ethapi.NewPublicTxTraceAPI(replayingBackend{env.EthAPI})
+	for n := deployBlock; n <= last; n++ {
+		_, ok := env.store.evm.GetTraces(n)
+		require.True(ok, "traces of block %d not stored", n)
+
+		number := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(n))
+		stored, err := storedApi.Block(ctx, number)
+		require.NoError(err)
+		replayed, err := replayApi.Block(ctx, number)
+		require.NoError(err)
+		requireSameTraces(t, replayed, stored, "block %d", n)
+	}
+
+	// the replayed blocks are filtered in parallel, so the order of the traces may differ
+	from, to := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(deployBlock)), rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(last))
+	for i, args := range []ethapi.FilterArgs{
+		{FromBlock: &from, ToBlock: &to},
+		{FromBlock: &from, ToBlock: &to, FromAddress: &[]common.Address{env.Address(1)}},
+		{FromBlock: &from, ToBlock: &to, ToAddress: &[]common.Address{env.Address(3)}},
+		{FromBlock: &from, ToBlock: &to, FromAddress: &[]common.Address{env.Address(2)}, ToAddress: &[]common.Address{env.Address(3)}},
+		{FromBlock: &from, ToBlock: &to, FromAddress: &[]common.Address{env.Address(3)}, ToAddress: &[]common.Address{env.Address(2)}},
+		{FromBlock: &from, ToBlock: &to, After: 1, Count: 2},
+	} {
+		stored, err := storedApi.Filter(ctx, args)
+		require.NoError(err)
+		replayed, err := replayApi.Filter(ctx, args)
+		require.NoError(err)
+
+		var storedTraces, replayedTraces []json.RawMessage
+		require.NoError(json.Unmarshal(stored, &storedTraces))
+		require.NoError(json.Unmarshal(replayed, &replayedTraces))
+		if args.FromAddress == nil || (*args.FromAddress)[0] != env.Address(3) {
+			require.NotEmpty(storedTraces, "filter %d", i)
+		}
+		require.ElementsMatch(replayedTraces, storedTraces, "filter %d", i)
+	}
+
+	traces, ok := env.store.evm.GetTraces(deployBlock)
+	require.True(ok)
+	found := false
+	for _, trace := range traces {
+		if trace.TransactionHash == deployReceipts[0].TxHash {
+			found = true
+			require.Equal("create", trace.TraceType)
+			require.Equal(env.Address(1), *trace.Action.From)
+			require.Equal(deployReceipts[0].ContractAddress, *trace.Result.Address)
+		}
+	}
+	require.True(found)
+
+	// address index
+	transferBlock := idx.Block(transferReceipts[0].BlockNumber.Uint64())
+	blocks, ok, err := env.store.evm.FindTraceBlocks(ctx, deployBlock, last, []common.Address{env.Address(2)}, []common.Address{env.Address(3)})
+	require.NoError(err)
+	require.True(ok)
+	require.Equal([]idx.Block{transferBlock}, blocks)
+
+	blocks, ok, err = env.store.evm.FindTraceBlocks(ctx, deployBlock, last, []common.Address{env.Address(1)}, nil)
+	require.NoError(err)
+	require.True(ok)
+	require.Contains(blocks, deployBlock)
+	require.NotContains(blocks, transferBlock)
+
+	blocks, ok, err = env.store.evm.FindTraceBlocks(ctx, deployBlock, transferBlock-1, []common.Address{env.Address(2)}, nil)
+	require.NoError(err)
+	require.True(ok)
+	require.Empty(blocks)
+
+	// ranges with not stored traces
+	_, ok, err = env.store.evm.FindTraceBlocks(ctx, 0, last, nil, nil)
+	require.NoError(err)
+	require.False(ok)
+	_, ok, err = env.store.evm.FindTraceBlocks(ctx, deployBlock, last+1, nil, nil)
+	require.NoError(err)
+	require.False(ok)
+}
+
+// replayingBackend hides the stored traces, so the trace API replays the blocks.
+type replayingBackend struct {
+	*EthAPIBackend
+}
+
+func (b replayingBackend) GetBlockTraces(ctx context.Context, number idx.Block) ([]txtrace.ActionTrace, bool, error) {
+	return nil, false, nil
+}
+
+func (b replayingBackend) FindTraceBlocks(ctx context.Context, from, to idx.Block, fromAddresses, toAddresses []common.Address) ([]idx.Block, bool, error) {
+	return nil, false, nil
+}
+
+func requireSameTraces(t *testing.T, expected, actual *[]txtrace.ActionTrace, msgAndArgs ...interface{}) {
+	expectedJson, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJson, err := json.Marshal(actual)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectedJson), string(actualJson), msgAndArgs...)
+}
@@ -1,17 +1,70 @@
 package gossip
 
 import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p/enode"
-	"math/rand"
-	"sync/atomic"
 )
 
-var isMaybeSyncedGauge = metrics.GetOrRegisterGauge("chain/maybeSynced", nil)
+var (
+	isMaybeSyncedGauge = metrics.GetOrRegisterGauge("chain/maybeSynced", nil)
+	isLlrSyncingGauge  = metrics.GetOrRegisterGauge("chain/llrSyncing", nil)
+	isSnapSyncingGauge = metrics.GetOrRegisterGauge("chain/snapSyncing", nil)
+)
+
+// SyncMode is the way a node gets synchronised with the network.
+type SyncMode string
+
+const (
+	// FullSync processes all the events of the DAG since the genesis.
+	FullSync SyncMode = "full"
+	// LlrSync applies the LLR block and epoch records, decided by the validators votes,
+	// without processing the events, and switches to the events processing near the head.
+	LlrSync SyncMode = "llr"
+	// SnapSync downloads the EVM state snapshot of a recent epoch from the peers, verified by the state root
+	// of the LLR epoch record, and switches to the events processing from the epoch.
+	SnapSync SyncMode = "snap"
+)
+
+// Validate checks that the sync mode is known. An empty mode means FullSync.
+func (m SyncMode) Validate() error {
+	if m != "" && m != FullSync && m != LlrSync && m != SnapSync {
+		return fmt.Errorf("unknown sync mode %q, must be %q, %q or %q", m, FullSync, LlrSync, SnapSync)
+	}
+	return nil
+}
+
+const (
+	// llrSyncMaxEpochLag is the max number of epochs the node may be behind its peers
+	// to switch from the LLR records to the events processing
+	llrSyncMaxEpochLag = 1
+	// llrSyncCheckPeriod is the period of checking whether the LLR sync may be finished
+	llrSyncCheckPeriod = time.Second
+)
+
+const (
+	llrSyncOff uint32 = iota
+	llrSyncApplying
+	llrSyncSwitching
+	llrSyncFailed
+)
+
+const (
+	snapSyncOff uint32 = iota
+	snapSyncFetching
+	snapSyncApplying
+)
 
 type syncStatus struct {
 	maybeSynced uint32
+	llrSync     uint32
+	snapSync    uint32
 }
 
 func (ss *syncStatus) MaybeSynced() bool {
@@ -23,12 +76,49 @@ func (ss *syncStatus) MarkMaybeSynced() {
 	isMaybeSyncedGauge.Update(int64(1))
 }
 
+// LlrSyncing returns true if the node is synchronised by the LLR records rather than by the events.
+func (ss *syncStatus) LlrSyncing() bool {
+	return atomic.LoadUint32(&ss.llrSync) != llrSyncOff
+}
+
+// LlrSyncFailed returns true if the LLR sync is stopped because the records didn't match the execution.
+func (ss *syncStatus) LlrSyncFailed() bool {
+	return atomic.LoadUint32(&ss.llrSync) == llrSyncFailed
+}
+
+func (ss *syncStatus) setLlrSync(v uint32) {
+	atomic.StoreUint32(&ss.llrSync, v)
+	if v == llrSyncOff {
+		isLlrSyncingGauge.Update(0)
+	} else {
+		isLlrSyncingGauge.Update(1)
+	}
+}
+
+// SnapSyncing returns true if the node is synchronised by an EVM state snapshot rather than by the events.
+func (ss *syncStatus) SnapSyncing() bool {
+	return atomic.LoadUint32(&ss.snapSync) != snapSyncOff
+}
+
+func (ss *syncStatus) setSnapSync(v uint32) {
+	atomic.StoreUint32(&ss.snapSync, v)
+	if v == snapSyncOff {
+		isSnapSyncingGauge.Update(0)
+	} else {
+		isSnapSyncingGauge.Update(1)
+	}
+}
+
 func (ss *syncStatus) AcceptEvents() bool {
-	return true
+	return !ss.LlrSyncing() && !ss.SnapSyncing()
 }
 
 func (ss *syncStatus) AcceptBlockRecords() bool {
-	return false
+	return atomic.LoadUint32(&ss.llrSync) == llrSyncApplying || atomic.LoadUint32(&ss.snapSync) == snapSyncFetching
+}
+
+func (ss *syncStatus) AcceptSnapshotChunks() bool {
+	return atomic.LoadUint32(&ss.snapSync) == snapSyncFetching
 }
 
 func (ss *syncStatus) AcceptTxs() bool {
@@ -36,7 +126,95 @@ func (ss *syncStatus) AcceptTxs() bool {
 }
 
 func (ss *syncStatus) RequestLLR() bool {
-	return ss.MaybeSynced()
+	return ss.MaybeSynced() || ss.LlrSyncing() || ss.SnapSyncing()
+}
+
+// llrSyncLoop switches the node from the LLR records to the events processing,
+// once the records are applied up to an epoch close to the peers' one.
+func (h *handler) llrSyncLoop() {
+	ticker := time.NewTicker(llrSyncCheckPeriod)
+	defer ticker.Stop()
+	defer h.loopsWg.Done()
+	for {
+		select {
+		case <-ticker.C:
+			if h.tryFinishLlrSync() {
+				return
+			}
+		case <-h.quitProgressBradcast:
+			return
+		}
+	}
+}
+
+// llrSyncedEpoch returns the epoch which starts right after the applied block records,
+// or 0 if the records are applied up to the middle of an epoch.
+func (h *handler) llrSyncedEpoch() idx.Epoch {
+	next := h.store.GetLlrState().LowestBlockToFill
+	if next == h.store.GetLatestBlockIndex()+1 {
+		// no records are applied on top of the current block state
+		return h.store.GetEpoch()
+	}
+	epoch := h.store.FindBlockEpoch(next)
+	if bs, _ := h.store.GetHistoryBlockEpochState(epoch); bs == nil || bs.LastBlock.Idx+1 != next {
+		return 0
+	}
+	return epoch
+}
+
+func (h *handler) tryFinishLlrSync() bool {
+	if h.syncStatus.LlrSyncFailed() {
+		return true
+	}
+	if h.peers.Len() == 0 {
+		return false
+	}
+	peerEpoch := h.highestPeerProgress().Epoch
+	if epoch := h.llrSyncedEpoch(); epoch == 0 || epoch+llrSyncMaxEpochLag < peerEpoch {
+		return false
+	}
+
+	// stop applying the records, and wait for the ones being applied
+	h.syncStatus.setLlrSync(llrSyncSwitching)
+	h.engineMu.Lock()
+	epoch := h.llrSyncedEpoch()
+	h.engineMu.Unlock()
+	if h.syncStatus.LlrSyncFailed() {
+		// a record mismatch was met meanwhile
+		return true
+	}
+	if epoch == 0 {
+		// a record was applied meanwhile
+		h.syncStatus.setLlrSync(llrSyncApplying)
+		return false
+	}
+	h.switchToLlrSyncedEpoch(epoch)
+	return true
+}
+
+// switchToLlrSyncedEpoch finishes the LLR sync at the synced epoch, and switches to the events processing.
+// If the synced state doesn't match the epoch record, the LLR sync is stopped instead.
+func (h *handler) switchToLlrSyncedEpoch(epoch idx.Epoch) {
+	if epoch > h.store.GetEpoch() {
+		bs, _ := h.store.GetHistoryBlockEpochState(epoch)
+		if last := h.store.GetBlock(bs.LastBlock.Idx); last == nil || last.Root != bs.FinalizedStateRoot {
+			// the live state is already modified by the records, and may not be continued by the events
+			h.syncStatus.setLlrSync(llrSyncFailed)
+			h.engineMu.Lock()
+			h.store.SetLlrSyncFailed(bs.LastBlock.Idx)
+			if err := h.store.Commit(); err != nil {
+				h.Log.Error("Failed to commit the LLR sync mismatch", "err", err)
+			}
+			h.engineMu.Unlock()
+			h.Log.Error("LLR synced state doesn't match the epoch record, LLR sync is stopped", "epoch", epoch, "block", bs.LastBlock.Idx)
+			return
+		}
+		if err := h.process.SwitchEpochTo(epoch); err != nil {
+			h.Log.Crit("Failed to switch to the LLR synced epoch", "epoch", epoch, "err", err)
+		}
+	}
+	h.syncStatus.setLlrSync(llrSyncOff)
+	h.Log.Info("LLR sync is finished, switching to events", "epoch", h.store.GetEpoch(), "block", h.store.GetLatestBlockIndex())
 }
 
 type txsync struct {
@@ -0,0 +1,139 @@
+package gossip
+
+import (
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+const (
+	// snapSyncMaxEpochLag is the max number of epochs the node may be behind its peers
+	// to sync by the events rather than by a snapshot. It's also the max number of epochs
+	// the LLR epoch records may be behind the peers to choose the snapshot epoch.
+	snapSyncMaxEpochLag = 2
+	// snapSyncCheckPeriod is the period of checking the snap sync progress
+	snapSyncCheckPeriod = time.Second
+)
+
+// snapSyncLoop drives the snap sync: chooses the snapshot epoch once the LLR epoch records are fetched,
+// applies the downloaded snapshot, and switches the node to the events processing.
+func (h *handler) snapSyncLoop() {
+	ticker := time.NewTicker(snapSyncCheckPeriod)
+	defer ticker.Stop()
+	defer h.loopsWg.Done()
+	for {
+		select {
+		case <-ticker.C:
+			if h.trySnapSync() {
+				return
+			}
+		case <-h.quitProgressBradcast:
+			return
+		}
+	}
+}
+
+// snapSyncTargetEpoch returns the latest snapshot epoch, which has the LLR epoch record,
+// or 0 if the epoch records are too far behind the peers.
+func (h *handler) snapSyncTargetEpoch(peerEpoch idx.Epoch) idx.Epoch {
+	top := h.store.GetLlrState().LowestEpochToFill - 1
+	if top+snapSyncMaxEpochLag < peerEpoch {
+		return 0
+	}
+	// give the peers time to export the snapshot of their current epoch
+	if top >= peerEpoch {
+		top = peerEpoch - 1
+	}
+	if h.snapMaxEpoch != 0 && top > h.snapMaxEpoch {
+		top = h.snapMaxEpoch
+	}
+	return top - top%h.config.Snapshots.EpochPeriod
+}
+
+func (h *handler) startSnapshotDownload(epoch idx.Epoch) bool {
+	bs, _ := h.store.GetHistoryBlockEpochState(epoch)
+	if bs == nil {
+		return false
+	}
+	if err := h.snapDownload.Start(epoch, bs.FinalizedStateRoot); err != nil {
+		h.Log.Error("Failed to start EVM state snapshot download", "epoch", epoch, "err", err)
+		return false
+	}
+	// the blocks before the snapshot aren't needed, but the last block of the snapshot is
+	h.engineMu.Lock()
+	h.store.ModifyLlrState(func(llrs *LlrState) {
+		if llrs.LowestBlockToDecide < bs.LastBlock.Idx {
+			llrs.LowestBlockToDecide = bs.LastBlock.Idx
+		}
+		if llrs.LowestBlockToFill < bs.LastBlock.Idx {
+			llrs.LowestBlockToFill = bs.LastBlock.Idx
+		}
+	})
+	h.engineMu.Unlock()
+	h.Log.Info("Downloading EVM state snapshot", "epoch", epoch, "block", bs.LastBlock.Idx, "root", bs.FinalizedStateRoot)
+	return true
+}
+
+func (h *handler) finishSnapSync() {
+	h.snapDownload.Drop()
+	h.syncStatus.setSnapSync(snapSyncOff)
+	h.Log.Info("Snap sync is finished, switching to events", "epoch", h.store.GetEpoch(), "block", h.store.GetLatestBlockIndex())
+}
+
+// trySnapSync makes a step of the snap sync, returns true once it's finished
+func (h *handler) trySnapSync() bool {
+	if h.peers.Len() == 0 {
+		return false
+	}
+	peerEpoch := h.highestPeerProgress().Epoch
+	if h.store.GetEpoch()+snapSyncMaxEpochLag >= peerEpoch {
+		// the node is close to the peers
+		h.finishSnapSync()
+		return true
+	}
+
+	epoch := h.snapDownload.Epoch()
+	if epoch == 0 {
+		epoch = h.snapSyncTargetEpoch(peerEpoch)
+		if epoch == 0 {
+			// wait for the epoch records
+			return false
+		}
+		if epoch <= h.store.GetEpoch() {
+			h.Log.Warn("No EVM state snapshot ahead of the current epoch", "epoch", h.store.GetEpoch())
+			h.finishSnapSync()
+			return true
+		}
+		h.startSnapshotDownload(epoch)
+		return false
+	}
+
+	if !h.snapDownload.Complete() {
+		if h.snapLeecher.Stalled(epoch) {
+			// fall back to an older snapshot
+			h.Log.Warn("No peer has the EVM state snapshot", "epoch", epoch)
+			h.snapMaxEpoch = epoch - 1
+			h.snapDownload.Drop()
+		}
+		return false
+	}
+	bs, _ := h.store.GetHistoryBlockEpochState(epoch)
+	if !h.store.HasBlock(bs.LastBlock.Idx) {
+		// wait for the block record
+		return false
+	}
+
+	h.syncStatus.setSnapSync(snapSyncApplying)
+	path, err := h.snapDownload.WorldState()
+	if err == nil {
+		err = h.process.StateSnapshot(epoch, path)
+	}
+	if err != nil {
+		h.Log.Error("Failed to apply EVM state snapshot", "epoch", epoch, "err", err)
+		h.snapDownload.Drop()
+		h.syncStatus.setSnapSync(snapSyncFetching)
+		return false
+	}
+	h.finishSnapSync()
+	return true
+}
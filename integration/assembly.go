@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/Fantom-foundation/go-opera/gossip"
 	"github.com/Fantom-foundation/go-opera/utils/adapters/vecmt2dagidx"
+	"github.com/Fantom-foundation/go-opera/utils/dbutil/memorydb"
 	"github.com/Fantom-foundation/go-opera/vecmt"
 	"github.com/Fantom-foundation/lachesis-base/abft"
 	"github.com/Fantom-foundation/lachesis-base/hash"
@@ -84,7 +85,7 @@ func CheckStateInitialized(chaindataDir string, cfg DBsConfig) error {
 	if isInterrupted(chaindataDir) {
 		return errors.New("genesis processing isn't finished")
 	}
-	dbs, err := GetDbProducer(chaindataDir, cfg.RuntimeCache)
+	dbs, err := GetDbProducer(chaindataDir, cfg)
 	if err != nil {
 		return err
 	}
@@ -92,10 +93,16 @@ func CheckStateInitialized(chaindataDir string, cfg DBsConfig) error {
 }
 
 func makeEngine(chaindataDir string, cfg Configs) (*abft.Lachesis, *vecmt.Index, *gossip.Store, *abft.Store, gossip.BlockProc, func() error, error) {
-	dbs, err := GetDbProducer(chaindataDir, cfg.DBs.RuntimeCache)
+	dbs, err := GetDbProducer(chaindataDir, cfg.DBs)
 	if err != nil {
 		return nil, nil, nil, nil, gossip.BlockProc{}, nil, err
 	}
+	// the in-memory DBs outlive the reopening, but not the node
+	closeDBs := func() error {
+		err := dbs.Close()
+		memorydb.FreeNamespace(chaindataDir)
+		return err
+	}
 
 	gdb, cdb, err := getStores(dbs, cfg)
 	if err != nil {
@@ -113,21 +120,21 @@ func makeEngine(chaindataDir string, cfg Configs) (*abft.Lachesis, *vecmt.Index,
 	err = gdb.EvmStore().Open()
 	if err != nil {
 		err = fmt.Errorf("failed to open EvmStore: %v", err)
-		return nil, nil, nil, nil, gossip.BlockProc{}, dbs.Close, err
+		return nil, nil, nil, nil, gossip.BlockProc{}, closeDBs, err
 	}
 
 	engine, vecClock, blockProc, err := rawMakeEngine(gdb, cdb, cfg)
 	if err != nil {
 		err = fmt.Errorf("failed to make engine: %v", err)
-		return nil, nil, nil, nil, gossip.BlockProc{}, dbs.Close, err
+		return nil, nil, nil, nil, gossip.BlockProc{}, closeDBs, err
 	}
 
-	return engine, vecClock, gdb, cdb, blockProc, dbs.Close, nil
+	return engine, vecClock, gdb, cdb, blockProc, closeDBs, nil
 }
 
 // MakeEngine makes consensus engine from config.
 func MakeEngine(chaindataDir string, cfg Configs) (*abft.Lachesis, *vecmt.Index, *gossip.Store, *abft.Store, gossip.BlockProc, func() error, error) {
-	if isEmpty(chaindataDir) || isInterrupted(chaindataDir) {
+	if isEmptyDBs(chaindataDir, cfg.DBs) || isInterrupted(chaindataDir) {
 		return nil, nil, nil, nil, gossip.BlockProc{}, nil, fmt.Errorf("database is empty or the genesis import interrupted")
 	}
 
@@ -1,24 +1,54 @@
 package integration
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Fantom-foundation/go-opera/gossip"
 	"github.com/Fantom-foundation/go-opera/utils/dbutil/dbcounter"
+	"github.com/Fantom-foundation/go-opera/utils/dbutil/memorydb"
 	"github.com/Fantom-foundation/go-opera/utils/dbutil/threads"
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/cachedproducer"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/flaggedproducer"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/leveldb"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/pebble"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/skipkeys"
+	"github.com/Fantom-foundation/lachesis-base/utils/fmtfilter"
 	"github.com/ethereum/go-ethereum/metrics"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 )
 
+// Key-value DB backends
+const (
+	PebbleBackend  = "pebble"
+	LevelDbBackend = "leveldb"
+	MemoryBackend  = "memory"
+)
+
+// dbsLayoutFile is the file in the chaindata dir, which records the backends of the DBs
+const dbsLayoutFile = "dbs-layout.json"
+
 type DBsConfig struct {
-	RuntimeCache  DBCacheConfig
+	// Backend is the backend of the DBs: "pebble", "leveldb" or "memory".
+	// Empty for the backend of the existing datadir, or pebble for a new one.
+	Backend string
+	// Routing overrides the backend and the directory of the DBs with names matching the patterns,
+	// e.g. "lachesis-%d" for the epoch DBs. Relative directories are relative to the chaindata dir.
+	Routing      map[string]DBRoute `toml:",omitempty"`
+	RuntimeCache DBCacheConfig
+}
+
+type DBRoute struct {
+	Backend string
+	Dir     string
 }
 
 type DBCacheConfig struct {
@@ -26,26 +56,293 @@ type DBCacheConfig struct {
 	Fdlimit uint64
 }
 
-func GetRawDbProducer(chaindataDir string, cfg DBCacheConfig) kvdb.IterableDBProducer {
-	if chaindataDir == "inmemory" || chaindataDir == "" {
-		chaindataDir, _ = os.MkdirTemp("", "opera-tmp")
+// DBsLayout is the effective backends of the DBs in a datadir
+type DBsLayout struct {
+	Backend string
+	Routing map[string]DBRoute `json:",omitempty"`
+}
+
+func checkBackend(backend string) error {
+	switch backend {
+	case PebbleBackend, LevelDbBackend, MemoryBackend:
+		return nil
 	}
-	cacher := func(name string) (int, int) {
-		return int(cfg.Cache), int(cfg.Fdlimit)
+	return fmt.Errorf("unknown DB backend %q", backend)
+}
+
+// Validate checks the backends and the routing patterns
+func (c DBsConfig) Validate() error {
+	if c.Backend != "" {
+		if err := checkBackend(c.Backend); err != nil {
+			return err
+		}
+	}
+	for pattern, route := range c.Routing {
+		if err := checkBackend(route.Backend); err != nil {
+			return fmt.Errorf("DB route %q: %w", pattern, err)
+		}
+		if _, err := fmtfilter.CompileFilter(pattern, pattern); err != nil {
+			return fmt.Errorf("DB route %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ReadDBsLayout reads the backends of the DBs recorded in the chaindata dir.
+// Returns nil if the layout isn't recorded.
+func ReadDBsLayout(chaindataDir string) (*DBsLayout, error) {
+	data, err := os.ReadFile(filepath.Join(chaindataDir, dbsLayoutFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	layout := &DBsLayout{}
+	if err := json.Unmarshal(data, layout); err != nil {
+		return nil, fmt.Errorf("failed to read DBs layout: %w", err)
+	}
+	return layout, nil
+}
+
+// WriteDBsLayout records the backends of the DBs in the chaindata dir
+func WriteDBsLayout(chaindataDir string, layout DBsLayout) error {
+	return writeJSONFile(filepath.Join(chaindataDir, dbsLayoutFile), layout)
+}
+
+// writeJSONFile replaces the file atomically
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func resolveRouting(chaindataDir string, routing map[string]DBRoute) map[string]DBRoute {
+	if len(routing) == 0 {
+		return nil
+	}
+	resolved := make(map[string]DBRoute, len(routing))
+	for pattern, route := range routing {
+		if route.Backend != MemoryBackend && !filepath.IsAbs(route.Dir) {
+			route.Dir = filepath.Join(chaindataDir, route.Dir)
+		}
+		resolved[pattern] = route
+	}
+	return resolved
+}
+
+// GetDBsLayout returns the backends of the DBs in the chaindata dir.
+// The configured backends must match the layout of an existing datadir,
+// which may be converted by the "sonictool db migrate-backend" command.
+func GetDBsLayout(chaindataDir string, cfg DBsConfig) (DBsLayout, error) {
+	if err := cfg.Validate(); err != nil {
+		return DBsLayout{}, err
+	}
+	configured := DBsLayout{
+		Backend: cfg.Backend,
+		Routing: resolveRouting(chaindataDir, cfg.Routing),
+	}
+	if chaindataDir == "inmemory" || chaindataDir == "" || cfg.Backend == MemoryBackend {
+		configured.Backend = MemoryBackend
+		return configured, nil
+	}
+	recorded, err := ReadDBsLayout(chaindataDir)
+	if err != nil {
+		return DBsLayout{}, err
+	}
+	if recorded == nil {
+		if configured.Backend == "" {
+			configured.Backend = PebbleBackend
+		}
+		if configured.Backend != PebbleBackend && !isEmpty(chaindataDir) {
+			// datadirs without the recorded layout are pebble ones
+			recorded = &DBsLayout{Backend: PebbleBackend}
+		} else {
+			return configured, nil
+		}
 	}
+	if configured.Backend != "" && configured.Backend != recorded.Backend ||
+		cfg.Routing != nil && !reflect.DeepEqual(configured.Routing, recorded.Routing) {
+		return DBsLayout{}, fmt.Errorf("configured DBs backend doesn't match the %s datadir, use 'sonictool db migrate-backend' to convert the datadir", recorded.Backend)
+	}
+	return *recorded, nil
+}
 
-	rawProducer := dbcounter.Wrap(pebble.NewProducer(chaindataDir, cacher), true)
+func GetRawDbProducer(chaindataDir string, cfg DBsConfig) (kvdb.IterableDBProducer, error) {
+	if err := resumeDBsMigration(chaindataDir); err != nil {
+		return nil, fmt.Errorf("failed to resume DBs migration: %w", err)
+	}
+	layout, err := GetDBsLayout(chaindataDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(chaindataDir); err == nil && layout.Backend != MemoryBackend {
+		if err := WriteDBsLayout(chaindataDir, layout); err != nil {
+			return nil, fmt.Errorf("failed to write DBs layout: %w", err)
+		}
+	}
+	rawProducer, err := newLayoutProducer(chaindataDir, layout, cfg.RuntimeCache)
+	if err != nil {
+		return nil, err
+	}
+	rawProducer = dbcounter.Wrap(rawProducer, true)
 
 	if metrics.Enabled {
 		rawProducer = WrapDatabaseWithMetrics(rawProducer)
 	}
-	return rawProducer
+	return rawProducer, nil
+}
+
+func newBackendProducer(backend, dir string, cfg DBCacheConfig) kvdb.IterableDBProducer {
+	cacher := func(name string) (int, int) {
+		return int(cfg.Cache), int(cfg.Fdlimit)
+	}
+	switch backend {
+	case LevelDbBackend:
+		return leveldb.NewProducer(dir, cacher)
+	case MemoryBackend:
+		return memorydb.NewProducer(dir)
+	default:
+		return pebble.NewProducer(dir, cacher)
+	}
+}
+
+// newLayoutProducer returns the producer, which opens the DBs by the backends of the layout
+func newLayoutProducer(chaindataDir string, layout DBsLayout, cfg DBCacheConfig) (kvdb.IterableDBProducer, error) {
+	if layout.Backend == MemoryBackend && (chaindataDir == "inmemory" || chaindataDir == "") {
+		// unique in-memory DBs
+		chaindataDir = ""
+	}
+	routes, err := compileRoutes(chaindataDir, layout)
+	if err != nil {
+		return nil, err
+	}
+	p := &routedProducer{
+		def:    newBackendProducer(layout.Backend, chaindataDir, cfg),
+		notDBs: make(map[string]bool),
+	}
+	for _, r := range routes {
+		if r.Backend != MemoryBackend {
+			if filepath.Dir(r.Dir) == filepath.Clean(chaindataDir) {
+				p.notDBs[filepath.Base(r.Dir)] = true
+			}
+			if err := os.MkdirAll(r.Dir, 0700); err != nil {
+				return nil, err
+			}
+		}
+		r.producer = newBackendProducer(r.Backend, r.Dir, cfg)
+		p.routes = append(p.routes, r)
+	}
+	return p, nil
+}
+
+// compileRoutes returns the routes of the layout, the more specific patterns first
+func compileRoutes(chaindataDir string, layout DBsLayout) ([]dbRoute, error) {
+	patterns := make([]string, 0, len(layout.Routing))
+	for pattern := range layout.Routing {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	routes := make([]dbRoute, 0, len(patterns))
+	for _, pattern := range patterns {
+		match, err := fmtfilter.CompileFilter(pattern, pattern)
+		if err != nil {
+			return nil, err
+		}
+		route := layout.Routing[pattern]
+		if route.Backend == MemoryBackend {
+			route.Dir = chaindataDir
+		}
+		routes = append(routes, dbRoute{
+			DBRoute: route,
+			match:   match,
+		})
+	}
+	return routes, nil
+}
+
+// routeOf returns the backend and the directory of the DB
+func routeOf(chaindataDir string, layout DBsLayout, routes []dbRoute, name string) DBRoute {
+	for _, r := range routes {
+		if _, err := r.match(name); err == nil {
+			return r.DBRoute
+		}
+	}
+	return DBRoute{
+		Backend: layout.Backend,
+		Dir:     chaindataDir,
+	}
+}
+
+type dbRoute struct {
+	DBRoute
+	match    func(name string) (string, error)
+	producer kvdb.IterableDBProducer
+}
+
+// routedProducer opens the DBs with the producers of the routes matching the DB names
+type routedProducer struct {
+	def    kvdb.IterableDBProducer
+	routes []dbRoute
+	// notDBs are the route dirs inside the chaindata dir
+	notDBs map[string]bool
+}
+
+func (p *routedProducer) isDB(name string) bool {
+	return !p.notDBs[name] && !strings.HasSuffix(name, migratingSuffix) && !strings.HasSuffix(name, migratedSuffix)
+}
+
+func (p *routedProducer) route(name string) (kvdb.IterableDBProducer, bool) {
+	for _, r := range p.routes {
+		if _, err := r.match(name); err == nil {
+			return r.producer, true
+		}
+	}
+	return p.def, false
 }
 
-func GetDbProducer(chaindataDir string, cfg DBCacheConfig) (kvdb.FullDBProducer, error) {
-	rawProducer := GetRawDbProducer(chaindataDir, cfg)
+// Names of existing databases.
+func (p *routedProducer) Names() []string {
+	names := make([]string, 0)
+	for _, name := range p.def.Names() {
+		if _, routed := p.route(name); !routed && p.isDB(name) {
+			names = append(names, name)
+		}
+	}
+	for _, r := range p.routes {
+		for _, name := range r.producer.Names() {
+			if producer, _ := p.route(name); producer == r.producer && p.isDB(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// OpenDB or create db with name.
+func (p *routedProducer) OpenDB(name string) (kvdb.Store, error) {
+	producer, _ := p.route(name)
+	return producer.OpenDB(name)
+}
+
+func GetDbProducer(chaindataDir string, cfg DBsConfig) (kvdb.FullDBProducer, error) {
+	rawProducer, err := GetRawDbProducer(chaindataDir, cfg)
+	if err != nil {
+		return nil, err
+	}
 	scopedProducer := flaggedproducer.Wrap(rawProducer, FlushIDKey) // pebble-flg
-	_, err := scopedProducer.Initialize(rawProducer.Names(), nil)
+	_, err = scopedProducer.Initialize(rawProducer.Names(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open existing databases: %v", err)
 	}
@@ -54,17 +351,26 @@ func GetDbProducer(chaindataDir string, cfg DBCacheConfig) (kvdb.FullDBProducer,
 	return threads.CountedFullDBProducer(skippingProducer), nil
 }
 
+// isEmptyDBs returns true if the DBs aren't initialized
+func isEmptyDBs(chaindataDir string, cfg DBsConfig) bool {
+	if cfg.Backend == MemoryBackend {
+		return len(memorydb.Names(chaindataDir)) == 0
+	}
+	return isEmpty(chaindataDir)
+}
+
 func isEmpty(dir string) bool {
 	f, err := os.Open(dir)
 	if err != nil {
 		return true
 	}
 	defer f.Close()
-	_, err = f.Readdirnames(1)
+	names, err := f.Readdirnames(2)
 	if err == io.EOF {
 		return true
 	}
-	return false
+	// the recorded layout alone doesn't make the datadir initialized
+	return len(names) == 1 && names[0] == dbsLayoutFile
 }
 
 type GossipStoreAdapter struct {
@@ -0,0 +1,244 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/log"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+const (
+	migratingSuffix = ".migrating"
+	migratedSuffix  = ".old"
+	// dbsMigrationFile is the file in the chaindata dir, which records the DBs migration during the swap of the DBs
+	dbsMigrationFile = "dbs-migration.json"
+)
+
+// MigrateDBsBackend converts the DBs of the chaindata dir into the backends of the target config.
+// The DBs are copied into the staging dirs first, so the datadir is kept intact if the copying fails.
+// The replaced DBs are moved aside into the ".old" dirs until the new ones are in place.
+// The swap is recorded beforehand, and an interrupted swap is finished when the DBs are opened.
+func MigrateDBsBackend(chaindataDir string, target DBsConfig) error {
+	if err := resumeDBsMigration(chaindataDir); err != nil {
+		return err
+	}
+	if isInterrupted(chaindataDir) {
+		return errors.New("genesis processing isn't finished")
+	}
+	if isEmpty(chaindataDir) {
+		return errors.New("database is empty")
+	}
+	if err := target.Validate(); err != nil {
+		return err
+	}
+	if target.Backend == "" || target.Backend == MemoryBackend {
+		return fmt.Errorf("target backend must be %s or %s", PebbleBackend, LevelDbBackend)
+	}
+	for pattern, route := range target.Routing {
+		if route.Backend == MemoryBackend {
+			return fmt.Errorf("DB route %q: in-memory DBs cannot be migrated to", pattern)
+		}
+	}
+	src, err := GetDBsLayout(chaindataDir, DBsConfig{})
+	if err != nil {
+		return err
+	}
+	dst := DBsLayout{
+		Backend: target.Backend,
+		Routing: resolveRouting(chaindataDir, target.Routing),
+	}
+	if reflect.DeepEqual(src, dst) {
+		log.Info("DBs already use the target backends", "backend", dst.Backend)
+		return nil
+	}
+
+	// the DBs are copied into the staging dirs, next to the target dirs
+	staged := DBsLayout{
+		Backend: dst.Backend,
+	}
+	dstDirs := []string{chaindataDir}
+	for pattern, route := range dst.Routing {
+		if staged.Routing == nil {
+			staged.Routing = make(map[string]DBRoute)
+		}
+		staged.Routing[pattern] = DBRoute{
+			Backend: route.Backend,
+			Dir:     route.Dir + migratingSuffix,
+		}
+		dstDirs = append(dstDirs, route.Dir)
+	}
+	for _, dir := range dstDirs {
+		if err := os.RemoveAll(dir + migratingSuffix); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir+migratingSuffix, 0700); err != nil {
+			return err
+		}
+	}
+
+	cache := DBCacheConfig{
+		Cache:   64 * 1024 * 1024,
+		Fdlimit: 100,
+	}
+	srcProducer, err := newLayoutProducer(chaindataDir, src, cache)
+	if err != nil {
+		return err
+	}
+	dstProducer, err := newLayoutProducer(chaindataDir+migratingSuffix, staged, cache)
+	if err != nil {
+		return err
+	}
+	names := srcProducer.Names()
+	for _, name := range names {
+		if err := copyDB(srcProducer, dstProducer, name); err != nil {
+			return fmt.Errorf("failed to copy %s DB: %w", name, err)
+		}
+	}
+
+	// the swap is recorded, so it's resumed if the process is interrupted
+	m := dbsMigration{
+		Src:   src,
+		Dst:   dst,
+		Names: names,
+	}
+	if err := writeJSONFile(filepath.Join(chaindataDir, dbsMigrationFile), m); err != nil {
+		return err
+	}
+	return finishDBsMigration(chaindataDir, m)
+}
+
+// dbsMigration is the record of the DBs migration, which is written once the DBs are copied
+type dbsMigration struct {
+	Src   DBsLayout
+	Dst   DBsLayout
+	Names []string
+}
+
+// resumeDBsMigration finishes the DBs migration interrupted during the swap of the DBs
+func resumeDBsMigration(chaindataDir string) error {
+	data, err := os.ReadFile(filepath.Join(chaindataDir, dbsMigrationFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var m dbsMigration
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("failed to read DBs migration: %w", err)
+	}
+	log.Warn("Resuming interrupted DBs migration", "backend", m.Dst.Backend, "dbs", len(m.Names))
+	return finishDBsMigration(chaindataDir, m)
+}
+
+// finishDBsMigration replaces the source DBs with the copied ones and records the new layout.
+// The DBs already moved into place are skipped, so it may be repeated after an interruption.
+func finishDBsMigration(chaindataDir string, m dbsMigration) error {
+	srcRoutes, err := compileRoutes(chaindataDir, m.Src)
+	if err != nil {
+		return err
+	}
+	dstRoutes, err := compileRoutes(chaindataDir, m.Dst)
+	if err != nil {
+		return err
+	}
+	for _, name := range m.Names {
+		srcRoute := routeOf(chaindataDir, m.Src, srcRoutes, name)
+		dstRoute := routeOf(chaindataDir, m.Dst, dstRoutes, name)
+		staged := filepath.Join(dstRoute.Dir+migratingSuffix, name)
+		if _, err := os.Stat(staged); errors.Is(err, os.ErrNotExist) {
+			// already in place
+			continue
+		} else if err != nil {
+			return err
+		}
+		// move the source DB aside
+		if _, err := os.Stat(filepath.Join(srcRoute.Dir, name)); err == nil {
+			if err := os.MkdirAll(srcRoute.Dir+migratedSuffix, 0700); err != nil {
+				return err
+			}
+			if err := os.Rename(filepath.Join(srcRoute.Dir, name), filepath.Join(srcRoute.Dir+migratedSuffix, name)); err != nil {
+				return err
+			}
+		}
+		// move the copied DB into place
+		if err := os.MkdirAll(dstRoute.Dir, 0700); err != nil {
+			return err
+		}
+		if err := os.Rename(staged, filepath.Join(dstRoute.Dir, name)); err != nil {
+			return err
+		}
+	}
+	if err := WriteDBsLayout(chaindataDir, m.Dst); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(chaindataDir, dbsMigrationFile)); err != nil {
+		return err
+	}
+
+	// erase the replaced DBs
+	for _, dir := range append(srcDirs(chaindataDir, m.Dst), srcDirs(chaindataDir, m.Src)...) {
+		_ = os.RemoveAll(dir + migratingSuffix)
+		_ = os.RemoveAll(dir + migratedSuffix)
+	}
+	for _, route := range m.Src.Routing {
+		// fails unless the route dir is empty
+		_ = os.Remove(route.Dir)
+	}
+	log.Info("DBs are migrated", "backend", m.Dst.Backend, "dbs", len(m.Names))
+	return nil
+}
+
+func srcDirs(chaindataDir string, layout DBsLayout) []string {
+	dirs := []string{chaindataDir}
+	for _, route := range layout.Routing {
+		dirs = append(dirs, route.Dir)
+	}
+	return dirs
+}
+
+func copyDB(src, dst kvdb.DBProducer, name string) (err error) {
+	srcDB, err := src.OpenDB(name)
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	dstDB, err := dst.OpenDB(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := dstDB.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	it := srcDB.NewIterator(nil, nil)
+	defer it.Release()
+	batch := dstDB.NewBatch()
+	keys := 0
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+		keys++
+		if batch.ValueSize() >= kvdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Copied DB", "name", name, "keys", keys)
+	return nil
+}
@@ -0,0 +1,193 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/utils/dbutil/memorydb"
+)
+
+var testDBsCache = DBCacheConfig{
+	Cache:   16 * 1024 * 1024,
+	Fdlimit: 64,
+}
+
+func writeTestDB(t *testing.T, dbs kvdb.DBProducer, name string) {
+	db, err := dbs.OpenDB(name)
+	require.NoError(t, err)
+	require.NoError(t, db.Put([]byte("key"), []byte(name)))
+	require.NoError(t, db.Close())
+}
+
+func checkTestDB(t *testing.T, dbs kvdb.DBProducer, name string) {
+	db, err := dbs.OpenDB(name)
+	require.NoError(t, err)
+	defer db.Close()
+	val, err := db.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, name, string(val))
+}
+
+func sortedNames(dbs kvdb.Iterable) []string {
+	names := dbs.Names()
+	sort.Strings(names)
+	return names
+}
+
+func TestMemoryBackend(t *testing.T) {
+	require := require.New(t)
+	cfg := DBsConfig{
+		Backend:      MemoryBackend,
+		RuntimeCache: testDBsCache,
+	}
+	dir := filepath.Join(t.TempDir(), "chaindata")
+	require.True(isEmptyDBs(dir, cfg))
+
+	dbs, err := GetRawDbProducer(dir, cfg)
+	require.NoError(err)
+	writeTestDB(t, dbs, "gossip")
+
+	// the data survives reopening, without touching the disk
+	dbs, err = GetRawDbProducer(dir, cfg)
+	require.NoError(err)
+	checkTestDB(t, dbs, "gossip")
+	require.Equal([]string{"gossip"}, dbs.Names())
+	require.False(isEmptyDBs(dir, cfg))
+	_, err = os.Stat(dir)
+	require.True(os.IsNotExist(err))
+
+	db, err := dbs.OpenDB("gossip")
+	require.NoError(err)
+	require.NoError(db.Close())
+	db.Drop()
+	require.Empty(dbs.Names())
+
+	// the DBs are freed once the node doesn't need them anymore
+	dbs, err = GetRawDbProducer(dir, cfg)
+	require.NoError(err)
+	writeTestDB(t, dbs, "gossip")
+	memorydb.FreeNamespace(dir)
+	require.True(isEmptyDBs(dir, cfg))
+}
+
+func TestDBsRouting(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	cfg := DBsConfig{
+		Backend: PebbleBackend,
+		Routing: map[string]DBRoute{
+			"lachesis-%d": {Backend: LevelDbBackend, Dir: "epochs"},
+		},
+		RuntimeCache: testDBsCache,
+	}
+	dbs, err := GetRawDbProducer(dir, cfg)
+	require.NoError(err)
+	writeTestDB(t, dbs, "gossip")
+	writeTestDB(t, dbs, "lachesis")
+	writeTestDB(t, dbs, "lachesis-1")
+	require.Equal([]string{"gossip", "lachesis", "lachesis-1"}, sortedNames(dbs))
+	require.DirExists(filepath.Join(dir, "lachesis"))
+	require.DirExists(filepath.Join(dir, "epochs", "lachesis-1"))
+	require.NoDirExists(filepath.Join(dir, "lachesis-1"))
+
+	// the recorded layout is used if the backend isn't configured
+	dbs, err = GetRawDbProducer(dir, DBsConfig{RuntimeCache: testDBsCache})
+	require.NoError(err)
+	require.Equal([]string{"gossip", "lachesis", "lachesis-1"}, sortedNames(dbs))
+	checkTestDB(t, dbs, "lachesis-1")
+
+	// a different backend requires a migration
+	_, err = GetRawDbProducer(dir, DBsConfig{Backend: LevelDbBackend, RuntimeCache: testDBsCache})
+	require.Error(err)
+	_, err = GetRawDbProducer(dir, DBsConfig{Backend: PebbleBackend, Routing: map[string]DBRoute{}, RuntimeCache: testDBsCache})
+	require.Error(err)
+	_, err = GetRawDbProducer(dir, DBsConfig{Backend: "rocksdb"})
+	require.Error(err)
+}
+
+func TestMigrateDBsBackend(t *testing.T) {
+	require := require.New(t)
+	dir := filepath.Join(t.TempDir(), "chaindata")
+	require.NoError(os.MkdirAll(dir, 0700))
+	dbs, err := GetRawDbProducer(dir, DBsConfig{RuntimeCache: testDBsCache})
+	require.NoError(err)
+	for _, name := range []string{"gossip", "lachesis", "lachesis-1"} {
+		writeTestDB(t, dbs, name)
+	}
+
+	target := DBsConfig{
+		Backend: LevelDbBackend,
+		Routing: map[string]DBRoute{
+			"lachesis-%d": {Backend: PebbleBackend, Dir: "epochs"},
+		},
+	}
+	require.NoError(MigrateDBsBackend(dir, target))
+
+	layout, err := ReadDBsLayout(dir)
+	require.NoError(err)
+	require.Equal(LevelDbBackend, layout.Backend)
+	require.Equal(filepath.Join(dir, "epochs"), layout.Routing["lachesis-%d"].Dir)
+
+	dbs, err = GetRawDbProducer(dir, DBsConfig{Backend: LevelDbBackend, RuntimeCache: testDBsCache})
+	require.NoError(err)
+	require.Equal([]string{"gossip", "lachesis", "lachesis-1"}, sortedNames(dbs))
+	for _, name := range dbs.Names() {
+		checkTestDB(t, dbs, name)
+	}
+	require.DirExists(filepath.Join(dir, "epochs", "lachesis-1"))
+	require.NoDirExists(dir + migratingSuffix)
+	require.NoDirExists(dir + migratedSuffix)
+
+	// back to a single pebble dir
+	require.NoError(MigrateDBsBackend(dir, DBsConfig{Backend: PebbleBackend}))
+	dbs, err = GetRawDbProducer(dir, DBsConfig{Backend: PebbleBackend, RuntimeCache: testDBsCache})
+	require.NoError(err)
+	require.Equal([]string{"gossip", "lachesis", "lachesis-1"}, sortedNames(dbs))
+	for _, name := range dbs.Names() {
+		checkTestDB(t, dbs, name)
+	}
+	require.NoDirExists(filepath.Join(dir, "epochs", "lachesis-1"))
+}
+
+func TestMigrateDBsBackendInterrupted(t *testing.T) {
+	require := require.New(t)
+	dir := filepath.Join(t.TempDir(), "chaindata")
+	require.NoError(os.MkdirAll(dir, 0700))
+	dbs, err := GetRawDbProducer(dir, DBsConfig{RuntimeCache: testDBsCache})
+	require.NoError(err)
+	for _, name := range []string{"gossip", "lachesis", "lachesis-1"} {
+		writeTestDB(t, dbs, name)
+	}
+
+	// the swap fails at the epoch DB, after the other DBs are replaced
+	epochs := filepath.Join(dir, "epochs")
+	require.NoError(os.WriteFile(epochs, nil, 0600))
+	target := DBsConfig{
+		Backend: LevelDbBackend,
+		Routing: map[string]DBRoute{
+			"lachesis-%d": {Backend: PebbleBackend, Dir: "epochs"},
+		},
+	}
+	require.Error(MigrateDBsBackend(dir, target))
+	require.FileExists(filepath.Join(dir, dbsMigrationFile))
+	layout, err := ReadDBsLayout(dir)
+	require.NoError(err)
+	require.Equal(PebbleBackend, layout.Backend)
+
+	// the swap is finished once the DBs are opened
+	require.NoError(os.Remove(epochs))
+	dbs, err = GetRawDbProducer(dir, DBsConfig{Backend: LevelDbBackend, RuntimeCache: testDBsCache})
+	require.NoError(err)
+	require.Equal([]string{"gossip", "lachesis", "lachesis-1"}, sortedNames(dbs))
+	for _, name := range dbs.Names() {
+		checkTestDB(t, dbs, name)
+	}
+	require.NoFileExists(filepath.Join(dir, dbsMigrationFile))
+	require.NoDirExists(dir + migratingSuffix)
+	require.NoDirExists(dir + migratedSuffix)
+}
@@ -2,7 +2,6 @@ package state
 
 import (
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"math/big"
@@ -16,7 +15,7 @@ type StateDB interface {
 	TxIndex() int
 	GetProof(addr common.Address) ([][]byte, error)
 	GetStorageProof(a common.Address, key common.Hash) ([][]byte, error)
-	StorageTrie(addr common.Address) state.Trie
+	GetStorageRoot(addr common.Address) (common.Hash, error)
 	SetBalance(addr common.Address, amount *big.Int)
 	SetCode(addr common.Address, code []byte)
 	SetStorage(addr common.Address, storage map[common.Hash]common.Hash)
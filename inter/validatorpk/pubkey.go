@@ -1,6 +1,8 @@
 package validatorpk
 
 import (
+	"bytes"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 )
@@ -24,6 +26,10 @@ func (pk PubKey) Empty() bool {
 	return len(pk.Raw) == 0 && pk.Type == 0
 }
 
+func (pk PubKey) Equal(other PubKey) bool {
+	return pk.Type == other.Type && bytes.Equal(pk.Raw, other.Raw)
+}
+
 func (pk PubKey) String() string {
 	return "0x" + common.Bytes2Hex(pk.Bytes())
 }
@@ -0,0 +1,38 @@
+package genesis
+
+import (
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type (
+	// StateDiff is the set of state changes made by a block.
+	// The deleted accounts are cleared before the other changes are applied.
+	StateDiff struct {
+		Block    idx.Block
+		Deleted  []common.Address
+		Balances []BalanceDiff
+		Nonces   []NonceDiff
+		Codes    []CodeDiff
+		Slots    []SlotDiff
+	}
+	BalanceDiff struct {
+		Address common.Address
+		Balance *big.Int
+	}
+	NonceDiff struct {
+		Address common.Address
+		Nonce   uint64
+	}
+	CodeDiff struct {
+		Address common.Address
+		Code    []byte
+	}
+	SlotDiff struct {
+		Address common.Address
+		Key     common.Hash
+		Value   common.Hash
+	}
+)
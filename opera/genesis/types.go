@@ -2,8 +2,10 @@ package genesis
 
 import (
 	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"io"
 
+	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/ibr"
 	"github.com/Fantom-foundation/go-opera/inter/ier"
 )
@@ -21,6 +23,15 @@ type (
 	Epochs interface {
 		ForEach(fn func(ier.LlrIdxFullEpochRecord) bool)
 	}
+	BlockVotes interface {
+		ForEach(fn func(inter.LlrSignedBlockVotes) bool)
+	}
+	EpochVotes interface {
+		ForEach(fn func(inter.LlrSignedEpochVote) bool)
+	}
+	StateDiffs interface {
+		ForEach(fn func(StateDiff) bool)
+	}
 	EvmItems interface {
 		ForEach(fn func(key, value []byte) bool)
 	}
@@ -33,6 +44,17 @@ type (
 	SignatureSection interface {
 		GetSignature() ([]byte, error)
 	}
+	DeltaSection interface {
+		// GetBase returns the base of a delta genesis, or an error if the genesis is not a delta.
+		GetBase() (*DeltaBase, error)
+	}
+	// DeltaBase identifies the block a delta genesis is applicable on top of.
+	DeltaBase struct {
+		Epoch   idx.Epoch
+		Block   idx.Block
+		Atropos hash.Event
+		Root    hash.Hash
+	}
 	SignedMetadata struct {
 		Signature []byte
 		Hashes []byte
@@ -42,10 +64,14 @@ type (
 
 		Blocks      Blocks
 		Epochs      Epochs
+		BlockVotes  BlockVotes
+		EpochVotes  EpochVotes
+		StateDiffs  StateDiffs
 		RawEvmItems EvmItems
 		FwsLiveSection
 		FwsArchiveSection
 		SignatureSection
+		DeltaSection
 	}
 )
 
@@ -27,6 +27,16 @@ func FwsArchiveSection(i int) string {
 	return getSectionName("fwa", i)
 }
 
+// DeltaSectionName is the name of the section, which marks a delta genesis and identifies its base.
+const DeltaSectionName = "delta"
+
+// Names of the sections of a delta genesis, which carry the LLR votes for the records and the state changes of the blocks.
+const (
+	BlockVotesSectionName = "bvs"
+	EpochVotesSectionName = "evs"
+	StateDiffsSectionName = "diffs"
+)
+
 type FilesMap func(string) (io.Reader, error)
 
 // Store is a node persistent storage working over a physical zip archive.
@@ -6,6 +6,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"io"
 
+	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/ibr"
 	"github.com/Fantom-foundation/go-opera/inter/ier"
 	"github.com/Fantom-foundation/go-opera/opera/genesis"
@@ -19,6 +20,15 @@ type (
 	Epochs struct {
 		fMap FilesMap
 	}
+	BlockVotes struct {
+		fMap FilesMap
+	}
+	EpochVotes struct {
+		fMap FilesMap
+	}
+	StateDiffs struct {
+		fMap FilesMap
+	}
 	RawEvmItems struct {
 		fMap FilesMap
 	}
@@ -31,6 +41,9 @@ type (
 	SignatureSection struct {
 		fMap FilesMap
 	}
+	DeltaSection struct {
+		fMap FilesMap
+	}
 )
 
 func (s *Store) Genesis() genesis.Genesis {
@@ -38,10 +51,14 @@ func (s *Store) Genesis() genesis.Genesis {
 		Header:      s.head,
 		Blocks:      s.Blocks(),
 		Epochs:      s.Epochs(),
+		BlockVotes:  s.BlockVotes(),
+		EpochVotes:  s.EpochVotes(),
+		StateDiffs:  s.StateDiffs(),
 		RawEvmItems: s.RawEvmItems(),
 		FwsLiveSection:  s.FwsLiveSection(),
 		FwsArchiveSection: s.FwsArchiveSection(),
 		SignatureSection: s.SignatureSection(),
+		DeltaSection: s.DeltaSection(),
 	}
 }
 
@@ -110,6 +127,81 @@ func (s Epochs) ForEach(fn func(ier.LlrIdxFullEpochRecord) bool) {
 	}
 }
 
+func (s *Store) BlockVotes() genesis.BlockVotes {
+	return BlockVotes{s.fMap}
+}
+
+func (s BlockVotes) ForEach(fn func(inter.LlrSignedBlockVotes) bool) {
+	f, err := s.fMap(BlockVotesSectionName)
+	if err != nil {
+		return
+	}
+	stream := rlp.NewStream(f, 0)
+	for {
+		bvs := inter.LlrSignedBlockVotes{}
+		err = stream.Decode(&bvs)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Crit("Failed to decode BlockVotes genesis section", "err", err)
+		}
+		if !fn(bvs) {
+			break
+		}
+	}
+}
+
+func (s *Store) EpochVotes() genesis.EpochVotes {
+	return EpochVotes{s.fMap}
+}
+
+func (s EpochVotes) ForEach(fn func(inter.LlrSignedEpochVote) bool) {
+	f, err := s.fMap(EpochVotesSectionName)
+	if err != nil {
+		return
+	}
+	stream := rlp.NewStream(f, 0)
+	for {
+		ev := inter.LlrSignedEpochVote{}
+		err = stream.Decode(&ev)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Crit("Failed to decode EpochVotes genesis section", "err", err)
+		}
+		if !fn(ev) {
+			break
+		}
+	}
+}
+
+func (s *Store) StateDiffs() genesis.StateDiffs {
+	return StateDiffs{s.fMap}
+}
+
+func (s StateDiffs) ForEach(fn func(genesis.StateDiff) bool) {
+	f, err := s.fMap(StateDiffsSectionName)
+	if err != nil {
+		return
+	}
+	stream := rlp.NewStream(f, 0)
+	for {
+		diff := genesis.StateDiff{}
+		err = stream.Decode(&diff)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Crit("Failed to decode StateDiffs genesis section", "err", err)
+		}
+		if !fn(diff) {
+			break
+		}
+	}
+}
+
 func (s *Store) RawEvmItems() genesis.EvmItems {
 	return RawEvmItems{s.fMap}
 }
@@ -160,3 +252,19 @@ func (s SignatureSection) GetSignature() ([]byte, error) {
 	}
 	return io.ReadAll(f)
 }
+
+func (s *Store) DeltaSection() genesis.DeltaSection {
+	return DeltaSection{s.fMap}
+}
+
+func (s DeltaSection) GetBase() (*genesis.DeltaBase, error) {
+	f, err := s.fMap(DeltaSectionName)
+	if err != nil {
+		return nil, err
+	}
+	base := &genesis.DeltaBase{}
+	if err := rlp.Decode(f, base); err != nil {
+		return nil, fmt.Errorf("failed to decode delta genesis section: %w", err)
+	}
+	return base, nil
+}
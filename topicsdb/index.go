@@ -41,6 +41,8 @@ func (tt *index) WrapTablesAsBatched() (unwrap func()) {
 }
 
 // FindInBlocks returns all log records of block range by pattern. 1st pattern element is an address.
+// Empty pattern elements are wildcards, the pattern of wildcards only matches every log record
+// which has at least the topics of the pattern.
 func (tt *index) FindInBlocks(ctx context.Context, from, to idx.Block, pattern [][]common.Hash) (logs []*types.Log, err error) {
 	err = tt.ForEachInBlocks(
 		ctx,
@@ -61,7 +63,7 @@ func (tt *index) ForEachInBlocks(ctx context.Context, from, to idx.Block, patter
 	}
 
 	pattern, err := limitPattern(pattern)
-	if err != nil {
+	if err != nil && err != ErrEmptyTopics {
 		return err
 	}
 
@@ -75,6 +77,9 @@ func (tt *index) ForEachInBlocks(ctx context.Context, from, to idx.Block, patter
 		return
 	}
 
+	if err == ErrEmptyTopics {
+		return tt.scanWildcard(ctx, minTopicsCount(pattern), uint64(from), uint64(to), onMatched, doNothing)
+	}
 	return tt.searchParallel(ctx, pattern, uint64(from), uint64(to), onMatched, doNothing)
 }
 
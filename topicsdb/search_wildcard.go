@@ -0,0 +1,70 @@
+package topicsdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// scanWildcard matches the log records of the blocks range which have at least minTopics topics.
+// It serves the patterns of wildcards only, which can't be searched by the topics.
+func (tt *index) scanWildcard(ctx context.Context, minTopics uint8, blockStart, blockEnd uint64, onMatched logHandler, onDbIterator func()) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	onDbIterator()
+	it := tt.table.Logrec.NewIterator(nil, uintToBytes(blockStart))
+	defer it.Release()
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(it.Key()) != logrecKeySize {
+			continue
+		}
+		var id ID
+		copy(id[:], it.Key())
+		if blockEnd > 0 && id.BlockNumber() > blockEnd {
+			break
+		}
+
+		topicsCount, err := tt.topicsCount(id, it.Value())
+		if err != nil {
+			return err
+		}
+		if topicsCount < minTopics {
+			continue
+		}
+		gonext, err := onMatched(newLogrec(id, topicsCount))
+		if err != nil {
+			return err
+		}
+		if !gonext {
+			break
+		}
+	}
+	return it.Error()
+}
+
+// topicsCount returns the topics count of the log record.
+// The record doesn't store the count, so it's taken from the index of the record address,
+// which is located after the topics and the block hash.
+func (tt *index) topicsCount(id ID, rec []byte) (uint8, error) {
+	for count := uint8(0); count <= maxTopicsCount; count++ {
+		offset := int(count)*hashSize + hashSize
+		if len(rec) < offset+common.AddressLength {
+			break
+		}
+		address := common.BytesToAddress(rec[offset : offset+common.AddressLength])
+		indexed, err := tt.table.Topic.Get(topicKey(address.Hash(), 0, id))
+		if err != nil {
+			return 0, err
+		}
+		if len(indexed) > 0 && bytesToPos(indexed) == count {
+			return count, nil
+		}
+	}
+	return 0, fmt.Errorf("log record %x is not indexed", id.Bytes())
+}
@@ -41,7 +41,7 @@ func (tt *withThreadPool) ForEachInBlocks(ctx context.Context, from, to idx.Bloc
 	}
 
 	pattern, err := limitPattern(pattern)
-	if err != nil {
+	if err != nil && err != ErrEmptyTopics {
 		return err
 	}
 
@@ -55,6 +55,26 @@ func (tt *withThreadPool) ForEachInBlocks(ctx context.Context, from, to idx.Bloc
 		return
 	}
 
+	if err == ErrEmptyTopics {
+		// the wildcard scan is a single thread
+		for {
+			got, release := threads.GlobalPool.Lock(1)
+			if got > 0 {
+				onDbIterator := func() {
+					release(got)
+				}
+				return tt.scanWildcard(ctx, minTopicsCount(pattern), uint64(from), uint64(to), onMatched, onDbIterator)
+			}
+			release(got)
+			select {
+			case <-time.After(time.Millisecond):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
 	splitby := 0
 	parallels := 0
 	for i := range pattern {
@@ -59,6 +59,14 @@ func limitPattern(pattern [][]common.Hash) (limited [][]common.Hash, err error)
 	return
 }
 
+// minTopicsCount returns the topics count which a log record has to have to match the pattern.
+func minTopicsCount(pattern [][]common.Hash) uint8 {
+	if len(pattern) == 0 {
+		return 0
+	}
+	return uint8(len(pattern) - 1)
+}
+
 func uniqOnly(hh []common.Hash) []common.Hash {
 	index := make(map[common.Hash]struct{}, len(hh))
 	for _, h := range hh {
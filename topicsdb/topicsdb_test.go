@@ -363,6 +363,80 @@ func TestIndexSearchSimple(t *testing.T) {
 
 }
 
+func TestIndexSearchWildcards(t *testing.T) {
+	logger.SetTestMode(t)
+	var (
+		hash1 = common.BytesToHash([]byte("topic1"))
+		hash2 = common.BytesToHash([]byte("topic2"))
+		addr1 = randAddress()
+		addr2 = randAddress()
+	)
+	testdata := []*types.Log{{
+		BlockNumber: 1,
+		Address:     addr1,
+		Topics:      []common.Hash{},
+		Data:        []byte("data"),
+	}, {
+		BlockNumber: 2,
+		Address:     addr2,
+		Topics:      []common.Hash{hash1},
+		Data:        []byte{},
+	}, {
+		BlockNumber: 2,
+		Address:     addr1,
+		Topics:      []common.Hash{hash1, hash2},
+		Index:       1,
+		Data:        make([]byte, 100),
+	}, {
+		BlockNumber: 5,
+		Address:     addr2,
+		Topics:      []common.Hash{hash2, hash2, hash2, hash2},
+		Data:        []byte{},
+	},
+	}
+
+	index := newTestIndex()
+	for _, l := range testdata {
+		err := index.Push(l)
+		require.NoError(t, err)
+	}
+
+	pooled := withThreadPool{index}
+
+	for dsc, method := range map[string]func(context.Context, idx.Block, idx.Block, [][]common.Hash) ([]*types.Log, error){
+		"index":  index.FindInBlocks,
+		"pooled": pooled.FindInBlocks,
+	} {
+		t.Run(dsc, func(t *testing.T) {
+			for _, tc := range []struct {
+				name     string
+				from, to idx.Block
+				pattern  [][]common.Hash
+				exp      []*types.Log
+			}{
+				{"empty pattern", 0, 0, [][]common.Hash{}, testdata},
+				{"any address", 0, 10, [][]common.Hash{{}}, testdata},
+				{"any 2 topics", 0, 10, [][]common.Hash{{}, {}, {}}, testdata[2:]},
+				{"any 4 topics", 0, 10, [][]common.Hash{{}, {}, {}, {}, {}}, testdata[3:]},
+				{"blocks range", 2, 4, [][]common.Hash{{}, {}}, testdata[1:3]},
+			} {
+				t.Run(tc.name, func(t *testing.T) {
+					require := require.New(t)
+					got, err := method(nil, tc.from, tc.to, tc.pattern)
+					require.NoError(err)
+					require.Equal(len(tc.exp), len(got))
+					for i, l := range tc.exp {
+						require.Equal(l.BlockNumber, got[i].BlockNumber)
+						require.Equal(l.Address, got[i].Address)
+						require.Equal(l.Topics, got[i].Topics)
+						require.Equal(l.Data, got[i].Data)
+					}
+				})
+			}
+		})
+	}
+}
+
 func TestMaxTopicsCount(t *testing.T) {
 	logger.SetTestMode(t)
 
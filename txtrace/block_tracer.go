@@ -0,0 +1,131 @@
+package txtrace
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+)
+
+// BlockTracer collects traces of all the transactions applied in a block,
+// so they can be stored during the block processing instead of replaying the block later.
+// It implements evmcore.TxTracer.
+type BlockTracer struct {
+	blockHash   common.Hash
+	blockNumber big.Int
+
+	tx      *types.Transaction
+	msg     types.Message
+	current *TraceStructLogger
+	traces  map[common.Hash][]ActionTrace
+}
+
+// NewBlockTracer creates a tracer of the block transactions.
+func NewBlockTracer(blockHash common.Hash, blockNumber *big.Int) *BlockTracer {
+	return &BlockTracer{
+		blockHash:   blockHash,
+		blockNumber: *blockNumber,
+		traces:      make(map[common.Hash][]ActionTrace),
+	}
+}
+
+// CaptureTxStart starts tracing of a new transaction.
+func (t *BlockTracer) CaptureTxStart(tx *types.Transaction, msg types.Message) {
+	t.tx = tx
+	t.msg = msg
+	t.current = &TraceStructLogger{
+		tx:          tx.Hash(),
+		from:        msg.From(),
+		to:          msg.To(),
+		value:       *msg.Value(),
+		blockHash:   t.blockHash,
+		blockNumber: t.blockNumber,
+		gasLimit:    tx.Gas(),
+	}
+}
+
+// CaptureTxEnd finishes tracing of the current transaction.
+// Traces of skipped transactions are dropped.
+func (t *BlockTracer) CaptureTxEnd(result *evmcore.ExecutionResult, err error) {
+	if t.current == nil {
+		return
+	}
+	defer func() {
+		t.current = nil
+	}()
+	if err != nil {
+		return
+	}
+	traces := *t.current.GetResult()
+	if len(traces) == 0 {
+		if result != nil && result.Err != nil {
+			errTrace := GetErrorTraceFromMsg(&t.msg, t.blockHash, t.blockNumber, t.tx.Hash(), 0, result.Err)
+			t.traces[t.tx.Hash()] = []ActionTrace{*errTrace}
+		}
+		return
+	}
+	// set gas used of the root call the same way as the replay
+	// with the gas from transaction receipt does
+	for i := range traces {
+		if len(traces[i].TraceAddress) == 0 && traces[i].Result != nil {
+			traces[i].Result.GasUsed = hexutil.Uint64(result.UsedGas)
+		}
+	}
+	t.traces[t.tx.Hash()] = traces
+}
+
+// GetResult returns traces of the given block transactions,
+// the transaction positions are taken from the receipts.
+func (t *BlockTracer) GetResult(txs types.Transactions, receipts types.Receipts) []ActionTrace {
+	result := make([]ActionTrace, 0, len(txs))
+	for i, tx := range txs {
+		for _, trace := range t.traces[tx.Hash()] {
+			if i < len(receipts) && receipts[i] != nil {
+				trace.TransactionPosition = uint64(receipts[i].TransactionIndex)
+			}
+			result = append(result, trace)
+		}
+	}
+	return result
+}
+
+// CaptureStart implements vm.Tracer.
+func (t *BlockTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	if t.current != nil {
+		t.current.CaptureStart(env, from, to, create, input, gas, value)
+	}
+}
+
+// CaptureState implements vm.Tracer.
+func (t *BlockTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+// CaptureEnter implements vm.Tracer.
+func (t *BlockTracer) CaptureEnter(op vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.current != nil {
+		t.current.CaptureEnter(op, from, to, input, gas, value)
+	}
+}
+
+// CaptureExit implements vm.Tracer.
+func (t *BlockTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if t.current != nil {
+		t.current.CaptureExit(output, gasUsed, err)
+	}
+}
+
+// CaptureEnd implements vm.Tracer.
+func (t *BlockTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {
+	if t.current != nil {
+		t.current.CaptureEnd(output, gasUsed, d, err)
+	}
+}
+
+// CaptureFault implements vm.Tracer.
+func (t *BlockTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
@@ -0,0 +1,165 @@
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+// callFrame is a single call of the call tree.
+// The fields order matches the output of the JavaScript callTracer.
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to,omitempty"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Calls        []callFrame     `json:"calls,omitempty"`
+}
+
+func (f *callFrame) processOutput(output []byte, err error) {
+	output = common.CopyBytes(output)
+	if err == nil {
+		f.Output = output
+		return
+	}
+	f.Error = err.Error()
+	if f.Type == vm.CREATE.String() || f.Type == vm.CREATE2.String() {
+		f.To = nil
+	}
+	if !errors.Is(err, vm.ErrExecutionReverted) || len(output) == 0 {
+		return
+	}
+	f.Output = output
+	if len(output) < 4 {
+		return
+	}
+	if unpacked, err := abi.UnpackRevert(output); err == nil {
+		f.RevertReason = unpacked
+	}
+}
+
+type callTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall"` // if true, inner calls are not traced
+}
+
+// callTracer reports the nested call frames of a transaction
+// with gas, input, output and revert reason of each of them.
+type callTracer struct {
+	noopCapture
+	interrupt
+
+	config    callTracerConfig
+	callstack []callFrame
+	gasLimit  uint64
+}
+
+func newCallTracer(_ *tracers.Context, cfg json.RawMessage) (Tracer, error) {
+	t := &callTracer{
+		callstack: make([]callFrame, 1),
+	}
+	if err := parseConfig(cfg, &t.config); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CaptureTxStart remembers the tx gas limit - reported as the gas of the top call.
+func (t *callTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+// CaptureTxEnd sets the gas used by the whole transaction to the top call.
+func (t *callTracer) CaptureTxEnd(restGas uint64) {
+	if t.gasLimit != 0 {
+		t.callstack[0].GasUsed = hexutil.Uint64(t.gasLimit - restGas)
+	}
+}
+
+// CaptureStart implements vm.Tracer and initializes the top call.
+func (t *callTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.setEnv(env)
+	gasLimit := t.gasLimit
+	if gasLimit == 0 {
+		gasLimit = gas
+	}
+	t.callstack[0] = callFrame{
+		Type:  vm.CALL.String(),
+		From:  from,
+		To:    &to,
+		Input: common.CopyBytes(input),
+		Gas:   hexutil.Uint64(gasLimit),
+	}
+	if create {
+		t.callstack[0].Type = vm.CREATE.String()
+	}
+	if value != nil {
+		t.callstack[0].Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+}
+
+// CaptureEnd implements vm.Tracer and finalizes the top call.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	t.callstack[0].GasUsed = hexutil.Uint64(gasUsed)
+	t.callstack[0].processOutput(output, err)
+}
+
+// CaptureEnter implements vm.Tracer and opens a new inner call frame.
+func (t *callTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.config.OnlyTopCall {
+		return
+	}
+	call := callFrame{
+		Type:  typ.String(),
+		From:  from,
+		To:    &to,
+		Input: common.CopyBytes(input),
+		Gas:   hexutil.Uint64(gas),
+	}
+	if value != nil {
+		call.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureExit implements vm.Tracer and attaches the finished call frame to its parent.
+func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if t.config.OnlyTopCall {
+		return
+	}
+	size := len(t.callstack)
+	if size <= 1 {
+		return
+	}
+	call := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+	size -= 1
+
+	call.GasUsed = hexutil.Uint64(gasUsed)
+	call.processOutput(output, err)
+	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
+}
+
+// GetResult returns the JSON-encoded call tree.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+	res, err := json.Marshal(t.callstack[0])
+	if err != nil {
+		return nil, err
+	}
+	return res, t.err()
+}
@@ -0,0 +1,255 @@
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+type account struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type prestateTracerConfig struct {
+	DiffMode bool `json:"diffMode"` // if true, the pre and post state of the modified accounts are returned
+}
+
+type prestateDiff struct {
+	Pre  map[common.Address]*account `json:"pre"`
+	Post map[common.Address]*account `json:"post"`
+}
+
+// prestateTracer reports the state of all the accounts and storage slots
+// touched by a transaction, as they were before the transaction was applied.
+// In the diff mode it reports the modified accounts only, before and after the transaction.
+type prestateTracer struct {
+	noopCapture
+	interrupt
+
+	config   prestateTracerConfig
+	env      *vm.EVM
+	pre      map[common.Address]*account
+	post     map[common.Address]*account
+	created  map[common.Address]bool
+	gasLimit uint64
+}
+
+func newPrestateTracer(_ *tracers.Context, cfg json.RawMessage) (Tracer, error) {
+	t := &prestateTracer{
+		pre:     make(map[common.Address]*account),
+		post:    make(map[common.Address]*account),
+		created: make(map[common.Address]bool),
+	}
+	if err := parseConfig(cfg, &t.config); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CaptureTxStart remembers the tx gas limit - needed to restore the sender pre-tx balance.
+func (t *prestateTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+// CaptureStart implements vm.Tracer and records the sender and the recipient.
+// As the sender already paid for the gas and the value is already transferred,
+// their balances and the sender nonce are reverted to the pre-tx values.
+func (t *prestateTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.setEnv(env)
+	t.env = env
+	if value == nil {
+		value = new(big.Int)
+	}
+	gasLimit := t.gasLimit
+	if gasLimit == 0 {
+		gasLimit = gas
+	}
+
+	t.lookupAccount(from)
+	if create {
+		// the new contract did not exist before the transaction
+		t.pre[to] = &account{
+			Balance: (*hexutil.Big)(new(big.Int)),
+			Storage: make(map[common.Hash]common.Hash),
+		}
+		t.created[to] = true
+	} else {
+		t.lookupAccount(to)
+		toBal := new(big.Int).Sub(t.pre[to].Balance.ToInt(), value)
+		t.pre[to].Balance = (*hexutil.Big)(toBal)
+	}
+
+	fee := new(big.Int)
+	if gasPrice := env.TxContext.GasPrice; gasPrice != nil {
+		fee.Mul(new(big.Int).SetUint64(gasLimit), gasPrice)
+	}
+	fromBal := new(big.Int).Add(t.pre[from].Balance.ToInt(), value)
+	fromBal.Add(fromBal, fee)
+	t.pre[from].Balance = (*hexutil.Big)(fromBal)
+	if t.pre[from].Nonce > 0 {
+		t.pre[from].Nonce--
+	}
+}
+
+// CaptureState implements vm.Tracer and records every account and storage slot
+// before it is accessed by the executed opcode.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if err != nil {
+		return
+	}
+	stackData := scope.Stack.Data()
+	stackLen := len(stackData)
+	caller := scope.Contract.Address()
+	switch {
+	case stackLen >= 1 && (op == vm.SLOAD || op == vm.SSTORE):
+		slot := common.Hash(stackData[stackLen-1].Bytes32())
+		t.lookupStorage(caller, slot)
+	case stackLen >= 1 && (op == vm.EXTCODECOPY || op == vm.EXTCODEHASH || op == vm.EXTCODESIZE || op == vm.BALANCE || op == vm.SELFDESTRUCT):
+		addr := common.Address(stackData[stackLen-1].Bytes20())
+		t.lookupAccount(addr)
+	case stackLen >= 5 && (op == vm.DELEGATECALL || op == vm.CALL || op == vm.STATICCALL || op == vm.CALLCODE):
+		addr := common.Address(stackData[stackLen-2].Bytes20())
+		t.lookupAccount(addr)
+	case op == vm.CREATE:
+		nonce := env.StateDB.GetNonce(caller)
+		addr := crypto.CreateAddress(caller, nonce)
+		t.lookupAccount(addr)
+		t.created[addr] = true
+	case stackLen >= 4 && op == vm.CREATE2:
+		offset := stackData[stackLen-2]
+		size := stackData[stackLen-3]
+		if !offset.IsUint64() || !size.IsUint64() || offset.Uint64()+size.Uint64() > uint64(scope.Memory.Len()) {
+			return
+		}
+		init := scope.Memory.GetCopy(int64(offset.Uint64()), int64(size.Uint64()))
+		salt := stackData[stackLen-4].Bytes32()
+		addr := crypto.CreateAddress2(caller, salt, crypto.Keccak256(init))
+		t.lookupAccount(addr)
+		t.created[addr] = true
+	}
+}
+
+// CaptureEnd implements vm.Tracer.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {}
+
+// CaptureTxEnd collects the post-tx state of the touched accounts in the diff mode.
+func (t *prestateTracer) CaptureTxEnd(restGas uint64) {
+	if t.env == nil {
+		return
+	}
+	if !t.config.DiffMode {
+		for addr := range t.created {
+			// remove accounts which did not exist before the transaction
+			if acc, ok := t.pre[addr]; ok && isEmptyAccount(acc) {
+				delete(t.pre, addr)
+			}
+		}
+		return
+	}
+
+	db := t.env.StateDB
+	for addr, pre := range t.pre {
+		created := t.created[addr] && isEmptyAccount(pre)
+		if created {
+			delete(t.pre, addr)
+		}
+		if !db.Exist(addr) || db.HasSuicided(addr) {
+			// deleted accounts are reported in the pre state only
+			continue
+		}
+
+		modified := false
+		post := &account{
+			Storage: make(map[common.Hash]common.Hash),
+		}
+		newBalance := db.GetBalance(addr)
+		newNonce := db.GetNonce(addr)
+		newCode := db.GetCode(addr)
+		if newBalance.Cmp(pre.Balance.ToInt()) != 0 {
+			modified = true
+			post.Balance = (*hexutil.Big)(new(big.Int).Set(newBalance))
+		}
+		if newNonce != pre.Nonce {
+			modified = true
+			post.Nonce = newNonce
+		}
+		if !bytes.Equal(newCode, pre.Code) {
+			modified = true
+			post.Code = common.CopyBytes(newCode)
+		}
+		for key, val := range pre.Storage {
+			newVal := db.GetState(addr, key)
+			if val == newVal {
+				// omit unchanged slots
+				delete(pre.Storage, key)
+				continue
+			}
+			modified = true
+			if val == (common.Hash{}) {
+				delete(pre.Storage, key)
+			}
+			if newVal != (common.Hash{}) {
+				post.Storage[key] = newVal
+			}
+		}
+
+		if modified {
+			t.post[addr] = post
+		} else if !created {
+			// the account was only read
+			delete(t.pre, addr)
+		}
+	}
+}
+
+// GetResult returns the JSON-encoded pre-tx state, or the state diff in the diff mode.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	var (
+		res []byte
+		err error
+	)
+	if t.config.DiffMode {
+		res, err = json.Marshal(prestateDiff{t.pre, t.post})
+	} else {
+		res, err = json.Marshal(t.pre)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, t.err()
+}
+
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.pre[addr]; ok {
+		return
+	}
+	db := t.env.StateDB
+	t.pre[addr] = &account{
+		Balance: (*hexutil.Big)(new(big.Int).Set(db.GetBalance(addr))),
+		Nonce:   db.GetNonce(addr),
+		Code:    common.CopyBytes(db.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+	if _, ok := t.pre[addr].Storage[key]; ok {
+		return
+	}
+	t.pre[addr].Storage[key] = t.env.StateDB.GetState(addr, key)
+}
+
+func isEmptyAccount(acc *account) bool {
+	return acc.Nonce == 0 && len(acc.Code) == 0 && acc.Balance.ToInt().Sign() == 0
+}
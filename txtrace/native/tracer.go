@@ -0,0 +1,116 @@
+// Package native implements the built-in debug tracers in Go,
+// as a faster alternative to their JavaScript counterparts.
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+// Tracer is a native tracer of a single transaction.
+type Tracer interface {
+	vm.Tracer
+	// CaptureTxStart is called before the transaction is applied.
+	CaptureTxStart(gasLimit uint64)
+	// CaptureTxEnd is called after the transaction is applied, including the gas refund.
+	CaptureTxEnd(restGas uint64)
+	// GetResult returns the JSON-encoded tracing result.
+	GetResult() (json.RawMessage, error)
+	// Stop terminates the tracing with the given error (e.g. on a timeout).
+	Stop(err error)
+}
+
+type ctorFn func(ctx *tracers.Context, cfg json.RawMessage) (Tracer, error)
+
+var ctors = map[string]ctorFn{
+	"callTracer":     newCallTracer,
+	"prestateTracer": newPrestateTracer,
+	"4byteTracer":    newFourByteTracer,
+}
+
+// Exists returns true if a native tracer of the given name is available.
+func Exists(name string) bool {
+	_, ok := ctors[name]
+	return ok
+}
+
+// New creates the native tracer of the given name.
+// The returned bool is false if no such tracer exists.
+func New(name string, ctx *tracers.Context, cfg json.RawMessage) (Tracer, bool, error) {
+	ctor, ok := ctors[name]
+	if !ok {
+		return nil, false, nil
+	}
+	if ctx == nil {
+		ctx = new(tracers.Context)
+	}
+	t, err := ctor(ctx, cfg)
+	return t, true, err
+}
+
+// parseConfig decodes an optional tracer config.
+func parseConfig(cfg json.RawMessage, v interface{}) error {
+	if len(cfg) == 0 || string(cfg) == "null" {
+		return nil
+	}
+	return json.Unmarshal(cfg, v)
+}
+
+// interrupt holds the reason for stopping a tracer
+// and aborts the traced EVM execution once stopped.
+type interrupt struct {
+	mu     sync.Mutex
+	env    *vm.EVM
+	reason error
+}
+
+func (i *interrupt) setEnv(env *vm.EVM) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.env = env
+	if i.reason != nil {
+		env.Cancel()
+	}
+}
+
+// Stop terminates the tracing with the given error.
+func (i *interrupt) Stop(err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.reason = err
+	if i.env != nil {
+		i.env.Cancel()
+	}
+}
+
+func (i *interrupt) err() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.reason
+}
+
+// noopCapture implements the vm.Tracer callbacks a tracer is not interested in.
+type noopCapture struct{}
+
+func (noopCapture) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+func (noopCapture) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (noopCapture) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (noopCapture) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (noopCapture) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (noopCapture) CaptureTxStart(gasLimit uint64) {}
+
+func (noopCapture) CaptureTxEnd(restGas uint64) {}
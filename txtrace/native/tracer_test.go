@@ -0,0 +1,174 @@
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sender   = common.HexToAddress("0x1000")
+	caller   = common.HexToAddress("0x2000")
+	callee   = common.HexToAddress("0x3000")
+	gasPrice = big.NewInt(10)
+	gasLimit = uint64(100000)
+)
+
+// callerCode calls the callee with the 0xdeadbeef selector as input.
+func callerCode() []byte {
+	code := []byte{0x7f, 0xde, 0xad, 0xbe, 0xef} // PUSH32 selector
+	code = append(code, make([]byte, 28)...)     // (left-aligned)
+	code = append(code, 0x60, 0x00, 0x52)        // MSTORE at 0
+	code = append(code, 0x60, 0x00, 0x60, 0x00)  // retSize, retOffset
+	code = append(code, 0x60, 0x04, 0x60, 0x00)  // argsSize, argsOffset
+	code = append(code, 0x60, 0x00, 0x73)        // value, PUSH20
+	code = append(code, callee.Bytes()...)       // address
+	code = append(code, 0x5a, 0xf1, 0x00)        // GAS, CALL, STOP
+	return code
+}
+
+// calleeCode stores 0x42 into the calleeSlot.
+var calleeCode = []byte{0x60, 0x42, 0x60, 0x01, 0x55, 0x00}
+
+var calleeSlot = common.Hash{31: 0x01}
+
+func newTestState(t *testing.T) *state.StateDB {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	statedb.AddBalance(sender, big.NewInt(1e18))
+	statedb.SetNonce(sender, 7)
+	statedb.SetCode(caller, callerCode())
+	statedb.SetCode(callee, calleeCode)
+	statedb.SetState(callee, calleeSlot, common.Hash{31: 0x01})
+	return statedb
+}
+
+// applyTx executes a simplified transaction the same way as evmcore.StateTransition does.
+func applyTx(t *testing.T, tracer Tracer, statedb *state.StateDB, input []byte, value *big.Int) {
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(1),
+		Difficulty:  big.NewInt(0),
+		BaseFee:     big.NewInt(0),
+		GasLimit:    gasLimit,
+	}
+	txCtx := vm.TxContext{
+		Origin:   sender,
+		GasPrice: gasPrice,
+	}
+	env := vm.NewEVM(blockCtx, txCtx, statedb, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+
+	tracer.CaptureTxStart(gasLimit)
+	statedb.SubBalance(sender, new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice))
+	statedb.SetNonce(sender, statedb.GetNonce(sender)+1)
+	_, restGas, err := env.Call(vm.AccountRef(sender), caller, input, gasLimit, value)
+	require.NoError(t, err)
+	statedb.AddBalance(sender, new(big.Int).Mul(new(big.Int).SetUint64(restGas), gasPrice))
+	tracer.CaptureTxEnd(restGas)
+}
+
+func runTracer(t *testing.T, name string, cfg string, statedb *state.StateDB) json.RawMessage {
+	tracer, ok, err := New(name, nil, json.RawMessage(cfg))
+	require.True(t, ok)
+	require.NoError(t, err)
+	applyTx(t, tracer, statedb, []byte{0x01, 0x02, 0x03, 0x04, 0x05}, big.NewInt(3))
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	return res
+}
+
+func TestNew(t *testing.T) {
+	require := require.New(t)
+
+	_, ok, err := New("unknownTracer", nil, nil)
+	require.False(ok)
+	require.NoError(err)
+	require.False(Exists("unknownTracer"))
+
+	for _, name := range []string{"callTracer", "prestateTracer", "4byteTracer"} {
+		require.True(Exists(name))
+		_, ok, err = New(name, nil, nil)
+		require.True(ok)
+		require.NoError(err)
+	}
+
+	_, _, err = New("callTracer", nil, json.RawMessage(`{"onlyTopCall":"yes"}`))
+	require.Error(err)
+}
+
+func TestCallTracer(t *testing.T) {
+	require := require.New(t)
+
+	res := runTracer(t, "callTracer", "", newTestState(t))
+	var top callFrame
+	require.NoError(json.Unmarshal(res, &top))
+	require.Equal("CALL", top.Type)
+	require.Equal(sender, top.From)
+	require.Equal(caller, *top.To)
+	require.Equal(big.NewInt(3), top.Value.ToInt())
+	require.Equal(gasLimit, uint64(top.Gas))
+	require.NotZero(top.GasUsed)
+	require.Empty(top.Error)
+	require.Len(top.Calls, 1)
+
+	inner := top.Calls[0]
+	require.Equal("CALL", inner.Type)
+	require.Equal(caller, inner.From)
+	require.Equal(callee, *inner.To)
+	require.Equal([]byte{0xde, 0xad, 0xbe, 0xef}, []byte(inner.Input))
+	require.NotZero(inner.GasUsed)
+	require.Less(uint64(inner.GasUsed), uint64(top.GasUsed))
+
+	res = runTracer(t, "callTracer", `{"onlyTopCall":true}`, newTestState(t))
+	top = callFrame{}
+	require.NoError(json.Unmarshal(res, &top))
+	require.Empty(top.Calls)
+}
+
+func TestPrestateTracer(t *testing.T) {
+	require := require.New(t)
+
+	statedb := newTestState(t)
+	res := runTracer(t, "prestateTracer", "", statedb)
+	var pre map[common.Address]*account
+	require.NoError(json.Unmarshal(res, &pre))
+	require.Len(pre, 3)
+	require.Equal(big.NewInt(1e18), pre[sender].Balance.ToInt())
+	require.Equal(uint64(7), pre[sender].Nonce)
+	require.Equal(0, pre[caller].Balance.ToInt().Sign())
+	require.Equal(calleeCode, []byte(pre[callee].Code))
+	require.Equal(common.Hash{31: 0x01}, pre[callee].Storage[calleeSlot])
+
+	res = runTracer(t, "prestateTracer", `{"diffMode":true}`, newTestState(t))
+	var diff prestateDiff
+	require.NoError(json.Unmarshal(res, &diff))
+	require.Contains(diff.Pre, sender)
+	require.Contains(diff.Post, sender)
+	require.Equal(uint64(8), diff.Post[sender].Nonce)
+	require.Equal(big.NewInt(3), diff.Post[caller].Balance.ToInt())
+	require.Equal(common.Hash{31: 0x01}, diff.Pre[callee].Storage[calleeSlot])
+	require.Equal(common.Hash{31: 0x42}, diff.Post[callee].Storage[calleeSlot])
+	require.Nil(diff.Post[callee].Code, "unchanged code must be omitted")
+}
+
+func TestFourByteTracer(t *testing.T) {
+	require := require.New(t)
+
+	res := runTracer(t, "4byteTracer", "", newTestState(t))
+	var ids map[string]int
+	require.NoError(json.Unmarshal(res, &ids))
+	require.Equal(map[string]int{
+		"0x01020304-1": 1,
+		"0xdeadbeef-0": 1,
+	}, ids)
+}
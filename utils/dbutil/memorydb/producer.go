@@ -0,0 +1,119 @@
+package memorydb
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+)
+
+// Producer of in-memory DBs. Unlike the on-disk DBs, the data is lost once the process exits,
+// but it survives closing and reopening the DB, so a producer may be used in place of an on-disk one.
+// The DBs are freed when they are dropped or when the producer is closed.
+type Producer struct {
+	namespace string
+
+	mu  sync.Mutex
+	dbs map[string]*Store
+}
+
+var (
+	namespaces   = make(map[string]*Producer)
+	namespacesMu sync.Mutex
+)
+
+// NewProducer returns the producer of the in-memory DBs of the namespace.
+// The producers of the same namespace share the DBs, until the namespace is freed.
+// An empty namespace is unique, its DBs are freed with the producer.
+func NewProducer(namespace string) *Producer {
+	if namespace == "" {
+		return &Producer{
+			dbs: make(map[string]*Store),
+		}
+	}
+	namespacesMu.Lock()
+	defer namespacesMu.Unlock()
+	if p, ok := namespaces[namespace]; ok {
+		return p
+	}
+	p := &Producer{
+		namespace: namespace,
+		dbs:       make(map[string]*Store),
+	}
+	namespaces[namespace] = p
+	return p
+}
+
+// Names of existing databases.
+func (p *Producer) Names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.dbs))
+	for name := range p.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenDB or create db with name.
+func (p *Producer) OpenDB(name string) (kvdb.Store, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if db, ok := p.dbs[name]; ok {
+		return db, nil
+	}
+	db := newStore(func() {
+		p.mu.Lock()
+		delete(p.dbs, name)
+		empty := len(p.dbs) == 0
+		p.mu.Unlock()
+		if empty {
+			p.free()
+		}
+	})
+	p.dbs[name] = db
+	return db, nil
+}
+
+// Close drops all the DBs and frees the namespace, so the next producer of the namespace starts empty.
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	p.dbs = make(map[string]*Store)
+	p.mu.Unlock()
+	p.free()
+	return nil
+}
+
+// free removes the producer from the namespaces, a namespace without DBs isn't kept
+func (p *Producer) free() {
+	if p.namespace == "" {
+		return
+	}
+	namespacesMu.Lock()
+	defer namespacesMu.Unlock()
+	if namespaces[p.namespace] == p {
+		delete(namespaces, p.namespace)
+	}
+}
+
+// FreeNamespace drops the DBs of the namespace, if any.
+func FreeNamespace(namespace string) {
+	namespacesMu.Lock()
+	p, ok := namespaces[namespace]
+	namespacesMu.Unlock()
+	if ok {
+		_ = p.Close()
+	}
+}
+
+// Names of the DBs of the namespace, the namespace isn't created if it doesn't exist.
+func Names(namespace string) []string {
+	namespacesMu.Lock()
+	p, ok := namespaces[namespace]
+	namespacesMu.Unlock()
+	if !ok {
+		return nil
+	}
+	return p.Names()
+}
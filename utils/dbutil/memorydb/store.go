@@ -0,0 +1,82 @@
+package memorydb
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+// Store is an in-memory key-value store, which keeps the data after Close until it's dropped.
+type Store struct {
+	*memorydb.Database
+	onDrop func()
+}
+
+var _ kvdb.Store = (*Store)(nil)
+
+func newStore(onDrop func()) *Store {
+	return &Store{
+		Database: memorydb.New(),
+		onDrop:   onDrop,
+	}
+}
+
+// Close leaves the data in memory, so it's available after the DB is reopened.
+func (s *Store) Close() error {
+	return nil
+}
+
+// Drop whole database.
+func (s *Store) Drop() {
+	if s.onDrop != nil {
+		s.onDrop()
+	}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (s *Store) NewIterator(prefix []byte, start []byte) kvdb.Iterator {
+	return s.Database.NewIterator(prefix, start)
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (s *Store) NewBatch() kvdb.Batch {
+	return &batch{s.Database.NewBatch()}
+}
+
+// GetSnapshot returns a copy of the current DB state.
+func (s *Store) GetSnapshot() (kvdb.Snapshot, error) {
+	snap := memorydb.New()
+	it := s.Database.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if err := snap.Put(it.Key(), it.Value()); err != nil {
+			return nil, err
+		}
+	}
+	return &snapshot{snap}, it.Error()
+}
+
+type batch struct {
+	ethdb.Batch
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w kvdb.Writer) error {
+	return b.Batch.Replay(w)
+}
+
+type snapshot struct {
+	*memorydb.Database
+}
+
+func (s *snapshot) NewIterator(prefix []byte, start []byte) kvdb.Iterator {
+	return s.Database.NewIterator(prefix, start)
+}
+
+// Release releases associated resources.
+func (s *snapshot) Release() {
+	_ = s.Database.Close()
+}
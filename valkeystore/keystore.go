@@ -1,6 +1,8 @@
 package valkeystore
 
 import (
+	"errors"
+
 	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/valkeystore/encryption"
 )
@@ -17,3 +19,25 @@ type KeystoreI interface {
 	Unlocked(pubkey validatorpk.PubKey) bool
 	GetUnlocked(pubkey validatorpk.PubKey) (*encryption.PrivateKey, error)
 }
+
+// UnlockWithPasswords unlocks the key with the first matching password of the list.
+// An already unlocked key is left as is.
+func UnlockWithPasswords(ks KeystoreI, pubkey validatorpk.PubKey, passwords []string) error {
+	if ks.Unlocked(pubkey) {
+		return nil
+	}
+	if !ks.Has(pubkey) {
+		return ErrNotFound
+	}
+	if len(passwords) == 0 {
+		return errors.New("no validator passwords to unlock the key with")
+	}
+	var err error
+	for _, password := range passwords {
+		err = ks.Unlock(pubkey, password)
+		if err == nil || err == ErrAlreadyUnlocked {
+			return nil
+		}
+	}
+	return err
+}
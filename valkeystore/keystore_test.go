@@ -0,0 +1,22 @@
+package valkeystore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlockWithPasswords(t *testing.T) {
+	require := require.New(t)
+	keystore := NewDefaultMemKeystore()
+	require.NoError(keystore.Add(pubkey1, key1, "auth1"))
+
+	require.ErrorIs(UnlockWithPasswords(keystore, pubkey2, []string{"auth2"}), ErrNotFound)
+	require.Error(UnlockWithPasswords(keystore, pubkey1, nil))
+	require.Error(UnlockWithPasswords(keystore, pubkey1, []string{"auth2", "auth3"}))
+	require.False(keystore.Unlocked(pubkey1))
+
+	require.NoError(UnlockWithPasswords(keystore, pubkey1, []string{"auth2", "auth1"}))
+	require.True(keystore.Unlocked(pubkey1))
+	require.NoError(UnlockWithPasswords(keystore, pubkey1, nil))
+}
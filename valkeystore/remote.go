@@ -0,0 +1,385 @@
+package valkeystore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/valkeystore/encryption"
+)
+
+// Remote signer protocol.
+//
+// The node sends a POST request to <url>/sign with a JSON body:
+//
+//	{"pubkey": "0xc0...", "digest": "0x<32 bytes>", "nonce": "0x<32 random bytes>", "event": "0x<unsigned event>"}
+//
+// The event field is optional and carries the binary-encoded event when an event is signed,
+// so that the signer may apply its own slashing protection. A signer which does so refuses
+// digests without the event, and events whose hash to sign doesn't match the digest.
+//
+// and the signer replies with status 200 and a JSON body:
+//
+//	{"signature": "0x<64 bytes R||S>"}
+//
+// or with a non-200 status and {"error": "<message>"}.
+//
+// A signer with slashing protection never signs another event in place of a signed one, even if the
+// signed event isn't published. A node which failed to publish a signed event requests the same event
+// again, and the signer replies with the signature of the last event signed for the validator.
+//
+// Both sides prove the knowledge of a shared secret by the RemoteSignerAuthHeader header:
+//   - the request header is hex(HMAC-SHA256(secret, request body)),
+//   - the response header is hex(HMAC-SHA256(secret, nonce || response body)),
+//     binding the response to the request nonce so that responses cannot be replayed.
+//
+// Over HTTPS the signer may additionally require a TLS client certificate.
+// The returned signature is verified against the pubkey before it is used.
+
+// RemoteSignerAuthHeader is the HTTP header carrying the HMAC of the message.
+const RemoteSignerAuthHeader = "X-Signer-Auth"
+
+var (
+	ErrRemoteSignerAuth      = errors.New("remote signer authentication failed")
+	ErrRemoteSignerSignature = errors.New("remote signer returned invalid signature")
+)
+
+type remoteSignRequest struct {
+	PubKey string        `json:"pubkey"`
+	Digest hexutil.Bytes `json:"digest"`
+	Nonce  hexutil.Bytes `json:"nonce"`
+	Event  hexutil.Bytes `json:"event,omitempty"`
+}
+
+// maxRemoteSignRequestSize limits the request body, which may contain a whole event.
+const maxRemoteSignRequestSize = 4 * 1024 * 1024
+
+type remoteSignResponse struct {
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// RemoteSignerConfig is a configuration of the remote signer client.
+type RemoteSignerConfig struct {
+	// URL of the signer: http://host:port, https://host:port or unix:///path/to/socket
+	URL string
+	// Secret shared with the signer for the mutual authentication
+	Secret []byte
+	// TLS client certificate and key, optional
+	TLSCert, TLSKey string
+	// TLS CA certificate to verify the signer with, system CAs are used if empty
+	TLSCA   string
+	Timeout time.Duration
+}
+
+// RemoteSigner is a SignerI which forwards the digests to an external signer.
+type RemoteSigner struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewRemoteSigner creates a remote signer client.
+func NewRemoteSigner(cfg RemoteSignerConfig) (*RemoteSigner, error) {
+	if len(cfg.Secret) == 0 {
+		return nil, errors.New("remote signer secret is not set")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote signer URL: %w", err)
+	}
+	transport := &http.Transport{}
+	signerURL := strings.TrimSuffix(cfg.URL, "/")
+	switch u.Scheme {
+	case "http":
+	case "https":
+		tlsConfig, err := makeSignerClientTLS(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		signerURL = "http://signer"
+	default:
+		return nil, fmt.Errorf("unsupported remote signer URL scheme %q", u.Scheme)
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	return &RemoteSigner{
+		url:    signerURL,
+		secret: cfg.Secret,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}, nil
+}
+
+func makeSignerClientTLS(cfg RemoteSignerConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSCert != "" || cfg.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load remote signer client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.TLSCA != "" {
+		pool, err := LoadCertPool(cfg.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// LoadCertPool reads PEM certificates from the file.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+func signerMAC(secret []byte, msg ...[]byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	for _, m := range msg {
+		mac.Write(m)
+	}
+	return mac.Sum(nil)
+}
+
+func checkSignerMAC(secret []byte, header string, msg ...[]byte) bool {
+	got, err := hexutil.Decode(header)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, signerMAC(secret, msg...))
+}
+
+func (s *RemoteSigner) Sign(pubkey validatorpk.PubKey, digest []byte) ([]byte, error) {
+	return s.sign(pubkey, digest, nil)
+}
+
+// SignEvent sends the event along with its hash to sign, so the signer can check it for double signing.
+func (s *RemoteSigner) SignEvent(pubkey validatorpk.PubKey, e *inter.EventPayload) ([]byte, error) {
+	raw, err := e.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return s.sign(pubkey, e.HashToSign().Bytes(), raw)
+}
+
+func (s *RemoteSigner) sign(pubkey validatorpk.PubKey, digest []byte, event []byte) ([]byte, error) {
+	if pubkey.Type != validatorpk.Types.Secp256k1 {
+		return nil, encryption.ErrNotSupportedType
+	}
+	res, err := s.request("/sign", pubkey, digest, event)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Signature) != 64 || !crypto.VerifySignature(pubkey.Raw, digest, res.Signature) {
+		return nil, ErrRemoteSignerSignature
+	}
+	return res.Signature, nil
+}
+
+func (s *RemoteSigner) request(path string, pubkey validatorpk.PubKey, digest []byte, event []byte) (*remoteSignResponse, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(remoteSignRequest{
+		PubKey: pubkey.String(),
+		Digest: digest,
+		Nonce:  nonce,
+		Event:  event,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RemoteSignerAuthHeader, hexutil.Encode(signerMAC(s.secret, body)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote signer response: %w", err)
+	}
+	if !checkSignerMAC(s.secret, resp.Header.Get(RemoteSignerAuthHeader), nonce, respBody) {
+		return nil, fmt.Errorf("%w (status %d)", ErrRemoteSignerAuth, resp.StatusCode)
+	}
+	var res remoteSignResponse
+	if err := json.Unmarshal(respBody, &res); err != nil {
+		return nil, fmt.Errorf("failed to decode remote signer response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer error: %s (status %d)", res.Error, resp.StatusCode)
+	}
+	return &res, nil
+}
+
+// RemoteSignerHandler serves the remote signer protocol on top of a local signer.
+type RemoteSignerHandler struct {
+	signer     SignerI
+	secret     []byte
+	checkEvent EventCheck
+
+	// the last signed event of every validator, which is signed again if requested
+	mu     sync.Mutex
+	signed map[idx.ValidatorID]signedEvent
+}
+
+// EventCheck checks and records the event before it gets signed.
+type EventCheck func(e *inter.EventPayload) error
+
+type signedEvent struct {
+	pubkey string
+	id     hash.Event
+	sig    []byte
+}
+
+// NewRemoteSignerHandler creates the remote signer protocol handler.
+func NewRemoteSignerHandler(signer SignerI, secret []byte) *RemoteSignerHandler {
+	return &RemoteSignerHandler{
+		signer: signer,
+		secret: secret,
+		signed: make(map[idx.ValidatorID]signedEvent),
+	}
+}
+
+// SetEventCheck makes the handler sign only events which pass the check, e.g. a slashing-protection check.
+// Requests without an event are refused afterwards.
+func (h *RemoteSignerHandler) SetEventCheck(check EventCheck) {
+	h.checkEvent = check
+}
+
+// decodeEvent checks that the digest is the hash to sign of the request event.
+func (h *RemoteSignerHandler) decodeEvent(req *remoteSignRequest) (*inter.EventPayload, error) {
+	if len(req.Event) == 0 {
+		return nil, errors.New("signing without an event is not allowed")
+	}
+	e := new(inter.EventPayload)
+	if err := e.UnmarshalBinary(req.Event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	if inter.CalcPayloadHash(e) != e.PayloadHash() {
+		return nil, errors.New("event payload hash mismatch")
+	}
+	if !bytes.Equal(e.HashToSign().Bytes(), req.Digest) {
+		return nil, errors.New("digest doesn't match the event")
+	}
+	return e, nil
+}
+
+// sign checks the request event, if the check is set, and signs the digest.
+func (h *RemoteSignerHandler) sign(pubkey validatorpk.PubKey, req *remoteSignRequest) ([]byte, error) {
+	if h.checkEvent == nil {
+		return h.signer.Sign(pubkey, req.Digest)
+	}
+	e, err := h.decodeEvent(req)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if last, ok := h.signed[e.Creator()]; ok && last.id == e.ID() && last.pubkey == req.PubKey {
+		// the node retries the event which it failed to publish
+		return last.sig, nil
+	}
+	if err := h.checkEvent(e); err != nil {
+		return nil, err
+	}
+	sig, err := h.signer.Sign(pubkey, req.Digest)
+	if err != nil {
+		return nil, err
+	}
+	h.signed[e.Creator()] = signedEvent{
+		pubkey: req.PubKey,
+		id:     e.ID(),
+		sig:    sig,
+	}
+	return sig, nil
+}
+
+func (h *RemoteSignerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/sign" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRemoteSignRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !checkSignerMAC(h.secret, r.Header.Get(RemoteSignerAuthHeader), body) {
+		// nonce isn't trusted, so the response isn't authenticated
+		http.Error(w, ErrRemoteSignerAuth.Error(), http.StatusUnauthorized)
+		return
+	}
+	var req remoteSignRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	var res remoteSignResponse
+	if pubkey, err := validatorpk.FromString(req.PubKey); err != nil {
+		status, res.Error = http.StatusBadRequest, err.Error()
+	} else if len(req.Digest) != 32 {
+		status, res.Error = http.StatusBadRequest, "digest must be 32 bytes"
+	} else if res.Signature, err = h.sign(pubkey, &req); err != nil {
+		status, res.Error = http.StatusForbidden, err.Error()
+	}
+
+	respBody, _ := json.Marshal(res)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(RemoteSignerAuthHeader, hexutil.Encode(signerMAC(h.secret, req.Nonce, respBody)))
+	w.WriteHeader(status)
+	_, _ = w.Write(respBody)
+}
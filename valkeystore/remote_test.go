@@ -0,0 +1,165 @@
+package valkeystore
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/valkeystore/slashing"
+)
+
+func newTestRemoteSignerHandler(t *testing.T, secret []byte) *RemoteSignerHandler {
+	keystore := NewDefaultMemKeystore()
+	require.NoError(t, keystore.Add(pubkey1, key1, "auth1"))
+	require.NoError(t, keystore.Unlock(pubkey1, "auth1"))
+	return NewRemoteSignerHandler(NewSigner(keystore), secret)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	require := require.New(t)
+	secret := []byte("secret")
+	digest := crypto.Keccak256([]byte("event"))
+
+	server := httptest.NewServer(newTestRemoteSignerHandler(t, secret))
+	defer server.Close()
+
+	signer, err := NewRemoteSigner(RemoteSignerConfig{URL: server.URL, Secret: secret})
+	require.NoError(err)
+	sig, err := signer.Sign(pubkey1, digest)
+	require.NoError(err)
+	require.True(crypto.VerifySignature(pubkey1.Raw, digest, sig))
+
+	// key which is not unlocked at the signer
+	_, err = signer.Sign(pubkey2, digest)
+	require.ErrorContains(err, "status 403")
+
+	// signer doesn't accept the node
+	wrongSigner, err := NewRemoteSigner(RemoteSignerConfig{URL: server.URL, Secret: []byte("wrong")})
+	require.NoError(err)
+	_, err = wrongSigner.Sign(pubkey1, digest)
+	require.True(errors.Is(err, ErrRemoteSignerAuth))
+
+	// node doesn't accept the signer
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"signature":"0x00"}`))
+	}))
+	defer fakeServer.Close()
+	signer, err = NewRemoteSigner(RemoteSignerConfig{URL: fakeServer.URL, Secret: secret})
+	require.NoError(err)
+	_, err = signer.Sign(pubkey1, digest)
+	require.True(errors.Is(err, ErrRemoteSignerAuth))
+}
+
+func TestRemoteSignerUnixSocket(t *testing.T) {
+	require := require.New(t)
+	secret := []byte("secret")
+	digest := crypto.Keccak256([]byte("vote"))
+
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(err)
+	server := &http.Server{Handler: newTestRemoteSignerHandler(t, secret)}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	signer, err := NewRemoteSigner(RemoteSignerConfig{URL: "unix://" + socket, Secret: secret})
+	require.NoError(err)
+	sig, err := signer.Sign(pubkey1, digest)
+	require.NoError(err)
+	require.True(crypto.VerifySignature(pubkey1.Raw, digest, sig))
+}
+
+func newTestEvent(seq idx.Event, extra string) *inter.EventPayload {
+	me := &inter.MutableEventPayload{}
+	me.SetVersion(1)
+	me.SetEpoch(1)
+	me.SetSeq(seq)
+	me.SetLamport(idx.Lamport(seq))
+	me.SetExtra([]byte(extra))
+	me.SetPayloadHash(inter.CalcPayloadHash(me))
+	return me.Build()
+}
+
+func TestRemoteSignerEventCheck(t *testing.T) {
+	require := require.New(t)
+	secret := []byte("secret")
+
+	handler := newTestRemoteSignerHandler(t, secret)
+	signed := map[idx.Event]hash.Event{}
+	handler.SetEventCheck(func(e *inter.EventPayload) error {
+		if prev, ok := signed[e.Seq()]; ok && prev != e.ID() {
+			return errors.New("doublesign")
+		}
+		signed[e.Seq()] = e.ID()
+		return nil
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	signer, err := NewRemoteSigner(RemoteSignerConfig{URL: server.URL, Secret: secret})
+	require.NoError(err)
+
+	e := newTestEvent(1, "a")
+	sig, err := signer.SignEvent(pubkey1, e)
+	require.NoError(err)
+	require.True(crypto.VerifySignature(pubkey1.Raw, e.HashToSign().Bytes(), sig))
+
+	// conflicting event
+	_, err = signer.SignEvent(pubkey1, newTestEvent(1, "b"))
+	require.ErrorContains(err, "doublesign")
+	// digest without the event
+	_, err = signer.Sign(pubkey1, e.HashToSign().Bytes())
+	require.ErrorContains(err, "status 403")
+}
+
+func TestRemoteSignerSignAgain(t *testing.T) {
+	require := require.New(t)
+	secret := []byte("secret")
+
+	protection, err := slashing.Open(t.TempDir())
+	require.NoError(err)
+	defer protection.Close()
+	handler := newTestRemoteSignerHandler(t, secret)
+	handler.SetEventCheck(func(e *inter.EventPayload) error {
+		return protection.CheckAndRecordEvent(e)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	signer, err := NewRemoteSigner(RemoteSignerConfig{URL: server.URL, Secret: secret})
+	require.NoError(err)
+
+	e1 := newTestEvent(1, "a")
+	sig1, err := signer.SignEvent(pubkey1, e1)
+	require.NoError(err)
+
+	// the node failed to publish the signed event, and retries the same event
+	sig, err := signer.SignEvent(pubkey1, e1)
+	require.NoError(err)
+	require.Equal(sig1, sig)
+
+	// no other event is signed in its place
+	_, err = signer.SignEvent(pubkey1, newTestEvent(1, "b"))
+	require.ErrorContains(err, "status 403")
+	_, err = signer.SignEvent(pubkey1, newTestEvent(2, "c"))
+	require.NoError(err)
+
+	last, err := protection.LastEvent(0)
+	require.NoError(err)
+	require.Equal(newTestEvent(2, "c").ID(), *last)
+
+	// there is no way to remove the records
+	res, err := http.Post(server.URL+"/release", "application/json", nil)
+	require.NoError(err)
+	require.NoError(res.Body.Close())
+	require.Equal(http.StatusNotFound, res.StatusCode)
+}
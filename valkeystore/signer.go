@@ -5,6 +5,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/crypto"
 
+	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/valkeystore/encryption"
 )
@@ -13,6 +14,12 @@ type SignerI interface {
 	Sign(pubkey validatorpk.PubKey, digest []byte) ([]byte, error)
 }
 
+// EventSignerI is implemented by signers which need the whole event rather than its digest,
+// e.g. to apply their own slashing protection.
+type EventSignerI interface {
+	SignEvent(pubkey validatorpk.PubKey, e *inter.EventPayload) ([]byte, error)
+}
+
 type Signer struct {
 	backend KeystoreI
 }
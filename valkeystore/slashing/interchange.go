@@ -0,0 +1,71 @@
+package slashing
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+)
+
+// Interchange is the JSON format to move the slashing-protection records between machines.
+type Interchange struct {
+	Events     []EventRecord     `json:"events"`
+	BlockVotes []BlockVoteRecord `json:"blockVotes"`
+	EpochVotes []EpochVoteRecord `json:"epochVotes"`
+}
+
+// Export writes all the records of the store into w as JSON.
+func (s *Store) Export(w io.Writer) error {
+	ic := Interchange{
+		Events:     []EventRecord{},
+		BlockVotes: []BlockVoteRecord{},
+		EpochVotes: []EpochVoteRecord{},
+	}
+	err := s.ForEach(func(r EventRecord) {
+		ic.Events = append(ic.Events, r)
+	}, func(r BlockVoteRecord) {
+		ic.BlockVotes = append(ic.BlockVotes, r)
+	}, func(r EpochVoteRecord) {
+		ic.EpochVotes = append(ic.EpochVotes, r)
+	})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ic)
+}
+
+// ImportJSON reads the records exported by Export from r and merges them into the store.
+// Nothing is imported if any record conflicts with the store.
+func (s *Store) ImportJSON(r io.Reader) (Interchange, error) {
+	var ic Interchange
+	if err := json.NewDecoder(r).Decode(&ic); err != nil {
+		return ic, err
+	}
+	return ic, s.Import(ic.Events, ic.BlockVotes, ic.EpochVotes)
+}
+
+type eventRecordJSON EventRecord
+
+// MarshalJSON encodes the event ID as hex.
+func (r EventRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		eventRecordJSON
+		ID hash.Hash `json:"id"`
+	}{eventRecordJSON(r), hash.Hash(r.ID)})
+}
+
+// UnmarshalJSON decodes the record encoded by MarshalJSON.
+func (r *EventRecord) UnmarshalJSON(b []byte) error {
+	var dec struct {
+		eventRecordJSON
+		ID hash.Hash `json:"id"`
+	}
+	if err := json.Unmarshal(b, &dec); err != nil {
+		return err
+	}
+	*r = EventRecord(dec.eventRecordJSON)
+	r.ID = hash.Event(dec.ID)
+	return nil
+}
@@ -0,0 +1,325 @@
+package slashing
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+var (
+	// ErrDoubleSign is returned if signing would conflict with a previously signed event or vote.
+	ErrDoubleSign = errors.New("slashing protection: conflicts with previously signed data")
+)
+
+const (
+	eventPrefix      = 'e' // validator, epoch, seq -> event ID, lamport
+	blockVotePrefix  = 'b' // validator, block -> epoch, record hash
+	epochVotePrefix  = 'v' // validator, epoch -> vote hash
+	validatorKeySize = 4
+)
+
+// Store is a slashing-protection database, which records every event and LLR vote signed by validators.
+// All the writes are synced to disk before they are acknowledged.
+type Store struct {
+	db *leveldb.DB
+	mu sync.Mutex
+}
+
+// EventRecord is a signed event.
+type EventRecord struct {
+	Validator idx.ValidatorID `json:"validator"`
+	Epoch     idx.Epoch       `json:"epoch"`
+	Seq       idx.Event       `json:"seq"`
+	Lamport   idx.Lamport     `json:"lamport"`
+	ID        hash.Event      `json:"-"`
+}
+
+// BlockVoteRecord is a signed LLR block vote.
+type BlockVoteRecord struct {
+	Validator idx.ValidatorID `json:"validator"`
+	Block     idx.Block       `json:"block"`
+	Epoch     idx.Epoch       `json:"epoch"`
+	Record    hash.Hash       `json:"record"`
+}
+
+// EpochVoteRecord is a signed LLR epoch vote.
+type EpochVoteRecord struct {
+	Validator idx.ValidatorID `json:"validator"`
+	Epoch     idx.Epoch       `json:"epoch"`
+	Vote      hash.Hash       `json:"vote"`
+}
+
+// Open opens or creates the store in the directory.
+func Open(dir string) (*Store, error) {
+	db, err := leveldb.OpenFile(dir, &opt.Options{
+		OpenFilesCacheCapacity: 16,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open slashing-protection DB %s: %w", dir, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func key(prefix byte, validator idx.ValidatorID, parts ...[]byte) []byte {
+	k := append([]byte{prefix}, validator.Bytes()...)
+	for _, p := range parts {
+		k = append(k, p...)
+	}
+	return k
+}
+
+func (s *Store) get(k []byte) ([]byte, error) {
+	v, err := s.db.Get(k, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return v, err
+}
+
+// last returns the greatest key and its value with the prefix.
+func (s *Store) last(prefix []byte) ([]byte, []byte, error) {
+	it := s.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer it.Release()
+	if !it.Last() {
+		return nil, nil, it.Error()
+	}
+	return append([]byte{}, it.Key()...), append([]byte{}, it.Value()...), nil
+}
+
+func eventRecordKey(r EventRecord) []byte {
+	return key(eventPrefix, r.Validator, r.Epoch.Bytes(), r.Seq.Bytes())
+}
+
+func eventRecordValue(r EventRecord) []byte {
+	return append(r.ID.Bytes(), r.Lamport.Bytes()...)
+}
+
+func blockVoteKey(r BlockVoteRecord) []byte {
+	return key(blockVotePrefix, r.Validator, r.Block.Bytes())
+}
+
+func blockVoteValue(r BlockVoteRecord) []byte {
+	return append(r.Epoch.Bytes(), r.Record.Bytes()...)
+}
+
+func epochVoteKey(r EpochVoteRecord) []byte {
+	return key(epochVotePrefix, r.Validator, r.Epoch.Bytes())
+}
+
+// checkEvent returns whether the event is already recorded, or an error if it conflicts with the recorded ones.
+func (s *Store) checkEvent(r EventRecord) (bool, error) {
+	prev, err := s.get(eventRecordKey(r))
+	if err != nil {
+		return false, err
+	}
+	if prev != nil {
+		if hash.BytesToEvent(prev[:32]) != r.ID {
+			return false, fmt.Errorf("%w: event %d:%d is already signed as %s", ErrDoubleSign, r.Epoch, r.Seq, hash.BytesToEvent(prev[:32]).String())
+		}
+		return true, nil
+	}
+	// events must be signed in the order of seq
+	lastKey, _, err := s.last(key(eventPrefix, r.Validator, r.Epoch.Bytes()))
+	if err != nil {
+		return false, err
+	}
+	if lastKey != nil {
+		if lastSeq := idx.BytesToEvent(lastKey[len(lastKey)-4:]); lastSeq >= r.Seq {
+			return false, fmt.Errorf("%w: event %d:%d is below the signed seq %d", ErrDoubleSign, r.Epoch, r.Seq, lastSeq)
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) checkBlockVote(r BlockVoteRecord) (bool, error) {
+	prev, err := s.get(blockVoteKey(r))
+	if err != nil || prev == nil {
+		return false, err
+	}
+	if idx.BytesToEpoch(prev[:4]) != r.Epoch || hash.BytesToHash(prev[4:]) != r.Record {
+		return false, fmt.Errorf("%w: block %d is already voted for %s", ErrDoubleSign, r.Block, hash.BytesToHash(prev[4:]).String())
+	}
+	return true, nil
+}
+
+func (s *Store) checkEpochVote(r EpochVoteRecord) (bool, error) {
+	prev, err := s.get(epochVoteKey(r))
+	if err != nil || prev == nil {
+		return false, err
+	}
+	if hash.BytesToHash(prev) != r.Vote {
+		return false, fmt.Errorf("%w: epoch %d is already voted for %s", ErrDoubleSign, r.Epoch, hash.BytesToHash(prev).String())
+	}
+	return true, nil
+}
+
+// EventRecords returns the records of the event and the LLR votes it contains.
+func EventRecords(e inter.EventPayloadI) (EventRecord, []BlockVoteRecord, *EpochVoteRecord) {
+	er := EventRecord{
+		Validator: e.Creator(),
+		Epoch:     e.Epoch(),
+		Seq:       e.Seq(),
+		Lamport:   e.Lamport(),
+		ID:        e.ID(),
+	}
+	bvs := e.BlockVotes()
+	bvRecords := make([]BlockVoteRecord, len(bvs.Votes))
+	for i, v := range bvs.Votes {
+		bvRecords[i] = BlockVoteRecord{
+			Validator: e.Creator(),
+			Block:     bvs.Start + idx.Block(i),
+			Epoch:     bvs.Epoch,
+			Record:    v,
+		}
+	}
+	var evRecord *EpochVoteRecord
+	if ev := e.EpochVote(); ev.Epoch != 0 {
+		evRecord = &EpochVoteRecord{
+			Validator: e.Creator(),
+			Epoch:     ev.Epoch,
+			Vote:      ev.Vote,
+		}
+	}
+	return er, bvRecords, evRecord
+}
+
+// CheckAndRecordEvent refuses the event if it or its LLR votes conflict with the previously signed ones,
+// and records them otherwise. Signing the same event again is allowed.
+// The records are never removed, even if the signed event isn't published.
+func (s *Store) CheckAndRecordEvent(e inter.EventPayloadI) error {
+	er, bvs, ev := EventRecords(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := new(leveldb.Batch)
+	if known, err := s.checkEvent(er); err != nil {
+		return err
+	} else if !known {
+		batch.Put(eventRecordKey(er), eventRecordValue(er))
+	}
+	for _, bv := range bvs {
+		if known, err := s.checkBlockVote(bv); err != nil {
+			return err
+		} else if !known {
+			batch.Put(blockVoteKey(bv), blockVoteValue(bv))
+		}
+	}
+	if ev != nil {
+		if known, err := s.checkEpochVote(*ev); err != nil {
+			return err
+		} else if !known {
+			batch.Put(epochVoteKey(*ev), ev.Vote.Bytes())
+		}
+	}
+	return s.db.Write(batch, &opt.WriteOptions{Sync: true})
+}
+
+// LastEvent returns ID of the last event signed by the validator.
+func (s *Store) LastEvent(validator idx.ValidatorID) (*hash.Event, error) {
+	_, v, err := s.last(key(eventPrefix, validator))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	id := hash.BytesToEvent(v[:32])
+	return &id, nil
+}
+
+// LastBlockVote returns the last block voted by the validator.
+func (s *Store) LastBlockVote(validator idx.ValidatorID) (*idx.Block, error) {
+	k, _, err := s.last(key(blockVotePrefix, validator))
+	if err != nil || k == nil {
+		return nil, err
+	}
+	b := idx.BytesToBlock(k[1+validatorKeySize:])
+	return &b, nil
+}
+
+// LastEpochVote returns the last epoch voted by the validator.
+func (s *Store) LastEpochVote(validator idx.ValidatorID) (*idx.Epoch, error) {
+	k, _, err := s.last(key(epochVotePrefix, validator))
+	if err != nil || k == nil {
+		return nil, err
+	}
+	e := idx.BytesToEpoch(k[1+validatorKeySize:])
+	return &e, nil
+}
+
+// ForEach iterates over all the records of the store.
+func (s *Store) ForEach(onEvent func(EventRecord), onBlockVote func(BlockVoteRecord), onEpochVote func(EpochVoteRecord)) error {
+	it := s.db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		k, v := it.Key(), it.Value()
+		validator := idx.BytesToValidatorID(k[1 : 1+validatorKeySize])
+		rest := k[1+validatorKeySize:]
+		switch k[0] {
+		case eventPrefix:
+			onEvent(EventRecord{
+				Validator: validator,
+				Epoch:     idx.BytesToEpoch(rest[:4]),
+				Seq:       idx.BytesToEvent(rest[4:8]),
+				ID:        hash.BytesToEvent(v[:32]),
+				Lamport:   idx.BytesToLamport(v[32:]),
+			})
+		case blockVotePrefix:
+			onBlockVote(BlockVoteRecord{
+				Validator: validator,
+				Block:     idx.BytesToBlock(rest),
+				Epoch:     idx.BytesToEpoch(v[:4]),
+				Record:    hash.BytesToHash(v[4:]),
+			})
+		case epochVotePrefix:
+			onEpochVote(EpochVoteRecord{
+				Validator: validator,
+				Epoch:     idx.BytesToEpoch(rest),
+				Vote:      hash.BytesToHash(v),
+			})
+		}
+	}
+	return it.Error()
+}
+
+// Import records the given records, refusing all of them if any conflicts with the store.
+func (s *Store) Import(events []EventRecord, blockVotes []BlockVoteRecord, epochVotes []EpochVoteRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch := new(leveldb.Batch)
+	for _, r := range events {
+		// imported events may come in any order, so only the same position is checked
+		prev, err := s.get(eventRecordKey(r))
+		if err != nil {
+			return err
+		}
+		if prev != nil && hash.BytesToEvent(prev[:32]) != r.ID {
+			return fmt.Errorf("%w: event %d:%d of validator %d", ErrDoubleSign, r.Epoch, r.Seq, r.Validator)
+		}
+		batch.Put(eventRecordKey(r), eventRecordValue(r))
+	}
+	for _, r := range blockVotes {
+		if _, err := s.checkBlockVote(r); err != nil {
+			return err
+		}
+		batch.Put(blockVoteKey(r), blockVoteValue(r))
+	}
+	for _, r := range epochVotes {
+		if _, err := s.checkEpochVote(r); err != nil {
+			return err
+		}
+		batch.Put(epochVoteKey(r), r.Vote.Bytes())
+	}
+	return s.db.Write(batch, &opt.WriteOptions{Sync: true})
+}
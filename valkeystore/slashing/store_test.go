@@ -0,0 +1,130 @@
+package slashing
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+func testEvent(epoch idx.Epoch, seq idx.Event, lamport idx.Lamport, extra string, bvs inter.LlrBlockVotes, ev inter.LlrEpochVote) *inter.EventPayload {
+	me := &inter.MutableEventPayload{}
+	me.SetVersion(1)
+	me.SetCreator(1)
+	me.SetEpoch(epoch)
+	me.SetSeq(seq)
+	me.SetLamport(lamport)
+	me.SetExtra([]byte(extra))
+	if bvs.Votes == nil {
+		bvs.Votes = []hash.Hash{}
+	}
+	me.SetBlockVotes(bvs)
+	me.SetEpochVote(ev)
+	me.SetPayloadHash(inter.CalcPayloadHash(me))
+	return me.Build()
+}
+
+func TestStore_CheckAndRecordEvent(t *testing.T) {
+	require := require.New(t)
+	s, err := Open(t.TempDir())
+	require.NoError(err)
+	defer s.Close()
+
+	e1 := testEvent(2, 1, 1, "a", inter.LlrBlockVotes{Start: 10, Epoch: 1, Votes: []hash.Hash{{1}, {2}}}, inter.LlrEpochVote{Epoch: 1, Vote: hash.Hash{3}})
+	err = s.CheckAndRecordEvent(e1)
+	require.NoError(err)
+	// signing the same event again is allowed
+	err = s.CheckAndRecordEvent(e1)
+	require.NoError(err)
+
+	// same seq, different event
+	err = s.CheckAndRecordEvent(testEvent(2, 1, 1, "b", inter.LlrBlockVotes{}, inter.LlrEpochVote{}))
+	require.True(errors.Is(err, ErrDoubleSign))
+
+	e2 := testEvent(2, 2, 2, "a", inter.LlrBlockVotes{}, inter.LlrEpochVote{})
+	err = s.CheckAndRecordEvent(e2)
+	require.NoError(err)
+	// lower seq which isn't recorded
+	err = s.CheckAndRecordEvent(testEvent(2, 1, 5, "c", inter.LlrBlockVotes{}, inter.LlrEpochVote{}))
+	require.True(errors.Is(err, ErrDoubleSign))
+
+	// conflicting block vote
+	err = s.CheckAndRecordEvent(testEvent(2, 3, 3, "a", inter.LlrBlockVotes{Start: 11, Epoch: 1, Votes: []hash.Hash{{9}}}, inter.LlrEpochVote{}))
+	require.True(errors.Is(err, ErrDoubleSign))
+	// conflicting epoch vote
+	err = s.CheckAndRecordEvent(testEvent(2, 3, 3, "a", inter.LlrBlockVotes{}, inter.LlrEpochVote{Epoch: 1, Vote: hash.Hash{9}}))
+	require.True(errors.Is(err, ErrDoubleSign))
+
+	// nothing was recorded by the refused events
+	last, err := s.LastEvent(1)
+	require.NoError(err)
+	require.Equal(e2.ID(), *last)
+
+	// the votes are recorded along with the event
+	e3 := testEvent(2, 3, 3, "a", inter.LlrBlockVotes{Start: 11, Epoch: 1, Votes: []hash.Hash{{2}, {4}}}, inter.LlrEpochVote{})
+	err = s.CheckAndRecordEvent(e3)
+	require.NoError(err)
+	lastBlock, err := s.LastBlockVote(1)
+	require.NoError(err)
+	require.Equal(idx.Block(12), *lastBlock)
+	last, err = s.LastEvent(1)
+	require.NoError(err)
+	require.Equal(e3.ID(), *last)
+	lastEpoch, err := s.LastEpochVote(1)
+	require.NoError(err)
+	require.Equal(idx.Epoch(1), *lastEpoch)
+
+	// new epoch starts from seq 1
+	err = s.CheckAndRecordEvent(testEvent(3, 1, 10, "a", inter.LlrBlockVotes{}, inter.LlrEpochVote{}))
+	require.NoError(err)
+	// other validators are independent
+	last, err = s.LastEvent(2)
+	require.NoError(err)
+	require.Nil(last)
+}
+
+func TestStore_ExportImport(t *testing.T) {
+	require := require.New(t)
+	src, err := Open(t.TempDir())
+	require.NoError(err)
+	defer src.Close()
+
+	e1 := testEvent(2, 1, 1, "a", inter.LlrBlockVotes{Start: 10, Epoch: 1, Votes: []hash.Hash{{1}}}, inter.LlrEpochVote{Epoch: 1, Vote: hash.Hash{3}})
+	err = src.CheckAndRecordEvent(e1)
+	require.NoError(err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(src.Export(buf))
+
+	dst, err := Open(t.TempDir())
+	require.NoError(err)
+	defer dst.Close()
+	ic, err := dst.ImportJSON(bytes.NewReader(buf.Bytes()))
+	require.NoError(err)
+	require.Len(ic.Events, 1)
+	require.Len(ic.BlockVotes, 1)
+	require.Len(ic.EpochVotes, 1)
+
+	// imported records protect from double signing
+	err = dst.CheckAndRecordEvent(testEvent(2, 1, 1, "b", inter.LlrBlockVotes{}, inter.LlrEpochVote{}))
+	require.True(errors.Is(err, ErrDoubleSign))
+	err = dst.CheckAndRecordEvent(e1)
+	require.NoError(err)
+
+	// conflicting import is refused as a whole
+	conflicting, err := Open(t.TempDir())
+	require.NoError(err)
+	defer conflicting.Close()
+	err = conflicting.CheckAndRecordEvent(testEvent(2, 1, 1, "b", inter.LlrBlockVotes{}, inter.LlrEpochVote{}))
+	require.NoError(err)
+	_, err = conflicting.ImportJSON(bytes.NewReader(buf.Bytes()))
+	require.True(errors.Is(err, ErrDoubleSign))
+	lastEpoch, err := conflicting.LastEpochVote(1)
+	require.NoError(err)
+	require.Nil(lastEpoch)
+}